@@ -12,9 +12,9 @@ import (
 	"github.com/isak/restySched/internal/handler"
 	"github.com/isak/restySched/internal/logger"
 	"github.com/isak/restySched/internal/n8n"
-	"github.com/isak/restySched/internal/repository/mongodb"
 	"github.com/isak/restySched/internal/scheduler"
 	"github.com/isak/restySched/internal/service"
+	"github.com/isak/restySched/internal/storage"
 	"github.com/rs/zerolog/log"
 )
 
@@ -28,65 +28,71 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to load configuration")
 	}
 
-	// Initialize MongoDB database
-	db, err := mongodb.InitDB(cfg.MongoURI, cfg.MongoDatabase)
+	// Initialize repositories
+	store, err := storage.Open(context.Background(), cfg)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to initialize MongoDB")
+		log.Fatal().Err(err).Str("driver", cfg.StorageDriver).Msg("Failed to initialize storage")
 	}
 
-	log.Info().Str("database", cfg.MongoDatabase).Msg("Connected to MongoDB")
-
-	// Initialize repositories
-	employeeRepo := mongodb.NewEmployeeRepository(db)
-	scheduleRepo := mongodb.NewScheduleRepository(db)
-	companyRepo := mongodb.NewCompanyConfigRepository(db)
-
 	// Initialize n8n client
 	n8nClient := n8n.NewClient(cfg.N8NWebhookURL)
 
 	// Initialize services
-	employeeService := service.NewEmployeeService(employeeRepo)
-	scheduleService := service.NewScheduleService(scheduleRepo, employeeRepo, companyRepo, n8nClient)
+	auditService := service.NewAuditService(store.Audit, cfg.AuditRetention)
+	employeeService := service.NewEmployeeService(store.Employees, store.Availability, auditService)
+	scheduleService := service.NewScheduleService(store.Schedules, store.Employees, store.CompanyConfig, n8nClient, auditService)
+	calendarService := service.NewCalendarService(store.Employees, store.Schedules, store.CompanyConfig, auditService)
+	authService := service.NewAuthService(store.Users, store.Sessions, store.Employees)
+	swapService := service.NewSwapService(store.ShiftSwaps, store.Schedules, store.Employees, store.CompanyConfig, store.Notifications, auditService)
+	leaveService := service.NewLeaveService(store.LeaveRequests, store.Employees, store.Availability, store.Schedules, store.CompanyConfig, store.Notifications, auditService)
+	companyConfigService := service.NewCompanyConfigService(store.CompanyConfig, auditService)
+
+	// Create the first admin account of a new installation
+	if cfg.AdminEmail != "" && cfg.AdminPassword != "" {
+		created, err := authService.EnsureAdmin(context.Background(), cfg.AdminEmail, cfg.AdminPassword)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to create the admin account")
+		}
+		if created {
+			log.Info().Str("email", cfg.AdminEmail).Msg("Created the admin account")
+		}
+	}
+
+	// Delete audit entries past the retention; they are pruned daily from then on
+	if pruned, err := auditService.Prune(context.Background()); err != nil {
+		log.Error().Err(err).Msg("Failed to delete old audit entries")
+	} else if pruned > 0 {
+		log.Info().Int("entries", pruned).Msg("Deleted audit entries past the retention")
+	}
+
+	// Describe the API
+	apiDoc := handler.NewOpenAPIDocument()
+	openAPIHandler, err := handler.NewOpenAPIHandler(apiDoc)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to build the OpenAPI document")
+	}
 
 	// Initialize handlers
-	homeHandler := handler.NewHomeHandler()
-	employeeHandler := handler.NewEmployeeHandler(employeeService)
-	scheduleHandler := handler.NewScheduleHandler(scheduleService)
-	healthHandler := handler.NewHealthHandler(employeeRepo)
-	companyConfigHandler := handler.NewCompanyConfigHandler(companyRepo)
+	authHandler := handler.NewAuthHandler(authService, cfg.SecureCookies)
+	h := handlers{
+		home:          handler.NewHomeHandler(),
+		health:        handler.NewHealthHandler(store.Employees),
+		employee:      handler.NewEmployeeHandler(employeeService, store.CompanyConfig),
+		schedule:      handler.NewScheduleHandler(scheduleService),
+		calendar:      handler.NewCalendarHandler(calendarService),
+		companyConfig: handler.NewCompanyConfigHandler(companyConfigService),
+		api:           handler.NewAPIHandler(employeeService, scheduleService, calendarService, swapService, leaveService, auditService, companyConfigService),
+		openAPI:       openAPIHandler,
+		auth:          authHandler,
+		user:          handler.NewUserHandler(authService, employeeService),
+		swap:          handler.NewSwapHandler(swapService),
+		leave:         handler.NewLeaveHandler(leaveService, employeeService),
+		audit:         handler.NewAuditHandler(auditService, employeeService),
+	}
 
 	// Setup routes
 	mux := http.NewServeMux()
-
-	// Health check routes
-	mux.HandleFunc("GET /health", healthHandler.Health)
-	mux.HandleFunc("GET /health/ready", healthHandler.Ready)
-
-	// Home
-	mux.HandleFunc("GET /", homeHandler.Home)
-
-	// Employee routes
-	mux.HandleFunc("GET /employees", employeeHandler.ListEmployees)
-	mux.HandleFunc("GET /employees/new", employeeHandler.ShowNewForm)
-	mux.HandleFunc("GET /employees/{id}/edit", employeeHandler.ShowEditForm)
-	mux.HandleFunc("POST /employees", employeeHandler.CreateEmployee)
-	mux.HandleFunc("PUT /employees/{id}", employeeHandler.UpdateEmployee)
-	mux.HandleFunc("DELETE /employees/{id}", employeeHandler.DeleteEmployee)
-
-	// Employee availability routes
-	mux.HandleFunc("GET /employees/{id}/availability", employeeHandler.ShowAvailabilityManager)
-	mux.HandleFunc("POST /employees/{id}/availability", employeeHandler.AddAvailability)
-	mux.HandleFunc("DELETE /employees/{id}/availability/{index}", employeeHandler.DeleteAvailability)
-
-	// Schedule routes
-	mux.HandleFunc("GET /schedules", scheduleHandler.ListSchedules)
-	mux.HandleFunc("POST /schedules/generate", scheduleHandler.GenerateBiweeklySchedule)
-	mux.HandleFunc("POST /schedules/{id}/send", scheduleHandler.SendToN8N)
-	mux.HandleFunc("DELETE /schedules/{id}", scheduleHandler.DeleteSchedule)
-
-	// Company configuration routes
-	mux.HandleFunc("GET /config", companyConfigHandler.ShowConfig)
-	mux.HandleFunc("POST /api/company-config", companyConfigHandler.SaveConfig)
+	registerRoutes(mux, h)
 
 	// Initialize and start scheduler if enabled
 	var sched *scheduler.Scheduler
@@ -106,7 +112,7 @@ func main() {
 	// Setup HTTP server
 	server := &http.Server{
 		Addr:         ":" + cfg.ServerPort,
-		Handler:      mux,
+		Handler:      authHandler.RequireAccess(apiDoc, mux, handler.ValidateRequestBodies(apiDoc, mux)),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -139,5 +145,9 @@ func main() {
 		log.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
 
+	if err := store.Close(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to close storage")
+	}
+
 	log.Info().Msg("Server exited gracefully")
 }
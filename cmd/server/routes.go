@@ -0,0 +1,190 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/isak/restySched/internal/handler"
+)
+
+// handlers holds the handlers the routes dispatch to
+type handlers struct {
+	home          *handler.HomeHandler
+	health        *handler.HealthHandler
+	employee      *handler.EmployeeHandler
+	schedule      *handler.ScheduleHandler
+	calendar      *handler.CalendarHandler
+	companyConfig *handler.CompanyConfigHandler
+	api           *handler.APIHandler
+	openAPI       *handler.OpenAPIHandler
+	auth          *handler.AuthHandler
+	user          *handler.UserHandler
+	swap          *handler.SwapHandler
+	leave         *handler.LeaveHandler
+	audit         *handler.AuditHandler
+}
+
+// router is the part of http.ServeMux the routes are registered with
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// registerRoutes registers every route of the server. Each route must be
+// described in handler.NewOpenAPIDocument; TestRoutesMatchOpenAPIDocument
+// fails when the two drift apart.
+func registerRoutes(mux router, h handlers) {
+	// Health check routes
+	mux.HandleFunc("GET /health", h.health.Health)
+	mux.HandleFunc("GET /health/ready", h.health.Ready)
+
+	// Sign-in and account routes
+	mux.HandleFunc("GET /login", h.auth.ShowLogin)
+	mux.HandleFunc("POST /login", h.auth.Login)
+	mux.HandleFunc("POST /logout", h.auth.Logout)
+	mux.HandleFunc("GET /account", h.user.ShowAccount)
+	mux.HandleFunc("POST /account/password", h.user.ChangePassword)
+	mux.HandleFunc("GET /my/shifts", h.calendar.MyShifts)
+	mux.HandleFunc("GET /my/notifications", h.swap.ShowNotifications)
+
+	// Home
+	mux.HandleFunc("GET /", h.home.Home)
+
+	// Employee routes
+	mux.HandleFunc("GET /employees", h.employee.ListEmployees)
+	mux.HandleFunc("GET /employees/new", h.employee.ShowNewForm)
+	mux.HandleFunc("GET /employees/import", h.employee.ShowImportForm)
+	mux.HandleFunc("POST /employees/import", h.employee.ImportEmployees)
+	mux.HandleFunc("GET /employees/{id}/edit", h.employee.ShowEditForm)
+	mux.HandleFunc("POST /employees", h.employee.CreateEmployee)
+	mux.HandleFunc("PUT /employees/{id}", h.employee.UpdateEmployee)
+	mux.HandleFunc("DELETE /employees/{id}", h.employee.DeleteEmployee)
+
+	// Employee availability routes
+	mux.HandleFunc("GET /employees/{id}/availability", h.employee.ShowAvailabilityManager)
+	mux.HandleFunc("POST /employees/{id}/availability", h.employee.AddAvailability)
+	mux.HandleFunc("DELETE /employees/{id}/availability/{availabilityID}", h.employee.DeleteAvailability)
+	mux.HandleFunc("POST /employees/{id}/availability/{availabilityID}/approve", h.employee.ApproveAvailability)
+	mux.HandleFunc("POST /employees/{id}/availability/{availabilityID}/reject", h.employee.RejectAvailability)
+	mux.HandleFunc("GET /availability/pending", h.employee.ListPendingAvailability)
+
+	// Leave routes
+	mux.HandleFunc("GET /employees/{id}/leave", h.leave.ShowLeaveManager)
+	mux.HandleFunc("POST /employees/{id}/leave", h.leave.RequestLeave)
+	mux.HandleFunc("POST /employees/{id}/leave/{leaveID}/cancel", h.leave.CancelLeave)
+	mux.HandleFunc("GET /leave/pending", h.leave.ListPendingLeave)
+	mux.HandleFunc("POST /leave/{id}/approve", h.leave.ApproveLeave)
+	mux.HandleFunc("POST /leave/{id}/reject", h.leave.RejectLeave)
+
+	// Employee calendar feed routes
+	mux.HandleFunc("GET /employees/{id}/calendar", h.calendar.ShowFeed)
+	mux.HandleFunc("POST /employees/{id}/calendar/rotate", h.calendar.RotateFeed)
+	mux.HandleFunc("GET /calendar/{token}", h.calendar.EmployeeFeed)
+
+	// Schedule routes
+	mux.HandleFunc("GET /schedules", h.schedule.ListSchedules)
+	mux.HandleFunc("POST /schedules/generate", h.schedule.GenerateSchedule)
+	mux.HandleFunc("POST /schedules/{id}/approve", h.schedule.ApproveSchedule)
+	mux.HandleFunc("POST /schedules/{id}/publish", h.schedule.PublishSchedule)
+	mux.HandleFunc("POST /schedules/{id}/complete", h.schedule.CompleteSchedule)
+	mux.HandleFunc("POST /schedules/{id}/reopen", h.schedule.ReopenSchedule)
+	mux.HandleFunc("POST /schedules/{id}/archive", h.schedule.ArchiveSchedule)
+	mux.HandleFunc("POST /schedules/{id}/send", h.schedule.SendToN8N)
+	mux.HandleFunc("GET /schedules/{id}/assignments.csv", h.schedule.ExportAssignmentsCSV)
+	mux.HandleFunc("GET /schedules/{id}/hours.csv", h.schedule.ExportHoursCSV)
+	mux.HandleFunc("GET /schedules/{id}/export.xlsx", h.schedule.ExportXLSX)
+	mux.HandleFunc("GET /schedules/{id}/print", h.schedule.PrintSchedule)
+	mux.HandleFunc("POST /schedules/{id}/assignments", h.schedule.AddAssignment)
+	mux.HandleFunc("DELETE /schedules/{id}/assignments/{assignmentID}", h.schedule.RemoveAssignment)
+	mux.HandleFunc("POST /schedules/{id}/assignments/{assignmentID}/move", h.schedule.MoveAssignment)
+	mux.HandleFunc("POST /schedules/{id}/assignments/{assignmentID}/swap", h.schedule.SwapAssignments)
+	mux.HandleFunc("DELETE /schedules/{id}", h.schedule.DeleteSchedule)
+
+	// Shift swap routes
+	mux.HandleFunc("GET /swaps", h.swap.ShowBoard)
+	mux.HandleFunc("POST /swaps", h.swap.OfferShift)
+	mux.HandleFunc("POST /swaps/{id}/claim", h.swap.ClaimShift)
+	mux.HandleFunc("POST /swaps/{id}/cancel", h.swap.CancelOffer)
+	mux.HandleFunc("POST /swaps/{id}/approve", h.swap.ApproveSwap)
+	mux.HandleFunc("POST /swaps/{id}/reject", h.swap.RejectSwap)
+	mux.HandleFunc("GET /schedules/{id}/swaps", h.swap.ShowScheduleSwaps)
+
+	// User account routes
+	mux.HandleFunc("GET /users", h.user.ListUsers)
+	mux.HandleFunc("GET /users/new", h.user.ShowNewForm)
+	mux.HandleFunc("POST /users", h.user.CreateUser)
+	mux.HandleFunc("GET /users/{id}/edit", h.user.ShowEditForm)
+	mux.HandleFunc("PUT /users/{id}", h.user.UpdateUser)
+	mux.HandleFunc("DELETE /users/{id}", h.user.DeactivateUser)
+	mux.HandleFunc("POST /users/{id}/activate", h.user.ActivateUser)
+
+	// Company configuration routes
+	mux.HandleFunc("GET /config", h.companyConfig.ShowConfig)
+	mux.HandleFunc("POST /api/company-config", h.companyConfig.SaveConfig)
+
+	// Audit routes
+	mux.HandleFunc("GET /audit", h.audit.ShowAuditLog)
+	mux.HandleFunc("GET /employees/{id}/audit", h.audit.ShowEmployeeAudit)
+	mux.HandleFunc("GET /schedules/{id}/audit", h.audit.ShowScheduleAudit)
+	mux.HandleFunc("GET /config/audit", h.audit.ShowConfigAudit)
+
+	// API documentation routes
+	mux.HandleFunc("GET /api/openapi.json", h.openAPI.Spec)
+	mux.HandleFunc("GET /api/docs", h.openAPI.Docs)
+
+	// JSON API routes
+	mux.HandleFunc("POST /api/v1/auth/login", h.auth.APILogin)
+	mux.HandleFunc("POST /api/v1/auth/logout", h.auth.APILogout)
+	mux.HandleFunc("GET /api/v1/auth/me", h.auth.APIMe)
+	mux.HandleFunc("GET /api/v1/me/shifts", h.api.MyShifts)
+	mux.HandleFunc("GET /api/v1/me/hours", h.api.MyHours)
+	mux.HandleFunc("GET /api/v1/me/swaps", h.api.MySwaps)
+	mux.HandleFunc("GET /api/v1/me/notifications", h.api.MyNotifications)
+	mux.HandleFunc("POST /api/v1/me/notifications/read", h.api.MarkNotificationsRead)
+	mux.HandleFunc("GET /api/v1/employees", h.api.ListEmployees)
+	mux.HandleFunc("POST /api/v1/employees", h.api.CreateEmployee)
+	mux.HandleFunc("GET /api/v1/employees/{id}", h.api.GetEmployee)
+	mux.HandleFunc("PUT /api/v1/employees/{id}", h.api.UpdateEmployee)
+	mux.HandleFunc("DELETE /api/v1/employees/{id}", h.api.DeleteEmployee)
+	mux.HandleFunc("GET /api/v1/employees/{id}/availability", h.api.ListAvailability)
+	mux.HandleFunc("POST /api/v1/employees/{id}/availability", h.api.AddAvailability)
+	mux.HandleFunc("GET /api/v1/employees/{id}/availability/{availabilityID}", h.api.GetAvailability)
+	mux.HandleFunc("PUT /api/v1/employees/{id}/availability/{availabilityID}", h.api.UpdateAvailability)
+	mux.HandleFunc("DELETE /api/v1/employees/{id}/availability/{availabilityID}", h.api.DeleteAvailability)
+	mux.HandleFunc("POST /api/v1/employees/{id}/availability/{availabilityID}/approve", h.api.ApproveAvailability)
+	mux.HandleFunc("POST /api/v1/employees/{id}/availability/{availabilityID}/reject", h.api.RejectAvailability)
+	mux.HandleFunc("GET /api/v1/availability", h.api.ListAvailabilityInPeriod)
+	mux.HandleFunc("GET /api/v1/availability/pending", h.api.ListPendingAvailability)
+	mux.HandleFunc("GET /api/v1/employees/{id}/leave", h.api.ListLeave)
+	mux.HandleFunc("POST /api/v1/employees/{id}/leave", h.api.RequestLeave)
+	mux.HandleFunc("GET /api/v1/employees/{id}/leave/balances", h.api.LeaveBalances)
+	mux.HandleFunc("POST /api/v1/employees/{id}/leave/{leaveID}/cancel", h.api.CancelLeave)
+	mux.HandleFunc("GET /api/v1/leave/pending", h.api.ListPendingLeave)
+	mux.HandleFunc("GET /api/v1/leave/{id}", h.api.GetLeave)
+	mux.HandleFunc("POST /api/v1/leave/{id}/approve", h.api.ApproveLeave)
+	mux.HandleFunc("POST /api/v1/leave/{id}/reject", h.api.RejectLeave)
+	mux.HandleFunc("GET /api/v1/schedules", h.api.ListSchedules)
+	mux.HandleFunc("POST /api/v1/schedules", h.api.GenerateSchedule)
+	mux.HandleFunc("GET /api/v1/schedules/{id}", h.api.GetSchedule)
+	mux.HandleFunc("DELETE /api/v1/schedules/{id}", h.api.DeleteSchedule)
+	mux.HandleFunc("POST /api/v1/schedules/{id}/transitions", h.api.TransitionSchedule)
+	mux.HandleFunc("POST /api/v1/schedules/{id}/send", h.api.SendToN8N)
+	mux.HandleFunc("GET /api/v1/schedules/{id}/assignments", h.api.ListAssignments)
+	mux.HandleFunc("POST /api/v1/schedules/{id}/assignments", h.api.AddAssignment)
+	mux.HandleFunc("DELETE /api/v1/schedules/{id}/assignments/{assignmentID}", h.api.RemoveAssignment)
+	mux.HandleFunc("POST /api/v1/schedules/{id}/assignments/{assignmentID}/move", h.api.MoveAssignment)
+	mux.HandleFunc("POST /api/v1/schedules/{id}/assignments/{assignmentID}/swap", h.api.SwapAssignments)
+	mux.HandleFunc("GET /api/v1/schedules/{id}/swaps", h.api.ListScheduleSwaps)
+	mux.HandleFunc("GET /api/v1/swaps", h.api.ListShiftOffers)
+	mux.HandleFunc("POST /api/v1/swaps", h.api.OfferShift)
+	mux.HandleFunc("GET /api/v1/swaps/pending", h.api.ListPendingSwaps)
+	mux.HandleFunc("POST /api/v1/swaps/{id}/claim", h.api.ClaimShift)
+	mux.HandleFunc("POST /api/v1/swaps/{id}/cancel", h.api.CancelShiftOffer)
+	mux.HandleFunc("POST /api/v1/swaps/{id}/approve", h.api.ApproveSwap)
+	mux.HandleFunc("POST /api/v1/swaps/{id}/reject", h.api.RejectSwap)
+	mux.HandleFunc("GET /api/v1/company-config", h.api.GetCompanyConfig)
+	mux.HandleFunc("PUT /api/v1/company-config", h.api.UpdateCompanyConfig)
+	mux.HandleFunc("GET /api/v1/audit", h.api.ListAudit)
+	mux.HandleFunc("GET /api/v1/employees/{id}/audit", h.api.ListEmployeeAudit)
+	mux.HandleFunc("GET /api/v1/schedules/{id}/audit", h.api.ListScheduleAudit)
+	mux.HandleFunc("GET /api/v1/company-config/audit", h.api.ListCompanyConfigAudit)
+	mux.HandleFunc("GET /api/v1/", h.api.NotFound)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/isak/restySched/internal/handler"
+)
+
+// recordingMux registers routes on a real ServeMux, so that conflicting
+// patterns still panic, and records their patterns
+type recordingMux struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func (m *recordingMux) HandleFunc(pattern string, h func(http.ResponseWriter, *http.Request)) {
+	m.patterns = append(m.patterns, pattern)
+	m.ServeMux.HandleFunc(pattern, h)
+}
+
+func TestRoutesMatchOpenAPIDocument(t *testing.T) {
+	mux := &recordingMux{ServeMux: http.NewServeMux()}
+	registerRoutes(mux, handlers{})
+
+	registered := make(map[string]bool)
+	for _, pattern := range mux.patterns {
+		if registered[pattern] {
+			t.Errorf("route %q is registered twice", pattern)
+		}
+		registered[pattern] = true
+	}
+
+	described := make(map[string]bool)
+	for _, pattern := range handler.NewOpenAPIDocument().Routes() {
+		described[pattern] = true
+	}
+
+	var undocumented, unregistered []string
+	for pattern := range registered {
+		if !described[pattern] {
+			undocumented = append(undocumented, pattern)
+		}
+	}
+	for pattern := range described {
+		if !registered[pattern] {
+			unregistered = append(unregistered, pattern)
+		}
+	}
+	sort.Strings(undocumented)
+	sort.Strings(unregistered)
+
+	for _, pattern := range undocumented {
+		t.Errorf("route %q is registered but not described in the OpenAPI document", pattern)
+	}
+	for _, pattern := range unregistered {
+		t.Errorf("route %q is described in the OpenAPI document but not registered", pattern)
+	}
+}
@@ -0,0 +1,36 @@
+// Package auth carries the signed-in user of a request in its context, so that
+// handlers and templates can read it without depending on the middleware.
+package auth
+
+import (
+	"context"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+type contextKey struct{}
+
+// identity is the signed-in user of a request and their session's CSRF token
+type identity struct {
+	user      *domain.User
+	csrfToken string
+}
+
+// WithUser returns a copy of ctx carrying the signed-in user and the CSRF
+// token of their session
+func WithUser(ctx context.Context, user *domain.User, csrfToken string) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity{user: user, csrfToken: csrfToken})
+}
+
+// User returns the signed-in user, or nil if the request is anonymous
+func User(ctx context.Context) *domain.User {
+	id, _ := ctx.Value(contextKey{}).(identity)
+	return id.user
+}
+
+// CSRFToken returns the CSRF token of the signed-in user's session, or "" if
+// the request is anonymous
+func CSRFToken(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(identity)
+	return id.csrfToken
+}
@@ -3,16 +3,32 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Storage drivers
+const (
+	StorageDriverMongoDB  = "mongodb"
+	StorageDriverSQLite   = "sqlite"
+	StorageDriverPostgres = "postgres"
+	StorageDriverMemory   = "memory" // data is lost when the server stops
+)
+
 type Config struct {
 	ServerPort      string
+	StorageDriver   string
 	MongoURI        string
 	MongoDatabase   string
+	DatabaseURL     string // SQLite database file or PostgreSQL connection URL
 	N8NWebhookURL   string
 	EnableScheduler bool
+	AdminEmail      string
+	AdminPassword   string
+	SecureCookies   bool
+	AuditRetention  time.Duration // how long audit entries are kept; 0 keeps them forever
 }
 
 // Load loads configuration from environment variables
@@ -22,10 +38,25 @@ func Load() (*Config, error) {
 
 	config := &Config{
 		ServerPort:      getEnv("SERVER_PORT", "8080"),
+		StorageDriver:   getEnv("STORAGE_DRIVER", StorageDriverMongoDB),
 		MongoURI:        getEnv("MONGO_URI", "mongodb://localhost:27017"),
 		MongoDatabase:   getEnv("MONGO_DATABASE", "restysched"),
+		DatabaseURL:     getEnv("DATABASE_URL", ""),
 		N8NWebhookURL:   getEnv("N8N_WEBHOOK_URL", ""),
 		EnableScheduler: getEnv("ENABLE_SCHEDULER", "true") == "true",
+		AdminEmail:      getEnv("ADMIN_EMAIL", ""),
+		AdminPassword:   getEnv("ADMIN_PASSWORD", ""),
+		SecureCookies:   getEnv("SESSION_COOKIE_SECURE", "true") == "true",
+	}
+
+	retentionDays, err := strconv.Atoi(getEnv("AUDIT_RETENTION_DAYS", "365"))
+	if err != nil || retentionDays < 0 {
+		return nil, fmt.Errorf("AUDIT_RETENTION_DAYS must be a number of days, or 0 to keep the audit trail forever")
+	}
+	config.AuditRetention = time.Duration(retentionDays) * 24 * time.Hour
+
+	if config.StorageDriver == StorageDriverSQLite && config.DatabaseURL == "" {
+		config.DatabaseURL = "restysched.db"
 	}
 
 	if err := config.Validate(); err != nil {
@@ -37,11 +68,22 @@ func Load() (*Config, error) {
 
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
-	if c.MongoURI == "" {
-		return fmt.Errorf("MONGO_URI is required")
-	}
-	if c.MongoDatabase == "" {
-		return fmt.Errorf("MONGO_DATABASE is required")
+	switch c.StorageDriver {
+	case StorageDriverMongoDB:
+		if c.MongoURI == "" {
+			return fmt.Errorf("MONGO_URI is required")
+		}
+		if c.MongoDatabase == "" {
+			return fmt.Errorf("MONGO_DATABASE is required")
+		}
+	case StorageDriverSQLite, StorageDriverPostgres:
+		if c.DatabaseURL == "" {
+			return fmt.Errorf("DATABASE_URL is required for %s", c.StorageDriver)
+		}
+	case StorageDriverMemory:
+	default:
+		return fmt.Errorf("STORAGE_DRIVER must be one of %q, %q, %q or %q",
+			StorageDriverMongoDB, StorageDriverSQLite, StorageDriverPostgres, StorageDriverMemory)
 	}
 	// N8N_WEBHOOK_URL is optional - app can run without n8n integration
 	return nil
@@ -0,0 +1,263 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AuditEntry records one change to an employee, a schedule or the company
+// configuration: who made it, when, and how the record differs. Entries are
+// only ever appended, and deleted once they are older than the retention.
+type AuditEntry struct {
+	ID         string        `json:"id" bson:"id"`
+	Timestamp  time.Time     `json:"timestamp" bson:"timestamp"`
+	Actor      string        `json:"actor" bson:"actor"` // email of the signed-in user, or who acted without one, such as the scheduler
+	EntityType string        `json:"entity_type" bson:"entity_type"`
+	EntityID   string        `json:"entity_id" bson:"entity_id"`
+	EntityName string        `json:"entity_name,omitempty" bson:"entity_name,omitempty"` // how the record was known at the time, e.g. the employee's name
+	Action     string        `json:"action" bson:"action"`
+	Changes    []FieldChange `json:"changes,omitempty" bson:"changes,omitempty"` // Before is empty for added values and After for removed ones
+}
+
+// Audited entity types. Availability periods are audited as changes to their
+// employee.
+const (
+	AuditEntityEmployee      = "employee"
+	AuditEntitySchedule      = "schedule"
+	AuditEntityCompanyConfig = "company_config"
+)
+
+// AuditEntities returns the audited entity types
+func AuditEntities() []string {
+	return []string{AuditEntityEmployee, AuditEntitySchedule, AuditEntityCompanyConfig}
+}
+
+// Audit actions. Schedule lifecycle transitions are recorded by their
+// ScheduleAction.
+const (
+	AuditActionCreate     = "create"
+	AuditActionUpdate     = "update"
+	AuditActionDeactivate = "deactivate"
+	AuditActionDelete     = "delete"
+
+	AuditActionAddAvailability      = "add_availability"
+	AuditActionSubmitAvailability   = "submit_availability"
+	AuditActionUpdateAvailability   = "update_availability"
+	AuditActionRemoveAvailability   = "remove_availability"
+	AuditActionWithdrawAvailability = "withdraw_availability"
+	AuditActionApproveAvailability  = "approve_availability"
+	AuditActionRejectAvailability   = "reject_availability"
+
+	AuditActionGenerate         = "generate"
+	AuditActionAddAssignment    = "add_assignment"
+	AuditActionRemoveAssignment = "remove_assignment"
+	AuditActionMoveAssignment   = "move_assignment"
+	AuditActionSwapAssignments  = "swap_assignments"
+	AuditActionApproveSwap      = "approve_swap"
+	AuditActionSendToN8N        = "send_to_n8n"
+)
+
+// AuditActions returns every audit action
+func AuditActions() []string {
+	return []string{
+		AuditActionCreate, AuditActionUpdate, AuditActionDeactivate, AuditActionDelete,
+		AuditActionAddAvailability, AuditActionSubmitAvailability, AuditActionUpdateAvailability, AuditActionRemoveAvailability,
+		AuditActionWithdrawAvailability, AuditActionApproveAvailability, AuditActionRejectAvailability,
+		AuditActionGenerate, AuditActionAddAssignment, AuditActionRemoveAssignment, AuditActionMoveAssignment,
+		AuditActionSwapAssignments, AuditActionApproveSwap, AuditActionSendToN8N,
+		ScheduleActionApprove, ScheduleActionPublish, ScheduleActionComplete, ScheduleActionReopen, ScheduleActionArchive,
+	}
+}
+
+// AuditFilter selects audit entries. Empty fields match every entry.
+type AuditFilter struct {
+	EntityType string
+	EntityID   string
+	Actor      string
+	Action     string
+	Since      time.Time // entries at or after
+	Until      time.Time // entries before
+	Limit      int       // at most this many of the newest entries; 0 for all
+}
+
+// Matches reports whether the entry is selected by the filter, ignoring its limit
+func (f AuditFilter) Matches(entry AuditEntry) bool {
+	return (f.EntityType == "" || entry.EntityType == f.EntityType) &&
+		(f.EntityID == "" || entry.EntityID == f.EntityID) &&
+		(f.Actor == "" || entry.Actor == f.Actor) &&
+		(f.Action == "" || entry.Action == f.Action) &&
+		(f.Since.IsZero() || !entry.Timestamp.Before(f.Since)) &&
+		(f.Until.IsZero() || entry.Timestamp.Before(f.Until))
+}
+
+// DiffEmployees compares two versions of an employee for the audit trail.
+// Availability is audited period by period with DiffAvailability, and the
+// calendar token is a secret, so neither is compared.
+func DiffEmployees(before, after *Employee) []FieldChange {
+	return Diff(employeeFields(before), employeeFields(after))
+}
+
+func employeeFields(employee *Employee) *Employee {
+	if employee == nil {
+		return nil
+	}
+	fields := *employee
+	fields.Availability = nil
+	return &fields
+}
+
+// DiffCalendarToken describes a change of an employee's calendar feed token
+// for the audit trail without revealing either token
+func DiffCalendarToken(before, after string) []FieldChange {
+	if before == after {
+		return nil
+	}
+	redact := func(token, label string) string {
+		if token == "" {
+			return ""
+		}
+		return label
+	}
+	return []FieldChange{{
+		Field:  "calendar_token",
+		Before: redact(before, "(previous token, redacted)"),
+		After:  redact(after, "(new token, redacted)"),
+	}}
+}
+
+// DiffAvailability compares two versions of an availability period for the
+// audit trail of its employee, describing each version in one line. Either
+// version may be nil, for periods that were added or removed.
+func DiffAvailability(before, after *Availability) []FieldChange {
+	change := FieldChange{Field: "availability"}
+	if before != nil {
+		change.Before = describeAvailability(*before)
+	}
+	if after != nil {
+		change.After = describeAvailability(*after)
+	}
+	if change.Before == change.After {
+		return nil
+	}
+	return []FieldChange{change}
+}
+
+// describeAvailability describes an availability period, e.g. "unavailable
+// 2024-03-04 to 2024-03-08, morning, pending: dentist"
+func describeAvailability(a Availability) string {
+	var b strings.Builder
+	b.WriteString(a.Type + " " + a.StartDate.Format("2006-01-02"))
+	switch {
+	case a.OpenEnded():
+		b.WriteString(" onwards")
+	case !a.EndDate.Equal(a.StartDate):
+		b.WriteString(" to " + a.EndDate.Format("2006-01-02"))
+	}
+	if a.Recurrence != nil {
+		b.WriteString(", " + a.Recurrence.Describe())
+	}
+	if a.HasTimeWindow() {
+		b.WriteString(", " + a.StartTime + "-" + a.EndTime)
+	}
+	if len(a.ShiftTypes) > 0 {
+		b.WriteString(", " + strings.Join(a.ShiftTypes, ", "))
+	}
+	if !a.Approved() {
+		b.WriteString(", " + a.Status)
+	}
+	if a.Reason != "" {
+		b.WriteString(": " + a.Reason)
+	}
+	return b.String()
+}
+
+// DiffSchedules compares two versions of a schedule for the audit trail: its
+// period, status and delivery field by field, and its assignments one by one,
+// each described by employee, day and shift. Either version may be nil.
+func DiffSchedules(before, after *Schedule) []FieldChange {
+	changes := Diff(scheduleFieldsOf(before), scheduleFieldsOf(after))
+
+	type assignmentChange struct {
+		date   time.Time
+		change FieldChange
+	}
+	var assignments []assignmentChange
+
+	described := func(s *Schedule) map[string]ShiftAssignment {
+		byUID := make(map[string]ShiftAssignment)
+		if s != nil {
+			for _, a := range s.Assignments {
+				byUID[a.UID()] = a
+			}
+		}
+		return byUID
+	}
+	beforeAssignments, afterAssignments := described(before), described(after)
+
+	for uid, a := range beforeAssignments {
+		change := FieldChange{Field: "assignment", Before: describeAssignment(before, a)}
+		if moved, ok := afterAssignments[uid]; ok {
+			change.After = describeAssignment(after, moved)
+		}
+		if change.Before != change.After {
+			assignments = append(assignments, assignmentChange{date: a.Date, change: change})
+		}
+	}
+	for uid, a := range afterAssignments {
+		if _, ok := beforeAssignments[uid]; !ok {
+			assignments = append(assignments, assignmentChange{date: a.Date, change: FieldChange{Field: "assignment", After: describeAssignment(after, a)}})
+		}
+	}
+
+	slices.SortFunc(assignments, func(a, b assignmentChange) int {
+		return cmp.Or(a.date.Compare(b.date),
+			cmp.Compare(a.change.Before, b.change.Before),
+			cmp.Compare(a.change.After, b.change.After))
+	})
+	for _, a := range assignments {
+		changes = append(changes, a.change)
+	}
+	return changes
+}
+
+// scheduleFields are the fields of a schedule compared as a whole by DiffSchedules
+type scheduleFields struct {
+	PeriodStart string `json:"period_start"`
+	PeriodEnd   string `json:"period_end"`
+	Status      string `json:"status"`
+	Strategy    string `json:"strategy"`
+	SentToN8N   bool   `json:"sent_to_n8n"`
+}
+
+func scheduleFieldsOf(schedule *Schedule) *scheduleFields {
+	if schedule == nil {
+		return nil
+	}
+	loc := schedule.Location()
+	return &scheduleFields{
+		PeriodStart: schedule.PeriodStart.In(loc).Format("2006-01-02"),
+		PeriodEnd:   schedule.PeriodEnd.In(loc).Format("2006-01-02"),
+		Status:      schedule.LifecycleStatus(),
+		Strategy:    schedule.Strategy,
+		SentToN8N:   schedule.SentToN8N,
+	}
+}
+
+// describeAssignment describes a shift of a schedule, e.g. "Jane Doe, Morning
+// on Sat 2024-03-09"
+func describeAssignment(schedule *Schedule, a ShiftAssignment) string {
+	name := a.ShiftType
+	if def := schedule.ShiftDefinition(a.ShiftType); def != nil {
+		name = def.DisplayName()
+	}
+	return fmt.Sprintf("%s, %s on %s", a.EmployeeName, name, a.Date.In(schedule.Location()).Format("Mon 2006-01-02"))
+}
+
+// SchedulePeriodName names a schedule by its period for the audit trail
+func SchedulePeriodName(schedule *Schedule) string {
+	fields := scheduleFieldsOf(schedule)
+	return fields.PeriodStart + " to " + fields.PeriodEnd
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAuditFilterMatches(t *testing.T) {
+	at := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	entry := AuditEntry{Timestamp: at, Actor: "jane@example.com", EntityType: AuditEntitySchedule, EntityID: "s1", Action: AuditActionRemoveAssignment}
+
+	tests := []struct {
+		name   string
+		filter AuditFilter
+		want   bool
+	}{
+		{"empty", AuditFilter{}, true},
+		{"entity", AuditFilter{EntityType: AuditEntitySchedule, EntityID: "s1"}, true},
+		{"other entity", AuditFilter{EntityType: AuditEntitySchedule, EntityID: "s2"}, false},
+		{"actor", AuditFilter{Actor: "john@example.com"}, false},
+		{"action", AuditFilter{Action: AuditActionRemoveAssignment}, true},
+		{"since is inclusive", AuditFilter{Since: at}, true},
+		{"until is exclusive", AuditFilter{Until: at}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Matches(entry); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiffAvailability(t *testing.T) {
+	before := &Availability{
+		Type:      AvailabilityTypeUnavailable,
+		StartDate: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC),
+		Reason:    "dentist",
+		Status:    AvailabilityStatusPending,
+	}
+	after := *before
+	after.Status = AvailabilityStatusApproved
+
+	want := []FieldChange{{
+		Field:  "availability",
+		Before: "unavailable 2024-03-04 to 2024-03-08, pending: dentist",
+		After:  "unavailable 2024-03-04 to 2024-03-08: dentist",
+	}}
+	if got := DiffAvailability(before, &after); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffAvailability() = %+v, want %+v", got, want)
+	}
+	if got := DiffAvailability(before, before); len(got) != 0 {
+		t.Errorf("DiffAvailability() of equal periods = %+v, want none", got)
+	}
+}
+
+func TestDiffSchedules(t *testing.T) {
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	before := &Schedule{
+		PeriodStart: monday,
+		PeriodEnd:   monday.AddDate(0, 0, 4),
+		Status:      ScheduleStatusDraft,
+		Assignments: []ShiftAssignment{
+			{ID: "a1", EmployeeName: "John Doe", Date: monday, ShiftType: "custom"},
+			{ID: "a2", EmployeeName: "John Doe", Date: monday.AddDate(0, 0, 1), ShiftType: "custom"},
+		},
+	}
+	after := *before
+	after.Assignments = []ShiftAssignment{
+		{ID: "a2", EmployeeName: "Jane Smith", Date: monday.AddDate(0, 0, 1), ShiftType: "custom"},
+		{ID: "a3", EmployeeName: "John Doe", Date: monday.AddDate(0, 0, 2), ShiftType: "custom"},
+	}
+
+	want := []FieldChange{
+		{Field: "assignment", Before: "John Doe, custom on Mon 2024-03-04"},
+		{Field: "assignment", Before: "John Doe, custom on Tue 2024-03-05", After: "Jane Smith, custom on Tue 2024-03-05"},
+		{Field: "assignment", After: "John Doe, custom on Wed 2024-03-06"},
+	}
+	if got := DiffSchedules(before, &after); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffSchedules() = %+v, want %+v", got, want)
+	}
+}
@@ -2,35 +2,71 @@ package domain
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
 var (
-	ErrInvalidCompanyName        = errors.New("company name is required")
-	ErrInvalidWorkingHours       = errors.New("invalid working hours configuration")
-	ErrInvalidShiftRequirements  = errors.New("invalid shift requirements")
-	ErrCompanyConfigNotFound     = errors.New("company configuration not found")
+	ErrInvalidCompanyName         = errors.New("company name is required")
+	ErrInvalidWorkingHours        = errors.New("invalid working hours configuration")
+	ErrInvalidShiftRequirements   = errors.New("invalid shift requirements")
+	ErrCompanyConfigNotFound      = errors.New("company configuration not found")
 	ErrCompanyConfigAlreadyExists = errors.New("company configuration already exists")
+	ErrUnknownSchedulingStrategy  = errors.New("unknown scheduling strategy")
+	ErrInvalidShiftDefinition     = errors.New("invalid shift definition: each shift needs a unique name, HH:MM start and end times and a break shorter than the shift")
+	ErrShiftBreakTooLong          = fmt.Errorf("unpaid breaks cannot be longer than %d minutes; define each part of a split shift as a shift of its own", MaxUnpaidBreakMinutes)
+	ErrUnknownShiftType           = errors.New("shift requirement references a shift that is not defined")
 )
 
+// SchedulingStrategy constants
+const (
+	SchedulingStrategyGreedy      = "greedy"       // fast day-by-day assignment
+	SchedulingStrategyLocalSearch = "local_search" // optimises the weighted scheduling objective
+)
+
+// SchedulingStrategies returns all available scheduling strategies
+func SchedulingStrategies() []string {
+	return []string{SchedulingStrategyGreedy, SchedulingStrategyLocalSearch}
+}
+
+// IsValidSchedulingStrategy checks if name is a known scheduling strategy
+func IsValidSchedulingStrategy(name string) bool {
+	for _, s := range SchedulingStrategies() {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
 // CompanyConfig represents the company's scheduling configuration
 type CompanyConfig struct {
-	ID          string    `json:"id" bson:"_id,omitempty"`
-	CompanyName string    `json:"company_name" bson:"company_name"`
+	ID          string `json:"id" bson:"_id,omitempty"`
+	CompanyName string `json:"company_name" bson:"company_name"`
 
 	// Business hours and days
 	WorkingHours WorkingHours `json:"working_hours" bson:"working_hours"`
 
+	// Shifts the company runs; empty means the default definitions
+	ShiftDefinitions []ShiftDefinition `json:"shift_definitions,omitempty" bson:"shift_definitions"`
+
 	// Shift requirements
 	ShiftRequirements []ShiftRequirement `json:"shift_requirements" bson:"shift_requirements"`
 
 	// Scheduling policies
 	SchedulingPolicies SchedulingPolicies `json:"scheduling_policies" bson:"scheduling_policies"`
 
+	// Default scheduling strategy used when a generate request does not pick one
+	SchedulingStrategy string `json:"scheduling_strategy,omitempty" bson:"scheduling_strategy,omitempty"`
+
 	// AI Context - additional instructions for n8n AI agent
 	AIContext string `json:"ai_context" bson:"ai_context"`
 
 	// Metadata
+	Version   int       `json:"version" bson:"version"` // counts the saved changes; updates must name the version they change
 	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
 }
@@ -48,13 +84,76 @@ type WorkingHours struct {
 	Timezone string `json:"timezone" bson:"timezone"` // e.g., "Europe/Oslo"
 }
 
+// Location returns the configured timezone, falling back to UTC if it is empty or unknown
+func (w WorkingHours) Location() *time.Location {
+	if w.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// IsWorkingDay reports whether the company operates on the given weekday
+func (w WorkingHours) IsWorkingDay(day time.Weekday) bool {
+	for _, d := range w.WorkingDays {
+		if time.Weekday(d) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// GetShiftDefinitions returns the company's shift definitions, or the default
+// definitions if none are configured
+func (c *CompanyConfig) GetShiftDefinitions() []ShiftDefinition {
+	if c == nil || len(c.ShiftDefinitions) == 0 {
+		return GetShiftDefinitions()
+	}
+	return c.ShiftDefinitions
+}
+
+// GetShiftDefinition returns the company's definition of shiftType, or nil if it is not defined
+func (c *CompanyConfig) GetShiftDefinition(shiftType string) *ShiftDefinition {
+	return FindShiftDefinition(c.GetShiftDefinitions(), shiftType)
+}
+
 // ShiftRequirement defines what shifts are needed and how many employees
 type ShiftRequirement struct {
-	ShiftType         string `json:"shift_type" bson:"shift_type"` // morning, afternoon, evening, full_day, night
-	MinEmployees      int    `json:"min_employees" bson:"min_employees"`
-	MaxEmployees      int    `json:"max_employees" bson:"max_employees"`
-	RequiredSkills    []string `json:"required_skills,omitempty" bson:"required_skills,omitempty"`
-	Description       string `json:"description" bson:"description"`
+	ShiftType      string   `json:"shift_type" bson:"shift_type"` // type of a defined shift, e.g. morning
+	MinEmployees   int      `json:"min_employees" bson:"min_employees"`
+	MaxEmployees   int      `json:"max_employees" bson:"max_employees"`
+	RequiredSkills []string `json:"required_skills,omitempty" bson:"required_skills,omitempty"`
+	Description    string   `json:"description" bson:"description"`
+}
+
+// SkillNeed is a skill a shift must be covered by and how many employees must hold it
+type SkillNeed struct {
+	Skill string
+	Count int
+}
+
+// skillCountPattern matches required skills written as "Server (2)"
+var skillCountPattern = regexp.MustCompile(`^(.+?)\s*\((\d+)\)$`)
+
+// SkillNeeds parses RequiredSkills into normalized skill names and counts. Entries may
+// carry a count in parentheses, e.g. "Server (2)"; entries without one need a single
+// qualified employee.
+func (r ShiftRequirement) SkillNeeds() []SkillNeed {
+	var needs []SkillNeed
+	for _, entry := range r.RequiredSkills {
+		need := SkillNeed{Skill: NormalizeSkillName(entry), Count: 1}
+		if m := skillCountPattern.FindStringSubmatch(strings.TrimSpace(entry)); m != nil {
+			count, _ := strconv.Atoi(m[2])
+			need = SkillNeed{Skill: NormalizeSkillName(m[1]), Count: count}
+		}
+		if need.Skill != "" && need.Count > 0 {
+			needs = append(needs, need)
+		}
+	}
+	return needs
 }
 
 // SchedulingPolicies defines rules and constraints for scheduling
@@ -93,6 +192,30 @@ func (c *CompanyConfig) Validate() error {
 		return ErrInvalidWorkingHours
 	}
 
+	for _, day := range c.WorkingHours.WorkingDays {
+		if day < 0 || day > 6 {
+			return ErrInvalidWorkingHours
+		}
+	}
+
+	if c.WorkingHours.Timezone != "" {
+		if _, err := time.LoadLocation(c.WorkingHours.Timezone); err != nil {
+			return ErrInvalidWorkingHours
+		}
+	}
+
+	// Validate shift definitions
+	seen := make(map[string]bool)
+	for _, def := range c.ShiftDefinitions {
+		if err := def.Validate(); err != nil {
+			return err
+		}
+		if seen[def.Type] {
+			return ErrInvalidShiftDefinition
+		}
+		seen[def.Type] = true
+	}
+
 	// Validate shift requirements
 	if len(c.ShiftRequirements) == 0 {
 		return ErrInvalidShiftRequirements
@@ -102,6 +225,13 @@ func (c *CompanyConfig) Validate() error {
 		if req.MinEmployees < 0 || req.MaxEmployees < req.MinEmployees {
 			return ErrInvalidShiftRequirements
 		}
+		if c.GetShiftDefinition(req.ShiftType) == nil {
+			return ErrUnknownShiftType
+		}
+	}
+
+	if c.SchedulingStrategy != "" && !IsValidSchedulingStrategy(c.SchedulingStrategy) {
+		return ErrUnknownSchedulingStrategy
 	}
 
 	return nil
@@ -124,20 +254,41 @@ func (c *CompanyConfig) GetContextForAI() string {
 	context += "- Hours: " + c.WorkingHours.OpenTime + " - " + c.WorkingHours.CloseTime + "\n"
 	context += "- Timezone: " + c.WorkingHours.Timezone + "\n\n"
 
+	context += "Shifts:\n"
+	for _, def := range c.GetShiftDefinitions() {
+		context += "- " + def.Type + " (" + def.DisplayName() + "): " + def.StartTime + " - " + def.EndTime
+		if def.Overnight {
+			context += " (overnight)"
+		}
+		if def.UnpaidBreakMinutes > 0 {
+			context += ", " + strconv.Itoa(def.UnpaidBreakMinutes) + " min unpaid break"
+		}
+		context += ", " + strconv.FormatFloat(def.PaidHours(), 'f', -1, 64) + "h paid\n"
+	}
+	context += "\n"
+
 	context += "Shift Requirements:\n"
 	for _, req := range c.ShiftRequirements {
 		context += "- " + req.ShiftType + ": "
 		context += req.Description
 		if req.MinEmployees > 0 {
-			context += " (Min: " + string(rune(req.MinEmployees+'0')) + " employees)"
+			context += " (Min: " + strconv.Itoa(req.MinEmployees) + " employees)"
+		}
+		if len(req.RequiredSkills) > 0 {
+			context += " [Required skills: " + strings.Join(req.RequiredSkills, ", ") + "]"
 		}
 		context += "\n"
 	}
 	context += "\n"
 
 	context += "Scheduling Policies:\n"
-	context += "- Maximum consecutive work days: " + string(rune(c.SchedulingPolicies.MaxConsecutiveDays+'0')) + "\n"
-	context += "- Minimum rest hours between shifts: " + string(rune(c.SchedulingPolicies.MinRestHours+'0')) + "h\n"
+	context += "- Maximum consecutive work days: " + strconv.Itoa(c.SchedulingPolicies.MaxConsecutiveDays) + "\n"
+	context += "- Minimum rest hours between shifts: " + strconv.Itoa(c.SchedulingPolicies.MinRestHours) + "h\n"
+	if c.SchedulingPolicies.AllowOvertime {
+		context += "- Overtime allowed, up to " + strconv.Itoa(c.SchedulingPolicies.MaxOvertimeHours) + "h per month\n"
+	} else {
+		context += "- No overtime beyond contracted monthly hours\n"
+	}
 	if c.SchedulingPolicies.FairDistribution {
 		context += "- Fair distribution of shifts across all employees\n"
 	}
@@ -152,3 +303,40 @@ func (c *CompanyConfig) GetContextForAI() string {
 
 	return context
 }
+
+// DefaultCompanyConfig returns the configuration a new installation starts with
+func DefaultCompanyConfig() *CompanyConfig {
+	return &CompanyConfig{
+		CompanyName: "Your Company Name",
+		WorkingHours: WorkingHours{
+			WorkingDays: []int{1, 2, 3, 4, 5}, // Monday to Friday
+			OpenTime:    "09:00",
+			CloseTime:   "17:00",
+			Timezone:    "Europe/Oslo",
+		},
+		ShiftDefinitions: GetShiftDefinitions(),
+		ShiftRequirements: []ShiftRequirement{
+			{
+				ShiftType:    ShiftTypeMorning,
+				MinEmployees: 1,
+				MaxEmployees: 2,
+				Description:  "Morning shift coverage",
+			},
+			{
+				ShiftType:    ShiftTypeAfternoon,
+				MinEmployees: 1,
+				MaxEmployees: 2,
+				Description:  "Afternoon shift coverage",
+			},
+		},
+		SchedulingPolicies: SchedulingPolicies{
+			MaxConsecutiveDays:     5,
+			MinRestHours:           12,
+			AllowOvertime:          true,
+			MaxOvertimeHours:       20,
+			WeekendConsentRequired: true,
+			FairDistribution:       true,
+		},
+		AIContext: "Please ensure fair distribution of shifts and respect employee availability preferences.",
+	}
+}
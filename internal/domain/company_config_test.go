@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompanyConfig_ValidateShiftDefinitions(t *testing.T) {
+	validConfig := func() *CompanyConfig {
+		return &CompanyConfig{
+			CompanyName: "Test Company",
+			WorkingHours: WorkingHours{
+				WorkingDays: []int{1, 2, 3, 4, 5},
+				OpenTime:    "06:00",
+				CloseTime:   "22:00",
+			},
+			ShiftDefinitions: []ShiftDefinition{
+				{Type: "early", Name: "Early", StartTime: "06:30", EndTime: "14:30", UnpaidBreakMinutes: 30, Color: "#22c55e"},
+				{Type: "late", Name: "Late", StartTime: "22:00", EndTime: "06:00", Overnight: true},
+			},
+			ShiftRequirements: []ShiftRequirement{
+				{ShiftType: "early", MinEmployees: 1, MaxEmployees: 2},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(c *CompanyConfig)
+		wantErr error
+	}{
+		{
+			name:    "valid custom shifts",
+			modify:  func(c *CompanyConfig) {},
+			wantErr: nil,
+		},
+		{
+			name:    "default shifts when none are defined",
+			modify:  func(c *CompanyConfig) { c.ShiftDefinitions = nil; c.ShiftRequirements[0].ShiftType = ShiftTypeNight },
+			wantErr: nil,
+		},
+		{
+			name:    "requirement references undefined shift",
+			modify:  func(c *CompanyConfig) { c.ShiftRequirements[0].ShiftType = ShiftTypeMorning },
+			wantErr: ErrUnknownShiftType,
+		},
+		{
+			name:    "duplicate shift type",
+			modify:  func(c *CompanyConfig) { c.ShiftDefinitions[1].Type = "early" },
+			wantErr: ErrInvalidShiftDefinition,
+		},
+		{
+			name:    "missing name",
+			modify:  func(c *CompanyConfig) { c.ShiftDefinitions[0].Name = " " },
+			wantErr: ErrInvalidShiftDefinition,
+		},
+		{
+			name:    "malformed time",
+			modify:  func(c *CompanyConfig) { c.ShiftDefinitions[0].EndTime = "25:00" },
+			wantErr: ErrInvalidShiftDefinition,
+		},
+		{
+			name:    "end before start without overnight flag",
+			modify:  func(c *CompanyConfig) { c.ShiftDefinitions[1].Overnight = false },
+			wantErr: ErrInvalidShiftDefinition,
+		},
+		{
+			name:    "overnight flag on a same-day shift",
+			modify:  func(c *CompanyConfig) { c.ShiftDefinitions[0].Overnight = true },
+			wantErr: ErrInvalidShiftDefinition,
+		},
+		{
+			name:    "break as long as the shift",
+			modify:  func(c *CompanyConfig) { c.ShiftDefinitions[0].UnpaidBreakMinutes = 480 },
+			wantErr: ErrInvalidShiftDefinition,
+		},
+		{
+			name:    "split shift as one span",
+			modify:  func(c *CompanyConfig) { c.ShiftDefinitions[0].UnpaidBreakMinutes = MaxUnpaidBreakMinutes + 1 },
+			wantErr: ErrShiftBreakTooLong,
+		},
+		{
+			name:    "invalid colour",
+			modify:  func(c *CompanyConfig) { c.ShiftDefinitions[0].Color = "green" },
+			wantErr: ErrInvalidShiftDefinition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := validConfig()
+			tt.modify(config)
+			if err := config.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShiftDefinition_PaidHours(t *testing.T) {
+	tests := []struct {
+		name string
+		def  ShiftDefinition
+		want float64
+	}{
+		{"default full day", *GetShiftDefinition(ShiftTypeFullDay), 8},
+		{"break deducted", ShiftDefinition{StartTime: "06:30", EndTime: "14:30", UnpaidBreakMinutes: 30}, 7.5},
+		{"longest break", ShiftDefinition{StartTime: "10:00", EndTime: "21:00", UnpaidBreakMinutes: 90}, 9.5},
+		{"overnight", ShiftDefinition{StartTime: "22:00", EndTime: "06:00", Overnight: true}, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.def.PaidHours(); got != tt.want {
+				t.Errorf("PaidHours() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShiftTypeFromName(t *testing.T) {
+	tests := map[string]string{
+		"Early (06:30)": "early_06_30",
+		"  Late  ":      "late",
+		"Split/Lunch":   "split_lunch",
+		"---":           "",
+	}
+
+	for name, want := range tests {
+		if got := ShiftTypeFromName(name); got != want {
+			t.Errorf("ShiftTypeFromName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
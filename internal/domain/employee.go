@@ -1,32 +1,210 @@
 package domain
 
 import (
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // Employee represents an employee in the system
 type Employee struct {
-	ID               string         `json:"id" bson:"id"`
-	Name             string         `json:"name" bson:"name"`
-	Email            string         `json:"email" bson:"email"`
-	Role             string         `json:"role" bson:"role"`
-	RoleDescription  string         `json:"role_description" bson:"role_description"`
-	MonthlyHours     int            `json:"monthly_hours" bson:"monthly_hours"`
-	Active           bool           `json:"active" bson:"active"`
-	Availability     []Availability `json:"availability,omitempty" bson:"availability,omitempty"`
-	CreatedAt        time.Time      `json:"created_at" bson:"created_at"`
-	UpdatedAt        time.Time      `json:"updated_at" bson:"updated_at"`
-}
-
-// Availability represents an employee's availability for a date range
+	ID              string             `json:"id" bson:"id"`
+	Name            string             `json:"name" bson:"name"`
+	Email           string             `json:"email" bson:"email"`
+	Role            string             `json:"role" bson:"role"`
+	RoleDescription string             `json:"role_description" bson:"role_description"`
+	MonthlyHours    int                `json:"monthly_hours" bson:"monthly_hours"`
+	Active          bool               `json:"active" bson:"active"`
+	Skills          []Skill            `json:"skills,omitempty" bson:"skills,omitempty"`
+	Availability    []Availability     `json:"availability,omitempty" bson:"availability,omitempty"`         // stored as separate records; repositories fill it in when loading employees
+	LeaveAllowances map[string]float64 `json:"leave_allowances,omitempty" bson:"leave_allowances,omitempty"` // days per calendar year by leave type; types without one are not limited
+	CalendarToken   string             `json:"-" bson:"calendar_token,omitempty"`                            // secret in the employee's calendar feed URL
+	Version         int                `json:"version" bson:"version"`                                       // counts the saved changes; updates must name the version they change
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// Skill represents a skill or certification an employee holds
+type Skill struct {
+	Name      string     `json:"name" bson:"name"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"` // nil if the skill does not expire
+}
+
+// ValidOn reports whether the skill has not expired on the given date
+func (s Skill) ValidOn(date time.Time) bool {
+	return s.ExpiresAt == nil || !calendarDay(date).After(calendarDay(*s.ExpiresAt))
+}
+
+// Availability represents an employee's availability for a date range. With a
+// recurrence it applies only on the days the rule selects within the range,
+// and may have no end date. With a time window it applies only between its
+// start and end time on those days.
 type Availability struct {
-	StartDate   time.Time `json:"start_date" bson:"start_date"`
-	EndDate     time.Time `json:"end_date" bson:"end_date"`
-	Type        string    `json:"type" bson:"type"` // available, unavailable, preferred
-	Reason      string    `json:"reason,omitempty" bson:"reason,omitempty"`
-	ShiftTypes  []string  `json:"shift_types,omitempty" bson:"shift_types,omitempty"` // If empty, applies to all shift types
+	ID             string      `json:"id" bson:"id"`
+	EmployeeID     string      `json:"employee_id" bson:"employee_id"`
+	StartDate      time.Time   `json:"start_date" bson:"start_date"`
+	EndDate        time.Time   `json:"end_date" bson:"end_date"` // zero for a recurring period without end
+	Type           string      `json:"type" bson:"type"`         // available, unavailable, preferred
+	Reason         string      `json:"reason,omitempty" bson:"reason,omitempty"`
+	ShiftTypes     []string    `json:"shift_types,omitempty" bson:"shift_types,omitempty"`           // If empty, applies to all shift types
+	Status         string      `json:"status,omitempty" bson:"status,omitempty"`                     // approved if empty
+	LeaveRequestID string      `json:"leave_request_id,omitempty" bson:"leave_request_id,omitempty"` // set for the period of an approved leave request
+	Recurrence     *Recurrence `json:"recurrence,omitempty" bson:"recurrence,omitempty"`             // repeats on set weekdays within the range
+	StartTime      string      `json:"start_time,omitempty" bson:"start_time,omitempty"`             // e.g. "08:00"; empty for the whole day
+	EndTime        string      `json:"end_time,omitempty" bson:"end_time,omitempty"`                 // e.g. "15:00"; before the start time for a window ending the next day
+	CreatedAt      time.Time   `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at" bson:"updated_at"`
+}
+
+// Approved reports whether the availability applies when scheduling. Periods
+// stored before approval existed have no status and count as approved.
+func (a Availability) Approved() bool {
+	return a.Status == "" || a.Status == AvailabilityStatusApproved
+}
+
+// Pending reports whether the availability waits for a manager's approval
+func (a Availability) Pending() bool {
+	return a.Status == AvailabilityStatusPending
+}
+
+// OpenEnded reports whether the availability recurs without an end date
+func (a Availability) OpenEnded() bool {
+	return a.Recurrence != nil && a.EndDate.IsZero()
+}
+
+// Covers reports whether the calendar day of date falls within the availability range
+// and, for a recurring period, is a day the rule selects. Dates are compared by
+// calendar day so that a shift dated at local midnight in the company timezone
+// matches availability entered as plain dates.
+func (a Availability) Covers(date time.Time) bool {
+	day := calendarDay(date)
+	if day.Before(calendarDay(a.StartDate)) || (!a.OpenEnded() && day.After(calendarDay(a.EndDate))) {
+		return false
+	}
+	return a.Recurrence == nil || a.Recurrence.Matches(a.StartDate, day)
+}
+
+// HasTimeWindow reports whether the availability applies only between its
+// start and end time instead of the whole day
+func (a Availability) HasTimeWindow() bool {
+	return a.StartTime != "" || a.EndTime != ""
+}
+
+// OverlapsShift reports whether a shift running from start to end falls into
+// the availability at any moment. Whole-day availability matches shifts that
+// run on a day it covers and time windows are compared with the shift's actual
+// hours, so an overnight shift also meets the following day.
+func (a Availability) OverlapsShift(start, end time.Time) bool {
+	if !a.HasTimeWindow() {
+		for _, day := range shiftDays(start, end) {
+			if a.Covers(day) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, window := range a.windows(start, end) {
+		if window[0].Before(end) && start.Before(window[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsShift reports whether a shift running from start to end lies
+// entirely within one of the availability's time windows. Whole-day
+// availability contains shifts that run only on days it covers.
+func (a Availability) ContainsShift(start, end time.Time) bool {
+	if !a.HasTimeWindow() {
+		for _, day := range shiftDays(start, end) {
+			if !a.Covers(day) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, window := range a.windows(start, end) {
+		if !start.Before(window[0]) && !end.After(window[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// windows returns the time windows on the covered days from the day before the
+// shift starts to the day it ends, as instants in the shift's location
+func (a Availability) windows(start, end time.Time) [][2]time.Time {
+	startH, startM := parseClock(a.StartTime)
+	endH, endM := parseClock(a.EndTime)
+
+	var windows [][2]time.Time
+	y, m, d := start.Date()
+	last := calendarDay(end)
+	for day := time.Date(y, m, d-1, 0, 0, 0, 0, start.Location()); !calendarDay(day).After(last); day = day.AddDate(0, 0, 1) {
+		if !a.Covers(day) {
+			continue
+		}
+		y, m, d := day.Date()
+		from := time.Date(y, m, d, startH, startM, 0, 0, start.Location())
+		to := time.Date(y, m, d, endH, endM, 0, 0, start.Location())
+		if !to.After(from) {
+			to = time.Date(y, m, d+1, endH, endM, 0, 0, start.Location())
+		}
+		windows = append(windows, [2]time.Time{from, to})
+	}
+	return windows
+}
+
+// shiftDays returns midnight of each day a shift running from start to end
+// takes place on, in the shift's location. A shift ending at midnight does not
+// take place on the following day.
+func shiftDays(start, end time.Time) []time.Time {
+	y, m, d := start.Date()
+	days := []time.Time{time.Date(y, m, d, 0, 0, 0, 0, start.Location())}
+	for day := days[0].AddDate(0, 0, 1); day.Before(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// OverlapsPeriod reports whether the availability applies to any day from start to end
+func (a Availability) OverlapsPeriod(start, end time.Time) bool {
+	if calendarDay(a.StartDate).After(calendarDay(end)) {
+		return false
+	}
+	return a.OpenEnded() || !calendarDay(a.EndDate).Before(calendarDay(start))
+}
+
+// Validate checks the availability type, that the range does not end before it
+// starts, the time window and any recurrence rule
+func (a Availability) Validate() error {
+	switch a.Type {
+	case AvailabilityTypeAvailable, AvailabilityTypeUnavailable, AvailabilityTypePreferred:
+	default:
+		return ErrInvalidAvailability
+	}
+	if !a.OpenEnded() && calendarDay(a.EndDate).Before(calendarDay(a.StartDate)) {
+		return ErrInvalidAvailability
+	}
+	if a.HasTimeWindow() {
+		start, errStart := time.Parse("15:04", a.StartTime)
+		end, errEnd := time.Parse("15:04", a.EndTime)
+		if errStart != nil || errEnd != nil || start.Equal(end) {
+			return fmt.Errorf("%w: the time window needs a start and end time (HH:MM) that differ", ErrInvalidAvailability)
+		}
+	}
+	if a.Recurrence != nil {
+		return a.Recurrence.Validate()
+	}
+	return nil
+}
+
+// calendarDay strips the time and zone from t, keeping the date as seen in t's own location
+func calendarDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
 }
 
 // AvailabilityType constants
@@ -36,13 +214,23 @@ const (
 	AvailabilityTypePreferred   = "preferred"
 )
 
+// AvailabilityStatus constants. Availability employees submit themselves is
+// pending until a manager approves or rejects it.
+const (
+	AvailabilityStatusApproved = "approved"
+	AvailabilityStatusPending  = "pending"
+	AvailabilityStatusRejected = "rejected"
+)
+
 // EmployeeCreateInput represents the data needed to create a new employee
 type EmployeeCreateInput struct {
-	Name            string `json:"name"`
-	Email           string `json:"email"`
-	Role            string `json:"role"`
-	RoleDescription string `json:"role_description"`
-	MonthlyHours    int    `json:"monthly_hours"`
+	Name            string             `json:"name"`
+	Email           string             `json:"email"`
+	Role            string             `json:"role"`
+	RoleDescription string             `json:"role_description"`
+	MonthlyHours    int                `json:"monthly_hours"`
+	Skills          []Skill            `json:"skills,omitempty"`
+	LeaveAllowances map[string]float64 `json:"leave_allowances,omitempty"`
 }
 
 // Email validation regex pattern
@@ -85,6 +273,20 @@ func (e *Employee) Validate() error {
 		return ErrInvalidMonthlyHours
 	}
 
+	// Validate skills
+	for _, skill := range e.Skills {
+		if skill.Name == "" || len(skill.Name) > 50 {
+			return ErrInvalidEmployeeSkill
+		}
+	}
+
+	// Validate leave allowances
+	for leaveType, days := range e.LeaveAllowances {
+		if !IsValidLeaveType(leaveType) || days < 0 || days > 366 {
+			return ErrInvalidLeaveAllowance
+		}
+	}
+
 	return nil
 }
 
@@ -94,70 +296,159 @@ func SanitizeEmployeeInput(input *EmployeeCreateInput) {
 	input.Email = strings.TrimSpace(strings.ToLower(input.Email))
 	input.Role = strings.TrimSpace(input.Role)
 	input.RoleDescription = strings.TrimSpace(input.RoleDescription)
+	for i := range input.Skills {
+		input.Skills[i].Name = NormalizeSkillName(input.Skills[i].Name)
+	}
 }
 
-// IsAvailableOn checks if the employee is available on a specific date
-func (e *Employee) IsAvailableOn(date time.Time, shiftType string) bool {
-	// If no availability constraints, employee is available
-	if len(e.Availability) == 0 {
-		return true
+// LeaveAllowance returns the employee's yearly allowance for a leave type, and
+// false if the type is not limited
+func (e *Employee) LeaveAllowance(leaveType string) (float64, bool) {
+	days, ok := e.LeaveAllowances[leaveType]
+	return days, ok
+}
+
+// NormalizeSkillName trims and lowercases a skill name so matching is case-insensitive
+func NormalizeSkillName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// HasSkill checks if the employee holds a skill that is still valid on the given date
+func (e *Employee) HasSkill(name string, date time.Time) bool {
+	name = NormalizeSkillName(name)
+	for _, skill := range e.Skills {
+		if NormalizeSkillName(skill.Name) == name && skill.ValidOn(date) {
+			return true
+		}
 	}
+	return false
+}
 
-	// Check each availability entry
-	for _, avail := range e.Availability {
-		// Check if date falls within this availability range
-		if (date.After(avail.StartDate) || date.Equal(avail.StartDate)) &&
-			(date.Before(avail.EndDate) || date.Equal(avail.EndDate)) {
-
-			// If shift types are specified, check if current shift type matches
-			if len(avail.ShiftTypes) > 0 {
-				shiftMatch := false
-				for _, st := range avail.ShiftTypes {
-					if st == shiftType {
-						shiftMatch = true
-						break
-					}
-				}
-				if !shiftMatch {
-					continue // This availability doesn't apply to this shift type
-				}
+// ParseSkills parses a comma-separated skill list as entered in the employee form.
+// Each entry is a skill name, optionally followed by ":YYYY-MM-DD" for its expiry date,
+// e.g. "bartender, first-aid:2026-05-31".
+func ParseSkills(input string) ([]Skill, error) {
+	var skills []Skill
+	for _, entry := range strings.Split(input, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		skill := Skill{Name: entry}
+		if name, expiry, found := strings.Cut(entry, ":"); found {
+			expiresAt, err := time.Parse("2006-01-02", strings.TrimSpace(expiry))
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid expiry date for %q", ErrInvalidEmployeeSkill, strings.TrimSpace(name))
 			}
+			skill = Skill{Name: name, ExpiresAt: &expiresAt}
+		}
+		skill.Name = NormalizeSkillName(skill.Name)
+		skills = append(skills, skill)
+	}
+	return skills, nil
+}
 
-			// If this is an unavailable period, employee is not available
-			if avail.Type == AvailabilityTypeUnavailable {
+// FormatSkills formats skills in the format accepted by ParseSkills
+func FormatSkills(skills []Skill) string {
+	parts := make([]string, len(skills))
+	for i, skill := range skills {
+		parts[i] = skill.Name
+		if skill.ExpiresAt != nil {
+			parts[i] += ":" + skill.ExpiresAt.Format("2006-01-02")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseLeaveAllowances parses a comma-separated list of yearly leave allowances
+// as entered in the employee form, each a leave type and a number of days,
+// e.g. "vacation:25, parental:10"
+func ParseLeaveAllowances(input string) (map[string]float64, error) {
+	allowances := make(map[string]float64)
+	for _, entry := range strings.Split(input, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		leaveType, value, found := strings.Cut(entry, ":")
+		leaveType = strings.ToLower(strings.TrimSpace(leaveType))
+		days, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !found || err != nil || !IsValidLeaveType(leaveType) {
+			return nil, fmt.Errorf("%w: invalid entry %q", ErrInvalidLeaveAllowance, entry)
+		}
+		allowances[leaveType] = days
+	}
+	if len(allowances) == 0 {
+		return nil, nil
+	}
+	return allowances, nil
+}
+
+// FormatLeaveAllowances formats leave allowances in the format accepted by ParseLeaveAllowances
+func FormatLeaveAllowances(allowances map[string]float64) string {
+	var parts []string
+	for _, leaveType := range LeaveTypes() {
+		if days, ok := allowances[leaveType]; ok {
+			parts = append(parts, leaveType+":"+strconv.FormatFloat(days, 'f', -1, 64))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// IsAvailableOn checks if the employee is available for a shift of shiftType
+// running from start to end; the shift's date is the day it starts on. An
+// unavailable period blocks shifts it overlaps. Available periods with a time
+// window limit their days to shifts that fit within one of their windows, e.g.
+// "available until 15:00".
+func (e *Employee) IsAvailableOn(shiftType string, start, end time.Time) bool {
+	restricted, fits := false, false
+	for _, avail := range e.Availability {
+		if !avail.Approved() || !avail.appliesToShiftType(shiftType) {
+			continue
+		}
+
+		switch avail.Type {
+		case AvailabilityTypeUnavailable:
+			if avail.OverlapsShift(start, end) {
 				return false
 			}
+		case AvailabilityTypeAvailable:
+			if avail.HasTimeWindow() && avail.Covers(start) {
+				restricted = true
+				fits = fits || avail.ContainsShift(start, end)
+			}
 		}
 	}
 
 	// Default to available if no unavailable periods match
-	return true
+	return !restricted || fits
 }
 
-// GetPreference returns the preference level for a date (0 = no preference, 1 = preferred)
-func (e *Employee) GetPreference(date time.Time, shiftType string) int {
+// GetPreference returns the preference level for a shift of shiftType running
+// from start to end (0 = no preference, 1 = preferred). A preferred time window
+// must contain the whole shift.
+func (e *Employee) GetPreference(shiftType string, start, end time.Time) int {
 	for _, avail := range e.Availability {
-		if (date.After(avail.StartDate) || date.Equal(avail.StartDate)) &&
-			(date.Before(avail.EndDate) || date.Equal(avail.EndDate)) {
-
-			// Check shift types if specified
-			if len(avail.ShiftTypes) > 0 {
-				shiftMatch := false
-				for _, st := range avail.ShiftTypes {
-					if st == shiftType {
-						shiftMatch = true
-						break
-					}
-				}
-				if !shiftMatch {
-					continue
-				}
-			}
-
-			if avail.Type == AvailabilityTypePreferred {
-				return 1
-			}
+		if avail.Approved() && avail.Type == AvailabilityTypePreferred &&
+			avail.appliesToShiftType(shiftType) && avail.ContainsShift(start, end) {
+			return 1
 		}
 	}
 	return 0
 }
+
+// appliesToShiftType reports whether the availability applies to shifts of
+// shiftType; without shift types it applies to all
+func (a Availability) appliesToShiftType(shiftType string) bool {
+	if len(a.ShiftTypes) == 0 {
+		return true
+	}
+	for _, st := range a.ShiftTypes {
+		if st == shiftType {
+			return true
+		}
+	}
+	return false
+}
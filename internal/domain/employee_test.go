@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"errors"
 	"testing"
+	"time"
 )
 
 func TestEmployee_Validate(t *testing.T) {
@@ -202,3 +204,293 @@ func TestSanitizeEmployeeInput(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSkills(t *testing.T) {
+	skills, err := ParseSkills(" Bartender , first-aid:2026-05-31,, ")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(skills) != 2 {
+		t.Fatalf("len(skills) = %d, want 2", len(skills))
+	}
+	if skills[0].Name != "bartender" || skills[0].ExpiresAt != nil {
+		t.Errorf("skills[0] = %+v, want bartender without expiry", skills[0])
+	}
+	if skills[1].Name != "first-aid" || skills[1].ExpiresAt == nil || skills[1].ExpiresAt.Format("2006-01-02") != "2026-05-31" {
+		t.Errorf("skills[1] = %+v, want first-aid expiring 2026-05-31", skills[1])
+	}
+
+	if got := FormatSkills(skills); got != "bartender, first-aid:2026-05-31" {
+		t.Errorf("FormatSkills() = %q", got)
+	}
+
+	if _, err := ParseSkills("first-aid:next-year"); !errors.Is(err, ErrInvalidEmployeeSkill) {
+		t.Errorf("Expected ErrInvalidEmployeeSkill, got %v", err)
+	}
+}
+
+func TestEmployee_HasSkill(t *testing.T) {
+	expiry := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	employee := Employee{
+		Skills: []Skill{
+			{Name: "bartender"},
+			{Name: "first-aid", ExpiresAt: &expiry},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		skill string
+		date  time.Time
+		want  bool
+	}{
+		{"permanent skill", "Bartender", time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{"before expiry", "first-aid", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), true},
+		{"on expiry day", "first-aid", time.Date(2025, 3, 31, 18, 0, 0, 0, time.UTC), true},
+		{"after expiry", "first-aid", time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), false},
+		{"missing skill", "chef", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := employee.HasSkill(tt.skill, tt.date); got != tt.want {
+				t.Errorf("HasSkill(%q, %v) = %v, want %v", tt.skill, tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAvailability_Validate(t *testing.T) {
+	monday := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		availability Availability
+		wantErr      bool
+	}{
+		{"single day", Availability{StartDate: monday, EndDate: monday, Type: AvailabilityTypeUnavailable}, false},
+		{"range", Availability{StartDate: monday, EndDate: monday.AddDate(0, 0, 6), Type: AvailabilityTypePreferred}, false},
+		{"ends before it starts", Availability{StartDate: monday, EndDate: monday.AddDate(0, 0, -1), Type: AvailabilityTypeAvailable}, true},
+		{"unknown type", Availability{StartDate: monday, EndDate: monday, Type: "busy"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.availability.Validate()
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidAvailability)) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEmployee_IsAvailableOn_Status(t *testing.T) {
+	monday := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{"", false},
+		{AvailabilityStatusApproved, false},
+		{AvailabilityStatusPending, true},
+		{AvailabilityStatusRejected, true},
+	}
+
+	for _, tt := range tests {
+		t.Run("status "+tt.status, func(t *testing.T) {
+			employee := Employee{Availability: []Availability{
+				{StartDate: monday, EndDate: monday, Type: AvailabilityTypeUnavailable, Status: tt.status},
+				{StartDate: monday, EndDate: monday, Type: AvailabilityTypePreferred, Status: tt.status},
+			}}
+			if got := employee.IsAvailableOn("morning", monday.Add(9*time.Hour), monday.Add(13*time.Hour)); got != tt.want {
+				t.Errorf("IsAvailableOn() = %v, want %v", got, tt.want)
+			}
+			wantPreference := 0
+			if !tt.want {
+				wantPreference = 1
+			}
+			if got := employee.GetPreference("morning", monday.Add(9*time.Hour), monday.Add(13*time.Hour)); got != wantPreference {
+				t.Errorf("GetPreference() = %d, want %d", got, wantPreference)
+			}
+		})
+	}
+}
+
+func TestShiftRequirement_SkillNeeds(t *testing.T) {
+	req := ShiftRequirement{RequiredSkills: []string{"Server (2)", "First-Aid", " ", "Chef (0)"}}
+
+	needs := req.SkillNeeds()
+
+	want := []SkillNeed{{Skill: "server", Count: 2}, {Skill: "first-aid", Count: 1}}
+	if len(needs) != len(want) {
+		t.Fatalf("SkillNeeds() = %+v, want %+v", needs, want)
+	}
+	for i := range want {
+		if needs[i] != want[i] {
+			t.Errorf("SkillNeeds()[%d] = %+v, want %+v", i, needs[i], want[i])
+		}
+	}
+}
+
+func TestParseLeaveAllowances(t *testing.T) {
+	allowances, err := ParseLeaveAllowances(" Vacation: 25, parental:10.5 ,, ")
+	if err != nil {
+		t.Fatalf("ParseLeaveAllowances() error = %v", err)
+	}
+	if len(allowances) != 2 || allowances[LeaveTypeVacation] != 25 || allowances[LeaveTypeParental] != 10.5 {
+		t.Errorf("ParseLeaveAllowances() = %v", allowances)
+	}
+	if got := FormatLeaveAllowances(allowances); got != "vacation:25, parental:10.5" {
+		t.Errorf("FormatLeaveAllowances() = %q", got)
+	}
+
+	if allowances, err := ParseLeaveAllowances("  "); err != nil || allowances != nil {
+		t.Errorf("ParseLeaveAllowances(empty) = %v, %v, want nil", allowances, err)
+	}
+	for _, input := range []string{"vacation", "holiday:5", "sick:many"} {
+		if _, err := ParseLeaveAllowances(input); !errors.Is(err, ErrInvalidLeaveAllowance) {
+			t.Errorf("ParseLeaveAllowances(%q) error = %v, want %v", input, err, ErrInvalidLeaveAllowance)
+		}
+	}
+}
+
+func TestLeaveRequest_WorkingDays(t *testing.T) {
+	// Monday Dec 29, 2025 to Sunday Jan 4, 2026
+	leave := LeaveRequest{
+		StartDate: time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name  string
+		hours WorkingHours
+		year  int
+		want  float64
+	}{
+		{"whole leave on weekdays", WorkingHours{}, 0, 5},
+		{"first year", WorkingHours{}, 2025, 3},
+		{"second year", WorkingHours{}, 2026, 2},
+		{"six day week", WorkingHours{WorkingDays: []int{1, 2, 3, 4, 5, 6}}, 0, 6},
+		{"other year", WorkingHours{}, 2024, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := leave.WorkingDays(tt.hours, tt.year); got != tt.want {
+				t.Errorf("WorkingDays() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 52 whole weeks and a Tuesday; the part in 2026 starts on Thursday Jan 1
+	year := LeaveRequest{
+		StartDate: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+	}
+	if got := year.WorkingDays(WorkingHours{}, 0); got != 261 {
+		t.Errorf("WorkingDays() of a year = %v, want 261", got)
+	}
+	if got := year.WorkingDays(WorkingHours{WorkingDays: []int{4}}, 2026); got != 26 {
+		t.Errorf("WorkingDays() of Thursdays in 2026 = %v, want 26", got)
+	}
+}
+
+func TestLeaveRequest_Validate(t *testing.T) {
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		end  time.Time
+		want error
+	}{
+		{"one day", start, nil},
+		{"longest leave", start.AddDate(0, 0, MaxLeaveDays-1), nil},
+		{"too long", start.AddDate(0, 0, MaxLeaveDays), ErrLeaveTooLong},
+		{"ends before it starts", start.AddDate(0, 0, -1), ErrInvalidLeavePeriod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leave := LeaveRequest{Type: LeaveTypeVacation, StartDate: start, EndDate: tt.end}
+			if err := leave.Validate(); err != tt.want {
+				t.Errorf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmployee_IsAvailableOn_TimeWindows(t *testing.T) {
+	monday := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	shift := func(day time.Time, start, end string) (time.Time, time.Time) {
+		return ShiftDefinition{StartTime: start, EndTime: end}.Span(day, time.UTC)
+	}
+	week := func(availType, start, end string) Availability {
+		return Availability{StartDate: monday, EndDate: monday.AddDate(0, 0, 6), Type: availType, StartTime: start, EndTime: end}
+	}
+
+	tests := []struct {
+		name       string
+		avail      Availability
+		day        time.Time
+		start, end string
+		want       bool
+	}{
+		{"shift within available hours", week(AvailabilityTypeAvailable, "06:00", "15:00"), monday, "06:30", "14:30", true},
+		{"shift past available hours", week(AvailabilityTypeAvailable, "06:00", "15:00"), monday, "09:00", "17:00", false},
+		{"available hours on another day", week(AvailabilityTypeAvailable, "06:00", "15:00"), monday.AddDate(0, 0, 7), "09:00", "17:00", true},
+		{"overnight shift within overnight hours", week(AvailabilityTypeAvailable, "20:00", "07:00"), monday, "22:00", "06:00", true},
+		{"shift overlapping unavailable hours", week(AvailabilityTypeUnavailable, "12:00", "14:00"), monday, "09:00", "13:00", false},
+		{"shift before unavailable hours", week(AvailabilityTypeUnavailable, "12:00", "14:00"), monday, "06:00", "12:00", true},
+		{"overnight shift into unavailable morning", week(AvailabilityTypeUnavailable, "05:00", "09:00"), monday, "22:00", "06:00", false},
+		{"overnight shift into the day after the period", week(AvailabilityTypeUnavailable, "05:00", "09:00"), monday.AddDate(0, 0, 6), "22:00", "06:00", true},
+		{"early shift during unavailable night from the day before", week(AvailabilityTypeUnavailable, "22:00", "06:00"), monday.AddDate(0, 0, 1), "04:00", "12:00", false},
+		{"overnight shift into an unavailable day", week(AvailabilityTypeUnavailable, "", ""), monday.AddDate(0, 0, -1), "22:00", "06:00", false},
+		{"shift ending at midnight before an unavailable day", week(AvailabilityTypeUnavailable, "", ""), monday.AddDate(0, 0, -1), "16:00", "00:00", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			employee := Employee{Availability: []Availability{tt.avail}}
+			start, end := shift(tt.day, tt.start, tt.end)
+			if got := employee.IsAvailableOn("custom", start, end); got != tt.want {
+				t.Errorf("IsAvailableOn(%s-%s) = %v, want %v", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+
+	preferred := Employee{Availability: []Availability{week(AvailabilityTypePreferred, "17:00", "23:00")}}
+	start, end := shift(monday, "18:00", "22:00")
+	if got := preferred.GetPreference("custom", start, end); got != 1 {
+		t.Errorf("GetPreference() within the window = %d, want 1", got)
+	}
+	start, end = shift(monday, "13:00", "21:00")
+	if got := preferred.GetPreference("custom", start, end); got != 0 {
+		t.Errorf("GetPreference() partly outside the window = %d, want 0", got)
+	}
+
+	mondays := Employee{Availability: []Availability{{StartDate: monday, EndDate: monday, Type: AvailabilityTypePreferred}}}
+	start, end = shift(monday, "22:00", "06:00")
+	if got := mondays.GetPreference("custom", start, end); got != 0 {
+		t.Errorf("GetPreference() of an overnight shift past the preferred day = %d, want 0", got)
+	}
+
+	for _, window := range [][2]string{{"08:00", ""}, {"8am", "15:00"}, {"15:00", "15:00"}} {
+		if err := week(AvailabilityTypeAvailable, window[0], window[1]).Validate(); err == nil {
+			t.Errorf("Validate() window %s-%s: want an error", window[0], window[1])
+		}
+	}
+}
+
+func TestAvailability_OverlapsPeriod(t *testing.T) {
+	jan := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	bounded := Availability{StartDate: jan(6), EndDate: jan(10)}
+	openEnded := Availability{StartDate: jan(6), Recurrence: &Recurrence{Weekdays: []int{1}}}
+
+	if !bounded.OverlapsPeriod(jan(10), jan(20)) || bounded.OverlapsPeriod(jan(11), jan(20)) || bounded.OverlapsPeriod(jan(1), jan(5)) {
+		t.Error("OverlapsPeriod() of a bounded period does not compare its dates")
+	}
+	if !openEnded.OverlapsPeriod(jan(20), jan(27)) || openEnded.OverlapsPeriod(jan(1), jan(5)) {
+		t.Error("OverlapsPeriod() of an open-ended period does not run without end")
+	}
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Domain errors
 var (
@@ -11,12 +14,72 @@ var (
 	ErrInvalidEmployeeRole   = errors.New("employee role is required and must be less than 100 characters")
 	ErrInvalidMonthlyHours   = errors.New("monthly hours must be between 1 and 744")
 	ErrEmployeeAlreadyExists = errors.New("an employee with this email already exists")
+	ErrInvalidEmployeeSkill  = errors.New("skill names are required and must be less than 50 characters")
+	ErrInvalidAvailability   = errors.New("availability needs a type of available, unavailable or preferred and an end date on or after its start date")
+	ErrAvailabilityNotFound  = errors.New("availability period not found")
+	ErrAvailabilityReviewed  = errors.New("availability period has already been reviewed")
+	ErrAvailabilityApproved  = errors.New("approved availability can only be removed by a manager")
+	ErrAvailabilityFromLeave = errors.New("availability from a leave request is removed by cancelling the request")
+	ErrInvalidRecurrence     = errors.New("invalid repeating availability")
+	ErrInvalidLeaveAllowance = errors.New("leave allowances must be between 0 and 366 days per year for a known leave type")
+	ErrInvalidImportFile     = errors.New("import file must be a CSV or XLSX file")
+	ErrImportMissingColumns  = errors.New("import file is missing required columns")
 
 	// Schedule errors
-	ErrScheduleNotFound      = errors.New("schedule not found")
-	ErrInvalidSchedulePeriod = errors.New("schedule period end must be after period start")
-	ErrScheduleAlreadySent   = errors.New("schedule has already been sent to n8n")
+	ErrScheduleNotFound          = errors.New("schedule not found")
+	ErrInvalidSchedulePeriod     = errors.New("schedule period end must be after period start")
+	ErrScheduleAlreadySent       = errors.New("schedule has already been sent to n8n")
+	ErrSchedulePeriodTooLong     = fmt.Errorf("schedule period cannot be longer than %d days", MaxSchedulePeriodDays)
+	ErrUnknownPeriodPreset       = errors.New("unknown schedule period preset")
+	ErrScheduleNotEditable       = errors.New("only draft schedules can be edited; reopen the schedule first")
+	ErrScheduleNotDeletable      = errors.New("only draft schedules can be deleted; archive the schedule instead")
+	ErrAssignmentNotFound        = errors.New("shift assignment not found")
+	ErrInvalidAssignment         = errors.New("assignments must be on a working day within the schedule period and for a required shift type")
+	ErrEmployeeDoubleBooked      = errors.New("employee already works a shift on that day")
+	ErrUnknownScheduleAction     = errors.New("unknown schedule action")
+	ErrInvalidScheduleTransition = errors.New("this action is not allowed for the schedule's current status")
+	ErrScheduleNotPublished      = errors.New("only published schedules can be sent to n8n")
+	ErrScheduleNotDelivered      = errors.New("schedule was published but could not be sent to n8n")
+
+	// Shift swap errors
+	ErrShiftSwapNotFound     = errors.New("shift swap not found")
+	ErrInvalidShiftSwapKind  = errors.New("shift swap kind must be swap or giveaway")
+	ErrShiftNotSwappable     = errors.New("only your own upcoming shifts in published schedules can be offered")
+	ErrShiftSwapExists       = errors.New("this shift is already on offer")
+	ErrShiftSwapNotOpen      = errors.New("this offer is no longer open")
+	ErrShiftSwapNotClaimed   = errors.New("only claimed offers can be approved or rejected")
+	ErrShiftSwapReturnNeeded = errors.New("a swap needs one of your own upcoming shifts in the same schedule in return")
+	ErrShiftSwapNotEligible  = errors.New("not eligible for the shift")
+	ErrShiftSwapOutdated     = errors.New("the shifts have changed since the offer was claimed")
+	ErrNotOfferingEmployee   = errors.New("only the employee who offered the shift can cancel the offer")
+
+	// Leave errors
+	ErrLeaveNotFound          = errors.New("leave request not found")
+	ErrInvalidLeaveType       = errors.New("leave type must be vacation, sick, parental or unpaid")
+	ErrInvalidLeavePeriod     = errors.New("leave needs a start date and an end date on or after it")
+	ErrLeaveTooLong           = fmt.Errorf("leave cannot be longer than %d days", MaxLeaveDays)
+	ErrLeaveOverlaps          = errors.New("the leave overlaps another pending or approved request")
+	ErrLeaveAllowanceExceeded = errors.New("the leave exceeds the remaining allowance")
+	ErrLeaveNotPending        = errors.New("only pending leave requests can be approved or rejected")
+	ErrLeaveNotActive         = errors.New("only pending or approved leave requests can be cancelled")
+
+	// User and session errors
+	ErrUserNotFound         = errors.New("user not found")
+	ErrUserAlreadyExists    = errors.New("a user with this email already exists")
+	ErrInvalidUserName      = errors.New("user name is required and must be less than 100 characters")
+	ErrInvalidUserEmail     = errors.New("valid user email is required (max 255 characters)")
+	ErrInvalidUserRole      = errors.New("user role must be admin, manager or employee")
+	ErrUserEmployeeRequired = errors.New("employee accounts must belong to an active employee")
+	ErrEmployeeHasAccount   = errors.New("this employee already has an account")
+	ErrInvalidPassword      = errors.New("password must be between 8 and 72 characters")
+	ErrInvalidCredentials   = errors.New("invalid email or password")
+	ErrLastAdmin            = errors.New("the last active admin cannot be deactivated or lose the admin role")
+	ErrSessionNotFound      = errors.New("session not found or expired")
+	ErrUnauthenticated      = errors.New("sign in to continue")
+	ErrForbidden            = errors.New("you do not have permission to do this")
+	ErrInvalidCSRFToken     = errors.New("missing or invalid CSRF token; reload the page and try again")
 
 	// General errors
 	ErrInternalServer = errors.New("internal server error")
+	ErrConflict       = errors.New("someone else has changed this since you loaded it; reload it and apply your changes again")
 )
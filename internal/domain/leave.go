@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// LeaveRequest is an employee's request for time off. Approved requests block
+// scheduling through an unavailable availability period linked to the request.
+type LeaveRequest struct {
+	ID           string          `json:"id" bson:"id"`
+	EmployeeID   string          `json:"employee_id" bson:"employee_id"`
+	EmployeeName string          `json:"employee_name" bson:"employee_name"`
+	Type         string          `json:"type" bson:"type"` // vacation, sick, parental, unpaid
+	StartDate    time.Time       `json:"start_date" bson:"start_date"`
+	EndDate      time.Time       `json:"end_date" bson:"end_date"`
+	Days         float64         `json:"days" bson:"days"` // working days of the company the request covers
+	Reason       string          `json:"reason,omitempty" bson:"reason,omitempty"`
+	Status       string          `json:"status" bson:"status"`
+	Conflicts    []LeaveConflict `json:"conflicts,omitempty" bson:"conflicts,omitempty"` // published shifts during the leave, as last checked
+	DecidedBy    string          `json:"decided_by,omitempty" bson:"decided_by,omitempty"`
+	DecidedAt    *time.Time      `json:"decided_at,omitempty" bson:"decided_at,omitempty"`
+	CreatedAt    time.Time       `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at" bson:"updated_at"`
+}
+
+// LeaveConflict is a published shift the employee is scheduled for during their leave
+type LeaveConflict struct {
+	ScheduleID   string    `json:"schedule_id" bson:"schedule_id"`
+	AssignmentID string    `json:"assignment_id" bson:"assignment_id"`
+	Date         time.Time `json:"date" bson:"date"`
+	ShiftType    string    `json:"shift_type" bson:"shift_type"`
+	StartTime    string    `json:"start_time" bson:"start_time"`
+	EndTime      string    `json:"end_time" bson:"end_time"`
+}
+
+// LeaveType constants
+const (
+	LeaveTypeVacation = "vacation"
+	LeaveTypeSick     = "sick"
+	LeaveTypeParental = "parental"
+	LeaveTypeUnpaid   = "unpaid"
+)
+
+// LeaveStatus constants. Requests are pending until a manager approves or
+// rejects them; pending and approved requests can be cancelled.
+const (
+	LeaveStatusPending   = "pending"
+	LeaveStatusApproved  = "approved"
+	LeaveStatusRejected  = "rejected"
+	LeaveStatusCancelled = "cancelled"
+)
+
+// LeaveTypes returns every leave type
+func LeaveTypes() []string {
+	return []string{LeaveTypeVacation, LeaveTypeSick, LeaveTypeParental, LeaveTypeUnpaid}
+}
+
+// LeaveStatuses returns every leave request status
+func LeaveStatuses() []string {
+	return []string{LeaveStatusPending, LeaveStatusApproved, LeaveStatusRejected, LeaveStatusCancelled}
+}
+
+// IsValidLeaveType reports whether t is a known leave type
+func IsValidLeaveType(t string) bool {
+	for _, lt := range LeaveTypes() {
+		if lt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// LeaveTypeLabel returns the display name of a leave type
+func LeaveTypeLabel(t string) string {
+	switch t {
+	case LeaveTypeVacation:
+		return "Vacation"
+	case LeaveTypeSick:
+		return "Sick leave"
+	case LeaveTypeParental:
+		return "Parental leave"
+	case LeaveTypeUnpaid:
+		return "Unpaid leave"
+	default:
+		return t
+	}
+}
+
+// MaxLeaveDays is the longest period a single leave request may cover
+const MaxLeaveDays = 366
+
+// Validate checks the leave type and that the range does not end before it
+// starts or last longer than MaxLeaveDays
+func (l *LeaveRequest) Validate() error {
+	if !IsValidLeaveType(l.Type) {
+		return ErrInvalidLeaveType
+	}
+	start, end := calendarDay(l.StartDate), calendarDay(l.EndDate)
+	if l.StartDate.IsZero() || l.EndDate.IsZero() || end.Before(start) {
+		return ErrInvalidLeavePeriod
+	}
+	if end.After(start.AddDate(0, 0, MaxLeaveDays-1)) {
+		return ErrLeaveTooLong
+	}
+	return nil
+}
+
+// Active reports whether the request is pending or approved
+func (l *LeaveRequest) Active() bool {
+	return l.Status == LeaveStatusPending || l.Status == LeaveStatusApproved
+}
+
+// Overlaps reports whether the two requests share a calendar day
+func (l *LeaveRequest) Overlaps(other *LeaveRequest) bool {
+	return !calendarDay(l.StartDate).After(calendarDay(other.EndDate)) &&
+		!calendarDay(other.StartDate).After(calendarDay(l.EndDate))
+}
+
+// Covers reports whether the calendar day of date falls within the leave
+func (l *LeaveRequest) Covers(date time.Time) bool {
+	return l.Availability().Covers(date)
+}
+
+// Availability returns the unavailable period that blocks scheduling while the
+// employee is on leave
+func (l *LeaveRequest) Availability() Availability {
+	return Availability{
+		EmployeeID:     l.EmployeeID,
+		StartDate:      l.StartDate,
+		EndDate:        l.EndDate,
+		Type:           AvailabilityTypeUnavailable,
+		Reason:         LeaveTypeLabel(l.Type),
+		Status:         AvailabilityStatusApproved,
+		LeaveRequestID: l.ID,
+	}
+}
+
+// WorkingDays counts the days of the leave falling on the company's working
+// days and within the given year; a zero year counts the whole leave. Without
+// configured working days the company works Monday to Friday, as when scheduling.
+func (l *LeaveRequest) WorkingDays(hours WorkingHours, year int) float64 {
+	start, end := calendarDay(l.StartDate), calendarDay(l.EndDate)
+	if year != 0 {
+		if first := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC); start.Before(first) {
+			start = first
+		}
+		if last := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC); end.After(last) {
+			end = last
+		}
+	}
+	if end.Before(start) {
+		return 0
+	}
+
+	working := func(day time.Weekday) bool {
+		if len(hours.WorkingDays) == 0 {
+			return day != time.Saturday && day != time.Sunday
+		}
+		return hours.IsWorkingDay(day)
+	}
+	perWeek := 0
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if working(day) {
+			perWeek++
+		}
+	}
+
+	// Whole weeks hold every working day once; only the remaining days need a look
+	total := int(end.Sub(start).Hours()/24) + 1
+	days := total / 7 * perWeek
+	for i, day := 0, start.Weekday(); i < total%7; i, day = i+1, (day+1)%7 {
+		if working(day) {
+			days++
+		}
+	}
+	return float64(days)
+}
+
+// SanitizeLeaveReason trims a leave request's reason and limits it to 500 characters
+func SanitizeLeaveReason(reason string) string {
+	reason = strings.TrimSpace(reason)
+	if len(reason) > 500 {
+		reason = reason[:500]
+	}
+	return reason
+}
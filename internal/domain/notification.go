@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Notification is a message to an employee, shown on their shifts page
+type Notification struct {
+	ID         string     `json:"id" bson:"id"`
+	EmployeeID string     `json:"employee_id" bson:"employee_id"`
+	Message    string     `json:"message" bson:"message"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	ReadAt     *time.Time `json:"read_at,omitempty" bson:"read_at,omitempty"`
+}
@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence repeats an availability period on set weekdays instead of every
+// day of its range, e.g. "never on Wednesdays" or "every other weekend"
+type Recurrence struct {
+	Weekdays   []int       `json:"weekdays" bson:"weekdays"`                         // 0 = Sunday, 6 = Saturday
+	Interval   int         `json:"interval,omitempty" bson:"interval,omitempty"`     // every n weeks counted from the week of the start date; weekly if 0 or 1
+	Exceptions []time.Time `json:"exceptions,omitempty" bson:"exceptions,omitempty"` // days the rule skips
+}
+
+// maxRecurrenceInterval is the longest gap between weeks a rule repeats in
+const maxRecurrenceInterval = 52
+
+// Validate checks that the rule names at least one weekday and a sensible interval
+func (r *Recurrence) Validate() error {
+	if len(r.Weekdays) == 0 {
+		return fmt.Errorf("%w: a repeating period needs at least one weekday", ErrInvalidRecurrence)
+	}
+	for _, day := range r.Weekdays {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("%w: weekdays run from 0 (Sunday) to 6 (Saturday)", ErrInvalidRecurrence)
+		}
+	}
+	if r.Interval < 0 || r.Interval > maxRecurrenceInterval {
+		return fmt.Errorf("%w: the interval must be between 1 and %d weeks", ErrInvalidRecurrence, maxRecurrenceInterval)
+	}
+	return nil
+}
+
+// Matches reports whether the rule, starting from start, applies on the
+// calendar day of date. Weeks run from Monday to Sunday.
+func (r *Recurrence) Matches(start, date time.Time) bool {
+	day := calendarDay(date)
+	if !slices.Contains(r.Weekdays, int(day.Weekday())) {
+		return false
+	}
+	for _, exception := range r.Exceptions {
+		if calendarDay(exception).Equal(day) {
+			return false
+		}
+	}
+	if r.Interval > 1 {
+		weeks := int(weekStart(day).Sub(weekStart(calendarDay(start))).Hours()/24) / 7
+		if weeks%r.Interval != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Describe returns the rule in words, e.g. "every 2 weeks on Mon, Wed"
+func (r *Recurrence) Describe() string {
+	days := make([]string, 0, len(r.Weekdays))
+	for _, day := range sortedWeekdays(r.Weekdays) {
+		days = append(days, time.Weekday(day).String()[:3])
+	}
+
+	description := "every week on " + strings.Join(days, ", ")
+	if r.Interval > 1 {
+		description = fmt.Sprintf("every %d weeks on %s", r.Interval, strings.Join(days, ", "))
+	}
+	if len(r.Exceptions) > 0 {
+		exceptions := make([]string, 0, len(r.Exceptions))
+		for _, exception := range r.Exceptions {
+			exceptions = append(exceptions, exception.Format("Jan 2"))
+		}
+		description += ", except " + strings.Join(exceptions, ", ")
+	}
+	return description
+}
+
+// RRule formats the rule as an iCalendar RRULE value, ending on until unless it is zero
+func (r *Recurrence) RRule(until time.Time) string {
+	days := make([]string, 0, len(r.Weekdays))
+	for _, day := range sortedWeekdays(r.Weekdays) {
+		days = append(days, rruleDays[day])
+	}
+
+	rule := "FREQ=WEEKLY"
+	if r.Interval > 1 {
+		rule += ";INTERVAL=" + strconv.Itoa(r.Interval)
+	}
+	rule += ";BYDAY=" + strings.Join(days, ",")
+	if !until.IsZero() {
+		rule += ";UNTIL=" + until.Format("20060102")
+	}
+	return rule
+}
+
+// rruleDays are the iCalendar weekday codes, indexed from Sunday
+var rruleDays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ParseRRule reads the weekly subset of an iCalendar RRULE, such as
+// "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;UNTIL=20251231". FREQ=DAILY repeats on
+// every weekday. It returns the rule and its UNTIL day, zero if it has none;
+// COUNT and the other parts are not supported.
+func ParseRRule(input string) (*Recurrence, time.Time, error) {
+	var until time.Time
+	rule := &Recurrence{}
+	freq := ""
+
+	input = strings.TrimPrefix(strings.TrimSpace(input), "RRULE:")
+	for _, part := range strings.Split(input, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			return nil, until, fmt.Errorf("%w: invalid rule part %q", ErrInvalidRecurrence, part)
+		}
+
+		switch strings.ToUpper(strings.TrimSpace(key)) {
+		case "FREQ":
+			freq = strings.ToUpper(strings.TrimSpace(value))
+		case "INTERVAL":
+			interval, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || interval < 1 {
+				return nil, until, fmt.Errorf("%w: invalid INTERVAL %q", ErrInvalidRecurrence, value)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day := slices.Index(rruleDays, strings.ToUpper(strings.TrimSpace(code)))
+				if day < 0 {
+					return nil, until, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidRecurrence, code)
+				}
+				if !slices.Contains(rule.Weekdays, day) {
+					rule.Weekdays = append(rule.Weekdays, day)
+				}
+			}
+		case "UNTIL":
+			value = strings.TrimSpace(value)
+			if len(value) < 8 {
+				return nil, until, fmt.Errorf("%w: invalid UNTIL %q", ErrInvalidRecurrence, value)
+			}
+			day, err := time.Parse("20060102", value[:8])
+			if err != nil {
+				return nil, until, fmt.Errorf("%w: invalid UNTIL %q", ErrInvalidRecurrence, value)
+			}
+			until = day
+		case "WKST":
+			// Weeks always start on Monday
+		default:
+			return nil, until, fmt.Errorf("%w: %s is not supported", ErrInvalidRecurrence, strings.ToUpper(key))
+		}
+	}
+
+	switch freq {
+	case "WEEKLY":
+	case "DAILY":
+		if rule.Interval > 1 || len(rule.Weekdays) > 0 {
+			return nil, until, fmt.Errorf("%w: a daily rule cannot have an INTERVAL or BYDAY", ErrInvalidRecurrence)
+		}
+		rule.Weekdays = []int{0, 1, 2, 3, 4, 5, 6}
+	default:
+		return nil, until, fmt.Errorf("%w: FREQ must be WEEKLY or DAILY", ErrInvalidRecurrence)
+	}
+	if rule.Interval == 1 {
+		rule.Interval = 0
+	}
+
+	if err := rule.Validate(); err != nil {
+		return nil, until, err
+	}
+	return rule, until, nil
+}
+
+// weekStart returns the Monday of the week of day
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// sortedWeekdays returns the weekdays from Monday to Sunday
+func sortedWeekdays(weekdays []int) []int {
+	sorted := slices.Clone(weekdays)
+	slices.SortFunc(sorted, func(a, b int) int {
+		return (a+6)%7 - (b+6)%7
+	})
+	return sorted
+}
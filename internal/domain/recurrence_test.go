@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAvailability_Recurrence(t *testing.T) {
+	// Monday Jan 6, 2025
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name  string
+		avail Availability
+		date  time.Time
+		want  bool
+	}{
+		{
+			name:  "weekday of a weekly rule",
+			avail: Availability{StartDate: start, Recurrence: &Recurrence{Weekdays: []int{3}}},
+			date:  day(15),
+			want:  true,
+		},
+		{
+			name:  "other weekday",
+			avail: Availability{StartDate: start, Recurrence: &Recurrence{Weekdays: []int{3}}},
+			date:  day(16),
+			want:  false,
+		},
+		{
+			name:  "before the start",
+			avail: Availability{StartDate: start, Recurrence: &Recurrence{Weekdays: []int{3}}},
+			date:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:  false,
+		},
+		{
+			name:  "open ended",
+			avail: Availability{StartDate: start, Recurrence: &Recurrence{Weekdays: []int{3}}},
+			date:  time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC),
+			want:  true,
+		},
+		{
+			name:  "after the end",
+			avail: Availability{StartDate: start, EndDate: day(31), Recurrence: &Recurrence{Weekdays: []int{3}}},
+			date:  time.Date(2025, 2, 5, 0, 0, 0, 0, time.UTC),
+			want:  false,
+		},
+		{
+			name:  "first week of an alternating rule",
+			avail: Availability{StartDate: start, Recurrence: &Recurrence{Weekdays: []int{0, 6}, Interval: 2}},
+			date:  day(12),
+			want:  true,
+		},
+		{
+			name:  "skipped week of an alternating rule",
+			avail: Availability{StartDate: start, Recurrence: &Recurrence{Weekdays: []int{0, 6}, Interval: 2}},
+			date:  day(18),
+			want:  false,
+		},
+		{
+			name:  "third week of an alternating rule",
+			avail: Availability{StartDate: start, Recurrence: &Recurrence{Weekdays: []int{0, 6}, Interval: 2}},
+			date:  day(25),
+			want:  true,
+		},
+		{
+			name:  "exception",
+			avail: Availability{StartDate: start, Recurrence: &Recurrence{Weekdays: []int{3}, Exceptions: []time.Time{day(15)}}},
+			date:  day(15).Add(9 * time.Hour),
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.avail.Covers(tt.date); got != tt.want {
+				t.Errorf("Covers(%s) = %v, want %v", tt.date.Format("Mon Jan 2"), got, tt.want)
+			}
+		})
+	}
+
+	// A recurring unavailable period blocks scheduling only on its weekdays
+	employee := Employee{Availability: []Availability{
+		{StartDate: start, Type: AvailabilityTypeUnavailable, Recurrence: &Recurrence{Weekdays: []int{3}}},
+		{StartDate: start, Type: AvailabilityTypePreferred, ShiftTypes: []string{"evening"}, Recurrence: &Recurrence{Weekdays: []int{0, 6}}},
+	}}
+	morning := func(d int) (string, time.Time, time.Time) {
+		return "morning", day(d).Add(9 * time.Hour), day(d).Add(13 * time.Hour)
+	}
+	evening := func(d int) (string, time.Time, time.Time) {
+		return "evening", day(d).Add(17 * time.Hour), day(d).Add(21 * time.Hour)
+	}
+	if employee.IsAvailableOn(morning(22)) {
+		t.Error("IsAvailableOn() on a recurring unavailable Wednesday = true")
+	}
+	if !employee.IsAvailableOn(morning(21)) {
+		t.Error("IsAvailableOn() on a Tuesday = false")
+	}
+	if employee.GetPreference(evening(25)) != 1 || employee.GetPreference(morning(25)) != 0 {
+		t.Error("GetPreference() does not follow the weekend evening rule")
+	}
+}
+
+func TestAvailability_ValidateRecurrence(t *testing.T) {
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	valid := Availability{StartDate: start, Type: AvailabilityTypeUnavailable, Recurrence: &Recurrence{Weekdays: []int{1}}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() open-ended recurring period error = %v", err)
+	}
+
+	for name, avail := range map[string]Availability{
+		"no end without recurrence": {StartDate: start, Type: AvailabilityTypeUnavailable},
+		"no weekdays":               {StartDate: start, Type: AvailabilityTypeUnavailable, Recurrence: &Recurrence{}},
+		"weekday out of range":      {StartDate: start, Type: AvailabilityTypeUnavailable, Recurrence: &Recurrence{Weekdays: []int{7}}},
+		"interval too long":         {StartDate: start, Type: AvailabilityTypeUnavailable, Recurrence: &Recurrence{Weekdays: []int{1}, Interval: 53}},
+	} {
+		if err := avail.Validate(); err == nil {
+			t.Errorf("Validate() %s: want an error", name)
+		}
+	}
+}
+
+func TestParseRRule(t *testing.T) {
+	rule, until, err := ParseRRule("RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,SA;UNTIL=20251231T235959Z;WKST=MO")
+	if err != nil {
+		t.Fatalf("ParseRRule() error = %v", err)
+	}
+	if len(rule.Weekdays) != 2 || rule.Weekdays[0] != 0 || rule.Weekdays[1] != 6 || rule.Interval != 2 {
+		t.Errorf("ParseRRule() = %+v", rule)
+	}
+	if !until.Equal(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseRRule() until = %v", until)
+	}
+	if got := rule.RRule(until); got != "FREQ=WEEKLY;INTERVAL=2;BYDAY=SA,SU;UNTIL=20251231" {
+		t.Errorf("RRule() = %q", got)
+	}
+	if got := rule.Describe(); got != "every 2 weeks on Sat, Sun" {
+		t.Errorf("Describe() = %q", got)
+	}
+
+	daily, until, err := ParseRRule("FREQ=DAILY")
+	if err != nil || len(daily.Weekdays) != 7 || daily.Interval != 0 || !until.IsZero() {
+		t.Errorf("ParseRRule(daily) = %+v, %v, %v", daily, until, err)
+	}
+
+	for _, input := range []string{"", "FREQ=MONTHLY;BYDAY=MO", "FREQ=WEEKLY", "FREQ=WEEKLY;BYDAY=XX", "FREQ=WEEKLY;BYDAY=MO;COUNT=3", "FREQ=WEEKLY;BYDAY=MO;UNTIL=2025"} {
+		if _, _, err := ParseRRule(input); !errors.Is(err, ErrInvalidRecurrence) {
+			t.Errorf("ParseRRule(%q) error = %v, want %v", input, err, ErrInvalidRecurrence)
+		}
+	}
+}
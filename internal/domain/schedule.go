@@ -1,23 +1,167 @@
 package domain
 
-import "time"
+import (
+	"regexp"
+	"strings"
+	"time"
+)
 
 // Schedule represents a generated schedule for a period
 type Schedule struct {
-	ID          string              `json:"id" bson:"id"`
-	PeriodStart time.Time           `json:"period_start" bson:"period_start"`
-	PeriodEnd   time.Time           `json:"period_end" bson:"period_end"`
-	Employees   []Employee          `json:"employees" bson:"employees"`
-	Assignments []ShiftAssignment   `json:"assignments" bson:"assignments"`
-	Status      string              `json:"status" bson:"status"` // draft, sent, completed
-	SentToN8N   bool                `json:"sent_to_n8n" bson:"sent_to_n8n"`
-	SentAt      *time.Time          `json:"sent_at,omitempty" bson:"sent_at,omitempty"`
-	CreatedAt   time.Time           `json:"created_at" bson:"created_at"`
-	UpdatedAt   time.Time           `json:"updated_at" bson:"updated_at"`
+	ID                   string               `json:"id" bson:"id"`
+	PeriodStart          time.Time            `json:"period_start" bson:"period_start"`
+	PeriodEnd            time.Time            `json:"period_end" bson:"period_end"`
+	Employees            []Employee           `json:"employees" bson:"employees"`
+	Assignments          []ShiftAssignment    `json:"assignments" bson:"assignments"`
+	Understaffed         []UnderstaffedShift  `json:"understaffed,omitempty" bson:"understaffed,omitempty"`
+	RelaxedConstraints   []RelaxedConstraint  `json:"relaxed_constraints,omitempty" bson:"relaxed_constraints,omitempty"`
+	Strategy             string               `json:"strategy,omitempty" bson:"strategy,omitempty"` // scheduling strategy that generated the assignments
+	Score                *ScheduleScore       `json:"score,omitempty" bson:"score,omitempty"`
+	Timezone             string               `json:"timezone,omitempty" bson:"timezone,omitempty"`                           // company timezone the schedule was generated in
+	ShiftDefinitions     []ShiftDefinition    `json:"shift_definitions,omitempty" bson:"shift_definitions,omitempty"`         // shifts as defined when the schedule was generated
+	Warnings             []ScheduleWarning    `json:"warnings,omitempty" bson:"warnings,omitempty"`                           // issues introduced by manual edits
+	EditedAt             *time.Time           `json:"edited_at,omitempty" bson:"edited_at,omitempty"`                         // last manual edit of the assignments
+	Status               string               `json:"status" bson:"status"`                                                   // draft, approved, published, completed, archived
+	Transitions          []ScheduleTransition `json:"transitions,omitempty" bson:"transitions,omitempty"`                     // lifecycle history, oldest first
+	PublishedAssignments []ShiftAssignment    `json:"published_assignments,omitempty" bson:"published_assignments,omitempty"` // assignments as last published to staff
+	CancelledAssignments []ShiftAssignment    `json:"cancelled_assignments,omitempty" bson:"cancelled_assignments,omitempty"` // published assignments removed by a later publication
+	SentToN8N            bool                 `json:"sent_to_n8n" bson:"sent_to_n8n"`
+	SentAt               *time.Time           `json:"sent_at,omitempty" bson:"sent_at,omitempty"`
+	Version              int                  `json:"version" bson:"version"` // counts the saved changes; updates must name the version they change
+	CreatedAt            time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt            time.Time            `json:"updated_at" bson:"updated_at"`
+}
+
+// Location returns the timezone assignment dates should be displayed in
+func (s *Schedule) Location() *time.Location {
+	return WorkingHours{Timezone: s.Timezone}.Location()
+}
+
+// IsEditable reports whether the schedule's assignments may be edited by hand.
+// Only drafts can be edited; approved and published schedules must be reopened first.
+func (s *Schedule) IsEditable() bool {
+	return s.Status == ScheduleStatusDraft
+}
+
+// LifecycleStatus returns the schedule's status, treating schedules sent to n8n
+// before the lifecycle existed as published
+func (s *Schedule) LifecycleStatus() string {
+	if s.Status == ScheduleStatusSent {
+		return ScheduleStatusPublished
+	}
+	return s.Status
+}
+
+// CanTransition reports whether action is allowed from the schedule's current status
+func (s *Schedule) CanTransition(action string) bool {
+	rule, ok := scheduleTransitionRules[action]
+	if !ok {
+		return false
+	}
+	for _, from := range rule.from {
+		if s.LifecycleStatus() == from {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition moves the schedule to the status action leads to and records who
+// made the change and when
+func (s *Schedule) Transition(action, actor string, at time.Time) error {
+	rule, ok := scheduleTransitionRules[action]
+	if !ok {
+		return ErrUnknownScheduleAction
+	}
+	if !s.CanTransition(action) {
+		return ErrInvalidScheduleTransition
+	}
+
+	s.Transitions = append(s.Transitions, ScheduleTransition{
+		Action: action,
+		From:   s.LifecycleStatus(),
+		To:     rule.to,
+		Actor:  actor,
+		At:     at,
+	})
+	s.Status = rule.to
+	if rule.to == ScheduleStatusPublished {
+		s.recordPublication()
+	}
+	return nil
+}
+
+// recordPublication snapshots the assignments being published. Assignments that
+// were in the previous publication but are no longer worked by the same employee
+// are recorded as cancelled so staff calendars can drop them.
+func (s *Schedule) recordPublication() {
+	published := make(map[string]bool, len(s.Assignments))
+	for _, a := range s.Assignments {
+		published[a.PublicationKey()] = true
+	}
+
+	var cancelled []ShiftAssignment
+	for _, a := range s.CancelledAssignments {
+		if !published[a.PublicationKey()] {
+			cancelled = append(cancelled, a)
+		}
+	}
+	for _, a := range s.PublishedAssignments {
+		if !published[a.PublicationKey()] {
+			cancelled = append(cancelled, a)
+		}
+	}
+
+	s.CancelledAssignments = cancelled
+	s.PublishedAssignments = append([]ShiftAssignment{}, s.Assignments...)
+}
+
+// VisibleAssignments returns the assignments staff currently see: those of the
+// last publication, which stay visible while the schedule is reopened for editing.
+// Schedules sent to n8n before publications were recorded show their assignments.
+func (s *Schedule) VisibleAssignments() []ShiftAssignment {
+	if s.PublishedAssignments != nil {
+		return s.PublishedAssignments
+	}
+	if s.Status == ScheduleStatusSent {
+		return s.Assignments
+	}
+	return nil
+}
+
+// LastTransition returns the most recent transition into status, or nil if the
+// schedule has never reached it
+func (s *Schedule) LastTransition(status string) *ScheduleTransition {
+	for i := len(s.Transitions) - 1; i >= 0; i-- {
+		if s.Transitions[i].To == status {
+			return &s.Transitions[i]
+		}
+	}
+	return nil
+}
+
+// FindAssignment returns the index of the assignment with the given ID, or -1
+func (s *Schedule) FindAssignment(id string) int {
+	for i := range s.Assignments {
+		if s.Assignments[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// ShiftDefinition returns the definition of shiftType as it was when the schedule
+// was generated, falling back to the default definitions for older schedules
+func (s *Schedule) ShiftDefinition(shiftType string) *ShiftDefinition {
+	if def := FindShiftDefinition(s.ShiftDefinitions, shiftType); def != nil {
+		return def
+	}
+	return GetShiftDefinition(shiftType)
 }
 
 // ShiftAssignment represents an employee's shift on a specific day
 type ShiftAssignment struct {
+	ID           string    `json:"id,omitempty" bson:"id,omitempty"`
 	EmployeeID   string    `json:"employee_id" bson:"employee_id"`
 	EmployeeName string    `json:"employee_name" bson:"employee_name"`
 	Date         time.Time `json:"date" bson:"date"`
@@ -27,72 +171,353 @@ type ShiftAssignment struct {
 	Hours        float64   `json:"hours" bson:"hours"`           // Duration in hours
 }
 
+// UID returns an identifier for the assignment that stays the same while it is
+// edited. Assignments created before they had IDs are identified by employee,
+// day and shift type.
+func (a ShiftAssignment) UID() string {
+	if a.ID != "" {
+		return a.ID
+	}
+	return a.EmployeeID + "-" + a.Date.UTC().Format("20060102") + "-" + a.ShiftType
+}
+
+// PublicationKey identifies the assignment as seen by the employee working it, so
+// that handing a shift to someone else cancels it for the previous employee
+func (a ShiftAssignment) PublicationKey() string {
+	return a.UID() + "/" + a.EmployeeID
+}
+
+// UnderstaffedShift records a shift that could not be filled to its minimum staffing
+type UnderstaffedShift struct {
+	Date          time.Time `json:"date" bson:"date"`
+	ShiftType     string    `json:"shift_type" bson:"shift_type"`
+	Required      int       `json:"required" bson:"required"` // MinEmployees from the shift requirement
+	Assigned      int       `json:"assigned" bson:"assigned"`
+	MissingSkills []string  `json:"missing_skills,omitempty" bson:"missing_skills,omitempty"` // required skills no available employee could cover
+}
+
+// RelaxedConstraint records a scheduling policy that was broken to reach minimum staffing
+type RelaxedConstraint struct {
+	Date         time.Time `json:"date" bson:"date"`
+	ShiftType    string    `json:"shift_type" bson:"shift_type"`
+	EmployeeID   string    `json:"employee_id" bson:"employee_id"`
+	EmployeeName string    `json:"employee_name" bson:"employee_name"`
+	Constraint   string    `json:"constraint" bson:"constraint"` // overtime, max_consecutive_days, min_rest_hours
+}
+
+// ScheduleWarning records an issue with a manually edited assignment. Warnings do
+// not block the edit; they are shown to the manager alongside the schedule.
+type ScheduleWarning struct {
+	AssignmentID string    `json:"assignment_id" bson:"assignment_id"`
+	Date         time.Time `json:"date" bson:"date"`
+	ShiftType    string    `json:"shift_type" bson:"shift_type"`
+	EmployeeID   string    `json:"employee_id" bson:"employee_id"`
+	EmployeeName string    `json:"employee_name" bson:"employee_name"`
+	Kind         string    `json:"kind" bson:"kind"` // unavailable, overstaffed
+}
+
+// ScheduleWarning kind constants
+const (
+	WarningUnavailable = "unavailable" // the employee is unavailable for the shift
+	WarningOverstaffed = "overstaffed" // the shift has more than MaxEmployees
+)
+
+// ScheduleScore is the quality of a schedule's assignments under the scheduling objective
+type ScheduleScore struct {
+	Objective        float64 `json:"objective" bson:"objective"` // weighted total, lower is better
+	CoverageGaps     int     `json:"coverage_gaps" bson:"coverage_gaps"`
+	PolicyViolations int     `json:"policy_violations" bson:"policy_violations"`
+	HourDeviation    float64 `json:"hour_deviation" bson:"hour_deviation"`     // total hours away from employee targets
+	PreferredShifts  int     `json:"preferred_shifts" bson:"preferred_shifts"` // shifts worked on preferred days
+	Fairness         float64 `json:"fairness" bson:"fairness"`                 // spread of assigned/target ratios in percentage points
+}
+
+// Scheduling constraint constants, in the order the generator is willing to relax them
+const (
+	ConstraintOvertime           = "overtime"
+	ConstraintMaxConsecutiveDays = "max_consecutive_days"
+	ConstraintMinRestHours       = "min_rest_hours"
+)
+
+// ScheduleTransition records one step in a schedule's lifecycle
+type ScheduleTransition struct {
+	Action string    `json:"action" bson:"action"` // approve, publish, complete, reopen, archive
+	From   string    `json:"from" bson:"from"`
+	To     string    `json:"to" bson:"to"`
+	Actor  string    `json:"actor" bson:"actor"` // who made the transition
+	At     time.Time `json:"at" bson:"at"`
+}
+
 // ScheduleStatus constants
 const (
-	ScheduleStatusDraft     = "draft"
-	ScheduleStatusSent      = "sent"
-	ScheduleStatusCompleted = "completed"
+	ScheduleStatusDraft     = "draft"     // being generated and edited
+	ScheduleStatusApproved  = "approved"  // signed off by a manager, not yet visible to staff
+	ScheduleStatusPublished = "published" // released to staff
+	ScheduleStatusCompleted = "completed" // the period has been worked
+	ScheduleStatusArchived  = "archived"  // kept for reference only
+
+	// ScheduleStatusSent is the status of schedules sent to n8n before the
+	// lifecycle existed. They are treated as published.
+	ScheduleStatusSent = "sent"
 )
 
+// ScheduleAction constants name the lifecycle transitions
+const (
+	ScheduleActionApprove  = "approve"
+	ScheduleActionPublish  = "publish"
+	ScheduleActionComplete = "complete"
+	ScheduleActionReopen   = "reopen"
+	ScheduleActionArchive  = "archive"
+)
+
+// scheduleTransitionRule lists the statuses an action may be taken from and the
+// status it leads to
+type scheduleTransitionRule struct {
+	from []string
+	to   string
+}
+
+// scheduleTransitionRules is the schedule lifecycle:
+// draft -> approved -> published -> completed -> archived, where approved and
+// published schedules can be reopened to draft and drafts can be archived unused
+var scheduleTransitionRules = map[string]scheduleTransitionRule{
+	ScheduleActionApprove:  {from: []string{ScheduleStatusDraft}, to: ScheduleStatusApproved},
+	ScheduleActionPublish:  {from: []string{ScheduleStatusApproved}, to: ScheduleStatusPublished},
+	ScheduleActionComplete: {from: []string{ScheduleStatusPublished}, to: ScheduleStatusCompleted},
+	ScheduleActionReopen:   {from: []string{ScheduleStatusApproved, ScheduleStatusPublished}, to: ScheduleStatusDraft},
+	ScheduleActionArchive:  {from: []string{ScheduleStatusDraft, ScheduleStatusCompleted}, to: ScheduleStatusArchived},
+}
+
+// ScheduleActions returns all lifecycle actions in the order they are usually taken
+func ScheduleActions() []string {
+	return []string{ScheduleActionApprove, ScheduleActionPublish, ScheduleActionComplete, ScheduleActionReopen, ScheduleActionArchive}
+}
+
+// Period preset constants
+const (
+	PeriodPresetNextTwoWeeks  = "next_two_weeks" // today and the following 13 days
+	PeriodPresetNextWeek      = "next_week"      // next calendar week, Monday to Sunday
+	PeriodPresetNextFortnight = "next_fortnight" // two weeks starting next Monday
+	PeriodPresetNextMonth     = "next_month"     // next calendar month
+)
+
+// MaxSchedulePeriodDays is the longest period a single schedule may cover
+const MaxSchedulePeriodDays = 93
+
+// PeriodPresets returns all available period presets
+func PeriodPresets() []string {
+	return []string{PeriodPresetNextTwoWeeks, PeriodPresetNextWeek, PeriodPresetNextFortnight, PeriodPresetNextMonth}
+}
+
+// LocalMidnight returns midnight in loc of the calendar date t has in its own location
+func LocalMidnight(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// PresetPeriod returns the first and last day of a period preset relative to now,
+// both at local midnight in loc
+func PresetPeriod(preset string, now time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	today := LocalMidnight(now.In(loc), loc)
+
+	// Days until next Monday; on a Monday that is a week away
+	untilMonday := (8 - int(today.Weekday())) % 7
+	if untilMonday == 0 {
+		untilMonday = 7
+	}
+	nextMonday := today.AddDate(0, 0, untilMonday)
+
+	switch preset {
+	case PeriodPresetNextTwoWeeks:
+		return today, today.AddDate(0, 0, 13), nil
+	case PeriodPresetNextWeek:
+		return nextMonday, nextMonday.AddDate(0, 0, 6), nil
+	case PeriodPresetNextFortnight:
+		return nextMonday, nextMonday.AddDate(0, 0, 13), nil
+	case PeriodPresetNextMonth:
+		first := time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, loc)
+		return first, first.AddDate(0, 1, -1), nil
+	default:
+		return time.Time{}, time.Time{}, ErrUnknownPeriodPreset
+	}
+}
+
 // ShiftType constants
 const (
-	ShiftTypeMorning    = "morning"      // 09:00 - 13:00
-	ShiftTypeAfternoon  = "afternoon"    // 13:00 - 17:00
-	ShiftTypeEvening    = "evening"      // 17:00 - 21:00
-	ShiftTypeFullDay    = "full_day"     // 09:00 - 17:00
-	ShiftTypeNight      = "night"        // 21:00 - 05:00
+	ShiftTypeMorning   = "morning"   // 09:00 - 13:00
+	ShiftTypeAfternoon = "afternoon" // 13:00 - 17:00
+	ShiftTypeEvening   = "evening"   // 17:00 - 21:00
+	ShiftTypeFullDay   = "full_day"  // 09:00 - 17:00
+	ShiftTypeNight     = "night"     // 21:00 - 05:00
 )
 
-// ShiftDefinition defines the time range for each shift type
+// MaxUnpaidBreakMinutes is the longest unpaid break a shift may have. A shift is
+// one span of time, so a longer gap, as in a split shift, would count as time at
+// work when checking availability and rest; each part is defined as a shift of
+// its own instead.
+const MaxUnpaidBreakMinutes = 90
+
+// ShiftDefinition defines a shift the company runs: when it starts and ends, how
+// much of it is an unpaid break and how it is shown in the UI
 type ShiftDefinition struct {
-	Type      string
-	StartTime string
-	EndTime   string
-	Hours     float64
+	Type               string `json:"type" bson:"type"`                                 // identifier referenced by shift requirements, e.g. "early"
+	Name               string `json:"name" bson:"name"`                                 // display name, e.g. "Early (06:30)"
+	StartTime          string `json:"start_time" bson:"start_time"`                     // e.g., "06:30"
+	EndTime            string `json:"end_time" bson:"end_time"`                         // e.g., "14:30"
+	UnpaidBreakMinutes int    `json:"unpaid_break_minutes" bson:"unpaid_break_minutes"` // deducted from paid hours
+	Overnight          bool   `json:"overnight" bson:"overnight"`                       // ends on the following day
+	Color              string `json:"color,omitempty" bson:"color,omitempty"`           // hex colour, e.g. "#3b82f6"
+}
+
+// shiftColorPattern matches a "#rrggbb" hex colour
+var shiftColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Validate checks that the shift definition is well formed
+func (d ShiftDefinition) Validate() error {
+	if d.Type == "" || strings.TrimSpace(d.Name) == "" {
+		return ErrInvalidShiftDefinition
+	}
+
+	start, errStart := time.Parse("15:04", d.StartTime)
+	end, errEnd := time.Parse("15:04", d.EndTime)
+	if errStart != nil || errEnd != nil {
+		return ErrInvalidShiftDefinition
+	}
+
+	// Overnight shifts must end on the clock before they start and vice versa
+	if d.Overnight != !end.After(start) {
+		return ErrInvalidShiftDefinition
+	}
+
+	if d.UnpaidBreakMinutes < 0 || d.PaidHours() <= 0 {
+		return ErrInvalidShiftDefinition
+	}
+	if d.UnpaidBreakMinutes > MaxUnpaidBreakMinutes {
+		return ErrShiftBreakTooLong
+	}
+
+	if d.Color != "" && !shiftColorPattern.MatchString(d.Color) {
+		return ErrInvalidShiftDefinition
+	}
+
+	return nil
 }
 
-// GetShiftDefinitions returns all available shift types
+// DisplayName returns the shift's name, falling back to its type
+func (d ShiftDefinition) DisplayName() string {
+	if d.Name != "" {
+		return d.Name
+	}
+	return d.Type
+}
+
+// Span returns the actual start and end instants of the shift on the given calendar
+// day in loc. Overnight shifts, and shifts whose end time is not after their start
+// time, end on the following day.
+func (d ShiftDefinition) Span(date time.Time, loc *time.Location) (time.Time, time.Time) {
+	y, m, day := date.In(loc).Date()
+	startH, startM := parseClock(d.StartTime)
+	endH, endM := parseClock(d.EndTime)
+
+	start := time.Date(y, m, day, startH, startM, 0, 0, loc)
+	end := time.Date(y, m, day, endH, endM, 0, 0, loc)
+	if d.Overnight || !end.After(start) {
+		end = time.Date(y, m, day+1, endH, endM, 0, 0, loc)
+	}
+	return start, end
+}
+
+// HoursOn returns the paid hours of the shift on the given day: its real duration,
+// which differs from PaidHours when the shift spans a daylight saving time change,
+// less the unpaid break
+func (d ShiftDefinition) HoursOn(date time.Time, loc *time.Location) float64 {
+	start, end := d.Span(date, loc)
+	return end.Sub(start).Hours() - float64(d.UnpaidBreakMinutes)/60
+}
+
+// PaidHours returns the nominal paid hours of the shift, ignoring daylight saving time
+func (d ShiftDefinition) PaidHours() float64 {
+	return d.HoursOn(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), time.UTC)
+}
+
+// parseClock parses an "HH:MM" string, returning 0:00 for malformed input
+func parseClock(clock string) (int, int) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, 0
+	}
+	return t.Hour(), t.Minute()
+}
+
+// GetShiftDefinitions returns the default shift definitions, used when the company
+// has not configured its own
 func GetShiftDefinitions() []ShiftDefinition {
 	return []ShiftDefinition{
-		{Type: ShiftTypeMorning, StartTime: "09:00", EndTime: "13:00", Hours: 4.0},
-		{Type: ShiftTypeAfternoon, StartTime: "13:00", EndTime: "17:00", Hours: 4.0},
-		{Type: ShiftTypeEvening, StartTime: "17:00", EndTime: "21:00", Hours: 4.0},
-		{Type: ShiftTypeFullDay, StartTime: "09:00", EndTime: "17:00", Hours: 8.0},
-		{Type: ShiftTypeNight, StartTime: "21:00", EndTime: "05:00", Hours: 8.0},
+		{Type: ShiftTypeMorning, Name: "Morning", StartTime: "09:00", EndTime: "13:00", Color: "#facc15"},
+		{Type: ShiftTypeAfternoon, Name: "Afternoon", StartTime: "13:00", EndTime: "17:00", Color: "#fb923c"},
+		{Type: ShiftTypeEvening, Name: "Evening", StartTime: "17:00", EndTime: "21:00", Color: "#a855f7"},
+		{Type: ShiftTypeFullDay, Name: "Full Day", StartTime: "09:00", EndTime: "17:00", Color: "#3b82f6"},
+		{Type: ShiftTypeNight, Name: "Night", StartTime: "21:00", EndTime: "05:00", Overnight: true, Color: "#6366f1"},
 	}
 }
 
-// GetShiftDefinition returns the shift definition for a given type
+// GetShiftDefinition returns the default shift definition for a given type
 func GetShiftDefinition(shiftType string) *ShiftDefinition {
-	for _, def := range GetShiftDefinitions() {
-		if def.Type == shiftType {
-			return &def
+	return FindShiftDefinition(GetShiftDefinitions(), shiftType)
+}
+
+// FindShiftDefinition returns the definition of shiftType in defs, or nil if it is not defined
+func FindShiftDefinition(defs []ShiftDefinition, shiftType string) *ShiftDefinition {
+	for i := range defs {
+		if defs[i].Type == shiftType {
+			return &defs[i]
 		}
 	}
 	return nil
 }
 
+// ShiftTypeFromName derives a shift type identifier from a display name,
+// e.g. "Early (06:30)" becomes "early_06_30"
+func ShiftTypeFromName(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if underscore && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+			underscore = false
+		} else {
+			underscore = true
+		}
+	}
+	return b.String()
+}
+
 // N8NSchedulePayload represents the data sent to n8n webhook
 type N8NSchedulePayload struct {
-	ScheduleID     string              `json:"schedule_id"`
-	PeriodStart    string              `json:"period_start"`
-	PeriodEnd      string              `json:"period_end"`
-	Employees      []N8NEmployeeData   `json:"employees"`
-	Assignments    []ShiftAssignment   `json:"assignments"`
-	TotalShifts    int                 `json:"total_shifts"`
-	TotalHours     float64             `json:"total_hours"`
-	GeneratedAt    string              `json:"generated_at"`
-	CompanyContext string              `json:"company_context"` // Company policies and requirements for AI agent
+	ScheduleID     string            `json:"schedule_id"`
+	PeriodStart    string            `json:"period_start"`
+	PeriodEnd      string            `json:"period_end"`
+	Employees      []N8NEmployeeData `json:"employees"`
+	Assignments    []ShiftAssignment `json:"assignments"`
+	TotalShifts    int               `json:"total_shifts"`
+	TotalHours     float64           `json:"total_hours"`
+	GeneratedAt    string            `json:"generated_at"`
+	CompanyContext string            `json:"company_context"` // Company policies and requirements for AI agent
 }
 
 // N8NEmployeeData represents employee data for n8n
 type N8NEmployeeData struct {
-	ID              string  `json:"id"`
-	Name            string  `json:"name"`
-	Email           string  `json:"email"`
-	Role            string  `json:"role"`
-	RoleDescription string  `json:"role_description"`
-	MonthlyHours    int     `json:"monthly_hours"`
-	AssignedHours   float64 `json:"assigned_hours"`
-	AssignedShifts  int     `json:"assigned_shifts"`
+	ID              string         `json:"id"`
+	Name            string         `json:"name"`
+	Email           string         `json:"email"`
+	Role            string         `json:"role"`
+	RoleDescription string         `json:"role_description"`
+	MonthlyHours    int            `json:"monthly_hours"`
+	AssignedHours   float64        `json:"assigned_hours"`
+	AssignedShifts  int            `json:"assigned_shifts"`
+	Skills          []Skill        `json:"skills,omitempty"`
+	Availability    []Availability `json:"availability,omitempty"` // approved periods during the schedule, with any time windows
 }
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPresetPeriod(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	// Late Wednesday in UTC, which is already Thursday in Oslo
+	wednesday := time.Date(2025, 1, 15, 23, 30, 0, 0, time.UTC)
+	// A Monday, where "next Monday" is a week away
+	monday := time.Date(2025, 3, 3, 9, 0, 0, 0, oslo)
+
+	tests := []struct {
+		name      string
+		preset    string
+		now       time.Time
+		wantStart string
+		wantEnd   string
+		wantErr   error
+	}{
+		{"next two weeks", PeriodPresetNextTwoWeeks, wednesday, "2025-01-16", "2025-01-29", nil},
+		{"next week", PeriodPresetNextWeek, wednesday, "2025-01-20", "2025-01-26", nil},
+		{"next week from a Monday", PeriodPresetNextWeek, monday, "2025-03-10", "2025-03-16", nil},
+		{"next fortnight", PeriodPresetNextFortnight, wednesday, "2025-01-20", "2025-02-02", nil},
+		{"next month", PeriodPresetNextMonth, wednesday, "2025-02-01", "2025-02-28", nil},
+		{"next month across year end", PeriodPresetNextMonth, time.Date(2025, 12, 10, 12, 0, 0, 0, oslo), "2026-01-01", "2026-01-31", nil},
+		{"unknown preset", "next_decade", wednesday, "", "", ErrUnknownPeriodPreset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := PresetPeriod(tt.preset, tt.now, oslo)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PresetPeriod() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			for _, got := range []time.Time{start, end} {
+				if got.Location() != oslo || got.Hour() != 0 || got.Minute() != 0 {
+					t.Errorf("%v is not local midnight in Oslo", got)
+				}
+			}
+			if got := start.Format("2006-01-02"); got != tt.wantStart {
+				t.Errorf("start = %s, want %s", got, tt.wantStart)
+			}
+			if got := end.Format("2006-01-02"); got != tt.wantEnd {
+				t.Errorf("end = %s, want %s", got, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestSchedule_Transition(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		action  string
+		want    string
+		wantErr error
+	}{
+		{"approve draft", ScheduleStatusDraft, ScheduleActionApprove, ScheduleStatusApproved, nil},
+		{"publish approved", ScheduleStatusApproved, ScheduleActionPublish, ScheduleStatusPublished, nil},
+		{"complete published", ScheduleStatusPublished, ScheduleActionComplete, ScheduleStatusCompleted, nil},
+		{"complete legacy sent", ScheduleStatusSent, ScheduleActionComplete, ScheduleStatusCompleted, nil},
+		{"reopen approved", ScheduleStatusApproved, ScheduleActionReopen, ScheduleStatusDraft, nil},
+		{"reopen published", ScheduleStatusPublished, ScheduleActionReopen, ScheduleStatusDraft, nil},
+		{"archive draft", ScheduleStatusDraft, ScheduleActionArchive, ScheduleStatusArchived, nil},
+		{"archive completed", ScheduleStatusCompleted, ScheduleActionArchive, ScheduleStatusArchived, nil},
+		{"publish draft", ScheduleStatusDraft, ScheduleActionPublish, "", ErrInvalidScheduleTransition},
+		{"reopen completed", ScheduleStatusCompleted, ScheduleActionReopen, "", ErrInvalidScheduleTransition},
+		{"archive published", ScheduleStatusPublished, ScheduleActionArchive, "", ErrInvalidScheduleTransition},
+		{"anything from archived", ScheduleStatusArchived, ScheduleActionReopen, "", ErrInvalidScheduleTransition},
+		{"unknown action", ScheduleStatusDraft, "shred", "", ErrUnknownScheduleAction},
+	}
+
+	at := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &Schedule{Status: tt.status}
+			err := schedule.Transition(tt.action, "manager", at)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Transition() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if schedule.Status != tt.status || len(schedule.Transitions) != 0 {
+					t.Errorf("Rejected transition changed the schedule: %s, %+v", schedule.Status, schedule.Transitions)
+				}
+				return
+			}
+
+			if schedule.Status != tt.want {
+				t.Errorf("Status = %s, want %s", schedule.Status, tt.want)
+			}
+			got := schedule.LastTransition(tt.want)
+			if got == nil || got.Action != tt.action || got.Actor != "manager" || !got.At.Equal(at) {
+				t.Errorf("Recorded transition = %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// ShiftSwap is an employee's offer of a published shift to colleagues. A
+// colleague claims it, giving one of their own shifts in return for a swap,
+// and a manager approves the change before the schedule is updated.
+type ShiftSwap struct {
+	ID           string           `json:"id" bson:"id"`
+	ScheduleID   string           `json:"schedule_id" bson:"schedule_id"`
+	Kind         string           `json:"kind" bson:"kind"` // swap, giveaway
+	Status       string           `json:"status" bson:"status"`
+	Shift        ShiftAssignment  `json:"shift" bson:"shift"` // the offered shift as it was offered
+	Note         string           `json:"note,omitempty" bson:"note,omitempty"`
+	ClaimedBy    string           `json:"claimed_by,omitempty" bson:"claimed_by,omitempty"` // employee ID
+	ClaimantName string           `json:"claimant_name,omitempty" bson:"claimant_name,omitempty"`
+	ReturnShift  *ShiftAssignment `json:"return_shift,omitempty" bson:"return_shift,omitempty"` // the claimant's shift given in return for a swap
+	DecidedBy    string           `json:"decided_by,omitempty" bson:"decided_by,omitempty"`     // manager who approved or rejected it
+	CreatedAt    time.Time        `json:"created_at" bson:"created_at"`
+	ClaimedAt    *time.Time       `json:"claimed_at,omitempty" bson:"claimed_at,omitempty"`
+	DecidedAt    *time.Time       `json:"decided_at,omitempty" bson:"decided_at,omitempty"`
+	UpdatedAt    time.Time        `json:"updated_at" bson:"updated_at"`
+}
+
+// ShiftSwapKind constants
+const (
+	ShiftSwapKindSwap     = "swap"     // the claimant gives one of their shifts in return
+	ShiftSwapKindGiveaway = "giveaway" // the claimant takes the shift over
+)
+
+// ShiftSwapStatus constants. Offers are open until claimed; claimed offers wait
+// for a manager to approve or reject them. The offering employee can cancel an
+// offer until it is decided.
+const (
+	ShiftSwapStatusOpen      = "open"
+	ShiftSwapStatusClaimed   = "claimed"
+	ShiftSwapStatusApproved  = "approved"
+	ShiftSwapStatusRejected  = "rejected"
+	ShiftSwapStatusCancelled = "cancelled"
+)
+
+// ShiftSwapKinds returns every kind of offer
+func ShiftSwapKinds() []string {
+	return []string{ShiftSwapKindSwap, ShiftSwapKindGiveaway}
+}
+
+// ShiftSwapStatuses returns every offer status
+func ShiftSwapStatuses() []string {
+	return []string{ShiftSwapStatusOpen, ShiftSwapStatusClaimed, ShiftSwapStatusApproved, ShiftSwapStatusRejected, ShiftSwapStatusCancelled}
+}
+
+// Active reports whether the offer has not been decided or cancelled yet
+func (s *ShiftSwap) Active() bool {
+	return s.Status == ShiftSwapStatusOpen || s.Status == ShiftSwapStatusClaimed
+}
+
+// OfferedBy returns the ID of the employee who offered the shift
+func (s *ShiftSwap) OfferedBy() string {
+	return s.Shift.EmployeeID
+}
+
+// Involves reports whether the employee offered or claimed the shift
+func (s *ShiftSwap) Involves(employeeID string) bool {
+	return employeeID != "" && (s.OfferedBy() == employeeID || s.ClaimedBy == employeeID)
+}
+
+// SanitizeShiftSwapNote trims a note and limits it to 500 characters
+func SanitizeShiftSwapNote(note string) string {
+	note = strings.TrimSpace(note)
+	if len(note) > 500 {
+		note = note[:500]
+	}
+	return note
+}
+
+// ApplySwap hands the offered shift to the claimant and, for a swap, the
+// claimant's shift to the offering employee, and publishes the change to staff.
+// It fails if either shift has changed hands since the offer was claimed.
+func (s *Schedule) ApplySwap(swap *ShiftSwap, offerer, claimant Employee) error {
+	i := s.FindAssignment(swap.Shift.ID)
+	if i < 0 || s.Assignments[i].EmployeeID != offerer.ID {
+		return ErrShiftSwapOutdated
+	}
+	j := -1
+	if swap.ReturnShift != nil {
+		j = s.FindAssignment(swap.ReturnShift.ID)
+		if j < 0 || s.Assignments[j].EmployeeID != claimant.ID {
+			return ErrShiftSwapOutdated
+		}
+	}
+
+	s.Assignments[i].EmployeeID, s.Assignments[i].EmployeeName = claimant.ID, claimant.Name
+	if j >= 0 {
+		s.Assignments[j].EmployeeID, s.Assignments[j].EmployeeName = offerer.ID, offerer.Name
+	}
+
+	if !s.hasEmployee(claimant.ID) {
+		s.Employees = append(s.Employees, claimant)
+	}
+	s.recordPublication()
+	return nil
+}
+
+func (s *Schedule) hasEmployee(id string) bool {
+	for _, emp := range s.Employees {
+		if emp.ID == id {
+			return true
+		}
+	}
+	return false
+}
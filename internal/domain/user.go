@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// User is an account that can sign in to the application
+type User struct {
+	ID           string    `json:"id" bson:"id"`
+	Name         string    `json:"name" bson:"name"`
+	Email        string    `json:"email" bson:"email"`
+	Role         string    `json:"role" bson:"role"`                                   // admin, manager or employee
+	EmployeeID   string    `json:"employee_id,omitempty" bson:"employee_id,omitempty"` // the employee an employee account belongs to
+	PasswordHash string    `json:"-" bson:"password_hash"`
+	Active       bool      `json:"active" bson:"active"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// User role constants. Admins can do everything managers can and also change
+// the company configuration and manage accounts.
+const (
+	UserRoleAdmin    = "admin"
+	UserRoleManager  = "manager"
+	UserRoleEmployee = "employee"
+)
+
+// UserRoles returns every user role, most privileged first
+func UserRoles() []string {
+	return []string{UserRoleAdmin, UserRoleManager, UserRoleEmployee}
+}
+
+// Password length limits. bcrypt ignores everything after 72 bytes.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// HasRole reports whether the user has one of the roles
+func (u *User) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if u.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManage reports whether the user may manage employees and schedules
+func (u *User) CanManage() bool {
+	return u.HasRole(UserRoleAdmin, UserRoleManager)
+}
+
+// IsEmployee reports whether the user is the account of the employee
+func (u *User) IsEmployee(employeeID string) bool {
+	return u.Role == UserRoleEmployee && u.EmployeeID != "" && u.EmployeeID == employeeID
+}
+
+// Validate checks if the user data is valid
+func (u *User) Validate() error {
+	if strings.TrimSpace(u.Name) == "" || len(u.Name) > 100 {
+		return ErrInvalidUserName
+	}
+	if !emailRegex.MatchString(u.Email) || len(u.Email) > 255 {
+		return ErrInvalidUserEmail
+	}
+	switch u.Role {
+	case UserRoleAdmin, UserRoleManager:
+	case UserRoleEmployee:
+		if u.EmployeeID == "" {
+			return ErrUserEmployeeRequired
+		}
+	default:
+		return ErrInvalidUserRole
+	}
+	return nil
+}
+
+// ValidatePassword checks the length of a new password
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+// UserInput represents the data needed to create or update a user. An empty
+// password leaves the password of an existing user unchanged.
+type UserInput struct {
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	Role       string `json:"role"`
+	EmployeeID string `json:"employee_id,omitempty"`
+	Password   string `json:"password,omitempty"`
+}
+
+// SanitizeUserInput trims input data and lower-cases the email, which is the login name
+func SanitizeUserInput(input *UserInput) {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Email = strings.ToLower(strings.TrimSpace(input.Email))
+	input.Role = strings.TrimSpace(input.Role)
+	input.EmployeeID = strings.TrimSpace(input.EmployeeID)
+	if input.Role != UserRoleEmployee {
+		input.EmployeeID = ""
+	}
+}
+
+// Session is a signed-in browser or API client. Only a hash of the session
+// token is stored, so a copy of the database cannot be used to sign in.
+type Session struct {
+	Token     string    `json:"-" bson:"-"` // only known when the session is created
+	TokenHash string    `json:"-" bson:"token_hash"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	CSRFToken string    `json:"-" bson:"csrf_token"` // sent back with every state-changing request
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// Expired reports whether the session has expired at the given time
+func (s *Session) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
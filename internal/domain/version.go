@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// Records that several people edit carry a version that starts at 1 and that
+// every update increments. An update names the version it was made to, and
+// is rejected with ErrConflict when the record has moved on since.
+
+// CheckVersion returns ErrConflict if the version a change was made to is not
+// the current one. Version 0 stands for the current version, for callers that
+// do not track versions.
+func CheckVersion(expected, current int) error {
+	if expected != 0 && expected != current {
+		return ErrConflict
+	}
+	return nil
+}
+
+// FieldChange is a field that differs between two versions of a record, with
+// the values formatted for display
+type FieldChange struct {
+	Field  string `json:"field" bson:"field"`
+	Before string `json:"before" bson:"before"`
+	After  string `json:"after" bson:"after"`
+}
+
+// bookkeepingFields change with every save and are left out of diffs
+var bookkeepingFields = []string{"version", "created_at", "updated_at"}
+
+// Diff compares two versions of a record field by field, by their JSON
+// encoding. Nested values are compared and shown whole. Either version may be
+// nil, for records that were created or removed.
+func Diff(before, after any) []FieldChange {
+	beforeFields, afterFields := jsonFields(before), jsonFields(after)
+
+	var names []string
+	for name := range beforeFields {
+		names = append(names, name)
+	}
+	for name := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var changes []FieldChange
+	for _, name := range names {
+		b, a := beforeFields[name], afterFields[name]
+		if b == a || slices.Contains(bookkeepingFields, name) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, Before: b, After: a})
+	}
+	return changes
+}
+
+// jsonFields returns the top-level fields of v's JSON object, strings
+// unquoted and other values as compact JSON. Empty strings, lists and objects
+// are left out, so that a field being absent and being empty compare equal.
+func jsonFields(v any) map[string]string {
+	fields := make(map[string]string)
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fields
+	}
+
+	for name, value := range raw {
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			if s != "" {
+				fields[name] = s
+			}
+			continue
+		}
+		switch text := strings.TrimSpace(string(value)); text {
+		case "null", "[]", "{}":
+		default:
+			fields[name] = text
+		}
+	}
+	return fields
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheckVersion(t *testing.T) {
+	if err := CheckVersion(0, 3); err != nil {
+		t.Errorf("CheckVersion(0, 3) error = %v, want nil", err)
+	}
+	if err := CheckVersion(3, 3); err != nil {
+		t.Errorf("CheckVersion(3, 3) error = %v, want nil", err)
+	}
+	if err := CheckVersion(2, 3); !errors.Is(err, ErrConflict) {
+		t.Errorf("CheckVersion(2, 3) error = %v, want %v", err, ErrConflict)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := &Employee{ID: "emp1", Name: "John Doe", Role: "Barista", MonthlyHours: 120, Version: 1}
+	after := *before
+	after.Role = "Head Barista"
+	after.MonthlyHours = 140
+	after.Skills = []Skill{{Name: "barista"}}
+	after.Version = 2
+
+	want := []FieldChange{
+		{Field: "monthly_hours", Before: "120", After: "140"},
+		{Field: "role", Before: "Barista", After: "Head Barista"},
+		{Field: "skills", Before: "", After: `[{"name":"barista"}]`},
+	}
+	if got := Diff(before, &after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v, want %+v", got, want)
+	}
+
+	if got := Diff(before, before); len(got) != 0 {
+		t.Errorf("Diff() of equal versions = %+v, want none", got)
+	}
+
+	// A created record has every field it sets changed
+	created := Diff(nil, &Employee{Name: "Jane", MonthlyHours: 80, Active: true})
+	want = []FieldChange{
+		{Field: "active", After: "true"},
+		{Field: "monthly_hours", After: "80"},
+		{Field: "name", After: "Jane"},
+	}
+	if !reflect.DeepEqual(created, want) {
+		t.Errorf("Diff(nil, employee) = %+v, want %+v", created, want)
+	}
+}
@@ -0,0 +1,162 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func testSheet() Sheet {
+	return Sheet{
+		Name:   "Assignments",
+		Header: []string{"Employee", "Shift", "Hours"},
+		Rows: [][]any{
+			{"Jane Smith", "Morning", 4.5},
+			{"=HYPERLINK(\"x\")", "Evening, late", 8},
+		},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b strings.Builder
+	if err := WriteCSV(&b, testSheet()); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	want := "Employee,Shift,Hours\n" +
+		"Jane Smith,Morning,4.5\n" +
+		"\"'=HYPERLINK(\"\"x\"\")\",\"Evening, late\",8\n"
+	if b.String() != want {
+		t.Errorf("WriteCSV() = %q, want %q", b.String(), want)
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	hours := Sheet{Name: "Hours / week [draft]", Header: []string{"Employee"}, Rows: [][]any{{"Tom & Jerry"}}}
+
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, testSheet(), hours); err != nil {
+		t.Fatalf("WriteXLSX() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("workbook is not a zip archive: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name] = string(content)
+	}
+
+	for name, wants := range map[string][]string{
+		"[Content_Types].xml":        {`PartName="/xl/worksheets/sheet2.xml"`},
+		"_rels/.rels":                {`Target="xl/workbook.xml"`},
+		"xl/workbook.xml":            {`<sheet name="Assignments" sheetId="1" r:id="rId1"/>`, `<sheet name="Hours - week -draft-" sheetId="2" r:id="rId2"/>`},
+		"xl/_rels/workbook.xml.rels": {`Target="worksheets/sheet2.xml"`, `Target="styles.xml"`},
+		"xl/styles.xml":              {`<b/>`},
+		"xl/worksheets/sheet1.xml": {
+			`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">Employee</t></is></c>`,
+			`<c r="C2" s="0"><v>4.5</v></c>`,
+			`<c r="C3" s="0"><v>8</v></c>`,
+		},
+		"xl/worksheets/sheet2.xml": {`Tom &amp; Jerry`},
+	} {
+		content, ok := parts[name]
+		if !ok {
+			t.Errorf("workbook has no part %s", name)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(content, want) {
+				t.Errorf("%s does not contain %q:\n%s", name, want, content)
+			}
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	for name, input := range map[string]string{
+		"comma":     "\xef\xbb\xbfName,Hours\nJane,160\n",
+		"semicolon": "Name;Hours\r\nJane;160\r\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			records, err := ReadCSV(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("ReadCSV() error = %v", err)
+			}
+			if len(records) != 2 || records[0][0] != "Name" || records[1][1] != "160" {
+				t.Errorf("ReadCSV() = %q", records)
+			}
+		})
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, testSheet()); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := ReadXLSX(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadXLSX() error = %v", err)
+	}
+	want := [][]string{
+		{"Employee", "Shift", "Hours"},
+		{"Jane Smith", "Morning", "4.5"},
+		{"=HYPERLINK(\"x\")", "Evening, late", "8"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("ReadXLSX() = %q, want %q", rows, want)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, rows[i], want[i])
+		}
+	}
+
+	if _, err := ReadXLSX(strings.NewReader("not a zip"), 9); err != ErrInvalidWorkbook {
+		t.Errorf("ReadXLSX() of a non-workbook error = %v, want %v", err, ErrInvalidWorkbook)
+	}
+}
+
+func TestReadXLSXRejectsOversizedPart(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("xl/sharedStrings.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("<sst><si><t>")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(bytes.Repeat([]byte("a"), maxPartSize)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadXLSX(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != ErrInvalidWorkbook {
+		t.Errorf("ReadXLSX() of an oversized part error = %v, want %v", err, ErrInvalidWorkbook)
+	}
+}
@@ -0,0 +1,198 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidWorkbook is returned when an XLSX file cannot be read
+var ErrInvalidWorkbook = errors.New("not a valid XLSX workbook")
+
+// ReadCSV reads all records of a CSV file. Semicolon-separated files, as saved
+// by spreadsheet apps in many European locales, are detected from the first line.
+func ReadCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 byte order mark
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		cr.Comma = ';'
+	}
+	return cr.ReadAll()
+}
+
+// maxColumns is the number of columns a worksheet can have
+const maxColumns = 16384
+
+// maxPartSize limits the decompressed size of each XML part of a workbook
+const maxPartSize = 64 << 20
+
+// ReadXLSX reads the rows of the first worksheet of an XLSX workbook as text.
+// Empty cells are returned as empty strings.
+func ReadXLSX(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidWorkbook
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := decodeZipXML(f, &sst); err != nil {
+			return nil, err
+		}
+		for _, item := range sst.Items {
+			shared = append(shared, item.String())
+		}
+	}
+
+	f, ok := files[firstWorksheet(files)]
+	if !ok {
+		return nil, ErrInvalidWorkbook
+	}
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeZipXML(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		var values []string
+		for i, cell := range row.Cells {
+			col := columnIndex(cell.Ref)
+			if col < 0 {
+				col = i
+			}
+			if col >= maxColumns {
+				return nil, ErrInvalidWorkbook
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err == nil && index >= 0 && index < len(shared) {
+					values[col] = shared[index]
+				}
+			case "inlineStr":
+				values[col] = cell.Inline.String()
+			default:
+				values[col] = cell.Value
+			}
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+// xlsxText is a shared or inline string, either plain or split into formatted runs
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+// firstWorksheet returns the archive path of the workbook's first sheet
+func firstWorksheet(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	wb, okWorkbook := files["xl/workbook.xml"]
+	wbRels, okRels := files["xl/_rels/workbook.xml.rels"]
+	if !okWorkbook || !okRels || decodeZipXML(wb, &workbook) != nil || decodeZipXML(wbRels, &rels) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+func decodeZipXML(f *zip.File, v any) error {
+	if f.UncompressedSize64 > maxPartSize {
+		return ErrInvalidWorkbook
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return ErrInvalidWorkbook
+	}
+	defer rc.Close()
+
+	// The size in the zip header can lie, so the read is limited as well
+	if err := xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v); err != nil {
+		return ErrInvalidWorkbook
+	}
+	return nil
+}
+
+// columnIndex returns the zero-based column of a cell reference such as "AB12",
+// or -1 if the reference has no column
+func columnIndex(ref string) int {
+	index := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		index = index*26 + int(c-'A') + 1
+	}
+	return index - 1
+}
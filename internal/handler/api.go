@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/isak/restySched/internal/service"
+	"github.com/rs/zerolog/log"
+)
+
+// APIHandler serves the versioned JSON API under /api/v1. It uses the same
+// services as the HTML handlers.
+type APIHandler struct {
+	employees     *service.EmployeeService
+	schedules     *service.ScheduleService
+	calendar      *service.CalendarService
+	swaps         *service.SwapService
+	leave         *service.LeaveService
+	audit         *service.AuditService
+	companyConfig *service.CompanyConfigService
+}
+
+// NewAPIHandler creates a new JSON API handler
+func NewAPIHandler(
+	employees *service.EmployeeService,
+	schedules *service.ScheduleService,
+	calendar *service.CalendarService,
+	swaps *service.SwapService,
+	leave *service.LeaveService,
+	audit *service.AuditService,
+	companyConfig *service.CompanyConfigService,
+) *APIHandler {
+	return &APIHandler{employees: employees, schedules: schedules, calendar: calendar, swaps: swaps, leave: leave, audit: audit, companyConfig: companyConfig}
+}
+
+// Pagination limits of list endpoints
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// maxJSONBodySize limits the size of JSON request bodies
+const maxJSONBodySize = 1 << 20
+
+// ListResponse is a page of a list endpoint
+type ListResponse struct {
+	Data       any        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Pagination describes which part of a list a page holds
+type Pagination struct {
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// page holds the limit and offset query parameters of a list request
+type page struct {
+	limit  int
+	offset int
+}
+
+// parsePage reads the limit and offset query parameters
+func parsePage(r *http.Request) (page, error) {
+	p := page{limit: defaultPageLimit}
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return p, fmt.Errorf("%w: limit must be between 1 and %d", errInvalidQuery, maxPageLimit)
+		}
+		p.limit = limit
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return p, fmt.Errorf("%w: offset must be 0 or more", errInvalidQuery)
+		}
+		p.offset = offset
+	}
+	return p, nil
+}
+
+// paginate returns the page of items as a list response
+func paginate[T any](items []T, p page) ListResponse {
+	start := min(p.offset, len(items))
+	end := min(start+p.limit, len(items))
+
+	data := items[start:end]
+	if data == nil {
+		data = []T{}
+	}
+	return ListResponse{
+		Data:       data,
+		Pagination: Pagination{Total: len(items), Limit: p.limit, Offset: p.offset},
+	}
+}
+
+// decodeJSON decodes a request body into v, rejecting unknown fields
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJSONBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidJSON, err)
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return fmt.Errorf("%w: unexpected data after the JSON object", errInvalidJSON)
+	}
+	return nil
+}
+
+// writeJSON sends v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Failed to write JSON response")
+	}
+}
+
+// NotFound answers requests for unknown API paths with a JSON error instead
+// of the HTML home page
+func (h *APIHandler) NotFound(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusNotFound, ErrorResponse{
+		Error:   "not_found",
+		Message: "no API endpoint " + r.Method + " " + r.URL.Path,
+		Code:    http.StatusNotFound,
+	})
+}
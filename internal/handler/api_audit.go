@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// ListAudit lists the changes made to employees, schedules and the company
+// configuration, newest first
+func (h *APIHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	h.listAudit(w, r, domain.AuditFilter{})
+}
+
+// ListEmployeeAudit lists the changes made to an employee and their
+// availability, newest first
+func (h *APIHandler) ListEmployeeAudit(w http.ResponseWriter, r *http.Request) {
+	h.listAudit(w, r, domain.AuditFilter{EntityType: domain.AuditEntityEmployee, EntityID: r.PathValue("id")})
+}
+
+// ListScheduleAudit lists the changes made to a schedule, newest first. The
+// trail of a deleted schedule is kept.
+func (h *APIHandler) ListScheduleAudit(w http.ResponseWriter, r *http.Request) {
+	h.listAudit(w, r, domain.AuditFilter{EntityType: domain.AuditEntitySchedule, EntityID: r.PathValue("id")})
+}
+
+// ListCompanyConfigAudit lists the changes made to the company configuration,
+// newest first
+func (h *APIHandler) ListCompanyConfigAudit(w http.ResponseWriter, r *http.Request) {
+	h.listAudit(w, r, domain.AuditFilter{EntityType: domain.AuditEntityCompanyConfig})
+}
+
+// listAudit writes a page of the audit entries selected by scope and the query
+func (h *APIHandler) listAudit(w http.ResponseWriter, r *http.Request, scope domain.AuditFilter) {
+	p, err := parsePage(r)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+	filter, err := parseAuditFilter(r, scope)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.audit.List(r.Context(), filter)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginate(entries, p))
+}
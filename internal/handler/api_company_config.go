@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// GetCompanyConfig returns the company configuration
+func (h *APIHandler) GetCompanyConfig(w http.ResponseWriter, r *http.Request) {
+	config, err := h.companyConfig.GetConfig(r.Context())
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, config.Version)
+	writeJSON(w, http.StatusOK, config)
+}
+
+// UpdateCompanyConfig validates and replaces the company configuration at the
+// version of the If-Match header
+func (h *APIHandler) UpdateCompanyConfig(w http.ResponseWriter, r *http.Request) {
+	var config domain.CompanyConfig
+	if err := decodeJSON(w, r, &config); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+	version, err := requiredVersion(r)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := config.Validate(); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := h.companyConfig.SaveConfig(r.Context(), &config, version); err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, config.Version)
+	writeJSON(w, http.StatusOK, config)
+}
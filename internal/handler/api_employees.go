@@ -0,0 +1,402 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isak/restySched/internal/auth"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/service"
+)
+
+// ListEmployees lists employees, optionally filtered by the active, role, skill
+// and q (name or email contains) query parameters
+func (h *APIHandler) ListEmployees(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePage(r)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	var active *bool
+	if v := query.Get("active"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			respondWithJSONError(w, fmt.Errorf("%w: active must be true or false", errInvalidQuery), http.StatusBadRequest)
+			return
+		}
+		active = &b
+	}
+	role := strings.TrimSpace(query.Get("role"))
+	skill := query.Get("skill")
+	search := strings.ToLower(strings.TrimSpace(query.Get("q")))
+
+	employees, err := h.employees.GetAllEmployees(r.Context())
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	filtered := make([]domain.Employee, 0, len(employees))
+	for _, emp := range employees {
+		if active != nil && emp.Active != *active {
+			continue
+		}
+		if role != "" && !strings.EqualFold(emp.Role, role) {
+			continue
+		}
+		if skill != "" && !emp.HasSkill(skill, time.Now()) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(emp.Name), search) && !strings.Contains(strings.ToLower(emp.Email), search) {
+			continue
+		}
+		filtered = append(filtered, emp)
+	}
+
+	writeJSON(w, http.StatusOK, paginate(filtered, p))
+}
+
+// GetEmployee returns an employee
+func (h *APIHandler) GetEmployee(w http.ResponseWriter, r *http.Request) {
+	employee, err := h.employees.GetEmployee(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, employee.Version)
+	writeJSON(w, http.StatusOK, employee)
+}
+
+// CreateEmployee creates an employee from an EmployeeCreateInput
+func (h *APIHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
+	var input domain.EmployeeCreateInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	employee, err := h.employees.CreateEmployee(r.Context(), input)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/employees/"+employee.ID)
+	setETag(w, employee.Version)
+	writeJSON(w, http.StatusCreated, employee)
+}
+
+// UpdateEmployee replaces an employee's details, including leave allowances,
+// with an EmployeeCreateInput. Availability, status and calendar feed are kept.
+// The employee is only changed if it is still at the version of the If-Match header.
+func (h *APIHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
+	var input domain.EmployeeCreateInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+	domain.SanitizeEmployeeInput(&input)
+	version, err := requiredVersion(r)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	employee, err := h.employees.GetEmployee(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if version != 0 {
+		employee.Version = version
+	}
+
+	employee.Name = input.Name
+	employee.Email = input.Email
+	employee.Role = input.Role
+	employee.RoleDescription = input.RoleDescription
+	employee.MonthlyHours = input.MonthlyHours
+	employee.Skills = input.Skills
+	employee.LeaveAllowances = input.LeaveAllowances
+
+	if err := h.employees.UpdateEmployee(r.Context(), employee); err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, employee.Version)
+	writeJSON(w, http.StatusOK, employee)
+}
+
+// DeleteEmployee deactivates an employee
+func (h *APIHandler) DeleteEmployee(w http.ResponseWriter, r *http.Request) {
+	if err := h.employees.DeleteEmployee(r.Context(), r.PathValue("id")); err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AvailabilityInput is an availability period in an API request, with dates as
+// YYYY-MM-DD and an optional time window as HH:MM. A weekly RRULE makes it
+// repeat within the range; its UNTIL sets the end date if end_date is empty,
+// and without either it repeats without end.
+type AvailabilityInput struct {
+	StartDate  string   `json:"start_date"`
+	EndDate    string   `json:"end_date,omitempty"`
+	Type       string   `json:"type"`
+	Reason     string   `json:"reason,omitempty"`
+	ShiftTypes []string `json:"shift_types,omitempty"`
+	StartTime  string   `json:"start_time,omitempty"`
+	EndTime    string   `json:"end_time,omitempty"`
+	RRule      string   `json:"rrule,omitempty"`
+	Exceptions []string `json:"exceptions,omitempty"`
+}
+
+// ListAvailability lists an employee's availability periods
+func (h *APIHandler) ListAvailability(w http.ResponseWriter, r *http.Request) {
+	employee, err := h.employees.GetEmployee(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	availability := employee.Availability
+	if availability == nil {
+		availability = []domain.Availability{}
+	}
+	writeJSON(w, http.StatusOK, availability)
+}
+
+// AddAvailability adds an availability period to an employee and returns it
+func (h *APIHandler) AddAvailability(w http.ResponseWriter, r *http.Request) {
+	var input AvailabilityInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	availability, err := input.availability()
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	// Availability employees enter themselves waits for a manager's approval
+	add := h.employees.SubmitAvailability
+	if canManage(r) {
+		add = h.employees.AddEmployeeAvailability
+	}
+
+	added, err := add(r.Context(), r.PathValue("id"), availability)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/employees/"+added.EmployeeID+"/availability/"+added.ID)
+	writeJSON(w, http.StatusCreated, added)
+}
+
+// availability converts the input into an availability period
+func (input AvailabilityInput) availability() (domain.Availability, error) {
+	var recurrence *domain.Recurrence
+	var until time.Time
+	if input.RRule != "" {
+		var err error
+		if recurrence, until, err = domain.ParseRRule(input.RRule); err != nil {
+			return domain.Availability{}, err
+		}
+		if recurrence.Exceptions, err = parseExceptions(input.Exceptions); err != nil {
+			return domain.Availability{}, err
+		}
+	}
+
+	startDate, errStart := time.Parse("2006-01-02", input.StartDate)
+	endDate := until
+	var errEnd error
+	if input.EndDate != "" || recurrence == nil {
+		endDate, errEnd = time.Parse("2006-01-02", input.EndDate)
+	}
+	if errStart != nil || errEnd != nil {
+		return domain.Availability{}, fmt.Errorf("%w: start_date and end_date must be YYYY-MM-DD", domain.ErrInvalidAvailability)
+	}
+
+	return domain.Availability{
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Type:       input.Type,
+		Reason:     strings.TrimSpace(input.Reason),
+		ShiftTypes: input.ShiftTypes,
+		Recurrence: recurrence,
+		StartTime:  input.StartTime,
+		EndTime:    input.EndTime,
+	}, nil
+}
+
+// GetAvailability returns one of an employee's availability periods
+func (h *APIHandler) GetAvailability(w http.ResponseWriter, r *http.Request) {
+	availability, err := h.employees.GetAvailability(r.Context(), r.PathValue("id"), r.PathValue("availabilityID"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, availability)
+}
+
+// UpdateAvailability replaces an availability period with an AvailabilityInput
+// and returns it. The period applies at once.
+func (h *APIHandler) UpdateAvailability(w http.ResponseWriter, r *http.Request) {
+	var input AvailabilityInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	changes, err := input.availability()
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	availability, err := h.employees.UpdateEmployeeAvailability(r.Context(), r.PathValue("id"), r.PathValue("availabilityID"), changes)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, availability)
+}
+
+// DeleteAvailability removes an employee's availability period
+func (h *APIHandler) DeleteAvailability(w http.ResponseWriter, r *http.Request) {
+	// Employees may only withdraw availability that has not been approved
+	remove := h.employees.WithdrawAvailability
+	if canManage(r) {
+		remove = h.employees.RemoveEmployeeAvailability
+	}
+
+	if err := remove(r.Context(), r.PathValue("id"), r.PathValue("availabilityID")); err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAvailabilityInPeriod lists every employee's availability periods that
+// apply to any day of the required from/to date range (YYYY-MM-DD)
+func (h *APIHandler) ListAvailabilityInPeriod(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePage(r)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	from, err := parseQueryDate(query.Get("from"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+	to, err := parseQueryDate(query.Get("to"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		respondWithJSONError(w, fmt.Errorf("%w: from and to are required, with to on or after from", errInvalidQuery), http.StatusBadRequest)
+		return
+	}
+
+	availability, err := h.employees.GetAvailabilityInPeriod(r.Context(), from, to)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if availability == nil {
+		availability = []domain.Availability{}
+	}
+
+	writeJSON(w, http.StatusOK, paginate(availability, p))
+}
+
+// ListPendingAvailability lists the availability employees submitted that
+// waits for approval
+func (h *APIHandler) ListPendingAvailability(w http.ResponseWriter, r *http.Request) {
+	pending, err := h.employees.GetPendingAvailability(r.Context())
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, pending)
+}
+
+// ApproveAvailability approves a pending availability period and returns it
+func (h *APIHandler) ApproveAvailability(w http.ResponseWriter, r *http.Request) {
+	h.reviewAvailability(w, r, h.employees.ApproveAvailability)
+}
+
+// RejectAvailability rejects a pending availability period and returns it
+func (h *APIHandler) RejectAvailability(w http.ResponseWriter, r *http.Request) {
+	h.reviewAvailability(w, r, h.employees.RejectAvailability)
+}
+
+func (h *APIHandler) reviewAvailability(
+	w http.ResponseWriter,
+	r *http.Request,
+	review func(ctx context.Context, employeeID, id string) (*domain.Availability, error),
+) {
+	availability, err := review(r.Context(), r.PathValue("id"), r.PathValue("availabilityID"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, availability)
+}
+
+// MyShifts lists the signed-in user's upcoming published shifts; accounts not
+// linked to an employee have none
+func (h *APIHandler) MyShifts(w http.ResponseWriter, r *http.Request) {
+	user := auth.User(r.Context())
+
+	shifts := []service.EmployeeShift{}
+	if user.EmployeeID != "" {
+		var err error
+		shifts, err = h.calendar.UpcomingShifts(r.Context(), user.EmployeeID, time.Now())
+		if err != nil {
+			respondWithJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, shifts)
+}
+
+// MyHours compares the hours the signed-in employee is scheduled for this
+// month with their monthly hours
+func (h *APIHandler) MyHours(w http.ResponseWriter, r *http.Request) {
+	user := auth.User(r.Context())
+	if user.EmployeeID == "" {
+		respondWithJSONError(w, domain.ErrEmployeeNotFound, http.StatusNotFound)
+		return
+	}
+
+	hours, err := h.calendar.HoursInMonth(r.Context(), user.EmployeeID, time.Now())
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, hours)
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// LeaveInput is a request for leave
+type LeaveInput struct {
+	Type      string `json:"type"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// ListLeave lists an employee's leave requests, latest first
+func (h *APIHandler) ListLeave(w http.ResponseWriter, r *http.Request) {
+	requests, err := h.leave.EmployeeLeave(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, requests)
+}
+
+// RequestLeave files a leave request for an employee
+func (h *APIHandler) RequestLeave(w http.ResponseWriter, r *http.Request) {
+	var input LeaveInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	startDate, errStart := time.Parse("2006-01-02", input.StartDate)
+	endDate, errEnd := time.Parse("2006-01-02", input.EndDate)
+	if errStart != nil || errEnd != nil {
+		respondWithJSONError(w, fmt.Errorf("%w: start_date and end_date must be YYYY-MM-DD", domain.ErrInvalidLeavePeriod), http.StatusBadRequest)
+		return
+	}
+
+	request, err := h.leave.RequestLeave(r.Context(), r.PathValue("id"), input.Type, startDate, endDate, input.Reason)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, request)
+}
+
+// LeaveBalances returns an employee's leave balances per type for the year
+// query parameter, by default the current year
+func (h *APIHandler) LeaveBalances(w http.ResponseWriter, r *http.Request) {
+	year := time.Now().Year()
+	if value := r.URL.Query().Get("year"); value != "" {
+		var err error
+		if year, err = strconv.Atoi(value); err != nil || year < 1 {
+			respondWithJSONError(w, fmt.Errorf("%w: year must be a positive number", errInvalidQuery), http.StatusBadRequest)
+			return
+		}
+	}
+
+	balances, err := h.leave.Balances(r.Context(), r.PathValue("id"), year)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, balances)
+}
+
+// CancelLeave cancels one of an employee's pending or approved requests
+func (h *APIHandler) CancelLeave(w http.ResponseWriter, r *http.Request) {
+	request, err := h.leave.CancelLeave(r.Context(), r.PathValue("id"), r.PathValue("leaveID"), requestActor(r))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, request)
+}
+
+// ListPendingLeave lists the leave requests waiting for a decision, with the
+// published shifts each one collides with
+func (h *APIHandler) ListPendingLeave(w http.ResponseWriter, r *http.Request) {
+	requests, err := h.leave.PendingLeave(r.Context())
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, requests)
+}
+
+// GetLeave returns a leave request
+func (h *APIHandler) GetLeave(w http.ResponseWriter, r *http.Request) {
+	request, err := h.leave.GetLeave(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, request)
+}
+
+// ApproveLeave approves a pending leave request, blocking the employee from
+// being scheduled during it
+func (h *APIHandler) ApproveLeave(w http.ResponseWriter, r *http.Request) {
+	h.decideLeave(w, r, h.leave.ApproveLeave)
+}
+
+// RejectLeave turns down a pending leave request
+func (h *APIHandler) RejectLeave(w http.ResponseWriter, r *http.Request) {
+	h.decideLeave(w, r, h.leave.RejectLeave)
+}
+
+func (h *APIHandler) decideLeave(
+	w http.ResponseWriter,
+	r *http.Request,
+	decide func(ctx context.Context, id, actor string) (*domain.LeaveRequest, error),
+) {
+	request, err := decide(r.Context(), r.PathValue("id"), requestActor(r))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, request)
+}
@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// GenerateScheduleInput selects the period and strategy of a new schedule.
+// Without a preset or dates it covers the next two weeks.
+type GenerateScheduleInput struct {
+	Preset    string `json:"preset,omitempty"`
+	StartDate string `json:"start_date,omitempty"` // YYYY-MM-DD, with preset empty or "custom"
+	EndDate   string `json:"end_date,omitempty"`   // YYYY-MM-DD, included
+	Strategy  string `json:"strategy,omitempty"`   // empty uses the company default
+}
+
+// TransitionInput is a lifecycle action on a schedule
+type TransitionInput struct {
+	Action    string `json:"action"`                // approve, publish, complete, reopen or archive
+	SendToN8N bool   `json:"send_to_n8n,omitempty"` // with publish, also send the schedule to n8n
+}
+
+// AssignmentInput places an employee on a shift for an added or moved assignment
+type AssignmentInput struct {
+	EmployeeID string `json:"employee_id,omitempty"` // required when adding
+	Date       string `json:"date"`                  // YYYY-MM-DD
+	ShiftType  string `json:"shift_type"`
+}
+
+// SwapInput names the assignment to swap employees with
+type SwapInput struct {
+	OtherID string `json:"other_id"`
+}
+
+// ListSchedules lists schedules, optionally filtered by lifecycle status and by
+// a from/to date range (YYYY-MM-DD) the schedule period overlaps
+func (h *APIHandler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePage(r)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	status := query.Get("status")
+	from, err := parseQueryDate(query.Get("from"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+	to, err := parseQueryDate(query.Get("to"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	schedules, err := h.schedules.GetAllSchedules(r.Context())
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	filtered := make([]domain.Schedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		if status != "" && schedule.LifecycleStatus() != status {
+			continue
+		}
+		if !from.IsZero() && schedule.PeriodEnd.Before(from) {
+			continue
+		}
+		if !to.IsZero() && schedule.PeriodStart.After(to.AddDate(0, 0, 1)) {
+			continue
+		}
+		filtered = append(filtered, schedule)
+	}
+
+	writeJSON(w, http.StatusOK, paginate(filtered, p))
+}
+
+// GetSchedule returns a schedule
+func (h *APIHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := h.schedules.GetSchedule(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, schedule.Version)
+	writeJSON(w, http.StatusOK, schedule)
+}
+
+// GenerateSchedule generates a draft schedule
+func (h *APIHandler) GenerateSchedule(w http.ResponseWriter, r *http.Request) {
+	var input GenerateScheduleInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := generateSchedule(r.Context(), h.schedules, input.Preset, input.StartDate, input.EndDate, input.Strategy)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/schedules/"+schedule.ID)
+	setETag(w, schedule.Version)
+	writeJSON(w, http.StatusCreated, schedule)
+}
+
+// DeleteSchedule deletes a draft schedule
+func (h *APIHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := h.schedules.DeleteSchedule(r.Context(), r.PathValue("id")); err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TransitionSchedule moves a schedule through its lifecycle and returns it
+func (h *APIHandler) TransitionSchedule(w http.ResponseWriter, r *http.Request) {
+	var input TransitionInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+	version, err := requiredVersion(r)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	id := r.PathValue("id")
+	var schedule *domain.Schedule
+	if input.Action == domain.ScheduleActionPublish {
+		schedule, err = h.schedules.PublishSchedule(r.Context(), id, version, requestActor(r), input.SendToN8N)
+	} else {
+		schedule, err = h.schedules.TransitionSchedule(r.Context(), id, version, input.Action, requestActor(r))
+	}
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, schedule.Version)
+	writeJSON(w, http.StatusOK, schedule)
+}
+
+// SendToN8N sends a published schedule to n8n and returns it
+func (h *APIHandler) SendToN8N(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := h.schedules.SendScheduleToN8N(r.Context(), id); err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.GetSchedule(w, r)
+}
+
+// ListAssignments lists a schedule's assignments, optionally filtered by the
+// employee_id, shift_type and date (YYYY-MM-DD) query parameters
+func (h *APIHandler) ListAssignments(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePage(r)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	employeeID := query.Get("employee_id")
+	shiftType := query.Get("shift_type")
+	date, err := parseQueryDate(query.Get("date"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.schedules.GetSchedule(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	loc := schedule.Location()
+	filtered := make([]domain.ShiftAssignment, 0, len(schedule.Assignments))
+	for _, a := range schedule.Assignments {
+		if employeeID != "" && a.EmployeeID != employeeID {
+			continue
+		}
+		if shiftType != "" && a.ShiftType != shiftType {
+			continue
+		}
+		if !date.IsZero() && a.Date.In(loc).Format("2006-01-02") != date.Format("2006-01-02") {
+			continue
+		}
+		filtered = append(filtered, a)
+	}
+
+	writeJSON(w, http.StatusOK, paginate(filtered, p))
+}
+
+// AddAssignment adds a shift to a draft schedule and returns the schedule with
+// any warnings the edit caused
+func (h *APIHandler) AddAssignment(w http.ResponseWriter, r *http.Request) {
+	var input AssignmentInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		respondWithJSONError(w, domain.ErrInvalidAssignment, http.StatusBadRequest)
+		return
+	}
+	version, err := requiredVersion(r)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.schedules.AddAssignment(r.Context(), r.PathValue("id"), version, input.EmployeeID, date, input.ShiftType)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, schedule.Version)
+	writeJSON(w, http.StatusCreated, schedule)
+}
+
+// RemoveAssignment removes a shift from a draft schedule and returns the schedule
+func (h *APIHandler) RemoveAssignment(w http.ResponseWriter, r *http.Request) {
+	version, err := requiredVersion(r)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.schedules.RemoveAssignment(r.Context(), r.PathValue("id"), version, r.PathValue("assignmentID"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, schedule.Version)
+	writeJSON(w, http.StatusOK, schedule)
+}
+
+// MoveAssignment moves a shift in a draft schedule to another day or shift type
+// and returns the schedule
+func (h *APIHandler) MoveAssignment(w http.ResponseWriter, r *http.Request) {
+	var input AssignmentInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		respondWithJSONError(w, domain.ErrInvalidAssignment, http.StatusBadRequest)
+		return
+	}
+	version, err := requiredVersion(r)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.schedules.MoveAssignment(r.Context(), r.PathValue("id"), version, r.PathValue("assignmentID"), date, input.ShiftType)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, schedule.Version)
+	writeJSON(w, http.StatusOK, schedule)
+}
+
+// SwapAssignments swaps the employees of two shifts in a draft schedule and
+// returns the schedule
+func (h *APIHandler) SwapAssignments(w http.ResponseWriter, r *http.Request) {
+	var input SwapInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+	version, err := requiredVersion(r)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.schedules.SwapAssignments(r.Context(), r.PathValue("id"), version, r.PathValue("assignmentID"), input.OtherID)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, schedule.Version)
+	writeJSON(w, http.StatusOK, schedule)
+}
+
+// parseQueryDate parses an optional YYYY-MM-DD query parameter
+func parseQueryDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: dates must be YYYY-MM-DD", errInvalidQuery)
+	}
+	return date, nil
+}
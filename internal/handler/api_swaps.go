@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/isak/restySched/internal/auth"
+	"github.com/isak/restySched/internal/domain"
+)
+
+// ShiftOfferInput is an offer of one of the signed-in employee's shifts
+type ShiftOfferInput struct {
+	ScheduleID   string `json:"schedule_id"`
+	AssignmentID string `json:"assignment_id"`
+	Kind         string `json:"kind"`
+	Note         string `json:"note,omitempty"`
+}
+
+// ClaimInput claims an offer; a swap names the claimant's shift given in return
+type ClaimInput struct {
+	ReturnAssignmentID string `json:"return_assignment_id,omitempty"`
+}
+
+// ListShiftOffers lists the open offers of other employees, with whether the
+// signed-in employee can claim them
+func (h *APIHandler) ListShiftOffers(w http.ResponseWriter, r *http.Request) {
+	offers, err := h.swaps.OpenOffers(r.Context(), auth.User(r.Context()).EmployeeID)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, offers)
+}
+
+// OfferShift puts one of the signed-in employee's upcoming shifts on offer
+func (h *APIHandler) OfferShift(w http.ResponseWriter, r *http.Request) {
+	var input ShiftOfferInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	employeeID := auth.User(r.Context()).EmployeeID
+	if employeeID == "" {
+		respondWithJSONError(w, domain.ErrForbidden, http.StatusForbidden)
+		return
+	}
+
+	swap, err := h.swaps.OfferShift(r.Context(), employeeID, input.ScheduleID, input.AssignmentID, input.Kind, input.Note)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, swap)
+}
+
+// ListPendingSwaps lists the claimed offers waiting for a decision
+func (h *APIHandler) ListPendingSwaps(w http.ResponseWriter, r *http.Request) {
+	swaps, err := h.swaps.PendingSwaps(r.Context())
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if swaps == nil {
+		swaps = []domain.ShiftSwap{}
+	}
+
+	writeJSON(w, http.StatusOK, swaps)
+}
+
+// ClaimShift claims an open offer for the signed-in employee
+func (h *APIHandler) ClaimShift(w http.ResponseWriter, r *http.Request) {
+	var input ClaimInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	employeeID := auth.User(r.Context()).EmployeeID
+	if employeeID == "" {
+		respondWithJSONError(w, domain.ErrForbidden, http.StatusForbidden)
+		return
+	}
+
+	swap, err := h.swaps.ClaimShift(r.Context(), r.PathValue("id"), employeeID, input.ReturnAssignmentID)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, swap)
+}
+
+// CancelShiftOffer withdraws one of the signed-in employee's offers
+func (h *APIHandler) CancelShiftOffer(w http.ResponseWriter, r *http.Request) {
+	swap, err := h.swaps.CancelOffer(r.Context(), r.PathValue("id"), auth.User(r.Context()).EmployeeID)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, swap)
+}
+
+// ApproveSwap applies a claimed offer to its schedule
+func (h *APIHandler) ApproveSwap(w http.ResponseWriter, r *http.Request) {
+	h.decideSwap(w, r, h.swaps.ApproveSwap)
+}
+
+// RejectSwap turns down a claimed offer
+func (h *APIHandler) RejectSwap(w http.ResponseWriter, r *http.Request) {
+	h.decideSwap(w, r, h.swaps.RejectSwap)
+}
+
+func (h *APIHandler) decideSwap(
+	w http.ResponseWriter,
+	r *http.Request,
+	decide func(ctx context.Context, id, actor string) (*domain.ShiftSwap, error),
+) {
+	swap, err := decide(r.Context(), r.PathValue("id"), requestActor(r))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, swap)
+}
+
+// ListScheduleSwaps lists every offer made in a schedule, oldest first
+func (h *APIHandler) ListScheduleSwaps(w http.ResponseWriter, r *http.Request) {
+	swaps, err := h.swaps.ScheduleHistory(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, swaps)
+}
+
+// MySwaps lists the open and claimed offers the signed-in employee made or claimed
+func (h *APIHandler) MySwaps(w http.ResponseWriter, r *http.Request) {
+	swaps := []domain.ShiftSwap{}
+	if employeeID := auth.User(r.Context()).EmployeeID; employeeID != "" {
+		var err error
+		if swaps, err = h.swaps.EmployeeSwaps(r.Context(), employeeID); err != nil {
+			respondWithJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, swaps)
+}
+
+// MyNotifications lists the signed-in employee's recent notifications, newest first
+func (h *APIHandler) MyNotifications(w http.ResponseWriter, r *http.Request) {
+	notifications := []domain.Notification{}
+	if employeeID := auth.User(r.Context()).EmployeeID; employeeID != "" {
+		var err error
+		if notifications, err = h.swaps.Notifications(r.Context(), employeeID); err != nil {
+			respondWithJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, notifications)
+}
+
+// MarkNotificationsRead marks all of the signed-in employee's notifications as read
+func (h *APIHandler) MarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	if employeeID := auth.User(r.Context()).EmployeeID; employeeID != "" {
+		if err := h.swaps.MarkNotificationsRead(r.Context(), employeeID); err != nil {
+			respondWithJSONError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
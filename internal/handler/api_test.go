@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"default page", "", []int{1, 2, 3, 4, 5}},
+		{"limit and offset", "?limit=2&offset=1", []int{2, 3}},
+		{"offset past the end", "?offset=10", []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parsePage(httptest.NewRequest(http.MethodGet, "/api/v1/employees"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("parsePage() error = %v", err)
+			}
+			resp := paginate(items, p)
+			data := resp.Data.([]int)
+			if len(data) != len(tt.want) || resp.Pagination.Total != len(items) {
+				t.Fatalf("paginate() = %v (total %d), want %v (total %d)", data, resp.Pagination.Total, tt.want, len(items))
+			}
+			for i := range data {
+				if data[i] != tt.want[i] {
+					t.Errorf("paginate() = %v, want %v", data, tt.want)
+				}
+			}
+		})
+	}
+
+	for _, query := range []string{"?limit=0", "?limit=1000", "?offset=-1", "?limit=ten"} {
+		if _, err := parsePage(httptest.NewRequest(http.MethodGet, "/api/v1/employees"+query, nil)); !errors.Is(err, errInvalidQuery) {
+			t.Errorf("parsePage(%q) error = %v, want %v", query, err, errInvalidQuery)
+		}
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"valid", `{"name": "Jane", "monthly_hours": 160}`, false},
+		{"unknown field", `{"name": "Jane", "hours": 160}`, true},
+		{"trailing data", `{"name": "Jane"} {}`, true},
+		{"wrong type", `{"monthly_hours": "lots"}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/employees", strings.NewReader(tt.body))
+			var input domain.EmployeeCreateInput
+			err := decodeJSON(httptest.NewRecorder(), r, &input)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, errInvalidJSON)) {
+				t.Errorf("decodeJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRespondWithErrorEscapesMessage(t *testing.T) {
+	w := httptest.NewRecorder()
+	respondWithError(w, fmt.Errorf("%w: %q", errInvalidQuery, "<script>alert(1)</script>"), http.StatusInternalServerError)
+
+	if body := w.Body.String(); strings.Contains(body, "<script>") || !strings.Contains(body, "&lt;script&gt;") {
+		t.Errorf("respondWithError() body = %s, want the message escaped", body)
+	}
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("respondWithError() status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestRespondWithJSONError(t *testing.T) {
+	tests := []struct {
+		err         error
+		wantStatus  int
+		wantError   string
+		wantMessage string
+	}{
+		{domain.ErrEmployeeNotFound, http.StatusNotFound, "not_found", domain.ErrEmployeeNotFound.Error()},
+		{domain.ErrInvalidScheduleTransition, http.StatusConflict, "conflict", domain.ErrInvalidScheduleTransition.Error()},
+		{domain.ErrConflict, http.StatusConflict, "conflict", domain.ErrConflict.Error()},
+		{errIfMatchRequired, http.StatusPreconditionRequired, "precondition_required", errIfMatchRequired.Error()},
+		{errors.New("connection refused"), http.StatusInternalServerError, "internal_server_error", "An internal error occurred. Please try again later."},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		respondWithJSONError(w, tt.err, http.StatusInternalServerError)
+
+		var resp ErrorResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("response is not JSON: %v", err)
+		}
+		if w.Code != tt.wantStatus || resp.Code != tt.wantStatus || resp.Error != tt.wantError || resp.Message != tt.wantMessage {
+			t.Errorf("respondWithJSONError(%v) = %d %+v, want %d %s %q", tt.err, w.Code, resp, tt.wantStatus, tt.wantError, tt.wantMessage)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Errorf("Content-Type = %q, want JSON", ct)
+		}
+	}
+}
+
+func TestExpectedVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		form    string
+		want    int
+		wantErr bool
+	}{
+		{"neither", "", "", 0, false},
+		{"if-match", `"3"`, "", 3, false},
+		{"weak if-match", `W/"3"`, "", 3, false},
+		{"any version", "*", "version=2", 0, false},
+		{"form field", "", "version=2", 2, false},
+		{"if-match before the form", `"3"`, "version=2", 3, false},
+		{"not a version", `"abc"`, "", 0, true},
+		{"version 0", `"0"`, "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/employees/emp1", strings.NewReader(tt.form))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			got, err := expectedVersion(r)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, errInvalidIfMatch)) {
+				t.Fatalf("expectedVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("expectedVersion() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiredVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    int
+		wantErr error
+	}{
+		{"if-match", `"3"`, 3, nil},
+		{"weak if-match", `W/"3"`, 3, nil},
+		{"missing", "", 0, errIfMatchRequired},
+		{"any version", "*", 0, errIfMatchRequired},
+		{"not a version", `"abc"`, 0, errInvalidIfMatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/api/v1/employees/emp1", nil)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			got, err := requiredVersion(r)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("requiredVersion() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("requiredVersion() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAuditFilter(t *testing.T) {
+	scheduleScope := domain.AuditFilter{EntityType: domain.AuditEntitySchedule, EntityID: "s1"}
+
+	tests := []struct {
+		name    string
+		query   string
+		scope   domain.AuditFilter
+		want    domain.AuditFilter
+		wantErr bool
+	}{
+		{"empty", "", domain.AuditFilter{}, domain.AuditFilter{}, false},
+		{
+			"every filter", "entity_type=employee&entity_id=emp1&actor=jane@example.com&action=update&since=2024-03-01&until=2024-03-31", domain.AuditFilter{},
+			domain.AuditFilter{
+				EntityType: domain.AuditEntityEmployee, EntityID: "emp1", Actor: "jane@example.com", Action: domain.AuditActionUpdate,
+				Since: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Until: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			}, false,
+		},
+		{"scope wins over the query", "entity_type=employee&entity_id=emp1", scheduleScope, scheduleScope, false},
+		{"unknown entity type", "entity_type=user", domain.AuditFilter{}, domain.AuditFilter{}, true},
+		{"unknown action", "action=rename", domain.AuditFilter{}, domain.AuditFilter{}, true},
+		{"invalid date", "since=March", domain.AuditFilter{}, domain.AuditFilter{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/audit?"+tt.query, nil)
+
+			got, err := parseAuditFilter(r, tt.scope)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, errInvalidQuery)) {
+				t.Fatalf("parseAuditFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseAuditFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/service"
+	"github.com/isak/restySched/web/templates"
+	"github.com/rs/zerolog/log"
+)
+
+// auditPageLimit is how many of the newest entries the audit pages show
+const auditPageLimit = 200
+
+// AuditHandler serves the audit trail: the audit log page of every change and
+// the history of one employee, schedule or the company configuration
+type AuditHandler struct {
+	audit     *service.AuditService
+	employees *service.EmployeeService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(audit *service.AuditService, employees *service.EmployeeService) *AuditHandler {
+	return &AuditHandler{audit: audit, employees: employees}
+}
+
+// ShowAuditLog shows the newest changes the query selects, with a form to
+// filter them
+func (h *AuditHandler) ShowAuditLog(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r, domain.AuditFilter{})
+	if err != nil {
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+	filter.Limit = auditPageLimit
+
+	entries, err := h.audit.List(r.Context(), filter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch audit log")
+		handleInternalError(w, err, "fetch audit log")
+		return
+	}
+
+	if err := templates.AuditLog(r.URL.Query(), entries, auditPageLimit, h.audit.Retention()).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render audit log")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// ShowEmployeeAudit shows the changes made to an employee and their
+// availability
+func (h *AuditHandler) ShowEmployeeAudit(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	employee, err := h.employees.GetEmployee(r.Context(), id)
+	if err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Employee not found")
+		respondWithError(w, err, http.StatusNotFound)
+		return
+	}
+
+	entries, ok := h.entityEntries(w, r, domain.AuditEntityEmployee, id)
+	if !ok {
+		return
+	}
+
+	if err := templates.EmployeeAudit(*employee, entries, auditPageLimit).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render employee audit")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// ShowScheduleAudit shows the changes made to a schedule
+func (h *AuditHandler) ShowScheduleAudit(w http.ResponseWriter, r *http.Request) {
+	h.renderTrail(w, r, domain.AuditEntitySchedule, r.PathValue("id"))
+}
+
+// ShowConfigAudit shows the changes made to the company configuration
+func (h *AuditHandler) ShowConfigAudit(w http.ResponseWriter, r *http.Request) {
+	h.renderTrail(w, r, domain.AuditEntityCompanyConfig, "")
+}
+
+// renderTrail renders the newest changes made to an entity as a list
+func (h *AuditHandler) renderTrail(w http.ResponseWriter, r *http.Request, entityType, entityID string) {
+	entries, ok := h.entityEntries(w, r, entityType, entityID)
+	if !ok {
+		return
+	}
+
+	if err := templates.AuditTrail(entries, auditPageLimit).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render audit trail")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// entityEntries fetches the newest changes made to an entity, responding with
+// the error if that fails
+func (h *AuditHandler) entityEntries(w http.ResponseWriter, r *http.Request, entityType, entityID string) ([]domain.AuditEntry, bool) {
+	filter := domain.AuditFilter{EntityType: entityType, EntityID: entityID, Limit: auditPageLimit}
+	entries, err := h.audit.List(r.Context(), filter)
+	if err != nil {
+		log.Error().Err(err).Str("entity_type", entityType).Str("entity_id", entityID).Msg("Failed to fetch audit trail")
+		handleInternalError(w, err, "fetch audit trail")
+		return nil, false
+	}
+	return entries, true
+}
+
+// parseAuditFilter reads the audit filter of the query on top of scope. The
+// entity_type and entity_id parameters are only read when scope names no
+// entity type. until is inclusive, so it selects entries before the next day.
+func parseAuditFilter(r *http.Request, scope domain.AuditFilter) (domain.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := scope
+
+	if filter.EntityType == "" {
+		filter.EntityType = query.Get("entity_type")
+		filter.EntityID = strings.TrimSpace(query.Get("entity_id"))
+		if filter.EntityType != "" && !slices.Contains(domain.AuditEntities(), filter.EntityType) {
+			return filter, fmt.Errorf("%w: entity_type must be one of %s", errInvalidQuery, strings.Join(domain.AuditEntities(), ", "))
+		}
+	}
+
+	filter.Actor = strings.TrimSpace(query.Get("actor"))
+	filter.Action = query.Get("action")
+	if filter.Action != "" && !slices.Contains(domain.AuditActions(), filter.Action) {
+		return filter, fmt.Errorf("%w: unknown action %q", errInvalidQuery, filter.Action)
+	}
+
+	since, err := parseQueryDate(query.Get("since"))
+	if err != nil {
+		return filter, err
+	}
+	until, err := parseQueryDate(query.Get("until"))
+	if err != nil {
+		return filter, err
+	}
+	filter.Since = since
+	if !until.IsZero() {
+		filter.Until = until.AddDate(0, 0, 1)
+	}
+	return filter, nil
+}
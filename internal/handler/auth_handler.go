@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/isak/restySched/internal/auth"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/openapi"
+	"github.com/isak/restySched/internal/service"
+	"github.com/isak/restySched/web/templates"
+	"github.com/rs/zerolog/log"
+)
+
+// Session cookie and CSRF header names
+const (
+	sessionCookieName = "restysched_session"
+	csrfHeaderName    = "X-CSRF-Token"
+)
+
+// AuthHandler signs users in and out and enforces who may call each route
+type AuthHandler struct {
+	service       *service.AuthService
+	secureCookies bool
+}
+
+// NewAuthHandler creates a new auth handler. With secureCookies the session
+// cookie is only sent over HTTPS.
+func NewAuthHandler(service *service.AuthService, secureCookies bool) *AuthHandler {
+	return &AuthHandler{service: service, secureCookies: secureCookies}
+}
+
+// LoginInput is the body of an API login
+type LoginInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// SessionResponse describes the signed-in user of an API session. Requests
+// other than GET must send the CSRF token in the X-CSRF-Token header.
+type SessionResponse struct {
+	User      *domain.User `json:"user"`
+	CSRFToken string       `json:"csrf_token"`
+}
+
+// ShowLogin shows the login page, or sends signed-in users on
+func (h *AuthHandler) ShowLogin(w http.ResponseWriter, r *http.Request) {
+	next := safeRedirect(r.URL.Query().Get("next"))
+	if auth.User(r.Context()) != nil {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
+
+	if err := templates.LoginPage(next, "").Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render login page")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// Login signs a user in from the login form and redirects to the page they
+// wanted to see
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	next := safeRedirect(r.FormValue("next"))
+
+	user, session, err := h.service.Login(r.Context(), r.FormValue("email"), r.FormValue("password"))
+	if errors.Is(err, domain.ErrInvalidCredentials) {
+		log.Warn().Str("email", r.FormValue("email")).Msg("Failed login")
+		w.WriteHeader(http.StatusUnauthorized)
+		if err := templates.LoginPage(next, err.Error()).Render(r.Context(), w); err != nil {
+			log.Error().Err(err).Msg("Failed to render login page")
+		}
+		return
+	}
+	if err != nil {
+		handleInternalError(w, err, "login")
+		return
+	}
+
+	log.Info().Str("user_id", user.ID).Str("email", user.Email).Msg("User signed in")
+
+	h.setSessionCookie(w, session)
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// Logout ends the session and returns to the login page
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := h.endSession(w, r); err != nil {
+		handleInternalError(w, err, "logout")
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", "/login")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// APILogin signs a user in and sets the session cookie. The response holds the
+// CSRF token to send with requests other than GET.
+func (h *AuthHandler) APILogin(w http.ResponseWriter, r *http.Request) {
+	var input LoginInput
+	if err := decodeJSON(w, r, &input); err != nil {
+		respondWithJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, session, err := h.service.Login(r.Context(), input.Email, input.Password)
+	if err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().Str("user_id", user.ID).Str("email", user.Email).Msg("User signed in")
+
+	h.setSessionCookie(w, session)
+	writeJSON(w, http.StatusOK, SessionResponse{User: user, CSRFToken: session.CSRFToken})
+}
+
+// APILogout ends the session
+func (h *AuthHandler) APILogout(w http.ResponseWriter, r *http.Request) {
+	if err := h.endSession(w, r); err != nil {
+		respondWithJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// APIMe returns the signed-in user and their CSRF token
+func (h *AuthHandler) APIMe(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, SessionResponse{User: auth.User(r.Context()), CSRFToken: auth.CSRFToken(r.Context())})
+}
+
+// endSession deletes the request's session and its cookie
+func (h *AuthHandler) endSession(w http.ResponseWriter, r *http.Request) error {
+	if cookie, err := r.Cookie(sessionCookieName); err == nil {
+		if err := h.service.Logout(r.Context(), cookie.Value); err != nil {
+			return err
+		}
+	}
+	h.clearSessionCookie(w)
+	return nil
+}
+
+func (h *AuthHandler) setSessionCookie(w http.ResponseWriter, session *domain.Session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    session.Token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (h *AuthHandler) clearSessionCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// RequireAccess identifies the signed-in user of each request and lets it
+// reach next only if the OpenAPI operation of its route allows the user's
+// role. Operations without security are public. Requests other than GET and
+// HEAD to protected routes must carry the session's CSRF token in the
+// X-CSRF-Token header. Routes missing from the document are refused.
+func (h *AuthHandler) RequireAccess(doc *openapi.Document, mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie(sessionCookieName); err == nil {
+			user, session, err := h.service.Authenticate(r.Context(), cookie.Value)
+			switch {
+			case err == nil:
+				r = r.WithContext(auth.WithUser(r.Context(), user, session.CSRFToken))
+			case errors.Is(err, domain.ErrSessionNotFound):
+				h.clearSessionCookie(w)
+			default:
+				log.Error().Err(err).Msg("Failed to authenticate session")
+				http.Error(w, "An internal error occurred. Please try again later.", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		_, pattern := mux.Handler(r)
+		if pattern == "" {
+			// Not found or method not allowed; let the mux answer
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		method, path := openapi.SplitPattern(pattern)
+		op := doc.Operation(method, path)
+		if op == nil {
+			log.Error().Str("pattern", pattern).Msg("Route is not described in the OpenAPI document")
+			deny(w, r, path, domain.ErrForbidden)
+			return
+		}
+
+		if err := authorize(op, path, r); err != nil {
+			deny(w, r, path, err)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorize checks that the request's user may call the operation at path
+func authorize(op *openapi.Operation, path string, r *http.Request) error {
+	if len(op.Security) == 0 {
+		return nil
+	}
+
+	user := auth.User(r.Context())
+	if user == nil {
+		return domain.ErrUnauthenticated
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		token := r.Header.Get(csrfHeaderName)
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(auth.CSRFToken(r.Context()))) != 1 {
+			return domain.ErrInvalidCSRFToken
+		}
+	}
+
+	if user.HasRole(op.Roles...) {
+		return nil
+	}
+	if op.OwnerParameter != "" {
+		owner, err := url.PathUnescape(openapi.PathValue(path, r.URL.EscapedPath(), op.OwnerParameter))
+		if err == nil && user.IsEmployee(owner) {
+			return nil
+		}
+	}
+
+	log.Warn().Str("user_id", user.ID).Str("method", r.Method).Str("path", r.URL.Path).Msg("Access denied")
+	return domain.ErrForbidden
+}
+
+// canManage reports whether the request's user is an admin or manager, whose
+// changes apply without approval
+func canManage(r *http.Request) bool {
+	user := auth.User(r.Context())
+	return user != nil && user.CanManage()
+}
+
+// deny answers a request that may not reach its route: JSON API requests with
+// a JSON error, and web requests of signed-out users by sending them to the
+// login page
+func deny(w http.ResponseWriter, r *http.Request, path string, err error) {
+	if strings.HasPrefix(path, "/api/v1/") {
+		respondWithJSONError(w, err, http.StatusForbidden)
+		return
+	}
+
+	if errors.Is(err, domain.ErrUnauthenticated) {
+		if r.Header.Get("HX-Request") == "true" {
+			// htmx follows HX-Redirect whatever the status
+			w.Header().Set("HX-Redirect", loginURL(r.Header.Get("HX-Current-URL")))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			http.Redirect(w, r, loginURL(r.URL.RequestURI()), http.StatusSeeOther)
+			return
+		}
+	}
+
+	respondWithError(w, err, http.StatusForbidden)
+}
+
+// loginURL returns the login page URL that leads back to the page at current
+func loginURL(current string) string {
+	if u, err := url.Parse(current); err == nil {
+		if next := safeRedirect(u.RequestURI()); next != "/" {
+			return "/login?next=" + url.QueryEscape(next)
+		}
+	}
+	return "/login"
+}
+
+// safeRedirect returns target if it is a path on this site, or "/" otherwise,
+// so that the login page cannot be used to send users elsewhere
+func safeRedirect(target string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") ||
+		strings.HasPrefix(target, "/login") {
+		return "/"
+	}
+	return target
+}
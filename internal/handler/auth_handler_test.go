@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/isak/restySched/internal/auth"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/openapi"
+)
+
+func TestAuthorize(t *testing.T) {
+	doc := NewOpenAPIDocument()
+
+	admin := &domain.User{ID: "u1", Role: domain.UserRoleAdmin, Active: true}
+	manager := &domain.User{ID: "u2", Role: domain.UserRoleManager, Active: true}
+	employee := &domain.User{ID: "u3", Role: domain.UserRoleEmployee, EmployeeID: "emp1", Active: true}
+
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		user    *domain.User
+		csrf    string
+		wantErr error
+	}{
+		{"public route without a user", "GET /health", "/health", nil, "", nil},
+		{"public POST without a CSRF token", "POST /login", "/login", nil, "", nil},
+		{"protected route without a user", "GET /employees", "/employees", nil, "", domain.ErrUnauthenticated},
+		{"manager route as manager", "GET /employees", "/employees", manager, "", nil},
+		{"manager route as employee", "GET /employees", "/employees", employee, "", domain.ErrForbidden},
+		{"admin route as manager", "GET /users", "/users", manager, "", domain.ErrForbidden},
+		{"admin route as admin", "GET /users", "/users", admin, "", nil},
+		{"POST without a CSRF token", "POST /logout", "/logout", manager, "", domain.ErrInvalidCSRFToken},
+		{"POST with a wrong CSRF token", "POST /logout", "/logout", manager, "wrong", domain.ErrInvalidCSRFToken},
+		{"POST with the CSRF token", "POST /logout", "/logout", manager, "csrf", nil},
+		{"own availability", "GET /employees/{id}/availability", "/employees/emp1/availability", employee, "", nil},
+		{"someone else's availability", "GET /employees/{id}/availability", "/employees/emp2/availability", employee, "", domain.ErrForbidden},
+		{"own employee record in the API", "GET /api/v1/employees/{id}", "/api/v1/employees/emp1", employee, "", nil},
+		{"deleting own employee record", "DELETE /api/v1/employees/{id}", "/api/v1/employees/emp1", employee, "csrf", domain.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, path := openapi.SplitPattern(tt.pattern)
+			op := doc.Operation(method, path)
+			if op == nil {
+				t.Fatalf("no operation for %q", tt.pattern)
+			}
+
+			req := httptest.NewRequest(method, tt.path, nil)
+			if tt.user != nil {
+				req = req.WithContext(auth.WithUser(req.Context(), tt.user, "csrf"))
+			}
+			if tt.csrf != "" {
+				req.Header.Set(csrfHeaderName, tt.csrf)
+			}
+
+			if err := authorize(op, path, req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("authorize() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSafeRedirect(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/schedules?week=2", "/schedules?week=2"},
+		{"", "/"},
+		{"https://evil.example.com", "/"},
+		{"//evil.example.com", "/"},
+		{"/\\evil.example.com", "/"},
+		{"/login?next=/", "/"},
+	}
+
+	for _, tt := range tests {
+		if got := safeRedirect(tt.target); got != tt.want {
+			t.Errorf("safeRedirect(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
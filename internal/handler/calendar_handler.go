@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/isak/restySched/internal/auth"
+	"github.com/isak/restySched/internal/service"
+	"github.com/isak/restySched/web/templates"
+	"github.com/rs/zerolog/log"
+)
+
+// CalendarHandler serves employees' personal iCalendar feeds
+type CalendarHandler struct {
+	service *service.CalendarService
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(service *service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{service: service}
+}
+
+// EmployeeFeed serves the iCalendar feed a token belongs to. The token may be
+// followed by ".ics", which some calendar apps require.
+func (h *CalendarHandler) EmployeeFeed(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSuffix(r.PathValue("token"), ".ics")
+
+	calendar, err := h.service.EmployeeFeed(r.Context(), token)
+	if err != nil {
+		if status := errorStatus(err, http.StatusInternalServerError); status == http.StatusNotFound {
+			// Do not reveal whether the token ever existed
+			http.NotFound(w, r)
+			return
+		}
+		handleInternalError(w, err, "build calendar feed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="shifts.ics"`)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if _, err := calendar.WriteTo(w); err != nil {
+		log.Error().Err(err).Msg("Failed to write calendar feed")
+	}
+}
+
+// ShowFeed shows an employee's calendar feed URL, creating it on first use
+func (h *CalendarHandler) ShowFeed(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	employee, err := h.service.EnsureFeedToken(r.Context(), id)
+	if err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to get calendar feed")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := templates.CalendarFeed(*employee, feedURL(r, employee.CalendarToken)).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render calendar feed")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// RotateFeed replaces an employee's calendar feed URL, revoking the old one
+func (h *CalendarHandler) RotateFeed(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	employee, err := h.service.RotateFeedToken(r.Context(), id)
+	if err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to rotate calendar feed")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().Str("id", id).Msg("Calendar feed rotated")
+
+	if err := templates.CalendarFeed(*employee, feedURL(r, employee.CalendarToken)).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render calendar feed")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// MyShifts shows the signed-in user's upcoming published shifts and their
+// hours this month
+func (h *CalendarHandler) MyShifts(w http.ResponseWriter, r *http.Request) {
+	user := auth.User(r.Context())
+
+	shifts := []service.EmployeeShift{}
+	var hours *service.MonthHours
+	if user.EmployeeID != "" {
+		var err error
+		shifts, err = h.service.UpcomingShifts(r.Context(), user.EmployeeID, time.Now())
+		if err != nil {
+			log.Error().Err(err).Str("employee_id", user.EmployeeID).Msg("Failed to fetch shifts")
+			handleInternalError(w, err, "fetch shifts")
+			return
+		}
+		hours, err = h.service.HoursInMonth(r.Context(), user.EmployeeID, time.Now())
+		if err != nil {
+			log.Error().Err(err).Str("employee_id", user.EmployeeID).Msg("Failed to sum hours")
+			handleInternalError(w, err, "sum hours")
+			return
+		}
+	}
+
+	if err := templates.MyShifts(*user, shifts, hours).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render shifts")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// feedURL returns the absolute URL of a calendar feed as seen by the client,
+// honouring the scheme set by a TLS-terminating proxy
+func feedURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/calendar/" + token + ".ics"
+}
@@ -1,22 +1,25 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 
-	"github.com/yourusername/restysched/internal/repository"
-	"github.com/yourusername/restysched/internal/domain"
-	"github.com/yourusername/restysched/web/templates"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/service"
+	"github.com/isak/restySched/web/templates"
 )
 
 type CompanyConfigHandler struct {
-	repo repository.CompanyConfigRepository
+	service *service.CompanyConfigService
 }
 
-func NewCompanyConfigHandler(repo repository.CompanyConfigRepository) *CompanyConfigHandler {
+func NewCompanyConfigHandler(service *service.CompanyConfigService) *CompanyConfigHandler {
 	return &CompanyConfigHandler{
-		repo: repo,
+		service: service,
 	}
 }
 
@@ -24,7 +27,7 @@ func (h *CompanyConfigHandler) ShowConfig(w http.ResponseWriter, r *http.Request
 	ctx := r.Context()
 
 	// Get or create default config
-	config, err := h.repo.GetOrCreate(ctx)
+	config, err := h.service.GetConfig(ctx)
 	if err != nil {
 		http.Error(w, "Failed to load configuration", http.StatusInternalServerError)
 		return
@@ -40,6 +43,11 @@ func (h *CompanyConfigHandler) SaveConfig(w http.ResponseWriter, r *http.Request
 		http.Error(w, "Invalid form data", http.StatusBadRequest)
 		return
 	}
+	version, err := expectedVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Parse working days
 	workingDays := []int{}
@@ -50,17 +58,34 @@ func (h *CompanyConfigHandler) SaveConfig(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	// Parse shift definitions. Renamed shifts keep their type so requirements and
+	// availability that reference them stay linked; new shifts derive it from the name.
+	shiftDefs := []domain.ShiftDefinition{}
+	for _, i := range formIndexes(r.Form, "shift_def_name_") {
+		idx := strconv.Itoa(i)
+		name := strings.TrimSpace(r.FormValue("shift_def_name_" + idx))
+		shiftType := r.FormValue("shift_def_type_" + idx)
+		if shiftType == "" {
+			shiftType = domain.ShiftTypeFromName(name)
+		}
+		shiftDefs = append(shiftDefs, domain.ShiftDefinition{
+			Type:               shiftType,
+			Name:               name,
+			StartTime:          r.FormValue("shift_def_start_" + idx),
+			EndTime:            r.FormValue("shift_def_end_" + idx),
+			UnpaidBreakMinutes: parseInt(r.FormValue("shift_def_break_"+idx), 0),
+			Overnight:          r.FormValue("shift_def_overnight_"+idx) == "true",
+			Color:              strings.ToLower(r.FormValue("shift_def_color_" + idx)),
+		})
+	}
+
 	// Parse shift requirements
 	shiftReqs := []domain.ShiftRequirement{}
-	i := 0
-	for {
-		shiftTypeKey := "shift_type_" + strconv.Itoa(i)
-		if r.FormValue(shiftTypeKey) == "" {
-			break
-		}
+	for _, i := range formIndexes(r.Form, "shift_type_") {
+		idx := strconv.Itoa(i)
 
 		// Parse required skills (comma-separated)
-		skillsStr := r.FormValue("required_skills_" + strconv.Itoa(i))
+		skillsStr := r.FormValue("required_skills_" + idx)
 		var skills []string
 		if skillsStr != "" {
 			for _, skill := range strings.Split(skillsStr, ",") {
@@ -72,14 +97,13 @@ func (h *CompanyConfigHandler) SaveConfig(w http.ResponseWriter, r *http.Request
 		}
 
 		req := domain.ShiftRequirement{
-			ShiftType:      r.FormValue(shiftTypeKey),
-			MinEmployees:   parseInt(r.FormValue("min_employees_"+strconv.Itoa(i)), 1),
-			MaxEmployees:   parseInt(r.FormValue("max_employees_"+strconv.Itoa(i)), 2),
+			ShiftType:      r.FormValue("shift_type_" + idx),
+			MinEmployees:   parseInt(r.FormValue("min_employees_"+idx), 1),
+			MaxEmployees:   parseInt(r.FormValue("max_employees_"+idx), 2),
 			RequiredSkills: skills,
-			Description:    r.FormValue("description_" + strconv.Itoa(i)),
+			Description:    r.FormValue("description_" + idx),
 		}
 		shiftReqs = append(shiftReqs, req)
-		i++
 	}
 
 	// Parse form data
@@ -91,14 +115,18 @@ func (h *CompanyConfigHandler) SaveConfig(w http.ResponseWriter, r *http.Request
 			CloseTime:   r.FormValue("close_time"),
 			Timezone:    r.FormValue("timezone"),
 		},
+		ShiftDefinitions:  shiftDefs,
 		ShiftRequirements: shiftReqs,
 		SchedulingPolicies: domain.SchedulingPolicies{
-			MaxConsecutiveDays: parseInt(r.FormValue("max_consecutive_days"), 5),
-			MinRestHours:       parseInt(r.FormValue("min_rest_hours"), 12),
-			MaxHoursPerWeek:    parseInt(r.FormValue("max_hours_per_week"), 40),
-			MaxShiftsPerDay:    parseInt(r.FormValue("max_shifts_per_day"), 1),
+			MaxConsecutiveDays:     parseInt(r.FormValue("max_consecutive_days"), 5),
+			MinRestHours:           parseInt(r.FormValue("min_rest_hours"), 12),
+			AllowOvertime:          r.FormValue("allow_overtime") == "true",
+			MaxOvertimeHours:       parseInt(r.FormValue("max_overtime_hours"), 0),
+			WeekendConsentRequired: r.FormValue("weekend_consent_required") == "true",
+			FairDistribution:       r.FormValue("fair_distribution") == "true",
 		},
-		AIContext: strings.TrimSpace(r.FormValue("ai_context")),
+		SchedulingStrategy: r.FormValue("scheduling_strategy"),
+		AIContext:          strings.TrimSpace(r.FormValue("ai_context")),
 	}
 
 	// Validate
@@ -109,8 +137,13 @@ func (h *CompanyConfigHandler) SaveConfig(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Update or create
-	err := h.repo.Update(ctx, config)
+	// Update or create. HTMX does not swap error responses, so a conflict is
+	// sent as 200 with the banner and the stored version for saving again.
+	err = h.service.SaveConfig(ctx, config, version)
+	if errors.Is(err, domain.ErrConflict) {
+		h.respondWithConflict(w, r, config)
+		return
+	}
 	if err != nil {
 		w.Header().Set("Content-Type", "text/html")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -125,6 +158,39 @@ func (h *CompanyConfigHandler) SaveConfig(w http.ResponseWriter, r *http.Request
 			Configuration saved successfully! This will be included in all schedule analysis sent to n8n.
 		</div>
 	`))
+	templates.CompanyConfigVersion(config.Version, true).Render(ctx, w)
+}
+
+// respondWithConflict shows how the stored configuration differs from the
+// rejected one, and moves the form to the stored version so that saving again
+// replaces it with the form's values
+func (h *CompanyConfigHandler) respondWithConflict(w http.ResponseWriter, r *http.Request, yours *domain.CompanyConfig) {
+	saved, err := h.service.GetConfig(r.Context())
+	if err != nil {
+		handleInternalError(w, err, "load company configuration")
+		return
+	}
+
+	yours.ID = saved.ID
+	if err := templates.CompanyConfigConflict(saved, yours).Render(r.Context(), w); err != nil {
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// formIndexes returns the sorted row indexes of form fields named prefix + index.
+// Rows can be removed in the UI, so the indexes are not necessarily contiguous.
+func formIndexes(form url.Values, prefix string) []int {
+	var indexes []int
+	for key, values := range form {
+		if !strings.HasPrefix(key, prefix) || len(values) == 0 || values[0] == "" {
+			continue
+		}
+		if i, err := strconv.Atoi(strings.TrimPrefix(key, prefix)); err == nil {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+	return indexes
 }
 
 func parseInt(s string, defaultVal int) int {
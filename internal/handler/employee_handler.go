@@ -1,22 +1,39 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
 	"github.com/isak/restySched/internal/service"
 	"github.com/isak/restySched/web/templates"
 	"github.com/rs/zerolog/log"
 )
 
 type EmployeeHandler struct {
-	service *service.EmployeeService
+	service     *service.EmployeeService
+	companyRepo repository.CompanyConfigRepository
 }
 
-func NewEmployeeHandler(service *service.EmployeeService) *EmployeeHandler {
-	return &EmployeeHandler{service: service}
+func NewEmployeeHandler(service *service.EmployeeService, companyRepo repository.CompanyConfigRepository) *EmployeeHandler {
+	return &EmployeeHandler{service: service, companyRepo: companyRepo}
+}
+
+// shiftDefinitions returns the company's shift definitions, falling back to the
+// defaults if the configuration cannot be loaded
+func (h *EmployeeHandler) shiftDefinitions(ctx context.Context) []domain.ShiftDefinition {
+	config, err := h.companyRepo.GetOrCreate(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load company configuration, using default shifts")
+		return domain.GetShiftDefinitions()
+	}
+	return config.GetShiftDefinitions()
 }
 
 func (h *EmployeeHandler) ListEmployees(w http.ResponseWriter, r *http.Request) {
@@ -70,12 +87,28 @@ func (h *EmployeeHandler) CreateEmployee(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	skills, err := domain.ParseSkills(r.FormValue("skills"))
+	if err != nil {
+		log.Warn().Err(err).Msg("Invalid skills format")
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	allowances, err := domain.ParseLeaveAllowances(r.FormValue("leave_allowances"))
+	if err != nil {
+		log.Warn().Err(err).Msg("Invalid leave allowances format")
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
 	input := domain.EmployeeCreateInput{
 		Name:            r.FormValue("name"),
 		Email:           r.FormValue("email"),
 		Role:            r.FormValue("role"),
 		RoleDescription: r.FormValue("role_description"),
 		MonthlyHours:    monthlyHours,
+		Skills:          skills,
+		LeaveAllowances: allowances,
 	}
 
 	employee, err := h.service.CreateEmployee(r.Context(), input)
@@ -98,6 +131,8 @@ func (h *EmployeeHandler) CreateEmployee(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+// UpdateEmployee saves the edit form, provided the employee is still at the
+// version the form was loaded at
 func (h *EmployeeHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
@@ -107,12 +142,21 @@ func (h *EmployeeHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	version, err := expectedVersion(r)
+	if err != nil {
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
 	employee, err := h.service.GetEmployee(r.Context(), id)
 	if err != nil {
 		log.Warn().Err(err).Str("id", id).Msg("Employee not found")
 		respondWithError(w, err, http.StatusNotFound)
 		return
 	}
+	if version != 0 {
+		employee.Version = version
+	}
 
 	monthlyHours, err := strconv.Atoi(r.FormValue("monthly_hours"))
 	if err != nil {
@@ -121,17 +165,37 @@ func (h *EmployeeHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	skills, err := domain.ParseSkills(r.FormValue("skills"))
+	if err != nil {
+		log.Warn().Err(err).Msg("Invalid skills format")
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	allowances, err := domain.ParseLeaveAllowances(r.FormValue("leave_allowances"))
+	if err != nil {
+		log.Warn().Err(err).Msg("Invalid leave allowances format")
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
 	employee.Name = r.FormValue("name")
 	employee.Email = r.FormValue("email")
 	employee.Role = r.FormValue("role")
 	employee.RoleDescription = r.FormValue("role_description")
 	employee.MonthlyHours = monthlyHours
+	employee.Skills = skills
+	employee.LeaveAllowances = allowances
 
 	if err := h.service.UpdateEmployee(r.Context(), employee); err != nil {
 		log.Warn().
 			Err(err).
 			Str("id", id).
 			Msg("Failed to update employee")
+		if errors.Is(err, domain.ErrConflict) {
+			h.respondWithConflict(w, r, employee)
+			return
+		}
 		respondWithError(w, err, http.StatusBadRequest)
 		return
 	}
@@ -145,6 +209,31 @@ func (h *EmployeeHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+// respondWithConflict renders the edit form again with the rejected values on
+// top of the saved employee and how the two differ. HTMX does not swap error
+// responses, so the conflict is sent as 200.
+func (h *EmployeeHandler) respondWithConflict(w http.ResponseWriter, r *http.Request, rejected *domain.Employee) {
+	saved, err := h.service.GetEmployee(r.Context(), rejected.ID)
+	if err != nil {
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	yours := *saved
+	yours.Name = rejected.Name
+	yours.Email = rejected.Email
+	yours.Role = rejected.Role
+	yours.RoleDescription = rejected.RoleDescription
+	yours.MonthlyHours = rejected.MonthlyHours
+	yours.Skills = rejected.Skills
+	yours.LeaveAllowances = rejected.LeaveAllowances
+
+	if err := templates.EmployeeFormWithConflict(&yours, saved).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render employee form")
+		handleInternalError(w, err, "render template")
+	}
+}
+
 func (h *EmployeeHandler) DeleteEmployee(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
@@ -169,7 +258,7 @@ func (h *EmployeeHandler) ShowAvailabilityManager(w http.ResponseWriter, r *http
 		return
 	}
 
-	if err := templates.AvailabilityManager(*employee).Render(r.Context(), w); err != nil {
+	if err := templates.AvailabilityManager(*employee, h.shiftDefinitions(r.Context())).Render(r.Context(), w); err != nil {
 		log.Error().Err(err).Msg("Failed to render availability manager")
 		handleInternalError(w, err, "render template")
 	}
@@ -193,18 +282,30 @@ func (h *EmployeeHandler) AddAvailability(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	endDate, err := time.Parse("2006-01-02", r.FormValue("end_date"))
+	// Parse the optional weekly repeat
+	recurrence, err := recurrenceFromForm(r)
 	if err != nil {
-		log.Warn().Err(err).Msg("Invalid end date format")
-		http.Error(w, "Invalid end date format", http.StatusBadRequest)
+		log.Warn().Err(err).Msg("Invalid repeat")
+		respondWithError(w, err, http.StatusBadRequest)
 		return
 	}
 
-	// Validate date range
-	if endDate.Before(startDate) {
-		log.Warn().Msg("End date is before start date")
-		http.Error(w, "End date must be after start date", http.StatusBadRequest)
-		return
+	// A repeating period may run without an end date
+	var endDate time.Time
+	if r.FormValue("end_date") != "" || recurrence == nil {
+		endDate, err = time.Parse("2006-01-02", r.FormValue("end_date"))
+		if err != nil {
+			log.Warn().Err(err).Msg("Invalid end date format")
+			http.Error(w, "Invalid end date format", http.StatusBadRequest)
+			return
+		}
+
+		// Validate date range
+		if endDate.Before(startDate) {
+			log.Warn().Msg("End date is before start date")
+			http.Error(w, "End date must be after start date", http.StatusBadRequest)
+			return
+		}
 	}
 
 	// Parse shift types (multiple select)
@@ -216,6 +317,9 @@ func (h *EmployeeHandler) AddAvailability(w http.ResponseWriter, r *http.Request
 		Type:       r.FormValue("type"),
 		Reason:     r.FormValue("reason"),
 		ShiftTypes: shiftTypes,
+		Recurrence: recurrence,
+		StartTime:  r.FormValue("start_time"),
+		EndTime:    r.FormValue("end_time"),
 	}
 
 	// Validate availability type
@@ -227,7 +331,13 @@ func (h *EmployeeHandler) AddAvailability(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	employee, err := h.service.AddEmployeeAvailability(r.Context(), id, availability)
+	// Availability employees enter themselves waits for a manager's approval
+	add := h.service.SubmitAvailability
+	if canManage(r) {
+		add = h.service.AddEmployeeAvailability
+	}
+
+	added, err := add(r.Context(), id, availability)
 	if err != nil {
 		log.Warn().
 			Err(err).
@@ -239,36 +349,95 @@ func (h *EmployeeHandler) AddAvailability(w http.ResponseWriter, r *http.Request
 
 	log.Info().
 		Str("id", id).
+		Str("availability_id", added.ID).
 		Str("type", availability.Type).
 		Time("start", startDate).
 		Time("end", endDate).
 		Msg("Availability added successfully")
 
-	// Return updated availability list
-	if err := templates.AvailabilityList(*employee).Render(r.Context(), w); err != nil {
+	h.renderAvailabilityList(w, r, id)
+}
+
+// renderAvailabilityList renders the employee's updated availability list
+func (h *EmployeeHandler) renderAvailabilityList(w http.ResponseWriter, r *http.Request, employeeID string) {
+	employee, err := h.service.GetEmployee(r.Context(), employeeID)
+	if err != nil {
+		log.Warn().Err(err).Str("id", employeeID).Msg("Employee not found")
+		respondWithError(w, err, http.StatusNotFound)
+		return
+	}
+
+	if err := templates.AvailabilityList(*employee, h.shiftDefinitions(r.Context())).Render(r.Context(), w); err != nil {
 		log.Error().Err(err).Msg("Failed to render availability list")
 		handleInternalError(w, err, "render template")
 	}
 }
 
+// recurrenceFromForm reads the optional repeat of the availability form: the
+// weekdays, every how many weeks, and the comma-separated YYYY-MM-DD days to
+// skip. Without weekdays the period does not repeat.
+func recurrenceFromForm(r *http.Request) (*domain.Recurrence, error) {
+	if len(r.Form["weekdays"]) == 0 {
+		return nil, nil
+	}
+
+	recurrence := &domain.Recurrence{}
+	for _, value := range r.Form["weekdays"] {
+		day, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid weekday %q", domain.ErrInvalidRecurrence, value)
+		}
+		recurrence.Weekdays = append(recurrence.Weekdays, day)
+	}
+	if value := r.FormValue("interval"); value != "" {
+		interval, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid interval %q", domain.ErrInvalidRecurrence, value)
+		}
+		recurrence.Interval = interval
+	}
+
+	exceptions, err := parseExceptions(strings.Split(r.FormValue("exceptions"), ","))
+	if err != nil {
+		return nil, err
+	}
+	recurrence.Exceptions = exceptions
+	return recurrence, nil
+}
+
+// parseExceptions parses the YYYY-MM-DD days a repeating period skips, ignoring blanks
+func parseExceptions(values []string) ([]time.Time, error) {
+	var exceptions []time.Time
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: exceptions must be YYYY-MM-DD, got %q", domain.ErrInvalidRecurrence, value)
+		}
+		exceptions = append(exceptions, day)
+	}
+	return exceptions, nil
+}
+
 // DeleteAvailability removes an availability period from an employee
 func (h *EmployeeHandler) DeleteAvailability(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
-	indexStr := r.PathValue("index")
+	availabilityID := r.PathValue("availabilityID")
 
-	index, err := strconv.Atoi(indexStr)
-	if err != nil {
-		log.Warn().Err(err).Msg("Invalid availability index")
-		http.Error(w, "Invalid availability index", http.StatusBadRequest)
-		return
+	// Employees may only withdraw availability that has not been approved
+	remove := h.service.WithdrawAvailability
+	if canManage(r) {
+		remove = h.service.RemoveEmployeeAvailability
 	}
 
-	employee, err := h.service.RemoveEmployeeAvailability(r.Context(), id, index)
-	if err != nil {
+	if err := remove(r.Context(), id, availabilityID); err != nil {
 		log.Warn().
 			Err(err).
 			Str("id", id).
-			Int("index", index).
+			Str("availability_id", availabilityID).
 			Msg("Failed to remove availability")
 		respondWithError(w, err, http.StatusBadRequest)
 		return
@@ -276,12 +445,62 @@ func (h *EmployeeHandler) DeleteAvailability(w http.ResponseWriter, r *http.Requ
 
 	log.Info().
 		Str("id", id).
-		Int("index", index).
+		Str("availability_id", availabilityID).
 		Msg("Availability removed successfully")
 
-	// Return updated availability list
-	if err := templates.AvailabilityList(*employee).Render(r.Context(), w); err != nil {
-		log.Error().Err(err).Msg("Failed to render availability list")
+	h.renderAvailabilityList(w, r, id)
+}
+
+// ListPendingAvailability shows the availability employees submitted that
+// waits for approval
+func (h *EmployeeHandler) ListPendingAvailability(w http.ResponseWriter, r *http.Request) {
+	pending, err := h.service.GetPendingAvailability(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch pending availability")
+		handleInternalError(w, err, "fetch pending availability")
+		return
+	}
+
+	if err := templates.PendingAvailabilityList(pending, h.shiftDefinitions(r.Context())).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render pending availability")
 		handleInternalError(w, err, "render template")
 	}
 }
+
+// ApproveAvailability approves an availability period an employee submitted
+func (h *EmployeeHandler) ApproveAvailability(w http.ResponseWriter, r *http.Request) {
+	h.reviewAvailability(w, r, h.service.ApproveAvailability, "approved")
+}
+
+// RejectAvailability rejects an availability period an employee submitted
+func (h *EmployeeHandler) RejectAvailability(w http.ResponseWriter, r *http.Request) {
+	h.reviewAvailability(w, r, h.service.RejectAvailability, "rejected")
+}
+
+func (h *EmployeeHandler) reviewAvailability(
+	w http.ResponseWriter,
+	r *http.Request,
+	review func(ctx context.Context, employeeID, id string) (*domain.Availability, error),
+	outcome string,
+) {
+	id := r.PathValue("id")
+	availabilityID := r.PathValue("availabilityID")
+
+	if _, err := review(r.Context(), id, availabilityID); err != nil {
+		log.Warn().
+			Err(err).
+			Str("id", id).
+			Str("availability_id", availabilityID).
+			Msg("Failed to review availability")
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	log.Info().
+		Str("id", id).
+		Str("availability_id", availabilityID).
+		Str("outcome", outcome).
+		Msg("Availability reviewed")
+
+	h.renderAvailabilityList(w, r, id)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/export"
+	"github.com/isak/restySched/internal/service"
+	"github.com/isak/restySched/web/templates"
+	"github.com/rs/zerolog/log"
+)
+
+// maxImportFileSize limits the size of uploaded employee import files
+const maxImportFileSize = 10 << 20
+
+// ShowImportForm shows the employee CSV/XLSX import form
+func (h *EmployeeHandler) ShowImportForm(w http.ResponseWriter, r *http.Request) {
+	if err := templates.EmployeeImportForm().Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render employee import form")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// ImportEmployees checks an uploaded employee file and shows a report of every
+// row. Unless dry_run is "false" nothing is saved; update_existing updates
+// employees whose email already exists instead of rejecting those rows.
+func (h *EmployeeHandler) ImportEmployees(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize)
+	if err := r.ParseMultipartForm(maxImportFileSize); err != nil {
+		log.Warn().Err(err).Msg("Invalid import upload")
+		respondWithError(w, domain.ErrInvalidImportFile, http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		log.Warn().Err(err).Msg("Import upload has no file")
+		respondWithError(w, domain.ErrInvalidImportFile, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	records, err := readImportFile(file, header.Filename)
+	if err != nil {
+		log.Warn().Err(err).Str("filename", header.Filename).Msg("Failed to read import file")
+		respondWithError(w, domain.ErrInvalidImportFile, http.StatusBadRequest)
+		return
+	}
+
+	opts := service.EmployeeImportOptions{
+		DryRun:         r.FormValue("dry_run") != "false",
+		UpdateExisting: r.FormValue("update_existing") == "on" || r.FormValue("update_existing") == "true",
+	}
+	report, err := h.service.ImportEmployees(r.Context(), records, opts)
+	if err != nil {
+		log.Warn().Err(err).Str("filename", header.Filename).Msg("Failed to import employees")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if !opts.DryRun {
+		log.Info().
+			Str("filename", header.Filename).
+			Int("created", report.Created).
+			Int("updated", report.Updated).
+			Int("skipped", report.Skipped).
+			Int("failed", report.Failed).
+			Msg("Employees imported")
+	}
+
+	if err := templates.EmployeeImportReport(*report).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render employee import report")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// readImportFile reads the records of an uploaded CSV or XLSX file. Workbooks
+// are recognised by their extension or their zip signature.
+func readImportFile(file io.Reader, filename string) ([][]string, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(filename), ".xlsx") || bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return export.ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	}
+	return export.ReadCSV(bytes.NewReader(data))
+}
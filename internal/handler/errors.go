@@ -3,9 +3,12 @@ package handler
 import (
 	"errors"
 	"fmt"
+	"html"
 	"net/http"
+	"strings"
 
 	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/openapi"
 	"github.com/rs/zerolog/log"
 )
 
@@ -16,27 +19,36 @@ type ErrorResponse struct {
 	Code    int    `json:"code"`
 }
 
-// respondWithError sends an error response with appropriate status code
-func respondWithError(w http.ResponseWriter, err error, defaultStatus int) {
-	status := defaultStatus
-	message := err.Error()
+// Request errors of the JSON API
+var (
+	errInvalidJSON  = errors.New("request body must be a valid JSON object of the expected fields")
+	errInvalidQuery = errors.New("invalid query parameter")
+)
 
-	// Map domain errors to HTTP status codes
-	switch {
-	case errors.Is(err, domain.ErrEmployeeNotFound),
-		errors.Is(err, domain.ErrScheduleNotFound):
-		status = http.StatusNotFound
+// respondWithJSONError sends an ErrorResponse with the status code mapped from err.
+// Internal errors are logged and replaced by a generic message.
+func respondWithJSONError(w http.ResponseWriter, err error, defaultStatus int) {
+	status := errorStatus(err, defaultStatus)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		log.Error().Err(err).Int("status", status).Msg("Internal server error")
+		message = "An internal error occurred. Please try again later."
+	} else {
+		log.Warn().Err(err).Int("status", status).Msg("Request error")
+	}
 
-	case errors.Is(err, domain.ErrInvalidEmployeeName),
-		errors.Is(err, domain.ErrInvalidEmployeeEmail),
-		errors.Is(err, domain.ErrInvalidEmployeeRole),
-		errors.Is(err, domain.ErrInvalidMonthlyHours),
-		errors.Is(err, domain.ErrInvalidSchedulePeriod):
-		status = http.StatusBadRequest
+	writeJSON(w, status, ErrorResponse{
+		Error:   strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
+		Message: message,
+		Code:    status,
+	})
+}
 
-	case errors.Is(err, domain.ErrScheduleAlreadySent):
-		status = http.StatusConflict
-	}
+// respondWithError sends an error response with appropriate status code
+func respondWithError(w http.ResponseWriter, err error, defaultStatus int) {
+	status := errorStatus(err, defaultStatus)
+	// Messages can repeat user input, such as a skill that could not be parsed
+	message := html.EscapeString(err.Error())
 
 	// Log the error with context
 	log.Error().
@@ -58,6 +70,107 @@ func respondWithError(w http.ResponseWriter, err error, defaultStatus int) {
 	w.Write([]byte(errorHTML))
 }
 
+// errorStatus maps domain errors to HTTP status codes
+func errorStatus(err error, defaultStatus int) int {
+	status := defaultStatus
+
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		status = http.StatusRequestEntityTooLarge
+
+	case errors.Is(err, errIfMatchRequired):
+		status = http.StatusPreconditionRequired
+
+	case errors.Is(err, domain.ErrEmployeeNotFound),
+		errors.Is(err, domain.ErrScheduleNotFound),
+		errors.Is(err, domain.ErrAssignmentNotFound),
+		errors.Is(err, domain.ErrAvailabilityNotFound),
+		errors.Is(err, domain.ErrShiftSwapNotFound),
+		errors.Is(err, domain.ErrLeaveNotFound),
+		errors.Is(err, domain.ErrUserNotFound):
+		status = http.StatusNotFound
+
+	case errors.Is(err, domain.ErrUnauthenticated),
+		errors.Is(err, domain.ErrInvalidCredentials):
+		status = http.StatusUnauthorized
+
+	case errors.Is(err, domain.ErrForbidden),
+		errors.Is(err, domain.ErrInvalidCSRFToken),
+		errors.Is(err, domain.ErrNotOfferingEmployee):
+		status = http.StatusForbidden
+
+	case errors.Is(err, errInvalidJSON),
+		errors.Is(err, errInvalidQuery),
+		errors.Is(err, errInvalidIfMatch),
+		errors.Is(err, openapi.ErrInvalidBody),
+		errors.Is(err, domain.ErrInvalidEmployeeName),
+		errors.Is(err, domain.ErrInvalidEmployeeEmail),
+		errors.Is(err, domain.ErrInvalidEmployeeRole),
+		errors.Is(err, domain.ErrInvalidMonthlyHours),
+		errors.Is(err, domain.ErrInvalidEmployeeSkill),
+		errors.Is(err, domain.ErrInvalidAvailability),
+		errors.Is(err, domain.ErrInvalidRecurrence),
+		errors.Is(err, domain.ErrInvalidImportFile),
+		errors.Is(err, domain.ErrImportMissingColumns),
+		errors.Is(err, domain.ErrInvalidSchedulePeriod),
+		errors.Is(err, domain.ErrSchedulePeriodTooLong),
+		errors.Is(err, domain.ErrUnknownPeriodPreset),
+		errors.Is(err, domain.ErrInvalidAssignment),
+		errors.Is(err, domain.ErrUnknownSchedulingStrategy),
+		errors.Is(err, domain.ErrUnknownScheduleAction),
+		errors.Is(err, domain.ErrInvalidCompanyName),
+		errors.Is(err, domain.ErrInvalidWorkingHours),
+		errors.Is(err, domain.ErrInvalidShiftRequirements),
+		errors.Is(err, domain.ErrInvalidShiftDefinition),
+		errors.Is(err, domain.ErrShiftBreakTooLong),
+		errors.Is(err, domain.ErrUnknownShiftType),
+		errors.Is(err, domain.ErrInvalidShiftSwapKind),
+		errors.Is(err, domain.ErrShiftNotSwappable),
+		errors.Is(err, domain.ErrShiftSwapReturnNeeded),
+		errors.Is(err, domain.ErrInvalidLeaveType),
+		errors.Is(err, domain.ErrInvalidLeavePeriod),
+		errors.Is(err, domain.ErrLeaveTooLong),
+		errors.Is(err, domain.ErrInvalidLeaveAllowance),
+		errors.Is(err, domain.ErrInvalidUserName),
+		errors.Is(err, domain.ErrInvalidUserEmail),
+		errors.Is(err, domain.ErrInvalidUserRole),
+		errors.Is(err, domain.ErrUserEmployeeRequired),
+		errors.Is(err, domain.ErrInvalidPassword):
+		status = http.StatusBadRequest
+
+	case errors.Is(err, domain.ErrConflict),
+		errors.Is(err, domain.ErrEmployeeAlreadyExists),
+		errors.Is(err, domain.ErrScheduleAlreadySent),
+		errors.Is(err, domain.ErrScheduleNotEditable),
+		errors.Is(err, domain.ErrScheduleNotDeletable),
+		errors.Is(err, domain.ErrInvalidScheduleTransition),
+		errors.Is(err, domain.ErrScheduleNotPublished),
+		errors.Is(err, domain.ErrEmployeeDoubleBooked),
+		errors.Is(err, domain.ErrAvailabilityReviewed),
+		errors.Is(err, domain.ErrAvailabilityApproved),
+		errors.Is(err, domain.ErrAvailabilityFromLeave),
+		errors.Is(err, domain.ErrShiftSwapExists),
+		errors.Is(err, domain.ErrShiftSwapNotOpen),
+		errors.Is(err, domain.ErrShiftSwapNotClaimed),
+		errors.Is(err, domain.ErrShiftSwapNotEligible),
+		errors.Is(err, domain.ErrShiftSwapOutdated),
+		errors.Is(err, domain.ErrLeaveOverlaps),
+		errors.Is(err, domain.ErrLeaveAllowanceExceeded),
+		errors.Is(err, domain.ErrLeaveNotPending),
+		errors.Is(err, domain.ErrLeaveNotActive),
+		errors.Is(err, domain.ErrUserAlreadyExists),
+		errors.Is(err, domain.ErrEmployeeHasAccount),
+		errors.Is(err, domain.ErrLastAdmin):
+		status = http.StatusConflict
+
+	case errors.Is(err, domain.ErrScheduleNotDelivered):
+		status = http.StatusBadGateway
+	}
+
+	return status
+}
+
 // respondWithSuccess sends a success message
 func respondWithSuccess(w http.ResponseWriter, message string) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -68,7 +181,7 @@ func respondWithSuccess(w http.ResponseWriter, message string) {
 			<strong class="font-bold">Success!</strong>
 			<span class="block sm:inline">%s</span>
 		</div>
-	`, message)
+	`, html.EscapeString(message))
 
 	w.Write([]byte(successHTML))
 }
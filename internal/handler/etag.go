@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// errInvalidIfMatch is returned for an If-Match header that is not the ETag of
+// a version
+var errInvalidIfMatch = errors.New(`If-Match must be the ETag of the version being changed, such as "3"`)
+
+// errIfMatchRequired is returned for an API change without an If-Match header
+var errIfMatchRequired = errors.New(`If-Match with the ETag of the version being changed, such as "3", is required`)
+
+// etag returns the entity tag of a version of an employee, schedule or the
+// company configuration
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setETag sets the ETag header to the version of the record in the response
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", etag(version))
+}
+
+// expectedVersion returns the version of the record a request changes, from
+// its If-Match header or else the version field of a submitted form. 0, for
+// neither or an If-Match of *, changes whatever version is stored.
+func expectedVersion(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" {
+		value = r.PostFormValue("version")
+		if value == "" {
+			return 0, nil
+		}
+	} else if value == "*" {
+		return 0, nil
+	}
+	return parseETag(value)
+}
+
+// requiredVersion returns the version of the record an API request changes,
+// from its If-Match header. API clients must name the version they change, so
+// that they never overwrite changes they have not seen; changing whatever
+// version is stored is left to the web forms.
+func requiredVersion(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, errIfMatchRequired
+	}
+	return parseETag(value)
+}
+
+// parseETag returns the version of an entity tag made by etag
+func parseETag(value string) (int, error) {
+	value = strings.Trim(strings.TrimPrefix(value, "W/"), `"`)
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
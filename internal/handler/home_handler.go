@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 
+	"github.com/isak/restySched/internal/auth"
 	"github.com/isak/restySched/web/templates"
 )
 
@@ -12,6 +13,11 @@ func NewHomeHandler() *HomeHandler {
 	return &HomeHandler{}
 }
 
+// Home shows the home page; employees go straight to their shifts
 func (h *HomeHandler) Home(w http.ResponseWriter, r *http.Request) {
+	if user := auth.User(r.Context()); user != nil && !user.CanManage() {
+		http.Redirect(w, r, "/my/shifts", http.StatusSeeOther)
+		return
+	}
 	templates.Home().Render(r.Context(), w)
 }
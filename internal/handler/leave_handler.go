@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/service"
+	"github.com/isak/restySched/web/templates"
+	"github.com/rs/zerolog/log"
+)
+
+// LeaveHandler serves employees' leave requests and balances and the list of
+// requests waiting for a manager's decision
+type LeaveHandler struct {
+	service   *service.LeaveService
+	employees *service.EmployeeService
+}
+
+// NewLeaveHandler creates a new leave handler
+func NewLeaveHandler(service *service.LeaveService, employees *service.EmployeeService) *LeaveHandler {
+	return &LeaveHandler{service: service, employees: employees}
+}
+
+// ShowLeaveManager shows an employee's leave balances for the current year,
+// their requests and a form to request leave
+func (h *LeaveHandler) ShowLeaveManager(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	employee, err := h.employees.GetEmployee(r.Context(), id)
+	if err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Employee not found")
+		respondWithError(w, err, http.StatusNotFound)
+		return
+	}
+
+	balances, requests, err := h.overview(r.Context(), id)
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("Failed to fetch leave")
+		handleInternalError(w, err, "fetch leave")
+		return
+	}
+
+	if err := templates.LeaveManager(*employee, balances, requests).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render leave manager")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// RequestLeave files a leave request for an employee
+func (h *LeaveHandler) RequestLeave(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	startDate, errStart := time.Parse("2006-01-02", r.FormValue("start_date"))
+	endDate, errEnd := time.Parse("2006-01-02", r.FormValue("end_date"))
+	if errStart != nil || errEnd != nil {
+		log.Warn().Str("id", id).Msg("Invalid leave dates")
+		respondWithError(w, domain.ErrInvalidLeavePeriod, http.StatusBadRequest)
+		return
+	}
+
+	request, err := h.service.RequestLeave(r.Context(), id, r.FormValue("type"), startDate, endDate, r.FormValue("reason"))
+	if err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to request leave")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().
+		Str("id", request.ID).
+		Str("employee_id", id).
+		Str("type", request.Type).
+		Time("start", startDate).
+		Time("end", endDate).
+		Msg("Leave requested")
+
+	h.renderLeaveList(w, r, id)
+}
+
+// CancelLeave cancels one of an employee's pending or approved requests
+func (h *LeaveHandler) CancelLeave(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	leaveID := r.PathValue("leaveID")
+
+	if _, err := h.service.CancelLeave(r.Context(), id, leaveID, requestActor(r)); err != nil {
+		log.Warn().Err(err).Str("id", leaveID).Msg("Failed to cancel leave")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().Str("id", leaveID).Str("employee_id", id).Msg("Leave cancelled")
+	h.renderLeaveList(w, r, id)
+}
+
+// ListPendingLeave shows the leave requests waiting for a decision, with the
+// published shifts each one collides with
+func (h *LeaveHandler) ListPendingLeave(w http.ResponseWriter, r *http.Request) {
+	pending, err := h.service.PendingLeave(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch pending leave")
+		handleInternalError(w, err, "fetch pending leave")
+		return
+	}
+
+	if err := templates.PendingLeaveList(pending).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render pending leave")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// ApproveLeave approves a leave request, removing its row
+func (h *LeaveHandler) ApproveLeave(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.ApproveLeave, "approved")
+}
+
+// RejectLeave turns down a leave request, removing its row
+func (h *LeaveHandler) RejectLeave(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.RejectLeave, "rejected")
+}
+
+func (h *LeaveHandler) decide(
+	w http.ResponseWriter,
+	r *http.Request,
+	decide func(ctx context.Context, id, actor string) (*domain.LeaveRequest, error),
+	outcome string,
+) {
+	id := r.PathValue("id")
+
+	if _, err := decide(r.Context(), id, requestActor(r)); err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to decide leave request")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().Str("id", id).Str("actor", requestActor(r)).Msgf("Leave request %s", outcome)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *LeaveHandler) renderLeaveList(w http.ResponseWriter, r *http.Request, employeeID string) {
+	balances, requests, err := h.overview(r.Context(), employeeID)
+	if err != nil {
+		log.Error().Err(err).Str("id", employeeID).Msg("Failed to fetch leave")
+		handleInternalError(w, err, "fetch leave")
+		return
+	}
+
+	if err := templates.LeaveList(employeeID, balances, requests).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render leave list")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// overview returns the employee's balances for the current year and their requests
+func (h *LeaveHandler) overview(ctx context.Context, employeeID string) ([]service.LeaveBalance, []domain.LeaveRequest, error) {
+	balances, err := h.service.Balances(ctx, employeeID, time.Now().Year())
+	if err != nil {
+		return nil, nil, err
+	}
+	requests, err := h.service.EmployeeLeave(ctx, employeeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return balances, requests, nil
+}
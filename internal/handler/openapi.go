@@ -0,0 +1,747 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/openapi"
+	"github.com/isak/restySched/internal/service"
+)
+
+// Media types of the file downloads
+const (
+	mediaTypeCSV      = "text/csv"
+	mediaTypeXLSX     = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	mediaTypeCalendar = "text/calendar"
+)
+
+// sessionScheme is the name of the session cookie security scheme
+const sessionScheme = "session"
+
+// access is who may call a route. RequireAccess enforces it from the
+// operation's security and roles.
+type access int
+
+const (
+	accessGroup    access = iota // the access of the route's group
+	accessPublic                 // anyone, signed in or not
+	accessSignedIn               // any signed-in user
+	accessOwner                  // managers, admins and the employee whose ID is the id path parameter
+	accessManager                // managers and admins
+	accessAdmin                  // admins only
+)
+
+// route describes one registered route for the OpenAPI document
+type route struct {
+	pattern  string // net/http pattern, e.g. "GET /employees/{id}"
+	id       string // operationId
+	summary  string
+	access   access // the group's access if unset
+	query    []openapi.Parameter
+	body     *openapi.RequestBody
+	status   string // success status; 200 if empty
+	response openapi.Response
+
+	// versioned routes return an employee, schedule or the company
+	// configuration with its version as ETag, and change it only if it is
+	// still at the version of the If-Match header, which they require
+	versioned bool
+}
+
+// NewOpenAPIDocument describes every route of the server: the HTML pages and
+// HTMX fragments with their form fields, the file downloads and the JSON API.
+// The request bodies it describes are validated by ValidateRequestBodies.
+func NewOpenAPIDocument() *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:   "RestySched",
+		Version: "1.0.0",
+		Description: "Employee shift scheduling. Routes under /api/v1 form the JSON API; " +
+			"the other routes serve the web interface as HTML pages and HTMX fragments.",
+	})
+	doc.Components.SecuritySchemes = map[string]openapi.SecurityScheme{
+		sessionScheme: {
+			Type: "apiKey", In: "cookie", Name: sessionCookieName,
+			Description: "Session cookie set by signing in. Requests other than GET and HEAD must also send the session's CSRF token in the " + csrfHeaderName + " header.",
+		},
+	}
+	describeSchemas(doc)
+
+	html := func(description string) openapi.Response {
+		return openapi.Response{Description: description, Content: map[string]openapi.MediaType{
+			openapi.MediaTypeHTML: {Schema: openapi.String("")},
+		}}
+	}
+	file := func(mediaType, description string) openapi.Response {
+		return openapi.Response{Description: description, Content: map[string]openapi.MediaType{
+			mediaType: {Schema: &openapi.Schema{Type: "string", Format: openapi.FormatBinary}},
+		}}
+	}
+	jsonOf := func(description string, schema *openapi.Schema) openapi.Response {
+		return openapi.Response{Description: description, Content: map[string]openapi.MediaType{
+			openapi.MediaTypeJSON: {Schema: schema},
+		}}
+	}
+	noContent := openapi.Response{Description: "Deleted"}
+
+	addRoutes(doc, "Health", accessPublic, []route{
+		{pattern: "GET /health", id: "health", summary: "Liveness probe", response: jsonOf("The application is running", doc.Schema(HealthResponse{}))},
+		{pattern: "GET /health/ready", id: "ready", summary: "Readiness probe, checking the database", response: jsonOf("The application can serve traffic", doc.Schema(HealthResponse{}))},
+	})
+
+	addRoutes(doc, "Documentation", accessSignedIn, []route{
+		{pattern: "GET /api/openapi.json", id: "getOpenAPIDocument", summary: "This OpenAPI document", access: accessPublic, response: jsonOf("The OpenAPI document", &openapi.Schema{Type: "object"})},
+		{pattern: "GET /api/docs", id: "showAPIDocs", summary: "API documentation page", response: html("The documentation page")},
+	})
+
+	addRoutes(doc, "Account", accessSignedIn, []route{
+		{pattern: "GET /login", id: "showLogin", summary: "Login page", access: accessPublic, query: []openapi.Parameter{
+			{Name: "next", In: "query", Description: "Page to show after signing in", Schema: openapi.String("")},
+		}, response: html("The login page")},
+		{
+			pattern: "POST /login", id: "submitLogin", summary: "Sign in from the login form", access: accessPublic,
+			body: formBody(openapi.Object(map[string]*openapi.Schema{
+				"email":    openapi.String("Email address of the account"),
+				"password": openapi.String("Password"),
+				"next":     openapi.String("Page to show after signing in"),
+			}, "email", "password")),
+			status:   "303",
+			response: openapi.Response{Description: "Signed in; redirects to the next page and sets the session cookie"},
+		},
+		{pattern: "POST /logout", id: "submitLogout", summary: "Sign out", response: openapi.Response{Description: "Signed out; redirects to the login page"}},
+		{pattern: "GET /account", id: "showAccount", summary: "Account page of the signed-in user", response: html("The account page")},
+		{
+			pattern: "POST /account/password", id: "submitPasswordChange", summary: "Change the signed-in user's password",
+			body: formBody(openapi.Object(map[string]*openapi.Schema{
+				"current_password": openapi.String("Current password"),
+				"new_password":     passwordSchema("New password"),
+			}, "current_password", "new_password")),
+			response: html("A success message"),
+		},
+		{pattern: "GET /my/shifts", id: "showMyShifts", summary: "The signed-in employee's upcoming shifts", response: html("The shifts page")},
+		{pattern: "GET /my/notifications", id: "showMyNotifications", summary: "The signed-in employee's recent notifications, marking them as read", response: html("The notification list")},
+	})
+
+	addRoutes(doc, "Pages", accessManager, []route{
+		{pattern: "GET /", id: "showHome", summary: "Home page; employees are redirected to their shifts", access: accessSignedIn, response: html("The home page")},
+		{pattern: "GET /config", id: "showConfig", summary: "Company configuration page", access: accessAdmin, response: html("The configuration page")},
+		{pattern: "POST /api/company-config", id: "saveConfig", summary: "Save the company configuration form", access: accessAdmin, body: formBody(withVersion(companyConfigForm())), response: html("A success message")},
+	})
+
+	addRoutes(doc, "Employees", accessManager, []route{
+		{pattern: "GET /employees", id: "showEmployees", summary: "Employee list page", response: html("The employee list")},
+		{pattern: "GET /employees/new", id: "showNewEmployeeForm", summary: "New employee form", response: html("The form")},
+		{pattern: "POST /employees", id: "submitEmployee", summary: "Create an employee from the form", body: formBody(employeeForm()), response: html("The employee list row")},
+		{pattern: "GET /employees/{id}/edit", id: "showEditEmployeeForm", summary: "Edit employee form", response: html("The form")},
+		{pattern: "PUT /employees/{id}", id: "submitEmployeeUpdate", summary: "Update an employee from the form", body: formBody(withVersion(employeeForm())), response: html("The employee list row")},
+		{pattern: "DELETE /employees/{id}", id: "removeEmployee", summary: "Deactivate an employee", response: html("Empty, removing the row")},
+		{pattern: "GET /employees/import", id: "showImportForm", summary: "Employee import form", response: html("The form")},
+		{
+			pattern: "POST /employees/import", id: "importEmployees", summary: "Import employees from a CSV or XLSX file",
+			body: &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+				openapi.MediaTypeMultipart: {Schema: openapi.Object(map[string]*openapi.Schema{
+					"file":            {Type: "string", Format: openapi.FormatBinary, Description: "CSV or XLSX file with a header row of name, email, role, monthly_hours and optionally role_description and skills"},
+					"dry_run":         openapi.Boolean(`Only report what would be imported unless "false"`),
+					"update_existing": openapi.Boolean("Update employees whose email already exists instead of rejecting the row"),
+				}, "file")},
+			}},
+			response: html("The import report"),
+		},
+		{pattern: "GET /employees/{id}/availability", id: "showAvailability", summary: "Availability manager of an employee", access: accessOwner, response: html("The availability manager")},
+		{pattern: "POST /employees/{id}/availability", id: "submitAvailability", summary: "Add an availability period from the form; periods employees add wait for approval", access: accessOwner, body: formBody(availabilityForm()), response: html("The updated availability manager")},
+		{pattern: "DELETE /employees/{id}/availability/{availabilityID}", id: "removeAvailability", summary: "Remove an availability period; employees can only withdraw periods that are not approved", access: accessOwner, response: html("The updated availability manager")},
+		{pattern: "POST /employees/{id}/availability/{availabilityID}/approve", id: "approveAvailabilityPeriod", summary: "Approve a pending availability period", response: html("The updated availability manager")},
+		{pattern: "POST /employees/{id}/availability/{availabilityID}/reject", id: "rejectAvailabilityPeriod", summary: "Reject a pending availability period", response: html("The updated availability manager")},
+		{pattern: "GET /availability/pending", id: "showPendingAvailability", summary: "Availability waiting for approval", response: html("The pending availability list")},
+		{pattern: "GET /employees/{id}/calendar", id: "showCalendarFeed", summary: "Calendar feed URL of an employee", access: accessOwner, response: html("The feed URL")},
+		{pattern: "POST /employees/{id}/calendar/rotate", id: "rotateCalendarFeed", summary: "Replace an employee's calendar feed URL", access: accessOwner, response: html("The new feed URL")},
+		{pattern: "GET /calendar/{token}", id: "getCalendarFeed", summary: "iCalendar feed of an employee's published shifts", access: accessPublic, response: file(mediaTypeCalendar, "The calendar")},
+	})
+
+	addRoutes(doc, "Schedules", accessManager, []route{
+		{pattern: "GET /schedules", id: "showSchedules", summary: "Schedule list page", response: html("The schedule list")},
+		{pattern: "POST /schedules/generate", id: "submitGenerateSchedule", summary: "Generate a schedule from the form", body: formBody(generateForm()), response: html("The schedule card")},
+		{pattern: "POST /schedules/{id}/approve", id: "approveSchedule", summary: "Approve a draft schedule", response: html("The schedule card")},
+		{
+			pattern: "POST /schedules/{id}/publish", id: "publishSchedule", summary: "Publish an approved schedule",
+			body: formBody(openapi.Object(map[string]*openapi.Schema{
+				"send_to_n8n": openapi.Boolean("Also send the schedule to n8n"),
+			})),
+			response: html("The schedule card"),
+		},
+		{pattern: "POST /schedules/{id}/complete", id: "completeSchedule", summary: "Mark a published schedule as worked", response: html("The schedule card")},
+		{pattern: "POST /schedules/{id}/reopen", id: "reopenSchedule", summary: "Return a schedule to draft", response: html("The schedule card")},
+		{pattern: "POST /schedules/{id}/archive", id: "archiveSchedule", summary: "Archive a schedule", response: html("The schedule card")},
+		{pattern: "POST /schedules/{id}/send", id: "submitSendToN8N", summary: "Send a published schedule to n8n", response: html("A success message")},
+		{pattern: "GET /schedules/{id}/assignments.csv", id: "exportAssignmentsCSV", summary: "Download the assignments as CSV", response: file(mediaTypeCSV, "The assignments")},
+		{pattern: "GET /schedules/{id}/hours.csv", id: "exportHoursCSV", summary: "Download the hours per employee as CSV", response: file(mediaTypeCSV, "The hours summary")},
+		{pattern: "GET /schedules/{id}/export.xlsx", id: "exportXLSX", summary: "Download the schedule as a workbook", response: file(mediaTypeXLSX, "The workbook")},
+		{pattern: "GET /schedules/{id}/print", id: "printSchedule", summary: "Printable week view", response: html("The printable page")},
+		{
+			pattern: "POST /schedules/{id}/assignments", id: "submitAssignment", summary: "Add a shift to a draft schedule",
+			body: formBody(openapi.Object(map[string]*openapi.Schema{
+				"employee_id": openapi.String("Employee to assign"),
+				"date":        openapi.Date("Day of the shift"),
+				"shift_type":  openapi.String("Type of a defined shift"),
+			}, "employee_id", "date", "shift_type")),
+			response: html("The schedule card"),
+		},
+		{pattern: "DELETE /schedules/{id}/assignments/{assignmentID}", id: "removeScheduleAssignment", summary: "Remove a shift from a draft schedule", response: html("The schedule card")},
+		{
+			pattern: "POST /schedules/{id}/assignments/{assignmentID}/move", id: "submitMoveAssignment", summary: "Move a shift to another day or shift type",
+			body: formBody(openapi.Object(map[string]*openapi.Schema{
+				"date":       openapi.Date("New day of the shift"),
+				"shift_type": openapi.String("New shift type"),
+			}, "date", "shift_type")),
+			response: html("The schedule card"),
+		},
+		{
+			pattern: "POST /schedules/{id}/assignments/{assignmentID}/swap", id: "submitSwapAssignments", summary: "Swap the employees of two shifts",
+			body: formBody(openapi.Object(map[string]*openapi.Schema{
+				"other_id": openapi.String("ID of the other assignment"),
+			}, "other_id")),
+			response: html("The schedule card"),
+		},
+		{pattern: "DELETE /schedules/{id}", id: "removeSchedule", summary: "Delete a draft schedule", response: html("Empty, removing the card")},
+	})
+
+	addRoutes(doc, "Users", accessAdmin, []route{
+		{pattern: "GET /users", id: "showUsers", summary: "User account list page", response: html("The user list")},
+		{pattern: "GET /users/new", id: "showNewUserForm", summary: "New user form", response: html("The form")},
+		{pattern: "POST /users", id: "submitUser", summary: "Create a user from the form", body: formBody(userForm(true)), response: html("Empty, redirecting to the user list")},
+		{pattern: "GET /users/{id}/edit", id: "showEditUserForm", summary: "Edit user form", response: html("The form")},
+		{pattern: "PUT /users/{id}", id: "submitUserUpdate", summary: "Update a user from the form, resetting the password if one is given", body: formBody(userForm(false)), response: html("Empty, redirecting to the user list")},
+		{pattern: "DELETE /users/{id}", id: "deactivateUser", summary: "Deactivate a user and end their sessions", response: html("The updated user row")},
+		{pattern: "POST /users/{id}/activate", id: "activateUser", summary: "Reactivate a user", response: html("The updated user row")},
+	})
+
+	addRoutes(doc, "Shift swaps", accessSignedIn, []route{
+		{pattern: "GET /swaps", id: "showSwaps", summary: "Swap board of open offers, the signed-in employee's offers and claims, and claims waiting for a manager", response: html("The swap board")},
+		{
+			pattern: "POST /swaps", id: "submitShiftOffer", summary: "Offer one of the signed-in employee's upcoming shifts",
+			body: formBody(openapi.Object(map[string]*openapi.Schema{
+				"schedule_id":   openapi.String("Schedule of the shift"),
+				"assignment_id": openapi.String("Assignment ID of the shift"),
+				"kind":          openapi.String("Swap for another shift, or give the shift away").OneOf(domain.ShiftSwapKinds()...),
+				"note":          openapi.String("Note to colleagues"),
+			}, "schedule_id", "assignment_id", "kind")),
+			response: html("A success message"),
+		},
+		{
+			pattern: "POST /swaps/{id}/claim", id: "submitSwapClaim", summary: "Claim an open offer for the signed-in employee",
+			body: formBody(openapi.Object(map[string]*openapi.Schema{
+				"return_assignment_id": openapi.String("For a swap, the assignment ID of the employee's shift given in return"),
+			})),
+			response: html("Empty, reloading the swap board"),
+		},
+		{pattern: "POST /swaps/{id}/cancel", id: "submitOfferCancel", summary: "Withdraw the signed-in employee's offer", response: html("Empty, reloading the swap board")},
+		{pattern: "POST /swaps/{id}/approve", id: "approveShiftSwap", summary: "Approve a claimed offer, updating the schedule", access: accessManager, response: html("Empty, removing the row")},
+		{pattern: "POST /swaps/{id}/reject", id: "rejectShiftSwap", summary: "Reject a claimed offer", access: accessManager, response: html("Empty, removing the row")},
+		{pattern: "GET /schedules/{id}/swaps", id: "showScheduleSwaps", summary: "History of the offers made in a schedule", access: accessManager, response: html("The swap history")},
+	})
+
+	addRoutes(doc, "Leave", accessOwner, []route{
+		{pattern: "GET /employees/{id}/leave", id: "showLeaveManager", summary: "Leave balances and requests of an employee, with a form to request leave", response: html("The leave manager")},
+		{
+			pattern: "POST /employees/{id}/leave", id: "submitLeaveRequest", summary: "Request leave for an employee",
+			body: formBody(openapi.Object(map[string]*openapi.Schema{
+				"type":       openapi.String("Leave type").OneOf(domain.LeaveTypes()...),
+				"start_date": openapi.Date("First day of the leave"),
+				"end_date":   openapi.Date("Last day of the leave"),
+				"reason":     openapi.String("Reason"),
+			}, "type", "start_date", "end_date")),
+			response: html("The updated balances and requests"),
+		},
+		{pattern: "POST /employees/{id}/leave/{leaveID}/cancel", id: "submitLeaveCancel", summary: "Cancel a pending or approved leave request", response: html("The updated balances and requests")},
+		{pattern: "GET /leave/pending", id: "showPendingLeave", summary: "Leave requests waiting for approval, with the published shifts they collide with", access: accessManager, response: html("The leave request page")},
+		{pattern: "POST /leave/{id}/approve", id: "approveLeaveRequest", summary: "Approve a leave request, blocking the employee from being scheduled during it", access: accessManager, response: html("Empty, removing the row")},
+		{pattern: "POST /leave/{id}/reject", id: "rejectLeaveRequest", summary: "Reject a leave request", access: accessManager, response: html("Empty, removing the row")},
+	})
+
+	addRoutes(doc, "Audit", accessManager, []route{
+		{
+			pattern: "GET /audit", id: "showAuditLog", summary: "Audit log page of the newest changes, with a filter form",
+			query: []openapi.Parameter{
+				{Name: "entity_type", In: "query", Description: "Type of the changed record", Schema: openapi.String("").OneOf(domain.AuditEntities()...)},
+				{Name: "entity_id", In: "query", Description: "ID of the changed record", Schema: openapi.String("")},
+				{Name: "actor", In: "query", Description: "Email of who made the change", Schema: openapi.String("")},
+				{Name: "action", In: "query", Description: "Action", Schema: openapi.String("").OneOf(domain.AuditActions()...)},
+				{Name: "since", In: "query", Description: "Changes on this day or later", Schema: openapi.Date("")},
+				{Name: "until", In: "query", Description: "Changes on this day or earlier", Schema: openapi.Date("")},
+			},
+			response: html("The audit log"),
+		},
+		{pattern: "GET /employees/{id}/audit", id: "showEmployeeAudit", summary: "Changes made to an employee and their availability", response: html("The change history")},
+		{pattern: "GET /schedules/{id}/audit", id: "showScheduleAudit", summary: "Changes made to a schedule", response: html("The change history")},
+		{pattern: "GET /config/audit", id: "showConfigAudit", summary: "Changes made to the company configuration", access: accessAdmin, response: html("The change history")},
+	})
+
+	pageQuery := []openapi.Parameter{
+		{Name: "limit", In: "query", Description: "Page size", Schema: openapi.Integer("").Between(1, maxPageLimit)},
+		{Name: "offset", In: "query", Description: "Number of items to skip", Schema: openapi.Integer("").AtLeast(0)},
+	}
+	query := func(params ...openapi.Parameter) []openapi.Parameter {
+		return append(params, pageQuery...)
+	}
+	list := func(item any) *openapi.Schema {
+		return openapi.Object(map[string]*openapi.Schema{
+			"data":       openapi.Array(doc.Schema(item)),
+			"pagination": doc.Schema(Pagination{}),
+		}, "data", "pagination")
+	}
+
+	addRoutes(doc, "Auth API", accessSignedIn, []route{
+		{pattern: "POST /api/v1/auth/login", id: "login", summary: "Sign in and set the session cookie", access: accessPublic, body: jsonBody(doc, LoginInput{}), response: jsonOf("The signed-in user and the CSRF token", doc.Schema(SessionResponse{}))},
+		{pattern: "POST /api/v1/auth/logout", id: "logout", summary: "Sign out", status: "204", response: openapi.Response{Description: "Signed out"}},
+		{pattern: "GET /api/v1/auth/me", id: "getCurrentUser", summary: "The signed-in user and the CSRF token", response: jsonOf("The signed-in user", doc.Schema(SessionResponse{}))},
+		{pattern: "GET /api/v1/me/hours", id: "getMyHours", summary: "The signed-in employee's scheduled hours this month and their monthly hours", response: jsonOf("The hours", doc.Schema(service.MonthHours{}))},
+		{pattern: "GET /api/v1/me/shifts", id: "listMyShifts", summary: "The signed-in employee's upcoming published shifts", response: jsonOf("The shifts, in order", openapi.Array(doc.Schema(service.EmployeeShift{})))},
+		{pattern: "GET /api/v1/me/swaps", id: "listMySwaps", summary: "Open and claimed offers the signed-in employee made or claimed", response: jsonOf("The offers", openapi.Array(doc.Schema(domain.ShiftSwap{})))},
+		{pattern: "GET /api/v1/me/notifications", id: "listMyNotifications", summary: "The signed-in employee's recent notifications", response: jsonOf("The notifications, newest first", openapi.Array(doc.Schema(domain.Notification{})))},
+		{pattern: "POST /api/v1/me/notifications/read", id: "markNotificationsRead", summary: "Mark the signed-in employee's notifications as read", status: "204", response: openapi.Response{Description: "Marked as read"}},
+	})
+
+	addRoutes(doc, "Employees API", accessManager, []route{
+		{
+			pattern: "GET /api/v1/employees", id: "listEmployees", summary: "List employees",
+			query: query(
+				openapi.Parameter{Name: "active", In: "query", Description: "Only active or inactive employees", Schema: openapi.Boolean("")},
+				openapi.Parameter{Name: "role", In: "query", Description: "Role, ignoring case", Schema: openapi.String("")},
+				openapi.Parameter{Name: "skill", In: "query", Description: "Holds this skill today", Schema: openapi.String("")},
+				openapi.Parameter{Name: "q", In: "query", Description: "Name or email contains", Schema: openapi.String("")},
+			),
+			response: jsonOf("A page of employees", list(domain.Employee{})),
+		},
+		{pattern: "POST /api/v1/employees", id: "createEmployee", summary: "Create an employee", body: jsonBody(doc, domain.EmployeeCreateInput{}), status: "201", response: jsonOf("The created employee", doc.Schema(domain.Employee{})), versioned: true},
+		{pattern: "GET /api/v1/employees/{id}", id: "getEmployee", summary: "Get an employee", access: accessOwner, response: jsonOf("The employee", doc.Schema(domain.Employee{})), versioned: true},
+		{pattern: "PUT /api/v1/employees/{id}", id: "updateEmployee", summary: "Update an employee", body: jsonBody(doc, domain.EmployeeCreateInput{}), response: jsonOf("The updated employee", doc.Schema(domain.Employee{})), versioned: true},
+		{pattern: "DELETE /api/v1/employees/{id}", id: "deleteEmployee", summary: "Deactivate an employee", status: "204", response: openapi.Response{Description: "Deactivated"}},
+		{pattern: "GET /api/v1/employees/{id}/availability", id: "listAvailability", summary: "List an employee's availability periods", access: accessOwner, response: jsonOf("The availability periods", openapi.Array(doc.Schema(domain.Availability{})))},
+		{pattern: "POST /api/v1/employees/{id}/availability", id: "addAvailability", summary: "Add an availability period; periods employees add wait for approval", access: accessOwner, body: jsonBody(doc, AvailabilityInput{}), status: "201", response: jsonOf("The added availability period", doc.Schema(domain.Availability{}))},
+		{pattern: "GET /api/v1/employees/{id}/availability/{availabilityID}", id: "getAvailability", summary: "Get an availability period", access: accessOwner, response: jsonOf("The availability period", doc.Schema(domain.Availability{}))},
+		{pattern: "PUT /api/v1/employees/{id}/availability/{availabilityID}", id: "updateAvailability", summary: "Replace an availability period; it applies at once", body: jsonBody(doc, AvailabilityInput{}), response: jsonOf("The availability period", doc.Schema(domain.Availability{}))},
+		{pattern: "DELETE /api/v1/employees/{id}/availability/{availabilityID}", id: "deleteAvailability", summary: "Remove an availability period; employees can only withdraw periods that are not approved", access: accessOwner, status: "204", response: noContent},
+		{pattern: "POST /api/v1/employees/{id}/availability/{availabilityID}/approve", id: "approveAvailability", summary: "Approve a pending availability period", response: jsonOf("The availability period", doc.Schema(domain.Availability{}))},
+		{pattern: "POST /api/v1/employees/{id}/availability/{availabilityID}/reject", id: "rejectAvailability", summary: "Reject a pending availability period", response: jsonOf("The availability period", doc.Schema(domain.Availability{}))},
+		{
+			pattern: "GET /api/v1/availability", id: "listAvailabilityInPeriod", summary: "List every employee's availability periods during a date range",
+			query: query(
+				openapi.Parameter{Name: "from", In: "query", Required: true, Description: "Periods apply on this day or later", Schema: openapi.Date("")},
+				openapi.Parameter{Name: "to", In: "query", Required: true, Description: "Periods apply on this day or earlier", Schema: openapi.Date("")},
+			),
+			response: jsonOf("A page of availability periods", list(domain.Availability{})),
+		},
+		{pattern: "GET /api/v1/availability/pending", id: "listPendingAvailability", summary: "List availability waiting for approval", response: jsonOf("The pending availability periods", openapi.Array(doc.Schema(service.PendingAvailability{})))},
+	})
+
+	addRoutes(doc, "Schedules API", accessManager, []route{
+		{
+			pattern: "GET /api/v1/schedules", id: "listSchedules", summary: "List schedules",
+			query: query(
+				openapi.Parameter{Name: "status", In: "query", Description: "Lifecycle status", Schema: openapi.String("").OneOf(scheduleStatuses()...)},
+				openapi.Parameter{Name: "from", In: "query", Description: "Period overlaps this day or later", Schema: openapi.Date("")},
+				openapi.Parameter{Name: "to", In: "query", Description: "Period overlaps this day or earlier", Schema: openapi.Date("")},
+			),
+			response: jsonOf("A page of schedules", list(domain.Schedule{})),
+		},
+		{pattern: "POST /api/v1/schedules", id: "generateSchedule", summary: "Generate a schedule", body: jsonBody(doc, GenerateScheduleInput{}), status: "201", response: jsonOf("The generated schedule", doc.Schema(domain.Schedule{})), versioned: true},
+		{pattern: "GET /api/v1/schedules/{id}", id: "getSchedule", summary: "Get a schedule", response: jsonOf("The schedule", doc.Schema(domain.Schedule{})), versioned: true},
+		{pattern: "DELETE /api/v1/schedules/{id}", id: "deleteSchedule", summary: "Delete a draft schedule", status: "204", response: noContent},
+		{pattern: "POST /api/v1/schedules/{id}/transitions", id: "transitionSchedule", summary: "Move a schedule through its lifecycle", body: jsonBody(doc, TransitionInput{}), response: jsonOf("The schedule", doc.Schema(domain.Schedule{})), versioned: true},
+		{pattern: "POST /api/v1/schedules/{id}/send", id: "sendToN8N", summary: "Send a published schedule to n8n", response: jsonOf("The schedule", doc.Schema(domain.Schedule{}))},
+		{
+			pattern: "GET /api/v1/schedules/{id}/assignments", id: "listAssignments", summary: "List a schedule's assignments",
+			query: query(
+				openapi.Parameter{Name: "employee_id", In: "query", Description: "Assigned employee", Schema: openapi.String("")},
+				openapi.Parameter{Name: "shift_type", In: "query", Description: "Shift type", Schema: openapi.String("")},
+				openapi.Parameter{Name: "date", In: "query", Description: "Day of the shift", Schema: openapi.Date("")},
+			),
+			response: jsonOf("A page of assignments", list(domain.ShiftAssignment{})),
+		},
+		{pattern: "POST /api/v1/schedules/{id}/assignments", id: "addAssignment", summary: "Add a shift to a draft schedule", body: jsonBody(doc, AssignmentInput{}), status: "201", response: jsonOf("The schedule", doc.Schema(domain.Schedule{})), versioned: true},
+		{pattern: "DELETE /api/v1/schedules/{id}/assignments/{assignmentID}", id: "removeAssignment", summary: "Remove a shift from a draft schedule", response: jsonOf("The schedule", doc.Schema(domain.Schedule{})), versioned: true},
+		{pattern: "POST /api/v1/schedules/{id}/assignments/{assignmentID}/move", id: "moveAssignment", summary: "Move a shift to another day or shift type", body: jsonBody(doc, AssignmentInput{}), response: jsonOf("The schedule", doc.Schema(domain.Schedule{})), versioned: true},
+		{pattern: "POST /api/v1/schedules/{id}/assignments/{assignmentID}/swap", id: "swapAssignments", summary: "Swap the employees of two shifts", body: jsonBody(doc, SwapInput{}), response: jsonOf("The schedule", doc.Schema(domain.Schedule{})), versioned: true},
+		{pattern: "GET /api/v1/", id: "apiNotFound", summary: "Unknown API paths", access: accessPublic, status: "404", response: jsonOf("No such endpoint", doc.Schema(ErrorResponse{}))},
+	})
+
+	addRoutes(doc, "Shift swaps API", accessSignedIn, []route{
+		{pattern: "GET /api/v1/swaps", id: "listShiftOffers", summary: "List the open offers of other employees and whether the signed-in employee can claim them", response: jsonOf("The offers, oldest first", openapi.Array(doc.Schema(service.OpenOffer{})))},
+		{pattern: "POST /api/v1/swaps", id: "offerShift", summary: "Offer one of the signed-in employee's upcoming shifts", body: jsonBody(doc, ShiftOfferInput{}), status: "201", response: jsonOf("The offer", doc.Schema(domain.ShiftSwap{}))},
+		{pattern: "GET /api/v1/swaps/pending", id: "listPendingSwaps", summary: "List claimed offers waiting for a decision", access: accessManager, response: jsonOf("The claimed offers, oldest first", openapi.Array(doc.Schema(domain.ShiftSwap{})))},
+		{pattern: "POST /api/v1/swaps/{id}/claim", id: "claimShift", summary: "Claim an open offer for the signed-in employee", body: jsonBody(doc, ClaimInput{}), response: jsonOf("The claimed offer", doc.Schema(domain.ShiftSwap{}))},
+		{pattern: "POST /api/v1/swaps/{id}/cancel", id: "cancelShiftOffer", summary: "Withdraw the signed-in employee's offer", response: jsonOf("The cancelled offer", doc.Schema(domain.ShiftSwap{}))},
+		{pattern: "POST /api/v1/swaps/{id}/approve", id: "approveSwap", summary: "Approve a claimed offer, updating the schedule", access: accessManager, response: jsonOf("The approved offer", doc.Schema(domain.ShiftSwap{}))},
+		{pattern: "POST /api/v1/swaps/{id}/reject", id: "rejectSwap", summary: "Reject a claimed offer", access: accessManager, response: jsonOf("The rejected offer", doc.Schema(domain.ShiftSwap{}))},
+		{pattern: "GET /api/v1/schedules/{id}/swaps", id: "listScheduleSwaps", summary: "History of the offers made in a schedule", access: accessManager, response: jsonOf("The offers, oldest first", openapi.Array(doc.Schema(domain.ShiftSwap{})))},
+	})
+
+	addRoutes(doc, "Leave API", accessOwner, []route{
+		{pattern: "GET /api/v1/employees/{id}/leave", id: "listLeave", summary: "List an employee's leave requests", response: jsonOf("The leave requests, latest first", openapi.Array(doc.Schema(domain.LeaveRequest{})))},
+		{pattern: "POST /api/v1/employees/{id}/leave", id: "requestLeave", summary: "Request leave for an employee; requests wait for approval", body: jsonBody(doc, LeaveInput{}), status: "201", response: jsonOf("The leave request", doc.Schema(domain.LeaveRequest{}))},
+		{
+			pattern: "GET /api/v1/employees/{id}/leave/balances", id: "getLeaveBalances", summary: "An employee's allowance, taken, pending and remaining leave per type in working days",
+			query: []openapi.Parameter{
+				{Name: "year", In: "query", Description: "Calendar year; the current year if omitted", Schema: openapi.Integer("").AtLeast(1)},
+			},
+			response: jsonOf("The balances", openapi.Array(doc.Schema(service.LeaveBalance{}))),
+		},
+		{pattern: "POST /api/v1/employees/{id}/leave/{leaveID}/cancel", id: "cancelLeave", summary: "Cancel a pending or approved leave request", response: jsonOf("The cancelled leave request", doc.Schema(domain.LeaveRequest{}))},
+		{pattern: "GET /api/v1/leave/pending", id: "listPendingLeave", summary: "List leave requests waiting for approval, with the published shifts they collide with", access: accessManager, response: jsonOf("The leave requests, earliest first", openapi.Array(doc.Schema(domain.LeaveRequest{})))},
+		{pattern: "GET /api/v1/leave/{id}", id: "getLeave", summary: "Get a leave request", access: accessManager, response: jsonOf("The leave request", doc.Schema(domain.LeaveRequest{}))},
+		{pattern: "POST /api/v1/leave/{id}/approve", id: "approveLeave", summary: "Approve a leave request, blocking the employee from being scheduled during it", access: accessManager, response: jsonOf("The approved leave request", doc.Schema(domain.LeaveRequest{}))},
+		{pattern: "POST /api/v1/leave/{id}/reject", id: "rejectLeave", summary: "Reject a leave request", access: accessManager, response: jsonOf("The rejected leave request", doc.Schema(domain.LeaveRequest{}))},
+	})
+
+	addRoutes(doc, "Company API", accessManager, []route{
+		{pattern: "GET /api/v1/company-config", id: "getCompanyConfig", summary: "Get the company configuration", response: jsonOf("The configuration", doc.Schema(domain.CompanyConfig{})), versioned: true},
+		{pattern: "PUT /api/v1/company-config", id: "updateCompanyConfig", summary: "Replace the company configuration", access: accessAdmin, body: jsonBody(doc, domain.CompanyConfig{}), response: jsonOf("The configuration", doc.Schema(domain.CompanyConfig{})), versioned: true},
+	})
+
+	auditQuery := []openapi.Parameter{
+		{Name: "actor", In: "query", Description: "Email of who made the change", Schema: openapi.String("")},
+		{Name: "action", In: "query", Description: "Action", Schema: openapi.String("").OneOf(domain.AuditActions()...)},
+		{Name: "since", In: "query", Description: "Changes on this day or later", Schema: openapi.Date("")},
+		{Name: "until", In: "query", Description: "Changes on this day or earlier", Schema: openapi.Date("")},
+	}
+	addRoutes(doc, "Audit API", accessManager, []route{
+		{
+			pattern: "GET /api/v1/audit", id: "listAudit", summary: "List the changes made to employees, their availability, schedules and the company configuration",
+			query: query(append([]openapi.Parameter{
+				{Name: "entity_type", In: "query", Description: "Type of the changed record", Schema: openapi.String("").OneOf(domain.AuditEntities()...)},
+				{Name: "entity_id", In: "query", Description: "ID of the changed record", Schema: openapi.String("")},
+			}, auditQuery...)...),
+			response: jsonOf("A page of changes, newest first", list(domain.AuditEntry{})),
+		},
+		{pattern: "GET /api/v1/employees/{id}/audit", id: "listEmployeeAudit", summary: "List the changes made to an employee and their availability", query: query(auditQuery...), response: jsonOf("A page of changes, newest first", list(domain.AuditEntry{}))},
+		{pattern: "GET /api/v1/schedules/{id}/audit", id: "listScheduleAudit", summary: "List the changes made to a schedule, including after it was deleted", query: query(auditQuery...), response: jsonOf("A page of changes, newest first", list(domain.AuditEntry{}))},
+		{pattern: "GET /api/v1/company-config/audit", id: "listCompanyConfigAudit", summary: "List the changes made to the company configuration", query: query(auditQuery...), response: jsonOf("A page of changes, newest first", list(domain.AuditEntry{}))},
+	})
+
+	return doc
+}
+
+// addRoutes adds routes to the document under a tag, with their path parameters,
+// error response and who may call them. Routes without an access of their own
+// get the group's.
+func addRoutes(doc *openapi.Document, tag string, groupAccess access, routes []route) {
+	doc.AddTag(openapi.Tag{Name: tag})
+	for _, rt := range routes {
+		method, path := openapi.SplitPattern(rt.pattern)
+
+		params := make([]openapi.Parameter, 0, len(rt.query)+2)
+		for _, name := range openapi.PathParameters(path) {
+			params = append(params, pathParameter(path, name))
+		}
+		params = append(params, rt.query...)
+
+		status := rt.status
+		if status == "" {
+			status = "200"
+		}
+		errorResponse := openapi.Response{Description: "Error message as an HTML fragment", Content: map[string]openapi.MediaType{
+			openapi.MediaTypeHTML: {Schema: openapi.String("")},
+		}}
+		if strings.HasPrefix(path, "/api/v1/") || strings.HasPrefix(path, "/health") {
+			errorResponse = openapi.Response{Description: "Error", Content: map[string]openapi.MediaType{
+				openapi.MediaTypeJSON: {Schema: doc.Schema(ErrorResponse{})},
+			}}
+		}
+
+		responses := map[string]openapi.Response{status: rt.response, "default": errorResponse}
+		if rt.versioned {
+			response := rt.response
+			response.Headers = map[string]openapi.Header{
+				"ETag": {Description: `Version of the returned record, e.g. "3", to send as If-Match when changing it`, Schema: openapi.String("")},
+			}
+			responses[status] = response
+
+			// Creating a record has no version to name
+			if method != "GET" && (method != "POST" || strings.Contains(path, "{")) {
+				params = append(params, openapi.Parameter{
+					Name: "If-Match", In: "header", Required: true, Schema: openapi.String(""),
+					Description: "ETag of the version being changed; if the record has changed since, 409 is returned",
+				})
+				responses["409"] = openapi.Response{
+					Description: "The record has changed since the If-Match version, or the change conflicts with its state",
+					Content:     errorResponse.Content,
+				}
+				responses["428"] = openapi.Response{
+					Description: "The If-Match header is missing",
+					Content:     errorResponse.Content,
+				}
+			}
+		}
+
+		op := &openapi.Operation{
+			OperationID: rt.id,
+			Summary:     rt.summary,
+			Tags:        []string{tag},
+			Parameters:  params,
+			RequestBody: rt.body,
+			Responses:   responses,
+		}
+
+		routeAccess := rt.access
+		if routeAccess == accessGroup {
+			routeAccess = groupAccess
+		}
+		switch routeAccess {
+		case accessPublic:
+		case accessSignedIn:
+			op.Roles = domain.UserRoles()
+		case accessOwner:
+			op.Roles = []string{domain.UserRoleAdmin, domain.UserRoleManager}
+			op.OwnerParameter = "id"
+		case accessManager:
+			op.Roles = []string{domain.UserRoleAdmin, domain.UserRoleManager}
+		case accessAdmin:
+			op.Roles = []string{domain.UserRoleAdmin}
+		default:
+			panic(fmt.Sprintf("route %q has no access", rt.pattern))
+		}
+		if op.Roles != nil {
+			op.Security = []openapi.SecurityRequirement{{sessionScheme: {}}}
+		}
+
+		doc.Add(method, path, op)
+	}
+}
+
+// pathParameter describes a path parameter by the resource it follows
+func pathParameter(path, name string) openapi.Parameter {
+	param := openapi.Parameter{Name: name, In: "path", Required: true, Schema: openapi.String("")}
+	switch name {
+	case "id":
+		switch {
+		case strings.Contains(path, "/employees/"):
+			param.Description = "Employee ID"
+		case strings.HasPrefix(path, "/users/"):
+			param.Description = "User ID"
+		case strings.Contains(path, "/swaps/"):
+			param.Description = "Shift swap ID"
+		case strings.Contains(path, "/leave/"):
+			param.Description = "Leave request ID"
+		default:
+			param.Description = "Schedule ID"
+		}
+	case "availabilityID":
+		param.Description = "Availability period ID"
+	case "assignmentID":
+		param.Description = "Assignment ID"
+	case "leaveID":
+		param.Description = "Leave request ID"
+	case "token":
+		param.Description = `Calendar feed token, optionally followed by ".ics"`
+	}
+	return param
+}
+
+// jsonBody returns a required JSON request body of the schema of v
+func jsonBody(doc *openapi.Document, v any) *openapi.RequestBody {
+	return &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+		openapi.MediaTypeJSON: {Schema: doc.Schema(v)},
+	}}
+}
+
+// formBody returns a URL-encoded form request body
+func formBody(schema *openapi.Schema) *openapi.RequestBody {
+	return &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+		openapi.MediaTypeForm: {Schema: schema},
+	}}
+}
+
+// describeSchemas refines the schemas generated from the domain and input types
+// with the required fields and allowed values the handlers check
+func describeSchemas(doc *openapi.Document) {
+	availabilityTypes := []string{domain.AvailabilityTypeAvailable, domain.AvailabilityTypeUnavailable, domain.AvailabilityTypePreferred}
+
+	doc.Component(domain.EmployeeCreateInput{}).Require("name", "email", "role", "monthly_hours")
+	doc.Component(domain.Skill{}).Require("name")
+	availabilityPeriod := doc.Component(domain.Availability{})
+	availabilityPeriod.Property("type").OneOf(availabilityTypes...)
+	availabilityPeriod.Property("status").OneOf(domain.AvailabilityStatusApproved, domain.AvailabilityStatusPending, domain.AvailabilityStatusRejected)
+
+	availabilityPeriod.Property("end_date").Description = "Last day of the period; the zero time for a recurring period without end"
+	availabilityPeriod.Property("start_time").Description = "Start of the daily time window, HH:MM; empty for the whole day"
+	availabilityPeriod.Property("end_time").Description = "End of the daily time window, HH:MM; before start_time for a window ending the next day"
+	recurrence := doc.Component(domain.Recurrence{}).Require("weekdays")
+	recurrence.Property("weekdays").Description = "Weekdays the period applies on, from 0 (Sunday) to 6 (Saturday)"
+	recurrence.Property("interval").Description = "Repeats every this many weeks, counted from the week of the start date; weekly if 0 or 1"
+
+	availability := doc.Component(AvailabilityInput{}).Require("start_date", "type")
+	availability.Property("start_date").Format = openapi.FormatDate
+	availability.Property("end_date").Format = openapi.FormatDate
+	availability.Property("end_date").Description = "Last day of the period; required unless rrule is set"
+	availability.Property("type").OneOf(availabilityTypes...)
+	availability.Property("shift_types").Description = "Shift types the period applies to; empty means all"
+	availability.Property("start_time").Description = "Start of a daily time window, HH:MM; shifts are compared with it by their actual hours"
+	availability.Property("end_time").Description = "End of the daily time window, HH:MM; before start_time for a window ending the next day"
+	availability.Property("rrule").Description = `Weekly iCalendar RRULE the period repeats by, e.g. "FREQ=WEEKLY;INTERVAL=2;BYDAY=SA,SU;UNTIL=20251231"`
+	availability.Property("exceptions").Description = "YYYY-MM-DD days a repeating period skips"
+
+	swap := doc.Component(domain.ShiftSwap{})
+	swap.Property("kind").OneOf(domain.ShiftSwapKinds()...)
+	swap.Property("status").OneOf(domain.ShiftSwapStatuses()...)
+	doc.Component(ShiftOfferInput{}).Require("schedule_id", "assignment_id", "kind").Property("kind").OneOf(domain.ShiftSwapKinds()...)
+
+	leave := doc.Component(domain.LeaveRequest{})
+	leave.Property("type").OneOf(domain.LeaveTypes()...)
+	leave.Property("status").OneOf(domain.LeaveStatuses()...)
+	leaveInput := doc.Component(LeaveInput{}).Require("type", "start_date", "end_date")
+	leaveInput.Property("type").OneOf(domain.LeaveTypes()...)
+	leaveInput.Property("start_date").Format = openapi.FormatDate
+	leaveInput.Property("end_date").Format = openapi.FormatDate
+
+	generate := doc.Component(GenerateScheduleInput{})
+	generate.Property("preset").OneOf(append(domain.PeriodPresets(), periodPresetCustom)...)
+	generate.Property("preset").Description = "Period to schedule; custom, or empty with start_date set, uses start_date and end_date"
+	generate.Property("start_date").Format = openapi.FormatDate
+	generate.Property("end_date").Format = openapi.FormatDate
+	generate.Property("strategy").OneOf(domain.SchedulingStrategies()...)
+	generate.Property("strategy").Description = "Scheduling strategy; empty uses the company default"
+
+	transition := doc.Component(TransitionInput{}).Require("action")
+	transition.Property("action").OneOf(domain.ScheduleActions()...)
+	transition.Property("send_to_n8n").Description = "With publish, also send the schedule to n8n"
+
+	assignment := doc.Component(AssignmentInput{}).Require("date", "shift_type")
+	assignment.Property("employee_id").Description = "Employee to assign; required when adding"
+	assignment.Property("date").Format = openapi.FormatDate
+
+	doc.Component(SwapInput{}).Require("other_id")
+	doc.Component(LoginInput{}).Require("email", "password")
+	doc.Component(SessionResponse{}).Require("user", "csrf_token")
+	doc.Component(domain.User{}).Property("role").OneOf(domain.UserRoles()...)
+
+	for _, versioned := range []any{domain.Employee{}, domain.Schedule{}, domain.CompanyConfig{}} {
+		doc.Component(versioned).Property("version").Description = "Counts the saved changes, starting at 1; also returned as ETag"
+	}
+
+	doc.Component(domain.Schedule{}).Property("status").OneOf(scheduleStatuses()...)
+	doc.Component(domain.CompanyConfig{}).Require("company_name")
+	doc.Component(domain.CompanyConfig{}).Property("scheduling_strategy").OneOf(domain.SchedulingStrategies()...)
+	doc.Component(domain.WorkingHours{}).Property("working_days").Items.Between(0, 6)
+	doc.Component(domain.WorkingHours{}).Property("working_days").Description = "Days the company operates, 0 = Sunday to 6 = Saturday"
+}
+
+// scheduleStatuses returns every schedule lifecycle status
+func scheduleStatuses() []string {
+	return []string{
+		domain.ScheduleStatusDraft,
+		domain.ScheduleStatusApproved,
+		domain.ScheduleStatusPublished,
+		domain.ScheduleStatusCompleted,
+		domain.ScheduleStatusArchived,
+	}
+}
+
+// employeeForm is the employee form of the web interface
+func employeeForm() *openapi.Schema {
+	return openapi.Object(map[string]*openapi.Schema{
+		"name":             openapi.String("Full name"),
+		"email":            openapi.String("Email address, unique per employee"),
+		"role":             openapi.String("Job role"),
+		"role_description": openapi.String("What the role involves"),
+		"monthly_hours":    openapi.Integer("Contracted hours per month"),
+		"skills":           openapi.String(`Comma-separated skills, each optionally followed by ":YYYY-MM-DD" for its expiry date, e.g. "bartender, first-aid:2026-05-31"`),
+		"leave_allowances": openapi.String(`Comma-separated yearly leave allowances in days by leave type, e.g. "vacation:25, parental:10"; leave types without one are not limited`),
+	}, "name", "email", "role", "monthly_hours")
+}
+
+// withVersion adds the version field of an edit form, which is saved only if
+// the record is still at the version the form was loaded at
+func withVersion(form *openapi.Schema) *openapi.Schema {
+	form.Properties["version"] = openapi.Integer("Version the form was loaded at; if the record has changed since, the form is shown again with the differences")
+	return form
+}
+
+// userForm is the user form of the admin pages. The password is required when
+// creating a user; when editing, an empty password keeps the current one.
+func userForm(create bool) *openapi.Schema {
+	password := passwordSchema("Password")
+	required := []string{"name", "email", "role"}
+	if create {
+		required = append(required, "password")
+	} else {
+		password = passwordSchema("New password; empty keeps the current one")
+	}
+
+	return openapi.Object(map[string]*openapi.Schema{
+		"name":        openapi.String("Full name"),
+		"email":       openapi.String("Email address, used to sign in"),
+		"role":        openapi.String("").OneOf(domain.UserRoles()...),
+		"employee_id": openapi.String("Employee the account belongs to; required for the employee role"),
+		"password":    password,
+	}, required...)
+}
+
+// passwordSchema is a password field of the length bcrypt supports
+func passwordSchema(description string) *openapi.Schema {
+	maxLength := domain.MaxPasswordLength
+	return &openapi.Schema{Type: "string", Description: description, MaxLength: &maxLength}
+}
+
+// availabilityForm is the availability form of the web interface
+func availabilityForm() *openapi.Schema {
+	return openapi.Object(map[string]*openapi.Schema{
+		"start_date":  openapi.Date("First day of the period"),
+		"end_date":    openapi.Date("Last day of the period; optional when it repeats"),
+		"type":        openapi.String("").OneOf(domain.AvailabilityTypeAvailable, domain.AvailabilityTypeUnavailable, domain.AvailabilityTypePreferred),
+		"reason":      openapi.String("Optional note"),
+		"shift_types": openapi.Array(openapi.String("Shift type the period applies to; none means all")),
+		"start_time":  openapi.String("Start of a daily time window, HH:MM; empty for the whole day"),
+		"end_time":    openapi.String("End of the daily time window, HH:MM"),
+		"weekdays":    openapi.Array(openapi.Integer("Weekday the period repeats on, from 0 (Sunday) to 6 (Saturday); none means every day").Between(0, 6)),
+		"interval":    openapi.Integer("Repeats every this many weeks").Between(1, 52),
+		"exceptions":  openapi.String("Comma-separated YYYY-MM-DD days a repeating period skips"),
+	}, "start_date", "type")
+}
+
+// generateForm is the schedule generation form of the web interface
+func generateForm() *openapi.Schema {
+	return openapi.Object(map[string]*openapi.Schema{
+		"preset":     openapi.String("Period to schedule; custom uses start_date and end_date").OneOf(append(domain.PeriodPresets(), periodPresetCustom)...),
+		"start_date": openapi.Date("First day of a custom period"),
+		"end_date":   openapi.Date("Last day of a custom period"),
+		"strategy":   openapi.String("Scheduling strategy; empty uses the company default").OneOf(domain.SchedulingStrategies()...),
+	})
+}
+
+// companyConfigForm is the company configuration form of the web interface.
+// Shift definitions and shift requirements are numbered rows, e.g. shift_type_0
+// and min_employees_0 describe the first requirement.
+func companyConfigForm() *openapi.Schema {
+	form := openapi.Object(map[string]*openapi.Schema{
+		"company_name":             openapi.String("Company name"),
+		"open_time":                openapi.String("Opening time, HH:MM"),
+		"close_time":               openapi.String("Closing time, HH:MM"),
+		"timezone":                 openapi.String("IANA timezone, e.g. Europe/Oslo"),
+		"working_days":             openapi.Array(openapi.Integer("0 = Sunday to 6 = Saturday").Between(0, 6)),
+		"max_consecutive_days":     openapi.Integer("Maximum consecutive work days"),
+		"min_rest_hours":           openapi.Integer("Minimum rest hours between shifts"),
+		"allow_overtime":           openapi.Boolean("Allow overtime"),
+		"max_overtime_hours":       openapi.Integer("Maximum overtime hours per month"),
+		"weekend_consent_required": openapi.Boolean("Require consent for weekend shifts"),
+		"fair_distribution":        openapi.Boolean("Distribute shifts fairly"),
+		"scheduling_strategy":      openapi.String("Default scheduling strategy").OneOf(domain.SchedulingStrategies()...),
+		"ai_context":               openapi.String("Additional instructions for the n8n AI agent"),
+	}, "company_name")
+
+	form.Description = "Shift definitions and requirements are numbered rows: shift_def_name_0, shift_def_type_0 and so on describe the first shift definition, shift_type_0, min_employees_0 and so on the first requirement."
+	form.PatternProperties = map[string]*openapi.Schema{
+		`^shift_def_name_\d+$`:      openapi.String("Display name of a shift definition"),
+		`^shift_def_type_\d+$`:      openapi.String("Identifier of a shift definition; derived from the name if empty"),
+		`^shift_def_start_\d+$`:     openapi.String("Start time, HH:MM"),
+		`^shift_def_end_\d+$`:       openapi.String("End time, HH:MM"),
+		`^shift_def_break_\d+$`:     openapi.Integer("Unpaid break in minutes"),
+		`^shift_def_overnight_\d+$`: openapi.Boolean("The shift ends on the following day"),
+		`^shift_def_color_\d+$`:     openapi.String(`Hex colour, e.g. "#3b82f6"`),
+		`^shift_type_\d+$`:          openapi.String("Shift type of a requirement"),
+		`^min_employees_\d+$`:       openapi.Integer("Minimum employees on the shift"),
+		`^max_employees_\d+$`:       openapi.Integer("Maximum employees on the shift"),
+		`^required_skills_\d+$`:     openapi.String(`Comma-separated skills the shift needs, optionally with a count, e.g. "Server (2)"`),
+		`^description_\d+$`:         openapi.String("Description of a requirement"),
+	}
+	return form
+}
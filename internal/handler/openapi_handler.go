@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+
+	"github.com/isak/restySched/internal/openapi"
+	"github.com/isak/restySched/web/templates"
+	"github.com/rs/zerolog/log"
+)
+
+// OpenAPIHandler serves the OpenAPI document and its documentation page
+type OpenAPIHandler struct {
+	doc  *openapi.Document
+	spec []byte
+}
+
+// NewOpenAPIHandler creates a new OpenAPI handler
+func NewOpenAPIHandler(doc *openapi.Document) (*OpenAPIHandler, error) {
+	spec, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &OpenAPIHandler{doc: doc, spec: spec}, nil
+}
+
+// Spec serves the OpenAPI document as JSON
+func (h *OpenAPIHandler) Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(h.spec)
+}
+
+// Docs shows the API documentation page
+func (h *OpenAPIHandler) Docs(w http.ResponseWriter, r *http.Request) {
+	if err := templates.APIDocs(h.doc).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render API docs")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// ValidateRequestBodies checks request bodies against the OpenAPI document
+// before they reach the handlers of mux. JSON bodies and URL-encoded forms are
+// validated; requests of routes without a described body, and multipart
+// uploads, are passed through unchecked. Invalid JSON API requests get a JSON
+// error and form posts an HTML error fragment.
+func ValidateRequestBodies(doc *openapi.Document, mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := mux.Handler(r)
+		method, path := openapi.SplitPattern(pattern)
+		op := doc.Operation(method, path)
+		if op == nil || op.RequestBody == nil {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		jsonBody, isJSON := op.RequestBody.Content[openapi.MediaTypeJSON]
+		formBody, isForm := op.RequestBody.Content[openapi.MediaTypeForm]
+		respond := respondWithError
+		if isJSON {
+			respond = respondWithJSONError
+		}
+		if !isJSON && (!isForm || mediaType == openapi.MediaTypeMultipart) {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxJSONBodySize))
+		if err != nil {
+			respond(w, err, http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// The JSON API decodes bodies as JSON whatever their content type, so
+		// they are validated the same way
+		if isJSON {
+			err = doc.ValidateJSON(jsonBody.Schema, body)
+		} else {
+			var values url.Values
+			values, err = url.ParseQuery(string(body))
+			if err != nil {
+				err = &openapi.ValidationError{Message: "is not a valid URL-encoded form"}
+			} else {
+				err = doc.ValidateForm(formBody.Schema, values)
+			}
+		}
+		if err != nil {
+			respond(w, err, http.StatusBadRequest)
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
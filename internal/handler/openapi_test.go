@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/isak/restySched/internal/openapi"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	doc := NewOpenAPIDocument()
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("failed to marshal the document: %v", err)
+	}
+	for _, m := range regexp.MustCompile(`"\$ref":"#/components/schemas/([^"]+)"`).FindAllStringSubmatch(string(data), -1) {
+		if _, ok := doc.Components.Schemas[m[1]]; !ok {
+			t.Errorf("reference to undefined schema %q", m[1])
+		}
+	}
+
+	ids := make(map[string]string)
+	for _, pattern := range doc.Routes() {
+		method, path := openapi.SplitPattern(pattern)
+		op := doc.Operation(method, path)
+
+		if other, ok := ids[op.OperationID]; ok || op.OperationID == "" {
+			t.Errorf("%s: operationId %q is empty or also used by %s", pattern, op.OperationID, other)
+		}
+		ids[op.OperationID] = pattern
+
+		var declared []string
+		for _, p := range op.Parameters {
+			if p.In == "path" {
+				declared = append(declared, p.Name)
+			}
+		}
+		if want := openapi.PathParameters(path); !reflect.DeepEqual(declared, want) {
+			t.Errorf("%s: path parameters = %v, want %v", pattern, declared, want)
+		}
+		if len(op.Responses) < 2 {
+			t.Errorf("%s: responses = %v, want a success and an error response", pattern, op.Responses)
+		}
+	}
+}
+
+func TestValidateRequestBodies(t *testing.T) {
+	var received string
+	reached := false
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/employees", echo)
+	mux.HandleFunc("POST /employees", echo)
+	mux.HandleFunc("POST /employees/import", echo)
+	mux.HandleFunc("POST /schedules/{id}/approve", echo)
+	mux.HandleFunc("GET /employees", echo)
+	handler := ValidateRequestBodies(NewOpenAPIDocument(), mux)
+
+	validEmployee := `{"name":"John Doe","email":"john@example.com","role":"Chef","monthly_hours":160}`
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		wantReached bool
+		wantStatus  int
+		wantType    string // content type of an error response
+	}{
+		{"valid JSON", "POST", "/api/v1/employees", "application/json", validEmployee, true, http.StatusOK, ""},
+		{"JSON of the wrong type", "POST", "/api/v1/employees", "application/json", `{"name":"John Doe","email":"john@example.com","role":"Chef","monthly_hours":"lots"}`, false, http.StatusBadRequest, "application/json"},
+		{"JSON missing a required field", "POST", "/api/v1/employees", "application/json", `{"name":"John Doe"}`, false, http.StatusBadRequest, "application/json"},
+		{"JSON with an unknown field", "POST", "/api/v1/employees", "application/json", `{"name":"John Doe","email":"john@example.com","role":"Chef","monthly_hours":160,"salary":1}`, false, http.StatusBadRequest, "application/json"},
+		{"JSON without a content type", "POST", "/api/v1/employees", "", `{"name":1}`, false, http.StatusBadRequest, "application/json"},
+		{"valid form", "POST", "/employees", "application/x-www-form-urlencoded", "name=John&email=john%40example.com&role=Chef&monthly_hours=160&skills=", true, http.StatusOK, ""},
+		{"form with a non-numeric field", "POST", "/employees", "application/x-www-form-urlencoded", "name=John&email=john%40example.com&role=Chef&monthly_hours=lots", false, http.StatusBadRequest, "text/html"},
+		{"multipart upload is not checked", "POST", "/employees/import", "multipart/form-data; boundary=x", "--x--", true, http.StatusOK, ""},
+		{"route without a body", "POST", "/schedules/s1/approve", "application/x-www-form-urlencoded", "", true, http.StatusOK, ""},
+		{"GET request", "GET", "/employees", "", "", true, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached, received = false, ""
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if reached != tt.wantReached {
+				t.Fatalf("handler reached = %v, want %v (response %d %s)", reached, tt.wantReached, rec.Code, rec.Body.String())
+			}
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantReached && received != tt.body {
+				t.Errorf("handler received body %q, want %q", received, tt.body)
+			}
+			if tt.wantType != "" && !strings.HasPrefix(rec.Header().Get("Content-Type"), tt.wantType) {
+				t.Errorf("Content-Type = %q, want %s", rec.Header().Get("Content-Type"), tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/export"
+	"github.com/isak/restySched/web/templates"
+	"github.com/rs/zerolog/log"
+)
+
+// ExportAssignmentsCSV downloads a schedule's shift assignments as CSV
+func (h *ScheduleHandler) ExportAssignmentsCSV(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.exportSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	setDownloadHeaders(w, "text/csv; charset=utf-8", exportFilename(schedule, "assignments", "csv"))
+	if err := export.WriteCSV(w, h.service.AssignmentsSheet(schedule)); err != nil {
+		log.Error().Err(err).Str("schedule_id", schedule.ID).Msg("Failed to write assignments CSV")
+	}
+}
+
+// ExportHoursCSV downloads a schedule's per-employee hours summary as CSV
+func (h *ScheduleHandler) ExportHoursCSV(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.exportSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	setDownloadHeaders(w, "text/csv; charset=utf-8", exportFilename(schedule, "hours", "csv"))
+	if err := export.WriteCSV(w, h.service.HoursSheet(schedule)); err != nil {
+		log.Error().Err(err).Str("schedule_id", schedule.ID).Msg("Failed to write hours CSV")
+	}
+}
+
+// ExportXLSX downloads a schedule as a workbook with an assignments sheet and an
+// hours summary sheet
+func (h *ScheduleHandler) ExportXLSX(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.exportSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	setDownloadHeaders(w, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", exportFilename(schedule, "schedule", "xlsx"))
+	if err := export.WriteXLSX(w, h.service.AssignmentsSheet(schedule), h.service.HoursSheet(schedule)); err != nil {
+		log.Error().Err(err).Str("schedule_id", schedule.ID).Msg("Failed to write schedule workbook")
+	}
+}
+
+// PrintSchedule shows a schedule as a printable week grid, which browsers can
+// also save as PDF
+func (h *ScheduleHandler) PrintSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.exportSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	if err := templates.SchedulePrint(*schedule).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render schedule print view")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+func (h *ScheduleHandler) exportSchedule(w http.ResponseWriter, r *http.Request) (*domain.Schedule, bool) {
+	id := r.PathValue("id")
+
+	schedule, err := h.service.GetSchedule(r.Context(), id)
+	if err != nil {
+		log.Warn().Err(err).Str("schedule_id", id).Msg("Failed to fetch schedule for export")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return nil, false
+	}
+	return schedule, true
+}
+
+func setDownloadHeaders(w http.ResponseWriter, contentType, filename string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+}
+
+// exportFilename names an export after its contents and the schedule period,
+// e.g. "assignments-2025-01-06-to-2025-01-19.csv"
+func exportFilename(schedule *domain.Schedule, kind, extension string) string {
+	loc := schedule.Location()
+	return fmt.Sprintf("%s-%s-to-%s.%s", kind,
+		schedule.PeriodStart.In(loc).Format("2006-01-02"), schedule.PeriodEnd.In(loc).Format("2006-01-02"), extension)
+}
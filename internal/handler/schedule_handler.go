@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/isak/restySched/internal/auth"
+	"github.com/isak/restySched/internal/domain"
 	"github.com/isak/restySched/internal/service"
 	"github.com/isak/restySched/web/templates"
 	"github.com/rs/zerolog/log"
@@ -30,10 +36,16 @@ func (h *ScheduleHandler) ListSchedules(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-func (h *ScheduleHandler) GenerateBiweeklySchedule(w http.ResponseWriter, r *http.Request) {
-	schedule, err := h.service.GenerateBiweeklySchedule(r.Context())
+// periodPresetCustom selects the start_date and end_date form values instead of a preset
+const periodPresetCustom = "custom"
+
+// GenerateSchedule generates a schedule for a period preset or for custom start and
+// end dates (YYYY-MM-DD, both included). Without either it covers the next two weeks.
+func (h *ScheduleHandler) GenerateSchedule(w http.ResponseWriter, r *http.Request) {
+	preset := r.FormValue("preset")
+	schedule, err := generateSchedule(r.Context(), h.service, preset, r.FormValue("start_date"), r.FormValue("end_date"), r.FormValue("strategy"))
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to generate schedule")
+		log.Error().Err(err).Str("preset", preset).Msg("Failed to generate schedule")
 		respondWithError(w, err, http.StatusInternalServerError)
 		return
 	}
@@ -43,6 +55,7 @@ func (h *ScheduleHandler) GenerateBiweeklySchedule(w http.ResponseWriter, r *htt
 		Time("period_start", schedule.PeriodStart).
 		Time("period_end", schedule.PeriodEnd).
 		Int("employees", len(schedule.Employees)).
+		Str("strategy", schedule.Strategy).
 		Msg("Schedule generated successfully")
 
 	if err := templates.ScheduleCard(*schedule).Render(r.Context(), w); err != nil {
@@ -51,6 +64,7 @@ func (h *ScheduleHandler) GenerateBiweeklySchedule(w http.ResponseWriter, r *htt
 	}
 }
 
+// SendToN8N sends a published schedule to n8n, for example after a failed delivery
 func (h *ScheduleHandler) SendToN8N(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
@@ -93,3 +107,243 @@ func (h *ScheduleHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request)
 	log.Info().Str("schedule_id", id).Msg("Schedule deleted successfully")
 	w.WriteHeader(http.StatusOK)
 }
+
+// AddAssignment adds a shift to a draft schedule
+func (h *ScheduleHandler) AddAssignment(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	date, err := time.Parse("2006-01-02", r.FormValue("date"))
+	if err != nil {
+		h.respondWithEditError(w, r, id, domain.ErrInvalidAssignment)
+		return
+	}
+	version, err := expectedVersion(r)
+	if err != nil {
+		h.respondWithEditError(w, r, id, err)
+		return
+	}
+
+	schedule, err := h.service.AddAssignment(r.Context(), id, version, r.FormValue("employee_id"), date, r.FormValue("shift_type"))
+	h.respondWithEdit(w, r, id, schedule, err, "Assignment added")
+}
+
+// RemoveAssignment removes a shift from a draft schedule
+func (h *ScheduleHandler) RemoveAssignment(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		h.respondWithEditError(w, r, id, err)
+		return
+	}
+
+	schedule, err := h.service.RemoveAssignment(r.Context(), id, version, r.PathValue("assignmentID"))
+	h.respondWithEdit(w, r, id, schedule, err, "Assignment removed")
+}
+
+// MoveAssignment moves a shift in a draft schedule to another day or shift type
+func (h *ScheduleHandler) MoveAssignment(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	date, err := time.Parse("2006-01-02", r.FormValue("date"))
+	if err != nil {
+		h.respondWithEditError(w, r, id, domain.ErrInvalidAssignment)
+		return
+	}
+	version, err := expectedVersion(r)
+	if err != nil {
+		h.respondWithEditError(w, r, id, err)
+		return
+	}
+
+	schedule, err := h.service.MoveAssignment(r.Context(), id, version, r.PathValue("assignmentID"), date, r.FormValue("shift_type"))
+	h.respondWithEdit(w, r, id, schedule, err, "Assignment moved")
+}
+
+// SwapAssignments swaps the employees of two shifts in a draft schedule
+func (h *ScheduleHandler) SwapAssignments(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		h.respondWithEditError(w, r, id, err)
+		return
+	}
+
+	schedule, err := h.service.SwapAssignments(r.Context(), id, version, r.PathValue("assignmentID"), r.FormValue("other_id"))
+	h.respondWithEdit(w, r, id, schedule, err, "Assignments swapped")
+}
+
+// ApproveSchedule signs off a draft schedule
+func (h *ScheduleHandler) ApproveSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		h.respondWithEditError(w, r, id, err)
+		return
+	}
+
+	schedule, err := h.service.ApproveSchedule(r.Context(), id, version, requestActor(r))
+	h.respondWithEdit(w, r, id, schedule, err, "Schedule approved")
+}
+
+// PublishSchedule publishes an approved schedule, sending it to n8n when the
+// send_to_n8n form value is set
+func (h *ScheduleHandler) PublishSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	sendToN8N := r.FormValue("send_to_n8n") == "on" || r.FormValue("send_to_n8n") == "true"
+	version, err := expectedVersion(r)
+	if err != nil {
+		h.respondWithEditError(w, r, id, err)
+		return
+	}
+
+	schedule, err := h.service.PublishSchedule(r.Context(), id, version, requestActor(r), sendToN8N)
+	if errors.Is(err, domain.ErrScheduleNotDelivered) {
+		// The schedule is published; show the failed delivery on its card
+		log.Warn().Err(err).Str("schedule_id", id).Msg("Published schedule could not be sent to n8n")
+		if err := templates.ScheduleCardWithError(*schedule, err.Error()).Render(r.Context(), w); err != nil {
+			log.Error().Err(err).Msg("Failed to render schedule card")
+			handleInternalError(w, err, "render template")
+		}
+		return
+	}
+	h.respondWithEdit(w, r, id, schedule, err, "Schedule published")
+}
+
+// CompleteSchedule marks a published schedule as worked
+func (h *ScheduleHandler) CompleteSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		h.respondWithEditError(w, r, id, err)
+		return
+	}
+
+	schedule, err := h.service.CompleteSchedule(r.Context(), id, version, requestActor(r))
+	h.respondWithEdit(w, r, id, schedule, err, "Schedule completed")
+}
+
+// ReopenSchedule returns an approved or published schedule to draft for editing
+func (h *ScheduleHandler) ReopenSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		h.respondWithEditError(w, r, id, err)
+		return
+	}
+
+	schedule, err := h.service.ReopenSchedule(r.Context(), id, version, requestActor(r))
+	h.respondWithEdit(w, r, id, schedule, err, "Schedule reopened")
+}
+
+// ArchiveSchedule archives a completed schedule or an unused draft
+func (h *ScheduleHandler) ArchiveSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	version, err := expectedVersion(r)
+	if err != nil {
+		h.respondWithEditError(w, r, id, err)
+		return
+	}
+
+	schedule, err := h.service.ArchiveSchedule(r.Context(), id, version, requestActor(r))
+	h.respondWithEdit(w, r, id, schedule, err, "Schedule archived")
+}
+
+// generateSchedule generates a schedule for a period preset, for custom start and
+// end dates (YYYY-MM-DD, both included), or for the next two weeks without either.
+// An empty strategy uses the company's configured strategy.
+func generateSchedule(ctx context.Context, svc *service.ScheduleService, preset, startDate, endDate, strategy string) (*domain.Schedule, error) {
+	if preset == "" && startDate != "" {
+		preset = periodPresetCustom
+	}
+
+	switch preset {
+	case periodPresetCustom:
+		start, errStart := time.Parse("2006-01-02", startDate)
+		end, errEnd := time.Parse("2006-01-02", endDate)
+		if errStart != nil || errEnd != nil {
+			return nil, fmt.Errorf("%w: start and end dates must be YYYY-MM-DD", domain.ErrInvalidSchedulePeriod)
+		}
+		return svc.GenerateSchedule(ctx, start, end, strategy)
+	case "":
+		return svc.GenerateBiweeklySchedule(ctx, strategy)
+	default:
+		return svc.GeneratePresetSchedule(ctx, preset, strategy)
+	}
+}
+
+// requestActor identifies who made a request for the schedule history by the
+// email of the signed-in user
+func requestActor(r *http.Request) string {
+	if user := auth.User(r.Context()); user != nil {
+		return user.Email
+	}
+	return "anonymous"
+}
+
+// respondWithEdit renders the schedule card after an edit, or the unchanged card
+// with the error if the edit was rejected
+func (h *ScheduleHandler) respondWithEdit(w http.ResponseWriter, r *http.Request, id string, schedule *domain.Schedule, err error, message string) {
+	if err != nil {
+		h.respondWithEditError(w, r, id, err)
+		return
+	}
+
+	log.Info().
+		Str("schedule_id", id).
+		Int("assignments", len(schedule.Assignments)).
+		Int("warnings", len(schedule.Warnings)).
+		Msg(message)
+
+	if err := templates.ScheduleCard(*schedule).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render schedule card")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// respondWithEditError shows a rejected edit inline on the schedule card. HTMX does
+// not swap error responses, so rejections the manager can fix are sent as 200.
+func (h *ScheduleHandler) respondWithEditError(w http.ResponseWriter, r *http.Request, id string, editErr error) {
+	if status := errorStatus(editErr, http.StatusInternalServerError); status >= http.StatusInternalServerError {
+		handleInternalError(w, editErr, "edit schedule")
+		return
+	}
+
+	schedule, err := h.service.GetSchedule(r.Context(), id)
+	if err != nil {
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Warn().Err(editErr).Str("schedule_id", id).Msg("Schedule edit rejected")
+
+	if errors.Is(editErr, domain.ErrConflict) {
+		if err := templates.ScheduleCardWithConflict(*schedule, conflictRetry(r)).Render(r.Context(), w); err != nil {
+			log.Error().Err(err).Msg("Failed to render schedule card")
+			handleInternalError(w, err, "render template")
+		}
+		return
+	}
+
+	if err := templates.ScheduleCardWithError(*schedule, editErr.Error()).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render schedule card")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// conflictRetry describes a schedule edit that was rejected because the
+// schedule had changed, for sending it again on the latest version
+func conflictRetry(r *http.Request) templates.ConflictRetry {
+	values := make(map[string]string)
+	for key := range r.PostForm {
+		if key != "version" {
+			values[key] = r.PostForm.Get(key)
+		}
+	}
+	return templates.ConflictRetry{Method: r.Method, Path: r.URL.Path, Values: values}
+}
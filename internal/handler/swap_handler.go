@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/isak/restySched/internal/auth"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/service"
+	"github.com/isak/restySched/web/templates"
+	"github.com/rs/zerolog/log"
+)
+
+// SwapHandler serves the shift swap board where employees offer and claim
+// shifts and managers decide on claimed offers
+type SwapHandler struct {
+	service *service.SwapService
+}
+
+// NewSwapHandler creates a new swap handler
+func NewSwapHandler(service *service.SwapService) *SwapHandler {
+	return &SwapHandler{service: service}
+}
+
+// ShowBoard shows the open offers, with whether the signed-in employee can
+// claim them, the employee's own offers and claims, and to managers the
+// claimed offers waiting for a decision
+func (h *SwapHandler) ShowBoard(w http.ResponseWriter, r *http.Request) {
+	user := auth.User(r.Context())
+
+	offers, err := h.service.OpenOffers(r.Context(), user.EmployeeID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch shift offers")
+		handleInternalError(w, err, "fetch shift offers")
+		return
+	}
+
+	mine := []domain.ShiftSwap{}
+	if user.EmployeeID != "" {
+		if mine, err = h.service.EmployeeSwaps(r.Context(), user.EmployeeID); err != nil {
+			log.Error().Err(err).Msg("Failed to fetch employee's shift swaps")
+			handleInternalError(w, err, "fetch shift swaps")
+			return
+		}
+	}
+
+	var pending []domain.ShiftSwap
+	if user.CanManage() {
+		if pending, err = h.service.PendingSwaps(r.Context()); err != nil {
+			log.Error().Err(err).Msg("Failed to fetch pending shift swaps")
+			handleInternalError(w, err, "fetch shift swaps")
+			return
+		}
+	}
+
+	if err := templates.SwapBoard(*user, offers, mine, pending).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render swap board")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// OfferShift puts one of the signed-in employee's shifts on offer
+func (h *SwapHandler) OfferShift(w http.ResponseWriter, r *http.Request) {
+	employeeID := auth.User(r.Context()).EmployeeID
+	if employeeID == "" {
+		respondWithError(w, domain.ErrForbidden, http.StatusForbidden)
+		return
+	}
+
+	swap, err := h.service.OfferShift(r.Context(), employeeID,
+		r.FormValue("schedule_id"), r.FormValue("assignment_id"), r.FormValue("kind"), r.FormValue("note"))
+	if err != nil {
+		log.Warn().Err(err).Str("employee_id", employeeID).Msg("Failed to offer shift")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().
+		Str("id", swap.ID).
+		Str("employee_id", employeeID).
+		Str("kind", swap.Kind).
+		Msg("Shift offered")
+
+	respondWithSuccess(w, "Your shift is on the swap board.")
+}
+
+// ClaimShift claims an open offer for the signed-in employee, giving the
+// return_assignment_id shift in return for a swap
+func (h *SwapHandler) ClaimShift(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	employeeID := auth.User(r.Context()).EmployeeID
+	if employeeID == "" {
+		respondWithError(w, domain.ErrForbidden, http.StatusForbidden)
+		return
+	}
+
+	if _, err := h.service.ClaimShift(r.Context(), id, employeeID, r.FormValue("return_assignment_id")); err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to claim shift")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().Str("id", id).Str("employee_id", employeeID).Msg("Shift claimed")
+	w.Header().Set("HX-Redirect", "/swaps")
+	w.WriteHeader(http.StatusOK)
+}
+
+// CancelOffer withdraws one of the signed-in employee's offers
+func (h *SwapHandler) CancelOffer(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if _, err := h.service.CancelOffer(r.Context(), id, auth.User(r.Context()).EmployeeID); err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to cancel shift offer")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().Str("id", id).Msg("Shift offer cancelled")
+	w.Header().Set("HX-Redirect", "/swaps")
+	w.WriteHeader(http.StatusOK)
+}
+
+// ApproveSwap applies a claimed offer to its schedule, removing its row
+func (h *SwapHandler) ApproveSwap(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.ApproveSwap, "approved")
+}
+
+// RejectSwap turns down a claimed offer, removing its row
+func (h *SwapHandler) RejectSwap(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.RejectSwap, "rejected")
+}
+
+func (h *SwapHandler) decide(
+	w http.ResponseWriter,
+	r *http.Request,
+	decide func(ctx context.Context, id, actor string) (*domain.ShiftSwap, error),
+	outcome string,
+) {
+	id := r.PathValue("id")
+
+	if _, err := decide(r.Context(), id, requestActor(r)); err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to decide shift swap")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().Str("id", id).Str("actor", requestActor(r)).Msgf("Shift swap %s", outcome)
+	w.WriteHeader(http.StatusOK)
+}
+
+// ShowScheduleSwaps shows the history of offers made in a schedule
+func (h *SwapHandler) ShowScheduleSwaps(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	swaps, err := h.service.ScheduleHistory(r.Context(), id)
+	if err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to fetch schedule swaps")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := templates.SwapHistory(swaps).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render swap history")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// ShowNotifications shows the signed-in employee's recent notifications and
+// marks them as read
+func (h *SwapHandler) ShowNotifications(w http.ResponseWriter, r *http.Request) {
+	employeeID := auth.User(r.Context()).EmployeeID
+
+	notifications := []domain.Notification{}
+	if employeeID != "" {
+		var err error
+		if notifications, err = h.service.Notifications(r.Context(), employeeID); err != nil {
+			log.Error().Err(err).Str("employee_id", employeeID).Msg("Failed to fetch notifications")
+			handleInternalError(w, err, "fetch notifications")
+			return
+		}
+		if err := h.service.MarkNotificationsRead(r.Context(), employeeID); err != nil {
+			log.Warn().Err(err).Str("employee_id", employeeID).Msg("Failed to mark notifications read")
+		}
+	}
+
+	if err := templates.NotificationList(notifications).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render notifications")
+		handleInternalError(w, err, "render template")
+	}
+}
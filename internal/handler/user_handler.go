@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/isak/restySched/internal/auth"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/service"
+	"github.com/isak/restySched/web/templates"
+	"github.com/rs/zerolog/log"
+)
+
+// UserHandler serves the admin pages for user accounts and every user's own
+// account page
+type UserHandler struct {
+	service   *service.AuthService
+	employees *service.EmployeeService
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(service *service.AuthService, employees *service.EmployeeService) *UserHandler {
+	return &UserHandler{service: service, employees: employees}
+}
+
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.service.GetAllUsers(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch users")
+		handleInternalError(w, err, "fetch users")
+		return
+	}
+
+	employees, err := h.employees.GetAllEmployees(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch employees")
+		handleInternalError(w, err, "fetch employees")
+		return
+	}
+
+	if err := templates.UserList(users, employeeNames(employees)).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render user list")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+func (h *UserHandler) ShowNewForm(w http.ResponseWriter, r *http.Request) {
+	h.renderForm(w, r, domain.User{Role: domain.UserRoleEmployee}, false)
+}
+
+func (h *UserHandler) ShowEditForm(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	user, err := h.service.GetUser(r.Context(), id)
+	if err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("User not found")
+		respondWithError(w, err, http.StatusNotFound)
+		return
+	}
+
+	h.renderForm(w, r, *user, true)
+}
+
+// renderForm renders the user form, offering the active employees to link
+// employee accounts to
+func (h *UserHandler) renderForm(w http.ResponseWriter, r *http.Request, user domain.User, isEdit bool) {
+	employees, err := h.employees.GetActiveEmployees(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch employees")
+		handleInternalError(w, err, "fetch employees")
+		return
+	}
+
+	if err := templates.UserForm(user, employees, isEdit).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render user form")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.service.CreateUser(r.Context(), userInput(r))
+	if err != nil {
+		log.Warn().Err(err).Str("email", r.FormValue("email")).Msg("Failed to create user")
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	log.Info().
+		Str("id", user.ID).
+		Str("email", user.Email).
+		Str("role", user.Role).
+		Msg("User created successfully")
+
+	w.Header().Set("HX-Redirect", "/users")
+	w.WriteHeader(http.StatusOK)
+}
+
+// UpdateUser changes a user's details, and resets their password if the form has one
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	user, err := h.service.UpdateUser(r.Context(), id, userInput(r))
+	if err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to update user")
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	log.Info().
+		Str("id", user.ID).
+		Str("email", user.Email).
+		Str("role", user.Role).
+		Msg("User updated successfully")
+
+	w.Header().Set("HX-Redirect", "/users")
+	w.WriteHeader(http.StatusOK)
+}
+
+// DeactivateUser stops a user from signing in and shows their updated row
+func (h *UserHandler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if err := h.service.DeactivateUser(r.Context(), id); err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to deactivate user")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().Str("id", id).Msg("User deactivated")
+	h.renderRow(w, r, id)
+}
+
+// ActivateUser lets a deactivated user sign in again and shows their updated row
+func (h *UserHandler) ActivateUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if err := h.service.ReactivateUser(r.Context(), id); err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to reactivate user")
+		respondWithError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().Str("id", id).Msg("User reactivated")
+	h.renderRow(w, r, id)
+}
+
+func (h *UserHandler) renderRow(w http.ResponseWriter, r *http.Request, id string) {
+	user, err := h.service.GetUser(r.Context(), id)
+	if err != nil {
+		handleInternalError(w, err, "fetch user")
+		return
+	}
+
+	employees, err := h.employees.GetAllEmployees(r.Context())
+	if err != nil {
+		handleInternalError(w, err, "fetch employees")
+		return
+	}
+
+	if err := templates.UserRow(*user, employeeNames(employees)).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render user row")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// ShowAccount shows the signed-in user's account page
+func (h *UserHandler) ShowAccount(w http.ResponseWriter, r *http.Request) {
+	if err := templates.AccountPage(*auth.User(r.Context())).Render(r.Context(), w); err != nil {
+		log.Error().Err(err).Msg("Failed to render account page")
+		handleInternalError(w, err, "render template")
+	}
+}
+
+// ChangePassword changes the signed-in user's password after checking the current one
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	user := auth.User(r.Context())
+
+	if err := h.service.ChangePassword(r.Context(), user.ID, r.FormValue("current_password"), r.FormValue("new_password")); err != nil {
+		log.Warn().Err(err).Str("id", user.ID).Msg("Failed to change password")
+		respondWithError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	log.Info().Str("id", user.ID).Msg("Password changed")
+	respondWithSuccess(w, "Your password has been changed.")
+}
+
+// userInput reads the user form
+func userInput(r *http.Request) domain.UserInput {
+	return domain.UserInput{
+		Name:       r.FormValue("name"),
+		Email:      r.FormValue("email"),
+		Role:       r.FormValue("role"),
+		EmployeeID: r.FormValue("employee_id"),
+		Password:   r.FormValue("password"),
+	}
+}
+
+// employeeNames maps employee IDs to names
+func employeeNames(employees []domain.Employee) map[string]string {
+	names := make(map[string]string, len(employees))
+	for _, employee := range employees {
+		names[employee.ID] = employee.Name
+	}
+	return names
+}
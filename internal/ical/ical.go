@@ -0,0 +1,192 @@
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a calendar event. Events are identified by UID, so a calendar app that
+// subscribes to a feed replaces an event when one with the same UID changes.
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Start        time.Time
+	End          time.Time
+	LastModified time.Time
+	Sequence     int  // revision of the event; apps apply a change only if it is higher than what they have
+	Cancelled    bool // published as STATUS:CANCELLED so subscribers remove it
+}
+
+// Calendar is an iCalendar (RFC 5545) calendar whose event times are written in
+// Location, with a matching VTIMEZONE definition
+type Calendar struct {
+	Name     string
+	Location *time.Location
+	Stamp    time.Time // when the calendar was generated, written as DTSTAMP
+	Events   []Event
+}
+
+// WriteTo writes the calendar in iCalendar format
+func (c *Calendar) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+
+	loc := c.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	stamp := c.Stamp.UTC().Format("20060102T150405Z")
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:-//RestySched//Schedule Feed//EN")
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+	if loc != time.UTC {
+		writeLine(&b, "X-WR-TIMEZONE:"+loc.String())
+		from, to := c.span()
+		writeTimezone(&b, loc, from, to)
+	}
+
+	for _, event := range c.Events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+escapeText(event.UID))
+		writeLine(&b, "DTSTAMP:"+stamp)
+		writeLine(&b, "DTSTART"+formatTime(event.Start, loc))
+		writeLine(&b, "DTEND"+formatTime(event.End, loc))
+		writeLine(&b, "SEQUENCE:"+strconv.Itoa(event.Sequence))
+		writeLine(&b, "SUMMARY:"+escapeText(event.Summary))
+		if event.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		if !event.LastModified.IsZero() {
+			writeLine(&b, "LAST-MODIFIED:"+event.LastModified.UTC().Format("20060102T150405Z"))
+		}
+		if event.Cancelled {
+			writeLine(&b, "STATUS:CANCELLED")
+		} else {
+			writeLine(&b, "STATUS:CONFIRMED")
+		}
+		writeLine(&b, "END:VEVENT")
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// span returns the period the calendar's events cover, or the day of Stamp if
+// there are none
+func (c *Calendar) span() (time.Time, time.Time) {
+	from, to := c.Stamp, c.Stamp
+	for i, event := range c.Events {
+		if i == 0 || event.Start.Before(from) {
+			from = event.Start
+		}
+		if i == 0 || event.End.After(to) {
+			to = event.End
+		}
+	}
+	return from, to
+}
+
+// formatTime formats t as a DTSTART or DTEND value, including the parameter
+// separator: ";TZID=Europe/Oslo:20250106T090000", or ":20250106T090000Z" in UTC
+func formatTime(t time.Time, loc *time.Location) string {
+	if loc == time.UTC {
+		return ":" + t.UTC().Format("20060102T150405Z")
+	}
+	return ";TZID=" + loc.String() + ":" + t.In(loc).Format("20060102T150405")
+}
+
+// writeTimezone writes a VTIMEZONE for loc with one observance for the offset in
+// effect at from and one for every offset change up to to
+func writeTimezone(b *strings.Builder, loc *time.Location, from, to time.Time) {
+	writeLine(b, "BEGIN:VTIMEZONE")
+	writeLine(b, "TZID:"+loc.String())
+
+	// Start a day early so that the first observance covers every event
+	t := from.Add(-24 * time.Hour).Truncate(time.Hour)
+	_, offset := t.In(loc).Zone()
+	writeObservance(b, t.In(loc), offset)
+
+	for ; t.Before(to); t = t.Add(24 * time.Hour) {
+		next := t.Add(24 * time.Hour)
+		if _, nextOffset := next.In(loc).Zone(); nextOffset == offset {
+			continue
+		}
+
+		// Narrow the change down to the second
+		lo, hi := t, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, midOffset := mid.In(loc).Zone(); midOffset == offset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+
+		writeObservance(b, hi.In(loc), offset)
+		_, offset = hi.In(loc).Zone()
+	}
+
+	writeLine(b, "END:VTIMEZONE")
+}
+
+// writeObservance writes a STANDARD or DAYLIGHT observance starting at t, whose
+// local start time is given in the offset in effect before it
+func writeObservance(b *strings.Builder, t time.Time, offsetFrom int) {
+	name, offsetTo := t.Zone()
+	kind := "STANDARD"
+	if t.IsDST() {
+		kind = "DAYLIGHT"
+	}
+
+	writeLine(b, "BEGIN:"+kind)
+	writeLine(b, "DTSTART:"+t.UTC().Add(time.Duration(offsetFrom)*time.Second).Format("20060102T150405"))
+	writeLine(b, "TZOFFSETFROM:"+formatOffset(offsetFrom))
+	writeLine(b, "TZOFFSETTO:"+formatOffset(offsetTo))
+	writeLine(b, "TZNAME:"+escapeText(name))
+	writeLine(b, "END:"+kind)
+}
+
+// formatOffset formats a UTC offset in seconds as "+HHMM"
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// escapeText escapes a TEXT property value
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeLine writes a content line, folding it at 75 octets without splitting
+// UTF-8 sequences
+func writeLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
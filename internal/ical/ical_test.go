@@ -0,0 +1,103 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendar_WriteTo(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	calendar := &Calendar{
+		Name:     "Jane Smith - Café, Bar; Grill",
+		Location: oslo,
+		Stamp:    time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Events: []Event{
+			// Night shift across the switch to summer time
+			{UID: "a1@restysched", Summary: "Night shift", Start: time.Date(2025, 3, 29, 21, 0, 0, 0, oslo), End: time.Date(2025, 3, 30, 5, 0, 0, 0, oslo)},
+			{UID: "a2@restysched", Summary: "Morning shift", Start: time.Date(2025, 3, 31, 9, 0, 0, 0, oslo), End: time.Date(2025, 3, 31, 13, 0, 0, 0, oslo), Sequence: 4, Cancelled: true},
+		},
+	}
+
+	var b strings.Builder
+	if _, err := calendar.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:Jane Smith - Café\\, Bar\\; Grill\r\n",
+		"TZID:Europe/Oslo\r\n",
+		// The switch to summer time at 02:00 local, 01:00 UTC
+		"BEGIN:DAYLIGHT\r\nDTSTART:20250330T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\n",
+		"UID:a1@restysched\r\nDTSTAMP:20250301T120000Z\r\nDTSTART;TZID=Europe/Oslo:20250329T210000\r\nDTEND;TZID=Europe/Oslo:20250330T050000\r\n",
+		"UID:a2@restysched\r\n",
+		"SEQUENCE:4\r\n",
+		"STATUS:CANCELLED\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar does not contain %q:\n%s", want, out)
+		}
+	}
+
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+}
+
+func TestCalendar_WriteTo_UTC(t *testing.T) {
+	calendar := &Calendar{
+		Location: time.UTC,
+		Events: []Event{
+			{UID: "a1", Summary: "Morning shift", Start: time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC), End: time.Date(2025, 1, 6, 13, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	var b strings.Builder
+	if _, err := calendar.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	out := b.String()
+
+	if strings.Contains(out, "VTIMEZONE") {
+		t.Error("UTC calendar should not define a timezone")
+	}
+	if !strings.Contains(out, "DTSTART:20250106T090000Z\r\n") {
+		t.Errorf("calendar does not contain UTC start time:\n%s", out)
+	}
+}
+
+func TestWriteLine_Folding(t *testing.T) {
+	var b strings.Builder
+	line := "DESCRIPTION:" + strings.Repeat("æ", 100)
+	writeLine(&b, line)
+
+	parts := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(parts) < 2 {
+		t.Fatalf("long line was not folded: %q", b.String())
+	}
+
+	unfolded := parts[0]
+	for _, part := range parts[1:] {
+		if !strings.HasPrefix(part, " ") {
+			t.Errorf("continuation line %q does not start with a space", part)
+		}
+		unfolded += strings.TrimPrefix(part, " ")
+	}
+	for _, part := range parts {
+		if len(part) > 75 {
+			t.Errorf("folded line longer than 75 octets: %q", part)
+		}
+	}
+	if unfolded != line {
+		t.Errorf("unfolded line = %q, want %q", unfolded, line)
+	}
+}
@@ -0,0 +1,245 @@
+// Package openapi builds an OpenAPI 3.1 document from Go types and validates
+// request bodies against it.
+package openapi
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Version is the OpenAPI version of the documents built by this package
+const Version = "3.1.0"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+
+	types map[string]reflect.Type // Go type of each component schema
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag groups operations; the order of the document's tags is the order in
+// which documentation shows the groups
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of a path, keyed by lower-case HTTP method
+type PathItem map[string]*Operation
+
+// Operation is one method on one path
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"` // empty for public operations
+
+	// Roles are the user roles allowed to call the operation
+	Roles []string `json:"x-roles,omitempty"`
+	// OwnerParameter names the path parameter holding the ID of the employee
+	// the operation is about; that employee may call it whatever the roles
+	OwnerParameter string `json:"x-owner-parameter,omitempty"`
+}
+
+// SecurityRequirement names the security schemes an operation accepts, with
+// their scopes
+type SecurityRequirement map[string][]string
+
+// Parameter is a path, query or header parameter of an operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path, query or header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body an operation accepts, keyed by media type
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// Response describes one response of an operation, keyed by media type
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header describes a response header
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType holds the schema of a body in one media type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas and security schemes of a document
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how clients authenticate
+type SecurityScheme struct {
+	Type        string `json:"type"` // apiKey, http, ...
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Media types of request and response bodies
+const (
+	MediaTypeJSON      = "application/json"
+	MediaTypeForm      = "application/x-www-form-urlencoded"
+	MediaTypeMultipart = "multipart/form-data"
+	MediaTypeHTML      = "text/html"
+)
+
+// New creates an empty document
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      make(map[string]PathItem),
+		Components: Components{Schemas: make(map[string]*Schema)},
+		types:      make(map[string]reflect.Type),
+	}
+}
+
+// Add adds an operation for a method and path. Path parameters use the same
+// {name} syntax as net/http patterns.
+func (d *Document) Add(method, path string, op *Operation) {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		d.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+}
+
+// AddTag adds a tag to the document unless it already has one of the same name
+func (d *Document) AddTag(tag Tag) {
+	for _, t := range d.Tags {
+		if t.Name == tag.Name {
+			return
+		}
+	}
+	d.Tags = append(d.Tags, tag)
+}
+
+// Operation returns the operation for a method and path, or nil if the document
+// does not describe it
+func (d *Document) Operation(method, path string) *Operation {
+	return d.Paths[path][strings.ToLower(method)]
+}
+
+// Routes returns every operation as a net/http pattern such as
+// "GET /employees/{id}", sorted
+func (d *Document) Routes() []string {
+	var routes []string
+	for path, item := range d.Paths {
+		for method := range item {
+			routes = append(routes, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// Route is an operation with its method and path
+type Route struct {
+	Method    string // upper case
+	Path      string
+	Operation *Operation
+}
+
+// methodOrder is the order in which routes of the same path are listed
+var methodOrder = map[string]int{"GET": 0, "POST": 1, "PUT": 2, "PATCH": 3, "DELETE": 4}
+
+// TaggedRoutes returns the routes of the operations with a tag, sorted by path and method
+func (d *Document) TaggedRoutes(tag string) []Route {
+	var routes []Route
+	for path, item := range d.Paths {
+		for method, op := range item {
+			for _, t := range op.Tags {
+				if t == tag {
+					routes = append(routes, Route{Method: strings.ToUpper(method), Path: path, Operation: op})
+					break
+				}
+			}
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return methodOrder[routes[i].Method] < methodOrder[routes[j].Method]
+	})
+	return routes
+}
+
+// PathParameters returns the names of the {name} segments of a path, in order.
+// A trailing wildcard such as {path...} is returned without the dots.
+func PathParameters(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			name := strings.TrimSuffix(segment[1:len(segment)-1], "...")
+			if name != "$" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// PathValue returns the value of the path parameter name in a request path
+// matching path, or "" if the path has no such parameter or does not match
+func PathValue(path, requestPath, name string) string {
+	segments := strings.Split(path, "/")
+	values := strings.Split(requestPath, "/")
+	for i, segment := range segments {
+		if i >= len(values) {
+			return ""
+		}
+		if segment == "{"+name+"}" {
+			return values[i]
+		}
+		if segment == "{"+name+"...}" {
+			return strings.Join(values[i:], "/")
+		}
+	}
+	return ""
+}
+
+// SplitPattern splits a net/http pattern such as "GET /employees" into its
+// method and path. Patterns without a method match every method and return "".
+func SplitPattern(pattern string) (method, path string) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		return "", pattern
+	}
+	return method, strings.TrimSpace(path)
+}
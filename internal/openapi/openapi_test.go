@@ -0,0 +1,230 @@
+package openapi
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testBase struct {
+	ID string `json:"id"`
+}
+
+type testItem struct {
+	Name string `json:"name"`
+}
+
+type testInput struct {
+	testBase
+	Name     string            `json:"name"`
+	Count    int               `json:"count,omitempty"`
+	Ratio    float64           `json:"ratio"`
+	Active   bool              `json:"active"`
+	Day      string            `json:"day"`
+	At       *time.Time        `json:"at,omitempty"`
+	Items    []testItem        `json:"items"`
+	Labels   map[string]string `json:"labels"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+func TestSchema(t *testing.T) {
+	doc := New(Info{Title: "Test", Version: "1"})
+
+	ref := doc.Schema(testInput{})
+	if ref.Ref != "#/components/schemas/testInput" {
+		t.Fatalf("Schema() = %+v, want a reference to testInput", ref)
+	}
+
+	s := doc.Components.Schemas["testInput"]
+	var names []string
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	want := map[string]string{
+		"id": "string", "name": "string", "count": "integer", "ratio": "number",
+		"active": "boolean", "day": "string", "at": "string", "items": "array", "labels": "object",
+	}
+	if len(s.Properties) != len(want) {
+		t.Errorf("properties = %v, want %d properties", names, len(want))
+	}
+	for name, typ := range want {
+		if p := s.Property(name); p == nil || p.Type != typ {
+			t.Errorf("property %q = %+v, want type %s", name, p, typ)
+		}
+	}
+	if s.Property("at").Format != FormatDateTime {
+		t.Errorf("time property format = %q, want %q", s.Property("at").Format, FormatDateTime)
+	}
+	if s.Property("items").Items.Ref != "#/components/schemas/testItem" {
+		t.Errorf("items = %+v, want a reference to testItem", s.Property("items").Items)
+	}
+	if s.AdditionalProperties != false {
+		t.Errorf("additionalProperties = %v, want false", s.AdditionalProperties)
+	}
+
+	if doc.Component(testInput{}) != s {
+		t.Error("Component() did not return the registered schema")
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	doc := New(Info{Title: "Test", Version: "1"})
+	input := doc.Component(testInput{}).Require("name", "items")
+	input.Property("day").Format = FormatDate
+	input.Property("count").Between(1, 10)
+	input.Property("name").OneOf("a", "b")
+	doc.Component(testItem{}).Require("name")
+
+	tests := []struct {
+		name      string
+		body      string
+		wantErr   bool
+		wantField string
+	}{
+		{"valid", `{"name":"a","count":3,"ratio":0.5,"active":true,"day":"2025-01-06","items":[{"name":"x"}],"labels":{"k":"v"}}`, false, ""},
+		{"null and empty optional fields", `{"name":"a","items":[],"day":"","at":null}`, false, ""},
+		{"missing required field", `{"name":"a"}`, true, "items"},
+		{"null required field", `{"name":"a","items":null}`, true, "items"},
+		{"unknown field", `{"name":"a","items":[],"extra":1}`, true, "extra"},
+		{"wrong type", `{"name":"a","items":[],"active":"yes"}`, true, "active"},
+		{"fractional integer", `{"name":"a","items":[],"count":1.5}`, true, "count"},
+		{"below minimum", `{"name":"a","items":[],"count":0}`, true, "count"},
+		{"not in enum", `{"name":"c","items":[]}`, true, "name"},
+		{"invalid date", `{"name":"a","items":[],"day":"06.01.2025"}`, true, "day"},
+		{"invalid date-time", `{"name":"a","items":[],"at":"tomorrow"}`, true, "at"},
+		{"nested item", `{"name":"a","items":[{"name":"x"},{}]}`, true, "items[1].name"},
+		{"map value", `{"name":"a","items":[],"labels":{"k":1}}`, true, "labels.k"},
+		{"not an object", `[]`, true, ""},
+		{"not JSON", `{"name":`, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := doc.ValidateJSON(doc.Schema(testInput{}), []byte(tt.body))
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("ValidateJSON() error = %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) || !errors.Is(err, ErrInvalidBody) {
+				t.Fatalf("ValidateJSON() error = %v, want a ValidationError", err)
+			}
+			if verr.Field != tt.wantField {
+				t.Errorf("field = %q, want %q (%v)", verr.Field, tt.wantField, err)
+			}
+		})
+	}
+}
+
+func TestValidateForm(t *testing.T) {
+	doc := New(Info{Title: "Test", Version: "1"})
+	form := Object(map[string]*Schema{
+		"name":    String(""),
+		"hours":   Integer(""),
+		"day":     Date(""),
+		"active":  Boolean(""),
+		"days":    Array(Integer("").Between(0, 6)),
+		"kind":    String("").OneOf("a", "b"),
+		"missing": String(""),
+	}, "name", "hours")
+	form.PatternProperties = map[string]*Schema{`^min_\d+$`: Integer("")}
+
+	tests := []struct {
+		name      string
+		values    url.Values
+		wantField string // empty for valid values
+	}{
+		{"valid", url.Values{"name": {"x"}, "hours": {"160"}, "day": {"2025-01-06"}, "active": {"on"}, "days": {"1", "5"}, "min_0": {"2"}}, ""},
+		{"blank optional fields", url.Values{"name": {"x"}, "hours": {"1"}, "day": {""}, "kind": {""}}, ""},
+		{"unknown fields are ignored", url.Values{"name": {"x"}, "hours": {"1"}, "other": {"?"}}, ""},
+		{"blank required field", url.Values{"name": {""}, "hours": {"1"}}, "name"},
+		{"missing required field", url.Values{"name": {"x"}}, "hours"},
+		{"not an integer", url.Values{"name": {"x"}, "hours": {"many"}}, "hours"},
+		{"not a boolean", url.Values{"name": {"x"}, "hours": {"1"}, "active": {"maybe"}}, "active"},
+		{"array item out of range", url.Values{"name": {"x"}, "hours": {"1"}, "days": {"1", "7"}}, "days[1]"},
+		{"pattern property", url.Values{"name": {"x"}, "hours": {"1"}, "min_3": {"two"}}, "min_3"},
+		{"not in enum", url.Values{"name": {"x"}, "hours": {"1"}, "kind": {"c"}}, "kind"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := doc.ValidateForm(form, tt.values)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("ValidateForm() error = %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("ValidateForm() error = %v, want a ValidationError", err)
+			}
+			if verr.Field != tt.wantField {
+				t.Errorf("field = %q, want %q (%v)", verr.Field, tt.wantField, err)
+			}
+		})
+	}
+}
+
+func TestPathParameters(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/employees", nil},
+		{"/employees/{id}/availability/{index}", []string{"id", "index"}},
+		{"/files/{path...}", []string{"path"}},
+		{"/{$}", nil},
+	}
+
+	for _, tt := range tests {
+		if got := PathParameters(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PathParameters(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPathValue(t *testing.T) {
+	tests := []struct {
+		path, requestPath, name, want string
+	}{
+		{"/employees/{id}/availability/{index}", "/employees/e1/availability/2", "id", "e1"},
+		{"/employees/{id}/availability/{index}", "/employees/e1/availability/2", "index", "2"},
+		{"/employees/{id}", "/employees/e1", "token", ""},
+		{"/files/{path...}", "/files/a/b.txt", "path", "a/b.txt"},
+		{"/employees/{id}/edit", "/employees", "id", ""},
+	}
+
+	for _, tt := range tests {
+		if got := PathValue(tt.path, tt.requestPath, tt.name); got != tt.want {
+			t.Errorf("PathValue(%q, %q, %q) = %q, want %q", tt.path, tt.requestPath, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	doc := New(Info{Title: "Test", Version: "1"})
+	doc.Add("POST", "/b", &Operation{Tags: []string{"x"}})
+	doc.Add("GET", "/b", &Operation{Tags: []string{"x"}})
+	doc.Add("DELETE", "/a", &Operation{Tags: []string{"y"}})
+
+	want := []string{"DELETE /a", "GET /b", "POST /b"}
+	if got := doc.Routes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Routes() = %v, want %v", got, want)
+	}
+
+	tagged := doc.TaggedRoutes("x")
+	if len(tagged) != 2 || tagged[0].Method != "GET" || tagged[1].Method != "POST" {
+		t.Errorf("TaggedRoutes() = %+v, want GET /b then POST /b", tagged)
+	}
+	if doc.Operation("get", "/b") == nil || doc.Operation("GET", "/a") != nil {
+		t.Error("Operation() did not look up operations by method and path")
+	}
+}
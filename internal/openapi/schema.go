@@ -0,0 +1,213 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of JSON Schema used to describe request and response bodies
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	PatternProperties    map[string]*Schema `json:"patternProperties,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"` // false or a *Schema
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+}
+
+// refPrefix is the prefix of references to component schemas
+const refPrefix = "#/components/schemas/"
+
+// Schema formats checked by the validator
+const (
+	FormatDate     = "date"      // YYYY-MM-DD
+	FormatDateTime = "date-time" // RFC 3339
+	FormatBinary   = "binary"    // an uploaded file
+)
+
+// Object returns an object schema with the given properties
+func Object(properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: "object", Properties: properties, Required: required}
+}
+
+// Array returns an array schema of items
+func Array(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+// String returns a string schema with a description
+func String(description string) *Schema {
+	return &Schema{Type: "string", Description: description}
+}
+
+// Integer returns an integer schema with a description
+func Integer(description string) *Schema {
+	return &Schema{Type: "integer", Description: description}
+}
+
+// Boolean returns a boolean schema with a description
+func Boolean(description string) *Schema {
+	return &Schema{Type: "boolean", Description: description}
+}
+
+// Date returns a YYYY-MM-DD string schema with a description
+func Date(description string) *Schema {
+	return &Schema{Type: "string", Format: FormatDate, Description: description}
+}
+
+// OneOf restricts the schema to the given values
+func (s *Schema) OneOf(values ...string) *Schema {
+	s.Enum = make([]any, len(values))
+	for i, v := range values {
+		s.Enum[i] = v
+	}
+	return s
+}
+
+// Between restricts a numeric schema to the range [min, max]
+func (s *Schema) Between(min, max float64) *Schema {
+	s.Minimum = &min
+	s.Maximum = &max
+	return s
+}
+
+// AtLeast restricts a numeric schema to values of min or more
+func (s *Schema) AtLeast(min float64) *Schema {
+	s.Minimum = &min
+	return s
+}
+
+// Require marks properties of an object schema as required
+func (s *Schema) Require(names ...string) *Schema {
+	s.Required = append(s.Required, names...)
+	return s
+}
+
+// Property returns the schema of an object's property, or nil if it has none
+func (s *Schema) Property(name string) *Schema {
+	return s.Properties[name]
+}
+
+// Schema returns the schema of the Go type of v. Named struct types are added to
+// the document's components and referenced; other types are described inline.
+// Struct fields follow their json tags, and structs reject unknown properties
+// like the API's JSON decoder does.
+func (d *Document) Schema(v any) *Schema {
+	return d.schemaOf(reflect.TypeOf(v))
+}
+
+// Component returns the component schema of the named struct type of v, adding
+// it to the document if needed, so that it can be refined with enums, required
+// properties and descriptions
+func (d *Document) Component(v any) *Schema {
+	ref := d.Schema(v).Ref
+	if ref == "" {
+		panic(fmt.Sprintf("openapi: %T is not a named struct type", v))
+	}
+	return d.Components.Schemas[strings.TrimPrefix(ref, refPrefix)]
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (d *Document) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: FormatDateTime}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		return &Schema{Ref: refPrefix + d.addComponent(t)}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return Array(d.schemaOf(t.Elem()))
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return d.structSchema(t)
+	default:
+		return &Schema{} // any value
+	}
+}
+
+// addComponent adds the schema of a named struct type to the components and
+// returns its name. Types of different packages with the same name are told
+// apart by their package name.
+func (d *Document) addComponent(t reflect.Type) string {
+	name := t.Name()
+	if existing, ok := d.types[name]; ok && existing != t {
+		name = packageName(t) + name
+	}
+	if _, ok := d.types[name]; ok {
+		return name
+	}
+
+	// Register the name before describing the fields so that recursive types
+	// refer to themselves
+	d.types[name] = t
+	d.Components.Schemas[name] = &Schema{}
+	*d.Components.Schemas[name] = *d.structSchema(t)
+	return name
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+	d.addFields(s, t)
+	return s
+}
+
+// addFields adds the JSON properties of a struct's fields, flattening embedded structs
+func (d *Document) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			d.addFields(s, field.Type)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = d.schemaOf(field.Type)
+	}
+}
+
+func packageName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	if pkg == "" {
+		return ""
+	}
+	return strings.ToUpper(pkg[:1]) + pkg[1:]
+}
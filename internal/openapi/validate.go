@@ -0,0 +1,282 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidBody is wrapped by every ValidationError
+var ErrInvalidBody = errors.New("request body does not match the API schema")
+
+// ValidationError describes the first part of a request body that does not match its schema
+type ValidationError struct {
+	Field   string // path of the offending value, e.g. skills[0].name; empty for the whole body
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return ErrInvalidBody.Error() + ": " + e.Message
+	}
+	return ErrInvalidBody.Error() + ": " + e.Field + " " + e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidBody
+}
+
+// ValidateJSON checks a JSON body against a schema. Null and empty strings are
+// accepted for any property that is not required, as the handlers treat them
+// like missing fields.
+func (d *Document) ValidateJSON(s *Schema, body []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return &ValidationError{Message: "is not valid JSON"}
+	}
+	return d.validate(s, v, "", false)
+}
+
+// ValidateForm checks URL-encoded form values against an object schema. Fields
+// are strings, or lists of strings where the schema expects an array, and are
+// parsed as the numbers and booleans the schema asks for. Blank fields count as
+// missing, and fields the schema does not describe are ignored, since browsers
+// send every input of a form.
+func (d *Document) ValidateForm(s *Schema, values url.Values) error {
+	s = d.resolve(s)
+	form := make(map[string]any, len(values))
+	for key, list := range values {
+		property := d.resolve(d.propertySchema(s, key))
+		if property != nil && property.Type == "array" {
+			items := make([]any, len(list))
+			for i, item := range list {
+				items[i] = item
+			}
+			form[key] = items
+		} else if len(list) > 0 {
+			form[key] = list[0]
+		}
+	}
+	return d.validate(s, form, "", true)
+}
+
+// resolve follows a reference to a component schema
+func (d *Document) resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, refPrefix)]
+	}
+	return s
+}
+
+// propertySchema returns the schema of an object's property, matching
+// pattern properties if no property has the name
+func (d *Document) propertySchema(s *Schema, name string) *Schema {
+	if s == nil {
+		return nil
+	}
+	if property, ok := s.Properties[name]; ok {
+		return property
+	}
+	for _, pattern := range sortedKeys(s.PatternProperties) {
+		if matched, err := regexp.MatchString(pattern, name); err == nil && matched {
+			return s.PatternProperties[pattern]
+		}
+	}
+	if additional, ok := s.AdditionalProperties.(*Schema); ok {
+		return additional
+	}
+	return nil
+}
+
+var datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+func (d *Document) validate(s *Schema, v any, field string, form bool) error {
+	s = d.resolve(s)
+	if s == nil {
+		return nil
+	}
+	if v == nil {
+		if s.Type == "" {
+			return nil
+		}
+		return &ValidationError{Field: field, Message: "must be " + article(s.Type)}
+	}
+	switch s.Type {
+	case "object":
+		object, ok := v.(map[string]any)
+		if !ok {
+			return &ValidationError{Field: field, Message: "must be an object"}
+		}
+		return d.validateObject(s, object, field, form)
+
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return &ValidationError{Field: field, Message: "must be an array"}
+		}
+		for i, item := range items {
+			if err := d.validate(s.Items, item, fmt.Sprintf("%s[%d]", field, i), form); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "string":
+		text, ok := v.(string)
+		if !ok {
+			return &ValidationError{Field: field, Message: "must be a string"}
+		}
+		return validateString(s, text, field)
+
+	case "integer", "number":
+		n, ok := number(v, s.Type == "integer", form)
+		if !ok {
+			return &ValidationError{Field: field, Message: "must be " + article(s.Type)}
+		}
+		if err := validateEnum(s, strconv.FormatFloat(n, 'f', -1, 64), field); err != nil {
+			return err
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			return &ValidationError{Field: field, Message: fmt.Sprintf("must be at least %v", *s.Minimum)}
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return &ValidationError{Field: field, Message: fmt.Sprintf("must be at most %v", *s.Maximum)}
+		}
+		return nil
+
+	case "boolean":
+		if _, ok := v.(bool); ok {
+			return nil
+		}
+		if text, ok := v.(string); ok && form {
+			switch text {
+			case "true", "false", "on", "1", "0":
+				return nil
+			}
+		}
+		return &ValidationError{Field: field, Message: "must be a boolean"}
+	}
+	return nil
+}
+
+func (d *Document) validateObject(s *Schema, object map[string]any, field string, form bool) error {
+	for _, name := range s.Required {
+		value, ok := object[name]
+		if !ok || value == nil || (form && value == "") {
+			return &ValidationError{Field: join(field, name), Message: "is required"}
+		}
+	}
+
+	for _, name := range sortedKeys(object) {
+		property := d.propertySchema(s, name)
+		if property == nil {
+			if s.AdditionalProperties == false {
+				return &ValidationError{Field: join(field, name), Message: "is not a known field"}
+			}
+			continue
+		}
+		if value := object[name]; value == nil || value == "" {
+			continue
+		}
+		if err := d.validate(property, object[name], join(field, name), form); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateString(s *Schema, text, field string) error {
+	if err := validateEnum(s, text, field); err != nil {
+		return err
+	}
+	if s.MaxLength != nil && len([]rune(text)) > *s.MaxLength {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("must be at most %d characters", *s.MaxLength)}
+	}
+	if s.Pattern != "" {
+		if matched, err := regexp.MatchString(s.Pattern, text); err == nil && !matched {
+			return &ValidationError{Field: field, Message: "does not match " + s.Pattern}
+		}
+	}
+
+	switch s.Format {
+	case FormatDate:
+		if _, err := time.Parse("2006-01-02", text); err != nil || !datePattern.MatchString(text) {
+			return &ValidationError{Field: field, Message: "must be a date in the format YYYY-MM-DD"}
+		}
+	case FormatDateTime:
+		if _, err := time.Parse(time.RFC3339, text); err != nil {
+			return &ValidationError{Field: field, Message: "must be an RFC 3339 date and time"}
+		}
+	}
+	return nil
+}
+
+func validateEnum(s *Schema, value, field string) error {
+	if len(s.Enum) == 0 {
+		return nil
+	}
+	allowed := make([]string, len(s.Enum))
+	for i, e := range s.Enum {
+		allowed[i] = fmt.Sprint(e)
+		if allowed[i] == value {
+			return nil
+		}
+	}
+	return &ValidationError{Field: field, Message: "must be one of " + strings.Join(allowed, ", ")}
+}
+
+// number returns the value of a JSON number, or of a form field holding one
+func number(v any, integer, form bool) (float64, bool) {
+	var text string
+	switch n := v.(type) {
+	case json.Number:
+		text = n.String()
+	case string:
+		if !form {
+			return 0, false
+		}
+		text = strings.TrimSpace(n)
+	default:
+		return 0, false
+	}
+
+	if integer {
+		i, err := strconv.ParseInt(text, 10, 64)
+		return float64(i), err == nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	return f, err == nil
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+func article(typ string) string {
+	switch typ {
+	case "array", "object", "integer":
+		return "an " + typ
+	}
+	return "a " + typ
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
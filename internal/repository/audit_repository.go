@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// AuditRepository defines the interface for the audit trail. Entries are
+// never changed once appended; they are only deleted after the retention.
+type AuditRepository interface {
+	// Append stores a new audit entry
+	Append(ctx context.Context, entry *domain.AuditEntry) error
+
+	// List retrieves the entries the filter selects, newest first
+	List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+
+	// DeleteBefore deletes the entries recorded before cutoff and returns how many were deleted
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int, error)
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// AvailabilityRepository defines the interface for availability period data
+// operations. Periods are stored as records of their own, keyed by ID.
+type AvailabilityRepository interface {
+	// Create creates a new availability period
+	Create(ctx context.Context, availability *domain.Availability) error
+
+	// GetByID retrieves an availability period by ID
+	GetByID(ctx context.Context, id string) (*domain.Availability, error)
+
+	// GetByEmployee retrieves an employee's availability periods, earliest start first
+	GetByEmployee(ctx context.Context, employeeID string) ([]domain.Availability, error)
+
+	// GetByStatus retrieves the availability periods with any of the given statuses, earliest start first
+	GetByStatus(ctx context.Context, statuses ...string) ([]domain.Availability, error)
+
+	// GetByPeriod retrieves every employee's availability periods that apply to
+	// any day from start to end, earliest start first
+	GetByPeriod(ctx context.Context, start, end time.Time) ([]domain.Availability, error)
+
+	// Update updates an existing availability period
+	Update(ctx context.Context, availability *domain.Availability) error
+
+	// Delete removes an availability period
+	Delete(ctx context.Context, id string) error
+}
@@ -14,7 +14,9 @@ type CompanyConfigRepository interface {
 	// Create creates a new company configuration
 	Create(ctx context.Context, config *domain.CompanyConfig) error
 
-	// Update updates the company configuration
+	// Update updates the company configuration if it is still at
+	// config.Version, and increments the version; it returns
+	// domain.ErrConflict otherwise. A missing configuration is created.
 	Update(ctx context.Context, config *domain.CompanyConfig) error
 
 	// GetOrCreate retrieves the config or creates a default one
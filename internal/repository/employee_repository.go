@@ -20,12 +20,19 @@ type EmployeeRepository interface {
 	// GetActive retrieves all active employees
 	GetActive(ctx context.Context) ([]domain.Employee, error)
 
-	// Update updates an existing employee
+	// Update updates an existing employee if it is still at employee.Version,
+	// and increments the version. It returns domain.ErrConflict otherwise.
 	Update(ctx context.Context, employee *domain.Employee) error
 
-	// Delete soft deletes an employee (sets active to false)
+	// Delete soft deletes an employee (sets active to false), incrementing its version
 	Delete(ctx context.Context, id string) error
 
 	// GetByEmail retrieves an employee by email
 	GetByEmail(ctx context.Context, email string) (*domain.Employee, error)
+
+	// GetByCalendarToken retrieves an employee by calendar feed token
+	GetByCalendarToken(ctx context.Context, token string) (*domain.Employee, error)
+
+	// SetCalendarToken replaces an employee's calendar feed token
+	SetCalendarToken(ctx context.Context, id, token string) error
 }
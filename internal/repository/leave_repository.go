@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// LeaveRepository defines the interface for leave request data operations
+type LeaveRepository interface {
+	// Create creates a new leave request
+	Create(ctx context.Context, request *domain.LeaveRequest) error
+
+	// GetByID retrieves a leave request by ID
+	GetByID(ctx context.Context, id string) (*domain.LeaveRequest, error)
+
+	// GetByEmployee retrieves an employee's leave requests, latest start first
+	GetByEmployee(ctx context.Context, employeeID string) ([]domain.LeaveRequest, error)
+
+	// GetByStatus retrieves the leave requests with any of the given statuses, earliest start first
+	GetByStatus(ctx context.Context, statuses ...string) ([]domain.LeaveRequest, error)
+
+	// Update updates an existing leave request
+	Update(ctx context.Context, request *domain.LeaveRequest) error
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type auditRepository struct {
+	db *DB
+}
+
+// NewAuditRepository creates a new in-memory audit repository
+func NewAuditRepository(db *DB) repository.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	r.db.auditEntries = append(r.db.auditEntries, clone(*entry))
+	return nil
+}
+
+func (r *auditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	entries := []domain.AuditEntry{}
+	for _, entry := range r.db.auditEntries {
+		if filter.Matches(entry) {
+			entries = append(entries, clone(entry))
+		}
+	}
+
+	slices.SortStableFunc(entries, func(a, b domain.AuditEntry) int {
+		return b.Timestamp.Compare(a.Timestamp)
+	})
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+func (r *auditRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	n := len(r.db.auditEntries)
+	r.db.auditEntries = slices.DeleteFunc(r.db.auditEntries, func(entry domain.AuditEntry) bool {
+		return entry.Timestamp.Before(cutoff)
+	})
+	return n - len(r.db.auditEntries), nil
+}
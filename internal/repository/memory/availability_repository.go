@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type availabilityRepository struct {
+	db *DB
+}
+
+// NewAvailabilityRepository creates a new in-memory availability repository
+func NewAvailabilityRepository(db *DB) repository.AvailabilityRepository {
+	return &availabilityRepository{db: db}
+}
+
+func (r *availabilityRepository) Create(ctx context.Context, availability *domain.Availability) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if availability.ID == "" {
+		availability.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	availability.CreatedAt = now
+	availability.UpdatedAt = now
+
+	r.db.availability = append(r.db.availability, clone(*availability))
+	return nil
+}
+
+func (r *availabilityRepository) GetByID(ctx context.Context, id string) (*domain.Availability, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return nil, domain.ErrAvailabilityNotFound
+	}
+
+	availability := clone(r.db.availability[i])
+	return &availability, nil
+}
+
+func (r *availabilityRepository) GetByEmployee(ctx context.Context, employeeID string) ([]domain.Availability, error) {
+	return r.find(func(avail domain.Availability) bool { return avail.EmployeeID == employeeID }), nil
+}
+
+func (r *availabilityRepository) GetByStatus(ctx context.Context, statuses ...string) ([]domain.Availability, error) {
+	return r.find(func(avail domain.Availability) bool { return slices.Contains(statuses, avail.Status) }), nil
+}
+
+func (r *availabilityRepository) GetByPeriod(ctx context.Context, start, end time.Time) ([]domain.Availability, error) {
+	return r.find(func(avail domain.Availability) bool { return avail.OverlapsPeriod(start, end) }), nil
+}
+
+func (r *availabilityRepository) find(match func(domain.Availability) bool) []domain.Availability {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return findAvailability(r.db, match)
+}
+
+func (r *availabilityRepository) Update(ctx context.Context, availability *domain.Availability) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := r.indexOf(availability.ID)
+	if i < 0 {
+		return domain.ErrAvailabilityNotFound
+	}
+
+	availability.UpdatedAt = time.Now()
+
+	record := &r.db.availability[i]
+	record.StartDate = availability.StartDate
+	record.EndDate = availability.EndDate
+	record.Type = availability.Type
+	record.Reason = availability.Reason
+	record.ShiftTypes = clone(availability.ShiftTypes)
+	record.Status = availability.Status
+	record.Recurrence = clone(availability.Recurrence)
+	record.StartTime = availability.StartTime
+	record.EndTime = availability.EndTime
+	record.UpdatedAt = availability.UpdatedAt
+	return nil
+}
+
+func (r *availabilityRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return domain.ErrAvailabilityNotFound
+	}
+
+	r.db.availability = slices.Delete(r.db.availability, i, i+1)
+	return nil
+}
+
+func (r *availabilityRepository) indexOf(id string) int {
+	return slices.IndexFunc(r.db.availability, func(avail domain.Availability) bool { return avail.ID == id })
+}
+
+// findAvailability returns copies of the matching availability periods,
+// earliest start first. The caller holds the lock.
+func findAvailability(db *DB, match func(domain.Availability) bool) []domain.Availability {
+	var availability []domain.Availability
+	for _, avail := range db.availability {
+		if match(avail) {
+			availability = append(availability, clone(avail))
+		}
+	}
+
+	slices.SortStableFunc(availability, func(a, b domain.Availability) int {
+		if c := a.StartDate.Compare(b.StartDate); c != 0 {
+			return c
+		}
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return availability
+}
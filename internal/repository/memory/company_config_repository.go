@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type companyConfigRepository struct {
+	db *DB
+}
+
+// NewCompanyConfigRepository creates a new in-memory company configuration repository
+func NewCompanyConfigRepository(db *DB) repository.CompanyConfigRepository {
+	return &companyConfigRepository{db: db}
+}
+
+// Get retrieves the company configuration
+func (r *companyConfigRepository) Get(ctx context.Context) (*domain.CompanyConfig, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	if r.db.companyConfig == nil {
+		return nil, domain.ErrCompanyConfigNotFound
+	}
+	return clone(r.db.companyConfig), nil
+}
+
+// Create creates a new company configuration
+func (r *companyConfigRepository) Create(ctx context.Context, config *domain.CompanyConfig) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.create(config)
+}
+
+// Update updates the company configuration (upsert if doesn't exist)
+func (r *companyConfigRepository) Update(ctx context.Context, config *domain.CompanyConfig) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing := r.db.companyConfig
+	if existing == nil {
+		return r.create(config)
+	}
+
+	if existing.Version != config.Version {
+		return domain.ErrConflict
+	}
+
+	config.ID = existing.ID
+	config.CreatedAt = existing.CreatedAt
+	config.UpdatedAt = time.Now()
+	config.Version++
+
+	r.db.companyConfig = clone(config)
+	return nil
+}
+
+// GetOrCreate retrieves the config or creates a default one if it doesn't exist
+func (r *companyConfigRepository) GetOrCreate(ctx context.Context) (*domain.CompanyConfig, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.db.companyConfig != nil {
+		return clone(r.db.companyConfig), nil
+	}
+
+	config := domain.DefaultCompanyConfig()
+	if err := r.create(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// create stores config as the only company configuration. The caller holds the lock.
+func (r *companyConfigRepository) create(config *domain.CompanyConfig) error {
+	if r.db.companyConfig != nil {
+		return domain.ErrCompanyConfigAlreadyExists
+	}
+
+	config.ID = uuid.New().String()
+	config.CreatedAt = time.Now()
+	config.UpdatedAt = config.CreatedAt
+	config.Version = 1
+
+	r.db.companyConfig = clone(config)
+	return nil
+}
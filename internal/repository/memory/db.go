@@ -0,0 +1,106 @@
+// Package memory implements the repositories in process memory. Data is lost
+// when the process exits, so it is meant for development and tests that
+// should not need a MongoDB server.
+package memory
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+// DB holds the records of every repository. Repositories created from the
+// same DB share its data, like collections of one MongoDB database.
+type DB struct {
+	mu sync.RWMutex
+
+	employees     []domain.Employee
+	availability  []domain.Availability
+	schedules     []domain.Schedule
+	companyConfig *domain.CompanyConfig
+	users         []domain.User
+	sessions      []domain.Session
+	shiftSwaps    []domain.ShiftSwap
+	notifications []domain.Notification
+	leaveRequests []domain.LeaveRequest
+	auditEntries  []domain.AuditEntry
+}
+
+// NewDB creates an empty in-memory database
+func NewDB() *DB {
+	return &DB{}
+}
+
+// clone returns a deep copy of v, so that records handed to callers and
+// records kept in the database never share slices, maps or pointers
+func clone[T any](v T) T {
+	var copied T
+	deepCopy(reflect.ValueOf(&copied).Elem(), reflect.ValueOf(v))
+	return copied
+}
+
+func deepCopy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		deepCopy(dst.Elem(), src.Elem())
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		iter := src.MapRange()
+		for iter.Next() {
+			value := reflect.New(src.Type().Elem()).Elem()
+			deepCopy(value, iter.Value())
+			dst.SetMapIndex(iter.Key(), value)
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Struct:
+		// Copy the whole struct first so that unexported fields, such as
+		// those of time.Time, carry over, then replace the exported ones
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).IsExported() {
+				deepCopy(dst.Field(i), src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// NewRepositories creates every repository on db
+func NewRepositories(db *DB) *repository.Repositories {
+	return &repository.Repositories{
+		Employees:     NewEmployeeRepository(db),
+		Availability:  NewAvailabilityRepository(db),
+		Schedules:     NewScheduleRepository(db),
+		CompanyConfig: NewCompanyConfigRepository(db),
+		Users:         NewUserRepository(db),
+		Sessions:      NewSessionRepository(db),
+		ShiftSwaps:    NewShiftSwapRepository(db),
+		Notifications: NewNotificationRepository(db),
+		LeaveRequests: NewLeaveRepository(db),
+		Audit:         NewAuditRepository(db),
+	}
+}
@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type employeeRepository struct {
+	db *DB
+}
+
+// NewEmployeeRepository creates a new in-memory employee repository. Employees
+// are loaded with their availability from the availability repository of db.
+func NewEmployeeRepository(db *DB) repository.EmployeeRepository {
+	return &employeeRepository{db: db}
+}
+
+func (r *employeeRepository) Create(ctx context.Context, employee *domain.Employee) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.indexOfEmail(employee.Email, "") >= 0 {
+		return domain.ErrEmployeeAlreadyExists
+	}
+
+	if employee.ID == "" {
+		employee.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	employee.CreatedAt = now
+	employee.UpdatedAt = now
+	employee.Active = true
+	employee.Version = 1
+
+	// Availability is stored by the availability repository
+	record := clone(*employee)
+	record.Availability = nil
+
+	r.db.employees = append(r.db.employees, record)
+	return nil
+}
+
+func (r *employeeRepository) GetByID(ctx context.Context, id string) (*domain.Employee, error) {
+	return r.findOne(func(employee domain.Employee) bool { return employee.ID == id })
+}
+
+func (r *employeeRepository) GetAll(ctx context.Context) ([]domain.Employee, error) {
+	return r.find(func(domain.Employee) bool { return true }), nil
+}
+
+func (r *employeeRepository) GetActive(ctx context.Context) ([]domain.Employee, error) {
+	return r.find(func(employee domain.Employee) bool { return employee.Active }), nil
+}
+
+func (r *employeeRepository) Update(ctx context.Context, employee *domain.Employee) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := r.indexOf(employee.ID)
+	if i < 0 {
+		return domain.ErrEmployeeNotFound
+	}
+	if r.db.employees[i].Version != employee.Version {
+		return domain.ErrConflict
+	}
+	if r.indexOfEmail(employee.Email, employee.ID) >= 0 {
+		return domain.ErrEmployeeAlreadyExists
+	}
+
+	employee.UpdatedAt = time.Now()
+	employee.Version++
+
+	record := &r.db.employees[i]
+	record.Name = employee.Name
+	record.Email = employee.Email
+	record.Role = employee.Role
+	record.RoleDescription = employee.RoleDescription
+	record.MonthlyHours = employee.MonthlyHours
+	record.Skills = clone(employee.Skills)
+	record.LeaveAllowances = clone(employee.LeaveAllowances)
+	record.Active = employee.Active
+	record.Version = employee.Version
+	record.UpdatedAt = employee.UpdatedAt
+	return nil
+}
+
+func (r *employeeRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return domain.ErrEmployeeNotFound
+	}
+
+	r.db.employees[i].Active = false
+	r.db.employees[i].Version++
+	r.db.employees[i].UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *employeeRepository) GetByEmail(ctx context.Context, email string) (*domain.Employee, error) {
+	return r.findOne(func(employee domain.Employee) bool { return employee.Email == email })
+}
+
+func (r *employeeRepository) GetByCalendarToken(ctx context.Context, token string) (*domain.Employee, error) {
+	return r.findOne(func(employee domain.Employee) bool { return employee.CalendarToken == token })
+}
+
+func (r *employeeRepository) SetCalendarToken(ctx context.Context, id, token string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return domain.ErrEmployeeNotFound
+	}
+
+	r.db.employees[i].CalendarToken = token
+	r.db.employees[i].UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *employeeRepository) findOne(match func(domain.Employee) bool) (*domain.Employee, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, employee := range r.db.employees {
+		if match(employee) {
+			employee = r.withAvailability(employee)
+			return &employee, nil
+		}
+	}
+	return nil, domain.ErrEmployeeNotFound
+}
+
+// find returns the matching employees sorted by name
+func (r *employeeRepository) find(match func(domain.Employee) bool) []domain.Employee {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	employees := []domain.Employee{}
+	for _, employee := range r.db.employees {
+		if match(employee) {
+			employees = append(employees, r.withAvailability(employee))
+		}
+	}
+
+	slices.SortStableFunc(employees, func(a, b domain.Employee) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return employees
+}
+
+// withAvailability returns a copy of the employee with their availability
+// filled in. The caller holds the lock.
+func (r *employeeRepository) withAvailability(employee domain.Employee) domain.Employee {
+	employee = clone(employee)
+	employee.Availability = findAvailability(r.db, func(avail domain.Availability) bool {
+		return avail.EmployeeID == employee.ID
+	})
+	return employee
+}
+
+func (r *employeeRepository) indexOf(id string) int {
+	return slices.IndexFunc(r.db.employees, func(employee domain.Employee) bool { return employee.ID == id })
+}
+
+// indexOfEmail finds another employee than exceptID with the email, which
+// must be unique like the unique index on email in MongoDB
+func (r *employeeRepository) indexOfEmail(email, exceptID string) int {
+	return slices.IndexFunc(r.db.employees, func(employee domain.Employee) bool {
+		return employee.Email == email && employee.ID != exceptID
+	})
+}
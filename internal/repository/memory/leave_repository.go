@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type leaveRepository struct {
+	db *DB
+}
+
+// NewLeaveRepository creates a new in-memory leave request repository
+func NewLeaveRepository(db *DB) repository.LeaveRepository {
+	return &leaveRepository{db: db}
+}
+
+func (r *leaveRepository) Create(ctx context.Context, request *domain.LeaveRequest) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if request.ID == "" {
+		request.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	request.CreatedAt = now
+	request.UpdatedAt = now
+
+	r.db.leaveRequests = append(r.db.leaveRequests, clone(*request))
+	return nil
+}
+
+func (r *leaveRepository) GetByID(ctx context.Context, id string) (*domain.LeaveRequest, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return nil, domain.ErrLeaveNotFound
+	}
+
+	request := clone(r.db.leaveRequests[i])
+	return &request, nil
+}
+
+func (r *leaveRepository) GetByEmployee(ctx context.Context, employeeID string) ([]domain.LeaveRequest, error) {
+	return r.find(func(request domain.LeaveRequest) bool { return request.EmployeeID == employeeID }, -1), nil
+}
+
+func (r *leaveRepository) GetByStatus(ctx context.Context, statuses ...string) ([]domain.LeaveRequest, error) {
+	return r.find(func(request domain.LeaveRequest) bool { return slices.Contains(statuses, request.Status) }, 1), nil
+}
+
+// find returns the matching leave requests sorted by start date, ascending
+// for a positive order and descending for a negative one
+func (r *leaveRepository) find(match func(domain.LeaveRequest) bool, order int) []domain.LeaveRequest {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var requests []domain.LeaveRequest
+	for _, request := range r.db.leaveRequests {
+		if match(request) {
+			requests = append(requests, clone(request))
+		}
+	}
+
+	slices.SortStableFunc(requests, func(a, b domain.LeaveRequest) int {
+		return order * a.StartDate.Compare(b.StartDate)
+	})
+	return requests
+}
+
+func (r *leaveRepository) Update(ctx context.Context, request *domain.LeaveRequest) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := r.indexOf(request.ID)
+	if i < 0 {
+		return domain.ErrLeaveNotFound
+	}
+
+	request.UpdatedAt = time.Now()
+
+	record := &r.db.leaveRequests[i]
+	record.Status = request.Status
+	record.Conflicts = clone(request.Conflicts)
+	record.DecidedBy = request.DecidedBy
+	record.DecidedAt = clone(request.DecidedAt)
+	record.UpdatedAt = request.UpdatedAt
+	return nil
+}
+
+func (r *leaveRepository) indexOf(id string) int {
+	return slices.IndexFunc(r.db.leaveRequests, func(request domain.LeaveRequest) bool { return request.ID == id })
+}
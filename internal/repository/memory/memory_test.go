@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+	"github.com/isak/restySched/internal/repository/repositorytest"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) *repository.Repositories {
+		return NewRepositories(NewDB())
+	})
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	db := NewDB()
+	employees := NewEmployeeRepository(db)
+	availability := NewAvailabilityRepository(db)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			employee := &domain.Employee{Name: fmt.Sprintf("Employee %d", i), Email: fmt.Sprintf("employee%d@example.com", i), MonthlyHours: 100}
+			if err := employees.Create(ctx, employee); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := availability.Create(ctx, &domain.Availability{EmployeeID: employee.ID, StartDate: time.Now(), EndDate: time.Now()}); err != nil {
+				t.Error(err)
+			}
+			if _, err := employees.GetAll(ctx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	all, err := employees.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 20 {
+		t.Fatalf("GetAll() = %d employees, want 20", len(all))
+	}
+	for _, employee := range all {
+		if len(employee.Availability) != 1 {
+			t.Errorf("%s has %d availability periods, want 1", employee.Name, len(employee.Availability))
+		}
+	}
+}
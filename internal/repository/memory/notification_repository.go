@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type notificationRepository struct {
+	db *DB
+}
+
+// NewNotificationRepository creates a new in-memory notification repository
+func NewNotificationRepository(db *DB) repository.NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+	notification.CreatedAt = time.Now()
+
+	r.db.notifications = append(r.db.notifications, clone(*notification))
+	return nil
+}
+
+func (r *notificationRepository) GetByEmployee(ctx context.Context, employeeID string, limit int) ([]domain.Notification, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var notifications []domain.Notification
+	for _, notification := range r.db.notifications {
+		if notification.EmployeeID == employeeID {
+			notifications = append(notifications, clone(notification))
+		}
+	}
+
+	slices.SortStableFunc(notifications, func(a, b domain.Notification) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	if limit > 0 && len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, employeeID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	for i, notification := range r.db.notifications {
+		if notification.EmployeeID == employeeID && notification.ReadAt == nil {
+			r.db.notifications[i].ReadAt = &now
+		}
+	}
+	return nil
+}
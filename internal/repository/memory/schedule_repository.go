@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type scheduleRepository struct {
+	db *DB
+}
+
+// NewScheduleRepository creates a new in-memory schedule repository
+func NewScheduleRepository(db *DB) repository.ScheduleRepository {
+	return &scheduleRepository{db: db}
+}
+
+func (r *scheduleRepository) Create(ctx context.Context, schedule *domain.Schedule) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if schedule.ID == "" {
+		schedule.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+	schedule.Version = 1
+
+	r.db.schedules = append(r.db.schedules, clone(*schedule))
+	return nil
+}
+
+func (r *scheduleRepository) GetByID(ctx context.Context, id string) (*domain.Schedule, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return nil, domain.ErrScheduleNotFound
+	}
+
+	schedule := clone(r.db.schedules[i])
+	return &schedule, nil
+}
+
+func (r *scheduleRepository) GetAll(ctx context.Context) ([]domain.Schedule, error) {
+	return r.find(func(domain.Schedule) bool { return true }), nil
+}
+
+func (r *scheduleRepository) GetByPeriod(ctx context.Context, start, end time.Time) ([]domain.Schedule, error) {
+	return r.find(func(schedule domain.Schedule) bool {
+		return !schedule.PeriodStart.Before(start) && !schedule.PeriodEnd.After(end)
+	}), nil
+}
+
+// find returns the matching schedules, latest period first
+func (r *scheduleRepository) find(match func(domain.Schedule) bool) []domain.Schedule {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	schedules := []domain.Schedule{}
+	for _, schedule := range r.db.schedules {
+		if match(schedule) {
+			schedules = append(schedules, clone(schedule))
+		}
+	}
+
+	slices.SortStableFunc(schedules, func(a, b domain.Schedule) int {
+		return b.PeriodStart.Compare(a.PeriodStart)
+	})
+	return schedules
+}
+
+func (r *scheduleRepository) Update(ctx context.Context, schedule *domain.Schedule) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := r.indexOf(schedule.ID)
+	if i < 0 {
+		return domain.ErrScheduleNotFound
+	}
+	if r.db.schedules[i].Version != schedule.Version {
+		return domain.ErrConflict
+	}
+
+	schedule.UpdatedAt = time.Now()
+	schedule.Version++
+
+	record := &r.db.schedules[i]
+	record.PeriodStart = schedule.PeriodStart
+	record.PeriodEnd = schedule.PeriodEnd
+	record.Employees = clone(schedule.Employees)
+	record.Assignments = clone(schedule.Assignments)
+	record.Understaffed = clone(schedule.Understaffed)
+	record.RelaxedConstraints = clone(schedule.RelaxedConstraints)
+	record.Score = clone(schedule.Score)
+	record.Warnings = clone(schedule.Warnings)
+	record.EditedAt = clone(schedule.EditedAt)
+	record.Status = schedule.Status
+	record.Transitions = clone(schedule.Transitions)
+	record.PublishedAssignments = clone(schedule.PublishedAssignments)
+	record.CancelledAssignments = clone(schedule.CancelledAssignments)
+	record.SentToN8N = schedule.SentToN8N
+	record.SentAt = clone(schedule.SentAt)
+	record.Version = schedule.Version
+	record.UpdatedAt = schedule.UpdatedAt
+	return nil
+}
+
+func (r *scheduleRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return domain.ErrScheduleNotFound
+	}
+
+	r.db.schedules = slices.Delete(r.db.schedules, i, i+1)
+	return nil
+}
+
+func (r *scheduleRepository) MarkAsSent(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return domain.ErrScheduleNotFound
+	}
+
+	now := time.Now()
+	r.db.schedules[i].SentToN8N = true
+	r.db.schedules[i].SentAt = &now
+	r.db.schedules[i].Version++
+	r.db.schedules[i].UpdatedAt = now
+	return nil
+}
+
+func (r *scheduleRepository) indexOf(id string) int {
+	return slices.IndexFunc(r.db.schedules, func(schedule domain.Schedule) bool { return schedule.ID == id })
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type sessionRepository struct {
+	db *DB
+}
+
+// NewSessionRepository creates a new in-memory session repository. Expired
+// sessions are removed whenever a session is created.
+func NewSessionRepository(db *DB) repository.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	r.db.sessions = slices.DeleteFunc(r.db.sessions, func(existing domain.Session) bool {
+		return existing.Expired(now)
+	})
+
+	// The token itself is never stored
+	record := *session
+	record.Token = ""
+
+	r.db.sessions = append(r.db.sessions, record)
+	return nil
+}
+
+func (r *sessionRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	i := slices.IndexFunc(r.db.sessions, func(session domain.Session) bool { return session.TokenHash == tokenHash })
+	if i < 0 {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	session := r.db.sessions[i]
+	return &session, nil
+}
+
+func (r *sessionRepository) Delete(ctx context.Context, tokenHash string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.sessions = slices.DeleteFunc(r.db.sessions, func(session domain.Session) bool {
+		return session.TokenHash == tokenHash
+	})
+	return nil
+}
+
+func (r *sessionRepository) DeleteByUser(ctx context.Context, userID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.sessions = slices.DeleteFunc(r.db.sessions, func(session domain.Session) bool {
+		return session.UserID == userID
+	})
+	return nil
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type shiftSwapRepository struct {
+	db *DB
+}
+
+// NewShiftSwapRepository creates a new in-memory shift swap repository
+func NewShiftSwapRepository(db *DB) repository.ShiftSwapRepository {
+	return &shiftSwapRepository{db: db}
+}
+
+func (r *shiftSwapRepository) Create(ctx context.Context, swap *domain.ShiftSwap) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if swap.ID == "" {
+		swap.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	swap.CreatedAt = now
+	swap.UpdatedAt = now
+
+	r.db.shiftSwaps = append(r.db.shiftSwaps, clone(*swap))
+	return nil
+}
+
+func (r *shiftSwapRepository) GetByID(ctx context.Context, id string) (*domain.ShiftSwap, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return nil, domain.ErrShiftSwapNotFound
+	}
+
+	swap := clone(r.db.shiftSwaps[i])
+	return &swap, nil
+}
+
+func (r *shiftSwapRepository) GetBySchedule(ctx context.Context, scheduleID string) ([]domain.ShiftSwap, error) {
+	return r.find(func(swap domain.ShiftSwap) bool { return swap.ScheduleID == scheduleID }), nil
+}
+
+func (r *shiftSwapRepository) GetByStatus(ctx context.Context, statuses ...string) ([]domain.ShiftSwap, error) {
+	return r.find(func(swap domain.ShiftSwap) bool { return slices.Contains(statuses, swap.Status) }), nil
+}
+
+// find returns the matching shift swaps, oldest first
+func (r *shiftSwapRepository) find(match func(domain.ShiftSwap) bool) []domain.ShiftSwap {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var swaps []domain.ShiftSwap
+	for _, swap := range r.db.shiftSwaps {
+		if match(swap) {
+			swaps = append(swaps, clone(swap))
+		}
+	}
+
+	slices.SortStableFunc(swaps, func(a, b domain.ShiftSwap) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return swaps
+}
+
+func (r *shiftSwapRepository) Update(ctx context.Context, swap *domain.ShiftSwap) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := r.indexOf(swap.ID)
+	if i < 0 {
+		return domain.ErrShiftSwapNotFound
+	}
+
+	swap.UpdatedAt = time.Now()
+
+	record := &r.db.shiftSwaps[i]
+	record.Status = swap.Status
+	record.Note = swap.Note
+	record.ClaimedBy = swap.ClaimedBy
+	record.ClaimantName = swap.ClaimantName
+	record.ReturnShift = clone(swap.ReturnShift)
+	record.DecidedBy = swap.DecidedBy
+	record.ClaimedAt = clone(swap.ClaimedAt)
+	record.DecidedAt = clone(swap.DecidedAt)
+	record.UpdatedAt = swap.UpdatedAt
+	return nil
+}
+
+func (r *shiftSwapRepository) indexOf(id string) int {
+	return slices.IndexFunc(r.db.shiftSwaps, func(swap domain.ShiftSwap) bool { return swap.ID == id })
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type userRepository struct {
+	db *DB
+}
+
+// NewUserRepository creates a new in-memory user repository
+func NewUserRepository(db *DB) repository.UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.indexOfEmail(user.Email, "") >= 0 {
+		return domain.ErrUserAlreadyExists
+	}
+
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.Active = true
+
+	r.db.users = append(r.db.users, clone(*user))
+	return nil
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	return r.findOne(func(user domain.User) bool { return user.ID == id })
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.findOne(func(user domain.User) bool { return user.Email == email })
+}
+
+func (r *userRepository) findOne(match func(domain.User) bool) (*domain.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	i := slices.IndexFunc(r.db.users, match)
+	if i < 0 {
+		return nil, domain.ErrUserNotFound
+	}
+
+	user := clone(r.db.users[i])
+	return &user, nil
+}
+
+func (r *userRepository) GetAll(ctx context.Context) ([]domain.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	users := clone(r.db.users)
+	if users == nil {
+		users = []domain.User{}
+	}
+
+	slices.SortStableFunc(users, func(a, b domain.User) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return users, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := r.indexOf(user.ID)
+	if i < 0 {
+		return domain.ErrUserNotFound
+	}
+	if r.indexOfEmail(user.Email, user.ID) >= 0 {
+		return domain.ErrUserAlreadyExists
+	}
+
+	user.UpdatedAt = time.Now()
+
+	record := &r.db.users[i]
+	record.Name = user.Name
+	record.Email = user.Email
+	record.Role = user.Role
+	record.EmployeeID = user.EmployeeID
+	record.Active = user.Active
+	record.UpdatedAt = user.UpdatedAt
+	return nil
+}
+
+func (r *userRepository) SetPasswordHash(ctx context.Context, id, hash string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return domain.ErrUserNotFound
+	}
+
+	r.db.users[i].PasswordHash = hash
+	r.db.users[i].UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *userRepository) indexOf(id string) int {
+	return slices.IndexFunc(r.db.users, func(user domain.User) bool { return user.ID == id })
+}
+
+// indexOfEmail finds another user than exceptID with the email; emails are
+// the login names and must be unique
+func (r *userRepository) indexOfEmail(email, exceptID string) int {
+	return slices.IndexFunc(r.db.users, func(user domain.User) bool {
+		return user.Email == email && user.ID != exceptID
+	})
+}
@@ -0,0 +1,86 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type auditRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuditRepository creates a new MongoDB audit repository
+func NewAuditRepository(db *mongo.Database) repository.AuditRepository {
+	return &auditRepository{
+		collection: db.Collection("audit_log"),
+	}
+}
+
+func (r *auditRepository) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *auditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	query := bson.M{}
+	for field, value := range map[string]string{
+		"entity_type": filter.EntityType,
+		"entity_id":   filter.EntityID,
+		"actor":       filter.Actor,
+		"action":      filter.Action,
+	} {
+		if value != "" {
+			query[field] = value
+		}
+	}
+	timestamp := bson.M{}
+	if !filter.Since.IsZero() {
+		timestamp["$gte"] = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		timestamp["$lt"] = filter.Until
+	}
+	if len(timestamp) > 0 {
+		query["timestamp"] = timestamp
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []domain.AuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *auditRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"timestamp": bson.M{"$lt": cutoff}})
+	if err != nil {
+		return 0, err
+	}
+	return int(result.DeletedCount), nil
+}
@@ -0,0 +1,196 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type availabilityRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAvailabilityRepository creates a new MongoDB availability repository
+func NewAvailabilityRepository(db *mongo.Database) repository.AvailabilityRepository {
+	return newAvailabilityRepository(db)
+}
+
+func newAvailabilityRepository(db *mongo.Database) *availabilityRepository {
+	return &availabilityRepository{
+		collection: db.Collection("availability"),
+	}
+}
+
+func (r *availabilityRepository) Create(ctx context.Context, availability *domain.Availability) error {
+	if availability.ID == "" {
+		availability.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	availability.CreatedAt = now
+	availability.UpdatedAt = now
+
+	_, err := r.collection.InsertOne(ctx, availability)
+	return err
+}
+
+func (r *availabilityRepository) GetByID(ctx context.Context, id string) (*domain.Availability, error) {
+	var availability domain.Availability
+
+	err := r.collection.FindOne(ctx, bson.M{"id": id}).Decode(&availability)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrAvailabilityNotFound
+		}
+		return nil, err
+	}
+
+	return &availability, nil
+}
+
+func (r *availabilityRepository) GetByEmployee(ctx context.Context, employeeID string) ([]domain.Availability, error) {
+	return r.find(ctx, bson.M{"employee_id": employeeID})
+}
+
+func (r *availabilityRepository) GetByStatus(ctx context.Context, statuses ...string) ([]domain.Availability, error) {
+	return r.find(ctx, bson.M{"status": bson.M{"$in": statuses}})
+}
+
+func (r *availabilityRepository) GetByPeriod(ctx context.Context, start, end time.Time) ([]domain.Availability, error) {
+	// Periods are compared by calendar day, so the query leaves a day of slack
+	// on both sides and the exact check is made on the results
+	candidates, err := r.find(ctx, bson.M{
+		"start_date": bson.M{"$lte": end.AddDate(0, 0, 1)},
+		"$or": bson.A{
+			bson.M{"end_date": bson.M{"$gte": start.AddDate(0, 0, -1)}},
+			bson.M{"end_date": time.Time{}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var availability []domain.Availability
+	for _, avail := range candidates {
+		if avail.OverlapsPeriod(start, end) {
+			availability = append(availability, avail)
+		}
+	}
+	return availability, nil
+}
+
+// forEmployees retrieves the availability periods of the given employees,
+// grouped by employee ID
+func (r *availabilityRepository) forEmployees(ctx context.Context, employeeIDs []string) (map[string][]domain.Availability, error) {
+	availability, err := r.find(ctx, bson.M{"employee_id": bson.M{"$in": employeeIDs}})
+	if err != nil {
+		return nil, err
+	}
+
+	byEmployee := make(map[string][]domain.Availability)
+	for _, avail := range availability {
+		byEmployee[avail.EmployeeID] = append(byEmployee[avail.EmployeeID], avail)
+	}
+	return byEmployee, nil
+}
+
+func (r *availabilityRepository) find(ctx context.Context, filter bson.M) ([]domain.Availability, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "start_date", Value: 1}, {Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var availability []domain.Availability
+	if err := cursor.All(ctx, &availability); err != nil {
+		return nil, err
+	}
+
+	return availability, nil
+}
+
+func (r *availabilityRepository) Update(ctx context.Context, availability *domain.Availability) error {
+	availability.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"start_date":  availability.StartDate,
+			"end_date":    availability.EndDate,
+			"type":        availability.Type,
+			"reason":      availability.Reason,
+			"shift_types": availability.ShiftTypes,
+			"status":      availability.Status,
+			"recurrence":  availability.Recurrence,
+			"start_time":  availability.StartTime,
+			"end_time":    availability.EndTime,
+			"updated_at":  availability.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"id": availability.ID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrAvailabilityNotFound
+	}
+
+	return nil
+}
+
+func (r *availabilityRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"id": id})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrAvailabilityNotFound
+	}
+
+	return nil
+}
+
+// migrateEmbeddedAvailability moves availability stored inside employee
+// documents, as it was before periods became records of their own, into the
+// availability collection
+func migrateEmbeddedAvailability(ctx context.Context, db *mongo.Database) error {
+	employees := db.Collection("employees")
+	availability := newAvailabilityRepository(db)
+
+	cursor, err := employees.Find(ctx, bson.M{"availability.0": bson.M{"$exists": true}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var employee domain.Employee
+		if err := cursor.Decode(&employee); err != nil {
+			return err
+		}
+
+		for _, avail := range employee.Availability {
+			avail.ID = ""
+			avail.EmployeeID = employee.ID
+			if err := availability.Create(ctx, &avail); err != nil {
+				return err
+			}
+		}
+
+		if _, err := employees.UpdateOne(ctx, bson.M{"id": employee.ID}, bson.M{"$unset": bson.M{"availability": ""}}); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
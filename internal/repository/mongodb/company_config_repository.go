@@ -46,6 +46,7 @@ func (r *CompanyConfigRepository) Create(ctx context.Context, config *domain.Com
 
 	config.CreatedAt = time.Now()
 	config.UpdatedAt = time.Now()
+	config.Version = 1
 
 	result, err := r.collection.InsertOne(ctx, config)
 	if err != nil {
@@ -81,18 +82,25 @@ func (r *CompanyConfigRepository) Update(ctx context.Context, config *domain.Com
 		return err
 	}
 
-	filter := bson.M{"_id": objectID}
-	update := bson.M{"$set": config}
+	// _id cannot be changed, so it is left out of the update
+	document := *config
+	document.ID = ""
+	document.Version = config.Version + 1
+
+	filter := bson.M{"_id": objectID, "version": config.Version}
+	update := bson.M{"$set": document}
 
 	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
 
+	// The configuration was found above, so it has changed since it was loaded
 	if result.MatchedCount == 0 {
-		return domain.ErrCompanyConfigNotFound
+		return domain.ErrConflict
 	}
 
+	config.Version++
 	return nil
 }
 
@@ -108,38 +116,7 @@ func (r *CompanyConfigRepository) GetOrCreate(ctx context.Context) (*domain.Comp
 	}
 
 	// Create default configuration
-	defaultConfig := &domain.CompanyConfig{
-		CompanyName: "Your Company Name",
-		WorkingHours: domain.WorkingHours{
-			WorkingDays: []int{1, 2, 3, 4, 5}, // Monday to Friday
-			OpenTime:    "09:00",
-			CloseTime:   "17:00",
-			Timezone:    "Europe/Oslo",
-		},
-		ShiftRequirements: []domain.ShiftRequirement{
-			{
-				ShiftType:    domain.ShiftTypeMorning,
-				MinEmployees: 1,
-				MaxEmployees: 2,
-				Description:  "Morning shift coverage",
-			},
-			{
-				ShiftType:    domain.ShiftTypeAfternoon,
-				MinEmployees: 1,
-				MaxEmployees: 2,
-				Description:  "Afternoon shift coverage",
-			},
-		},
-		SchedulingPolicies: domain.SchedulingPolicies{
-			MaxConsecutiveDays:     5,
-			MinRestHours:           12,
-			AllowOvertime:          true,
-			MaxOvertimeHours:       20,
-			WeekendConsentRequired: true,
-			FairDistribution:       true,
-		},
-		AIContext: "Please ensure fair distribution of shifts and respect employee availability preferences.",
-	}
+	defaultConfig := domain.DefaultCompanyConfig()
 
 	if err := r.Create(ctx, defaultConfig); err != nil {
 		return nil, err
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -36,9 +38,35 @@ func InitDB(mongoURI, dbName string) (*mongo.Database, error) {
 		return nil, fmt.Errorf("failed to create indexes: %w", err)
 	}
 
+	// Move availability kept inside employee documents into its own collection
+	if err := migrateEmbeddedAvailability(ctx, db); err != nil {
+		return nil, fmt.Errorf("failed to migrate availability: %w", err)
+	}
+
+	// Number documents saved before records had versions
+	if err := migrateVersions(ctx, db); err != nil {
+		return nil, fmt.Errorf("failed to migrate versions: %w", err)
+	}
+
 	return db, nil
 }
 
+// NewRepositories creates every repository on db
+func NewRepositories(db *mongo.Database) *repository.Repositories {
+	return &repository.Repositories{
+		Employees:     NewEmployeeRepository(db),
+		Availability:  NewAvailabilityRepository(db),
+		Schedules:     NewScheduleRepository(db),
+		CompanyConfig: NewCompanyConfigRepository(db),
+		Users:         NewUserRepository(db),
+		Sessions:      NewSessionRepository(db),
+		ShiftSwaps:    NewShiftSwapRepository(db),
+		Notifications: NewNotificationRepository(db),
+		LeaveRequests: NewLeaveRepository(db),
+		Audit:         NewAuditRepository(db),
+	}
+}
+
 func createIndexes(ctx context.Context, db *mongo.Database) error {
 	// Employees collection indexes
 	employeesCollection := db.Collection("employees")
@@ -60,6 +88,42 @@ func createIndexes(ctx context.Context, db *mongo.Database) error {
 		return fmt.Errorf("failed to create active index: %w", err)
 	}
 
+	// Calendar token index, unique among employees that have one
+	_, err = employeesCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "calendar_token", Value: 1}},
+		Options: options.Index().SetUnique(true).SetSparse(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create calendar token index: %w", err)
+	}
+
+	// Availability collection indexes
+	availabilityCollection := db.Collection("availability")
+
+	// Employee index, for loading employees with their availability
+	_, err = availabilityCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "employee_id", Value: 1}, {Key: "start_date", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create availability employee index: %w", err)
+	}
+
+	// Period index, for availability across employees
+	_, err = availabilityCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "start_date", Value: 1}, {Key: "end_date", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create availability period index: %w", err)
+	}
+
+	// Status index, for the periods waiting for approval
+	_, err = availabilityCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create availability status index: %w", err)
+	}
+
 	// Schedules collection indexes
 	schedulesCollection := db.Collection("schedules")
 
@@ -82,5 +146,150 @@ func createIndexes(ctx context.Context, db *mongo.Database) error {
 		return fmt.Errorf("failed to create status index: %w", err)
 	}
 
+	// Users collection indexes
+	usersCollection := db.Collection("users")
+
+	// Email unique index; emails are the login names
+	_, err = usersCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create user email index: %w", err)
+	}
+
+	// Sessions collection indexes
+	sessionsCollection := db.Collection("sessions")
+
+	// Token hash unique index
+	_, err = sessionsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "token_hash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session token index: %w", err)
+	}
+
+	// User index, for signing a user out everywhere
+	_, err = sessionsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session user index: %w", err)
+	}
+
+	// TTL index, removing sessions once they expire
+	_, err = sessionsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session expiry index: %w", err)
+	}
+
+	// Shift swaps collection indexes
+	shiftSwapsCollection := db.Collection("shift_swaps")
+
+	// Schedule index, for a schedule's swap history
+	_, err = shiftSwapsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "schedule_id", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create shift swap schedule index: %w", err)
+	}
+
+	// Status index, for the open and claimed offers
+	_, err = shiftSwapsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create shift swap status index: %w", err)
+	}
+
+	// Notifications collection indexes
+	_, err = db.Collection("notifications").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "employee_id", Value: 1},
+			{Key: "created_at", Value: -1},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create notification index: %w", err)
+	}
+
+	// Leave requests collection indexes
+	leaveCollection := db.Collection("leave_requests")
+
+	// Employee index, for an employee's requests and balances
+	_, err = leaveCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "employee_id", Value: 1},
+			{Key: "start_date", Value: -1},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create leave employee index: %w", err)
+	}
+
+	// Status index, for requests waiting for approval
+	_, err = leaveCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create leave status index: %w", err)
+	}
+
+	// Audit collection indexes
+	auditCollection := db.Collection("audit_log")
+
+	// Entity index, for the history of one record
+	_, err = auditCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "entity_type", Value: 1},
+			{Key: "entity_id", Value: 1},
+			{Key: "timestamp", Value: -1},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create audit entity index: %w", err)
+	}
+
+	// Timestamp index, for the whole trail and for deleting old entries
+	_, err = auditCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "timestamp", Value: -1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create audit timestamp index: %w", err)
+	}
+
+	return nil
+}
+
+// migrateVersions gives the documents of versioned collections that were
+// saved before records had versions their first version
+func migrateVersions(ctx context.Context, db *mongo.Database) error {
+	for _, name := range []string{"employees", "schedules", "company_config"} {
+		_, err := db.Collection(name).UpdateMany(ctx,
+			bson.M{"version": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"version": 1}},
+		)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
 	return nil
 }
+
+// versionMismatch explains why a versioned update of the document with the
+// id matched nothing: notFound if there is no such document, and
+// domain.ErrConflict if it is at another version
+func versionMismatch(ctx context.Context, collection *mongo.Collection, id string, notFound error) error {
+	n, err := collection.CountDocuments(ctx, bson.M{"id": id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return domain.ErrConflict
+}
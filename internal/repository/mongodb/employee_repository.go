@@ -13,13 +13,16 @@ import (
 )
 
 type employeeRepository struct {
-	collection *mongo.Collection
+	collection   *mongo.Collection
+	availability *availabilityRepository
 }
 
-// NewEmployeeRepository creates a new MongoDB employee repository
+// NewEmployeeRepository creates a new MongoDB employee repository. Employees
+// are loaded with their availability from the availability collection.
 func NewEmployeeRepository(db *mongo.Database) repository.EmployeeRepository {
 	return &employeeRepository{
-		collection: db.Collection("employees"),
+		collection:   db.Collection("employees"),
+		availability: newAvailabilityRepository(db),
 	}
 }
 
@@ -32,8 +35,13 @@ func (r *employeeRepository) Create(ctx context.Context, employee *domain.Employ
 	employee.CreatedAt = now
 	employee.UpdatedAt = now
 	employee.Active = true
+	employee.Version = 1
 
-	_, err := r.collection.InsertOne(ctx, employee)
+	// Availability is stored in its own collection
+	document := *employee
+	document.Availability = nil
+
+	_, err := r.collection.InsertOne(ctx, document)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
 			return domain.ErrEmployeeAlreadyExists
@@ -55,7 +63,11 @@ func (r *employeeRepository) GetByID(ctx context.Context, id string) (*domain.Em
 		return nil, err
 	}
 
-	return &employee, nil
+	employees := []domain.Employee{employee}
+	if err := r.withAvailability(ctx, employees); err != nil {
+		return nil, err
+	}
+	return &employees[0], nil
 }
 
 func (r *employeeRepository) GetAll(ctx context.Context) ([]domain.Employee, error) {
@@ -76,6 +88,9 @@ func (r *employeeRepository) GetAll(ctx context.Context) ([]domain.Employee, err
 		employees = []domain.Employee{}
 	}
 
+	if err := r.withAvailability(ctx, employees); err != nil {
+		return nil, err
+	}
 	return employees, nil
 }
 
@@ -97,6 +112,9 @@ func (r *employeeRepository) GetActive(ctx context.Context) ([]domain.Employee,
 		employees = []domain.Employee{}
 	}
 
+	if err := r.withAvailability(ctx, employees); err != nil {
+		return nil, err
+	}
 	return employees, nil
 }
 
@@ -110,20 +128,28 @@ func (r *employeeRepository) Update(ctx context.Context, employee *domain.Employ
 			"role":             employee.Role,
 			"role_description": employee.RoleDescription,
 			"monthly_hours":    employee.MonthlyHours,
+			"skills":           employee.Skills,
+			"leave_allowances": employee.LeaveAllowances,
 			"active":           employee.Active,
+			"version":          employee.Version + 1,
 			"updated_at":       employee.UpdatedAt,
 		},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"id": employee.ID}, update)
+	filter := bson.M{"id": employee.ID, "version": employee.Version}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrEmployeeAlreadyExists
+		}
 		return err
 	}
 
 	if result.MatchedCount == 0 {
-		return domain.ErrEmployeeNotFound
+		return versionMismatch(ctx, r.collection, employee.ID, domain.ErrEmployeeNotFound)
 	}
 
+	employee.Version++
 	return nil
 }
 
@@ -133,6 +159,7 @@ func (r *employeeRepository) Delete(ctx context.Context, id string) error {
 			"active":     false,
 			"updated_at": time.Now(),
 		},
+		"$inc": bson.M{"version": 1},
 	}
 
 	result, err := r.collection.UpdateOne(ctx, bson.M{"id": id}, update)
@@ -158,5 +185,68 @@ func (r *employeeRepository) GetByEmail(ctx context.Context, email string) (*dom
 		return nil, err
 	}
 
-	return &employee, nil
+	employees := []domain.Employee{employee}
+	if err := r.withAvailability(ctx, employees); err != nil {
+		return nil, err
+	}
+	return &employees[0], nil
+}
+
+func (r *employeeRepository) GetByCalendarToken(ctx context.Context, token string) (*domain.Employee, error) {
+	var employee domain.Employee
+
+	err := r.collection.FindOne(ctx, bson.M{"calendar_token": token}).Decode(&employee)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+
+	employees := []domain.Employee{employee}
+	if err := r.withAvailability(ctx, employees); err != nil {
+		return nil, err
+	}
+	return &employees[0], nil
+}
+
+func (r *employeeRepository) SetCalendarToken(ctx context.Context, id, token string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"calendar_token": token,
+			"updated_at":     time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"id": id}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrEmployeeNotFound
+	}
+
+	return nil
+}
+
+// withAvailability fills in the employees' availability from the availability collection
+func (r *employeeRepository) withAvailability(ctx context.Context, employees []domain.Employee) error {
+	if len(employees) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(employees))
+	for i, employee := range employees {
+		ids[i] = employee.ID
+	}
+
+	byEmployee, err := r.availability.forEmployees(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range employees {
+		employees[i].Availability = byEmployee[employees[i].ID]
+	}
+	return nil
 }
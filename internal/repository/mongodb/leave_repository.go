@@ -0,0 +1,101 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type leaveRepository struct {
+	collection *mongo.Collection
+}
+
+// NewLeaveRepository creates a new MongoDB leave request repository
+func NewLeaveRepository(db *mongo.Database) repository.LeaveRepository {
+	return &leaveRepository{
+		collection: db.Collection("leave_requests"),
+	}
+}
+
+func (r *leaveRepository) Create(ctx context.Context, request *domain.LeaveRequest) error {
+	if request.ID == "" {
+		request.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	request.CreatedAt = now
+	request.UpdatedAt = now
+
+	_, err := r.collection.InsertOne(ctx, request)
+	return err
+}
+
+func (r *leaveRepository) GetByID(ctx context.Context, id string) (*domain.LeaveRequest, error) {
+	var request domain.LeaveRequest
+
+	err := r.collection.FindOne(ctx, bson.M{"id": id}).Decode(&request)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrLeaveNotFound
+		}
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+func (r *leaveRepository) GetByEmployee(ctx context.Context, employeeID string) ([]domain.LeaveRequest, error) {
+	return r.find(ctx, bson.M{"employee_id": employeeID}, -1)
+}
+
+func (r *leaveRepository) GetByStatus(ctx context.Context, statuses ...string) ([]domain.LeaveRequest, error) {
+	return r.find(ctx, bson.M{"status": bson.M{"$in": statuses}}, 1)
+}
+
+func (r *leaveRepository) find(ctx context.Context, filter bson.M, order int) ([]domain.LeaveRequest, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "start_date", Value: order}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var requests []domain.LeaveRequest
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+func (r *leaveRepository) Update(ctx context.Context, request *domain.LeaveRequest) error {
+	request.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":     request.Status,
+			"conflicts":  request.Conflicts,
+			"decided_by": request.DecidedBy,
+			"decided_at": request.DecidedAt,
+			"updated_at": request.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"id": request.ID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrLeaveNotFound
+	}
+
+	return nil
+}
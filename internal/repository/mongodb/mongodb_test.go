@@ -0,0 +1,35 @@
+package mongodb
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/repository"
+	"github.com/isak/restySched/internal/repository/repositorytest"
+)
+
+// The conformance suite needs a MongoDB server. Set MONGO_TEST_URI, such as
+// mongodb://localhost:27017, to run it; every test uses a database of its own
+// that is dropped afterwards.
+func TestConformance(t *testing.T) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	repositorytest.Run(t, func(t *testing.T) *repository.Repositories {
+		db, err := InitDB(uri, "restysched_test_"+uuid.New().String()[:8])
+		if err != nil {
+			t.Fatalf("InitDB() error = %v", err)
+		}
+		t.Cleanup(func() {
+			ctx := context.Background()
+			_ = db.Drop(ctx)
+			_ = db.Client().Disconnect(ctx)
+		})
+
+		return NewRepositories(db)
+	})
+}
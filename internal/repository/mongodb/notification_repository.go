@@ -0,0 +1,61 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type notificationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewNotificationRepository creates a new MongoDB notification repository
+func NewNotificationRepository(db *mongo.Database) repository.NotificationRepository {
+	return &notificationRepository{
+		collection: db.Collection("notifications"),
+	}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+	notification.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, notification)
+	return err
+}
+
+func (r *notificationRepository) GetByEmployee(ctx context.Context, employeeID string, limit int) ([]domain.Notification, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"employee_id": employeeID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var notifications []domain.Notification
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, employeeID string) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"employee_id": employeeID, "read_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"read_at": time.Now()}},
+	)
+	return err
+}
@@ -31,6 +31,7 @@ func (r *scheduleRepository) Create(ctx context.Context, schedule *domain.Schedu
 	now := time.Now()
 	schedule.CreatedAt = now
 	schedule.UpdatedAt = now
+	schedule.Version = 1
 
 	_, err := r.collection.InsertOne(ctx, schedule)
 	return err
@@ -102,25 +103,37 @@ func (r *scheduleRepository) Update(ctx context.Context, schedule *domain.Schedu
 
 	update := bson.M{
 		"$set": bson.M{
-			"period_start": schedule.PeriodStart,
-			"period_end":   schedule.PeriodEnd,
-			"employees":    schedule.Employees,
-			"status":       schedule.Status,
-			"sent_to_n8n":  schedule.SentToN8N,
-			"sent_at":      schedule.SentAt,
-			"updated_at":   schedule.UpdatedAt,
+			"period_start":          schedule.PeriodStart,
+			"period_end":            schedule.PeriodEnd,
+			"employees":             schedule.Employees,
+			"assignments":           schedule.Assignments,
+			"understaffed":          schedule.Understaffed,
+			"relaxed_constraints":   schedule.RelaxedConstraints,
+			"score":                 schedule.Score,
+			"warnings":              schedule.Warnings,
+			"edited_at":             schedule.EditedAt,
+			"status":                schedule.Status,
+			"transitions":           schedule.Transitions,
+			"published_assignments": schedule.PublishedAssignments,
+			"cancelled_assignments": schedule.CancelledAssignments,
+			"sent_to_n8n":           schedule.SentToN8N,
+			"sent_at":               schedule.SentAt,
+			"version":               schedule.Version + 1,
+			"updated_at":            schedule.UpdatedAt,
 		},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"id": schedule.ID}, update)
+	filter := bson.M{"id": schedule.ID, "version": schedule.Version}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
 
 	if result.MatchedCount == 0 {
-		return domain.ErrScheduleNotFound
+		return versionMismatch(ctx, r.collection, schedule.ID, domain.ErrScheduleNotFound)
 	}
 
+	schedule.Version++
 	return nil
 }
 
@@ -144,9 +157,9 @@ func (r *scheduleRepository) MarkAsSent(ctx context.Context, id string) error {
 		"$set": bson.M{
 			"sent_to_n8n": true,
 			"sent_at":     now,
-			"status":      domain.ScheduleStatusSent,
 			"updated_at":  now,
 		},
+		"$inc": bson.M{"version": 1},
 	}
 
 	result, err := r.collection.UpdateOne(ctx, bson.M{"id": id}, update)
@@ -0,0 +1,51 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type sessionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSessionRepository creates a new MongoDB session repository. Expired
+// sessions are removed by a TTL index on expires_at.
+func NewSessionRepository(db *mongo.Database) repository.SessionRepository {
+	return &sessionRepository{
+		collection: db.Collection("sessions"),
+	}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+func (r *sessionRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	var session domain.Session
+
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *sessionRepository) Delete(ctx context.Context, tokenHash string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"token_hash": tokenHash})
+	return err
+}
+
+func (r *sessionRepository) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
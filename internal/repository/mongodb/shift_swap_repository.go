@@ -0,0 +1,105 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type shiftSwapRepository struct {
+	collection *mongo.Collection
+}
+
+// NewShiftSwapRepository creates a new MongoDB shift swap repository
+func NewShiftSwapRepository(db *mongo.Database) repository.ShiftSwapRepository {
+	return &shiftSwapRepository{
+		collection: db.Collection("shift_swaps"),
+	}
+}
+
+func (r *shiftSwapRepository) Create(ctx context.Context, swap *domain.ShiftSwap) error {
+	if swap.ID == "" {
+		swap.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	swap.CreatedAt = now
+	swap.UpdatedAt = now
+
+	_, err := r.collection.InsertOne(ctx, swap)
+	return err
+}
+
+func (r *shiftSwapRepository) GetByID(ctx context.Context, id string) (*domain.ShiftSwap, error) {
+	var swap domain.ShiftSwap
+
+	err := r.collection.FindOne(ctx, bson.M{"id": id}).Decode(&swap)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrShiftSwapNotFound
+		}
+		return nil, err
+	}
+
+	return &swap, nil
+}
+
+func (r *shiftSwapRepository) GetBySchedule(ctx context.Context, scheduleID string) ([]domain.ShiftSwap, error) {
+	return r.find(ctx, bson.M{"schedule_id": scheduleID})
+}
+
+func (r *shiftSwapRepository) GetByStatus(ctx context.Context, statuses ...string) ([]domain.ShiftSwap, error) {
+	return r.find(ctx, bson.M{"status": bson.M{"$in": statuses}})
+}
+
+func (r *shiftSwapRepository) find(ctx context.Context, filter bson.M) ([]domain.ShiftSwap, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var swaps []domain.ShiftSwap
+	if err := cursor.All(ctx, &swaps); err != nil {
+		return nil, err
+	}
+
+	return swaps, nil
+}
+
+func (r *shiftSwapRepository) Update(ctx context.Context, swap *domain.ShiftSwap) error {
+	swap.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":        swap.Status,
+			"note":          swap.Note,
+			"claimed_by":    swap.ClaimedBy,
+			"claimant_name": swap.ClaimantName,
+			"return_shift":  swap.ReturnShift,
+			"decided_by":    swap.DecidedBy,
+			"claimed_at":    swap.ClaimedAt,
+			"decided_at":    swap.DecidedAt,
+			"updated_at":    swap.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"id": swap.ID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrShiftSwapNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type userRepository struct {
+	collection *mongo.Collection
+}
+
+// NewUserRepository creates a new MongoDB user repository
+func NewUserRepository(db *mongo.Database) repository.UserRepository {
+	return &userRepository{
+		collection: db.Collection("users"),
+	}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.Active = true
+
+	_, err := r.collection.InsertOne(ctx, user)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrUserAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	return r.findOne(ctx, bson.M{"id": id})
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.findOne(ctx, bson.M{"email": email})
+}
+
+func (r *userRepository) findOne(ctx context.Context, filter bson.M) (*domain.User, error) {
+	var user domain.User
+
+	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) GetAll(ctx context.Context) ([]domain.User, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []domain.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	if users == nil {
+		users = []domain.User{}
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	user.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":        user.Name,
+			"email":       user.Email,
+			"role":        user.Role,
+			"employee_id": user.EmployeeID,
+			"active":      user.Active,
+			"updated_at":  user.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"id": user.ID}, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrUserAlreadyExists
+		}
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) SetPasswordHash(ctx context.Context, id, hash string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"password_hash": hash,
+			"updated_at":    time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"id": id}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package repository
+
+// Repositories are the repositories of one storage backend, sharing one
+// database
+type Repositories struct {
+	Employees     EmployeeRepository
+	Availability  AvailabilityRepository
+	Schedules     ScheduleRepository
+	CompanyConfig CompanyConfigRepository
+	Users         UserRepository
+	Sessions      SessionRepository
+	ShiftSwaps    ShiftSwapRepository
+	Notifications NotificationRepository
+	LeaveRequests LeaveRepository
+	Audit         AuditRepository
+}
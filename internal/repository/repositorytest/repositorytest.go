@@ -0,0 +1,832 @@
+// Package repositorytest is a conformance suite for implementations of the
+// repository interfaces. Every storage backend runs it, so that services
+// behave the same whichever backend they are given.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+// Run runs the conformance suite. open is called for every test and must
+// return repositories backed by an empty store.
+func Run(t *testing.T, open func(t *testing.T) *repository.Repositories) {
+	t.Run("Employees", func(t *testing.T) { testEmployees(t, open(t)) })
+	t.Run("EmployeeAvailability", func(t *testing.T) { testEmployeeAvailability(t, open(t)) })
+	t.Run("Availability", func(t *testing.T) { testAvailability(t, open(t)) })
+	t.Run("Schedules", func(t *testing.T) { testSchedules(t, open(t)) })
+	t.Run("CompanyConfig", func(t *testing.T) { testCompanyConfig(t, open(t)) })
+	t.Run("CompanyConfigUpsert", func(t *testing.T) { testCompanyConfigUpsert(t, open(t)) })
+	t.Run("Users", func(t *testing.T) { testUsers(t, open(t)) })
+	t.Run("Sessions", func(t *testing.T) { testSessions(t, open(t)) })
+	t.Run("ShiftSwaps", func(t *testing.T) { testShiftSwaps(t, open(t)) })
+	t.Run("Notifications", func(t *testing.T) { testNotifications(t, open(t)) })
+	t.Run("LeaveRequests", func(t *testing.T) { testLeaveRequests(t, open(t)) })
+	t.Run("Audit", func(t *testing.T) { testAudit(t, open(t)) })
+}
+
+func day(d int) time.Time {
+	return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+func testEmployees(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	repo := repos.Employees
+
+	john := &domain.Employee{Name: "John Doe", Email: "john@example.com", Role: "Barista", MonthlyHours: 120, Skills: []domain.Skill{{Name: "barista"}}}
+	jane := &domain.Employee{Name: "Jane Smith", Email: "jane@example.com", Role: "Manager", MonthlyHours: 160}
+	for _, employee := range []*domain.Employee{john, jane} {
+		if err := repo.Create(ctx, employee); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if john.ID == "" || !john.Active || john.Version != 1 || john.CreatedAt.IsZero() || john.UpdatedAt.IsZero() {
+		t.Errorf("Create() = %+v, want an active employee at version 1 with an ID and timestamps", john)
+	}
+
+	duplicate := &domain.Employee{Name: "Johnny", Email: "john@example.com", Role: "Cook", MonthlyHours: 80}
+	if err := repo.Create(ctx, duplicate); !errors.Is(err, domain.ErrEmployeeAlreadyExists) {
+		t.Errorf("Create() duplicate email error = %v, want %v", err, domain.ErrEmployeeAlreadyExists)
+	}
+
+	got, err := repo.GetByID(ctx, john.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.Name != john.Name || got.Email != john.Email || got.MonthlyHours != 120 || len(got.Skills) != 1 || !got.Active {
+		t.Errorf("GetByID() = %+v, want %+v", got, john)
+	}
+	if _, err := repo.GetByID(ctx, "missing"); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Errorf("GetByID() missing error = %v, want %v", err, domain.ErrEmployeeNotFound)
+	}
+
+	// Employees handed out are copies
+	got.Skills[0].Name = "changed"
+	if again, _ := repo.GetByID(ctx, john.ID); again.Skills[0].Name != "barista" {
+		t.Error("Changing a loaded employee changed the stored one")
+	}
+
+	if got, err := repo.GetByEmail(ctx, "jane@example.com"); err != nil || got.ID != jane.ID {
+		t.Errorf("GetByEmail() = %+v, %v, want %s", got, err, jane.ID)
+	}
+	if _, err := repo.GetByEmail(ctx, "nobody@example.com"); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Errorf("GetByEmail() missing error = %v, want %v", err, domain.ErrEmployeeNotFound)
+	}
+
+	all, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if len(all) != 2 || all[0].ID != jane.ID || all[1].ID != john.ID {
+		t.Errorf("GetAll() = %+v, want Jane then John", all)
+	}
+
+	john.Role = "Head Barista"
+	john.LeaveAllowances = map[string]float64{domain.LeaveTypeVacation: 25}
+	if err := repo.Update(ctx, john); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, _ := repo.GetByID(ctx, john.ID); got.Role != "Head Barista" || got.LeaveAllowances[domain.LeaveTypeVacation] != 25 || got.Version != 2 {
+		t.Errorf("GetByID() after Update() = %+v", got)
+	}
+	if john.Version != 2 {
+		t.Errorf("Update() version = %d, want 2", john.Version)
+	}
+
+	// Updating a version that has been changed since is a conflict
+	stale := *john
+	stale.Version = 1
+	stale.Role = "Cook"
+	if err := repo.Update(ctx, &stale); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Update() stale version error = %v, want %v", err, domain.ErrConflict)
+	}
+	if got, _ := repo.GetByID(ctx, john.ID); got.Role != "Head Barista" || got.Version != 2 {
+		t.Errorf("GetByID() after a conflicting Update() = %+v, want it unchanged", got)
+	}
+	jane.Email = "john@example.com"
+	if err := repo.Update(ctx, jane); !errors.Is(err, domain.ErrEmployeeAlreadyExists) {
+		t.Errorf("Update() duplicate email error = %v, want %v", err, domain.ErrEmployeeAlreadyExists)
+	}
+	jane.Email = "jane@example.com"
+	if err := repo.Update(ctx, &domain.Employee{ID: "missing", Email: "missing@example.com"}); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Errorf("Update() missing error = %v, want %v", err, domain.ErrEmployeeNotFound)
+	}
+
+	if err := repo.SetCalendarToken(ctx, jane.ID, "secret-token"); err != nil {
+		t.Fatalf("SetCalendarToken() error = %v", err)
+	}
+	if got, err := repo.GetByCalendarToken(ctx, "secret-token"); err != nil || got.ID != jane.ID {
+		t.Errorf("GetByCalendarToken() = %+v, %v, want %s", got, err, jane.ID)
+	}
+	if _, err := repo.GetByCalendarToken(ctx, "other-token"); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Errorf("GetByCalendarToken() missing error = %v, want %v", err, domain.ErrEmployeeNotFound)
+	}
+	if err := repo.SetCalendarToken(ctx, "missing", "token"); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Errorf("SetCalendarToken() missing error = %v, want %v", err, domain.ErrEmployeeNotFound)
+	}
+
+	// Deleting deactivates the employee but keeps the record
+	if err := repo.Delete(ctx, jane.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got, err := repo.GetByID(ctx, jane.ID); err != nil || got.Active || got.Version != jane.Version+1 {
+		t.Errorf("GetByID() after Delete() = %+v, %v, want an inactive employee at the next version", got, err)
+	}
+	active, err := repo.GetActive(ctx)
+	if err != nil {
+		t.Fatalf("GetActive() error = %v", err)
+	}
+	if len(active) != 1 || active[0].ID != john.ID {
+		t.Errorf("GetActive() = %+v, want only John", active)
+	}
+	if all, _ := repo.GetAll(ctx); len(all) != 2 {
+		t.Errorf("GetAll() after Delete() = %d employees, want 2", len(all))
+	}
+	if err := repo.Delete(ctx, "missing"); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Errorf("Delete() missing error = %v, want %v", err, domain.ErrEmployeeNotFound)
+	}
+}
+
+func testEmployeeAvailability(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+
+	employee := &domain.Employee{
+		Name:         "John Doe",
+		Email:        "john@example.com",
+		Role:         "Barista",
+		MonthlyHours: 120,
+		Availability: []domain.Availability{{StartDate: day(6), EndDate: day(10), Type: domain.AvailabilityTypeUnavailable}},
+	}
+	if err := repos.Employees.Create(ctx, employee); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Availability is kept by the availability repository only
+	if got, _ := repos.Employees.GetByID(ctx, employee.ID); len(got.Availability) != 0 {
+		t.Errorf("GetByID() availability = %+v, want none stored with the employee", got.Availability)
+	}
+
+	later := &domain.Availability{EmployeeID: employee.ID, StartDate: day(20), EndDate: day(21), Type: domain.AvailabilityTypePreferred}
+	earlier := &domain.Availability{EmployeeID: employee.ID, StartDate: day(13), EndDate: day(14), Type: domain.AvailabilityTypeUnavailable}
+	other := &domain.Availability{EmployeeID: "someone-else", StartDate: day(13), EndDate: day(14), Type: domain.AvailabilityTypeUnavailable}
+	for _, avail := range []*domain.Availability{later, earlier, other} {
+		if err := repos.Availability.Create(ctx, avail); err != nil {
+			t.Fatalf("Availability.Create() error = %v", err)
+		}
+	}
+
+	got, err := repos.Employees.GetByID(ctx, employee.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if len(got.Availability) != 2 || got.Availability[0].ID != earlier.ID || got.Availability[1].ID != later.ID {
+		t.Errorf("GetByID() availability = %+v, want the employee's periods earliest first", got.Availability)
+	}
+	if got.IsAvailableOn(domain.ShiftTypeMorning, day(13).Add(9*time.Hour), day(13).Add(13*time.Hour)) {
+		t.Error("Loaded employee is available during an unavailable period")
+	}
+
+	for name, load := range map[string]func() ([]domain.Employee, error){
+		"GetAll":    func() ([]domain.Employee, error) { return repos.Employees.GetAll(ctx) },
+		"GetActive": func() ([]domain.Employee, error) { return repos.Employees.GetActive(ctx) },
+	} {
+		employees, err := load()
+		if err != nil {
+			t.Fatalf("%s() error = %v", name, err)
+		}
+		if len(employees) != 1 || len(employees[0].Availability) != 2 {
+			t.Errorf("%s() = %+v, want one employee with two periods", name, employees)
+		}
+	}
+
+	// Updating an employee leaves their availability alone
+	got.Availability = nil
+	if err := repos.Employees.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, _ := repos.Employees.GetByEmail(ctx, employee.Email); len(got.Availability) != 2 {
+		t.Errorf("GetByEmail() after Update() availability = %+v, want two periods", got.Availability)
+	}
+}
+
+func testAvailability(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	repo := repos.Availability
+
+	january := &domain.Availability{
+		EmployeeID: "emp1",
+		StartDate:  day(6),
+		EndDate:    day(10),
+		Type:       domain.AvailabilityTypeUnavailable,
+		ShiftTypes: []string{domain.ShiftTypeMorning},
+		Status:     domain.AvailabilityStatusApproved,
+	}
+	wednesdays := &domain.Availability{
+		EmployeeID: "emp2",
+		StartDate:  day(1),
+		Type:       domain.AvailabilityTypeUnavailable,
+		Recurrence: &domain.Recurrence{Weekdays: []int{3}},
+		Status:     domain.AvailabilityStatusPending,
+	}
+	february := &domain.Availability{
+		EmployeeID: "emp1",
+		StartDate:  time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2025, 2, 7, 0, 0, 0, 0, time.UTC),
+		Type:       domain.AvailabilityTypePreferred,
+		StartTime:  "08:00",
+		EndTime:    "12:00",
+		Status:     domain.AvailabilityStatusPending,
+	}
+	for _, avail := range []*domain.Availability{february, january, wednesdays} {
+		if err := repo.Create(ctx, avail); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if january.ID == "" || january.CreatedAt.IsZero() {
+		t.Errorf("Create() = %+v, want an ID and timestamps", january)
+	}
+
+	got, err := repo.GetByID(ctx, february.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.EmployeeID != "emp1" || !got.StartDate.Equal(february.StartDate) || got.StartTime != "08:00" || got.EndTime != "12:00" || got.Status != domain.AvailabilityStatusPending {
+		t.Errorf("GetByID() = %+v, want %+v", got, february)
+	}
+	if _, err := repo.GetByID(ctx, "missing"); !errors.Is(err, domain.ErrAvailabilityNotFound) {
+		t.Errorf("GetByID() missing error = %v, want %v", err, domain.ErrAvailabilityNotFound)
+	}
+
+	assertIDs := func(name string, periods []domain.Availability, err error, want ...*domain.Availability) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s() error = %v", name, err)
+		}
+		if len(periods) != len(want) {
+			t.Errorf("%s() = %d periods, want %d", name, len(periods), len(want))
+			return
+		}
+		for i := range want {
+			if periods[i].ID != want[i].ID {
+				t.Errorf("%s()[%d] = %s, want %s", name, i, periods[i].ID, want[i].ID)
+			}
+		}
+	}
+
+	periods, err := repo.GetByEmployee(ctx, "emp1")
+	assertIDs("GetByEmployee", periods, err, january, february)
+	periods, err = repo.GetByStatus(ctx, domain.AvailabilityStatusPending)
+	assertIDs("GetByStatus", periods, err, wednesdays, february)
+	periods, err = repo.GetByStatus(ctx, domain.AvailabilityStatusApproved, domain.AvailabilityStatusPending)
+	assertIDs("GetByStatus(all)", periods, err, wednesdays, january, february)
+
+	// Periods are compared by day, and the open-ended repeating period
+	// overlaps any range after its start
+	periods, err = repo.GetByPeriod(ctx, day(10), day(14))
+	assertIDs("GetByPeriod", periods, err, wednesdays, january)
+	periods, err = repo.GetByPeriod(ctx, day(11), day(13))
+	assertIDs("GetByPeriod(after January)", periods, err, wednesdays)
+	periods, err = repo.GetByPeriod(ctx, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC))
+	assertIDs("GetByPeriod(December)", periods, err)
+	periods, err = repo.GetByPeriod(ctx, time.Date(2025, 2, 7, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 12, 0, 0, 0, 0, time.UTC))
+	assertIDs("GetByPeriod(February)", periods, err, wednesdays, february)
+
+	got.Status = domain.AvailabilityStatusApproved
+	got.EndDate = time.Date(2025, 2, 14, 0, 0, 0, 0, time.UTC)
+	got.ShiftTypes = []string{domain.ShiftTypeEvening}
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updated, _ := repo.GetByID(ctx, february.ID); updated.Status != domain.AvailabilityStatusApproved || !updated.EndDate.Equal(got.EndDate) || len(updated.ShiftTypes) != 1 {
+		t.Errorf("GetByID() after Update() = %+v", updated)
+	}
+	if err := repo.Update(ctx, &domain.Availability{ID: "missing"}); !errors.Is(err, domain.ErrAvailabilityNotFound) {
+		t.Errorf("Update() missing error = %v, want %v", err, domain.ErrAvailabilityNotFound)
+	}
+
+	if err := repo.Delete(ctx, january.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.GetByID(ctx, january.ID); !errors.Is(err, domain.ErrAvailabilityNotFound) {
+		t.Errorf("GetByID() after Delete() error = %v, want %v", err, domain.ErrAvailabilityNotFound)
+	}
+	if err := repo.Delete(ctx, january.ID); !errors.Is(err, domain.ErrAvailabilityNotFound) {
+		t.Errorf("Delete() twice error = %v, want %v", err, domain.ErrAvailabilityNotFound)
+	}
+}
+
+func testSchedules(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	repo := repos.Schedules
+
+	first := &domain.Schedule{
+		PeriodStart: day(6),
+		PeriodEnd:   day(12),
+		Status:      domain.ScheduleStatusDraft,
+		Assignments: []domain.ShiftAssignment{{ID: "a1", EmployeeID: "emp1", Date: day(6), ShiftType: domain.ShiftTypeMorning, Hours: 4}},
+	}
+	second := &domain.Schedule{PeriodStart: day(13), PeriodEnd: day(19), Status: domain.ScheduleStatusDraft}
+	for _, schedule := range []*domain.Schedule{first, second} {
+		if err := repo.Create(ctx, schedule); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if first.ID == "" || first.Version != 1 || first.CreatedAt.IsZero() || first.UpdatedAt.IsZero() {
+		t.Errorf("Create() = %+v, want version 1 with an ID and timestamps", first)
+	}
+
+	got, err := repo.GetByID(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if !got.PeriodStart.Equal(day(6)) || !got.PeriodEnd.Equal(day(12)) || len(got.Assignments) != 1 || got.Assignments[0].EmployeeID != "emp1" {
+		t.Errorf("GetByID() = %+v, want %+v", got, first)
+	}
+	if _, err := repo.GetByID(ctx, "missing"); !errors.Is(err, domain.ErrScheduleNotFound) {
+		t.Errorf("GetByID() missing error = %v, want %v", err, domain.ErrScheduleNotFound)
+	}
+
+	// Schedules handed out are copies
+	got.Assignments[0].EmployeeID = "emp2"
+	if again, _ := repo.GetByID(ctx, first.ID); again.Assignments[0].EmployeeID != "emp1" {
+		t.Error("Changing a loaded schedule changed the stored one")
+	}
+
+	all, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if len(all) != 2 || all[0].ID != second.ID || all[1].ID != first.ID {
+		t.Errorf("GetAll() = %+v, want the latest period first", all)
+	}
+
+	// Only schedules lying completely within the period are returned
+	for _, tt := range []struct {
+		start, end time.Time
+		want       []string
+	}{
+		{day(6), day(19), []string{second.ID, first.ID}},
+		{day(6), day(12), []string{first.ID}},
+		{day(7), day(19), []string{second.ID}},
+		{day(20), day(26), nil},
+	} {
+		schedules, err := repo.GetByPeriod(ctx, tt.start, tt.end)
+		if err != nil {
+			t.Fatalf("GetByPeriod() error = %v", err)
+		}
+		var ids []string
+		for _, schedule := range schedules {
+			ids = append(ids, schedule.ID)
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("GetByPeriod(%s, %s) = %v, want %v", tt.start.Format("Jan 2"), tt.end.Format("Jan 2"), ids, tt.want)
+		}
+	}
+
+	got.Status = domain.ScheduleStatusApproved
+	got.Assignments = append(got.Assignments, domain.ShiftAssignment{ID: "a2", EmployeeID: "emp1", Date: day(7), ShiftType: domain.ShiftTypeEvening, Hours: 4})
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	updated, _ := repo.GetByID(ctx, first.ID)
+	if updated.Status != domain.ScheduleStatusApproved || len(updated.Assignments) != 2 || !updated.CreatedAt.Equal(got.CreatedAt) || updated.Version != 2 {
+		t.Errorf("GetByID() after Update() = %+v", updated)
+	}
+	stale, _ := repo.GetByID(ctx, second.ID)
+	if err := repo.Update(ctx, stale); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	stale.Version = 1
+	stale.Status = domain.ScheduleStatusArchived
+	if err := repo.Update(ctx, stale); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Update() stale version error = %v, want %v", err, domain.ErrConflict)
+	}
+	if err := repo.Update(ctx, &domain.Schedule{ID: "missing"}); !errors.Is(err, domain.ErrScheduleNotFound) {
+		t.Errorf("Update() missing error = %v, want %v", err, domain.ErrScheduleNotFound)
+	}
+
+	if err := repo.MarkAsSent(ctx, first.ID); err != nil {
+		t.Fatalf("MarkAsSent() error = %v", err)
+	}
+	if sent, _ := repo.GetByID(ctx, first.ID); !sent.SentToN8N || sent.SentAt == nil || sent.Status != domain.ScheduleStatusApproved || sent.Version != 3 {
+		t.Errorf("GetByID() after MarkAsSent() = %+v, want sent at version 3 with the status unchanged", sent)
+	}
+	// An update made before the delivery must not clear the sent flag
+	updated.Status = domain.ScheduleStatusArchived
+	if err := repo.Update(ctx, updated); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Update() after MarkAsSent() error = %v, want %v", err, domain.ErrConflict)
+	}
+	if err := repo.MarkAsSent(ctx, "missing"); !errors.Is(err, domain.ErrScheduleNotFound) {
+		t.Errorf("MarkAsSent() missing error = %v, want %v", err, domain.ErrScheduleNotFound)
+	}
+
+	if err := repo.Delete(ctx, second.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.GetByID(ctx, second.ID); !errors.Is(err, domain.ErrScheduleNotFound) {
+		t.Errorf("GetByID() after Delete() error = %v, want %v", err, domain.ErrScheduleNotFound)
+	}
+	if err := repo.Delete(ctx, second.ID); !errors.Is(err, domain.ErrScheduleNotFound) {
+		t.Errorf("Delete() twice error = %v, want %v", err, domain.ErrScheduleNotFound)
+	}
+}
+
+func testCompanyConfig(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	repo := repos.CompanyConfig
+
+	if _, err := repo.Get(ctx); !errors.Is(err, domain.ErrCompanyConfigNotFound) {
+		t.Errorf("Get() on an empty store error = %v, want %v", err, domain.ErrCompanyConfigNotFound)
+	}
+
+	created, err := repo.GetOrCreate(ctx)
+	if err != nil {
+		t.Fatalf("GetOrCreate() error = %v", err)
+	}
+	if created.ID == "" || created.Version != 1 || created.CompanyName != domain.DefaultCompanyConfig().CompanyName || len(created.ShiftRequirements) != 2 {
+		t.Errorf("GetOrCreate() = %+v, want the default configuration at version 1", created)
+	}
+	if again, err := repo.GetOrCreate(ctx); err != nil || again.ID != created.ID {
+		t.Errorf("GetOrCreate() twice = %+v, %v, want the configuration created first", again, err)
+	}
+	if err := repo.Create(ctx, domain.DefaultCompanyConfig()); !errors.Is(err, domain.ErrCompanyConfigAlreadyExists) {
+		t.Errorf("Create() second configuration error = %v, want %v", err, domain.ErrCompanyConfigAlreadyExists)
+	}
+
+	// Updating keeps the ID and creation time of the stored configuration
+	update := domain.DefaultCompanyConfig()
+	update.Version = created.Version
+	update.CompanyName = "Resty Café"
+	update.SchedulingPolicies.MinRestHours = 11
+	if err := repo.Update(ctx, update); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err := repo.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	// Stores may keep times with millisecond precision only
+	if got.ID != created.ID || got.CompanyName != "Resty Café" || got.SchedulingPolicies.MinRestHours != 11 || got.CreatedAt.Sub(created.CreatedAt).Abs() > time.Millisecond {
+		t.Errorf("Get() after Update() = %+v, want %s renamed", got, created.ID)
+	}
+	if got.Version != 2 || update.Version != 2 {
+		t.Errorf("Update() version = %d, stored %d, want 2", update.Version, got.Version)
+	}
+
+	stale := domain.DefaultCompanyConfig()
+	stale.Version = created.Version
+	if err := repo.Update(ctx, stale); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Update() stale version error = %v, want %v", err, domain.ErrConflict)
+	}
+	if got, _ := repo.Get(ctx); got.CompanyName != "Resty Café" {
+		t.Errorf("Get() after a conflicting Update() = %+v, want it unchanged", got)
+	}
+}
+
+// testCompanyConfigUpsert checks that updating the company configuration of
+// an empty store creates it
+func testCompanyConfigUpsert(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+
+	config := domain.DefaultCompanyConfig()
+	config.CompanyName = "Resty Café"
+	if err := repos.CompanyConfig.Update(ctx, config); err != nil {
+		t.Fatalf("Update() on an empty store error = %v", err)
+	}
+	if got, err := repos.CompanyConfig.Get(ctx); err != nil || got.CompanyName != "Resty Café" || got.ID == "" {
+		t.Errorf("Get() after Update() = %+v, %v, want the configuration", got, err)
+	}
+}
+
+func testUsers(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	repo := repos.Users
+
+	zoe := &domain.User{Name: "Zoe", Email: "zoe@example.com", Role: domain.UserRoleAdmin, PasswordHash: "hash"}
+	adam := &domain.User{Name: "Adam", Email: "adam@example.com", Role: domain.UserRoleEmployee, EmployeeID: "employee-1"}
+	for _, user := range []*domain.User{zoe, adam} {
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if zoe.ID == "" || !zoe.Active || zoe.CreatedAt.IsZero() {
+		t.Errorf("Create() = %+v, want an active user with an ID and timestamps", zoe)
+	}
+	if err := repo.Create(ctx, &domain.User{Name: "Other", Email: "zoe@example.com"}); !errors.Is(err, domain.ErrUserAlreadyExists) {
+		t.Errorf("Create() duplicate email error = %v, want %v", err, domain.ErrUserAlreadyExists)
+	}
+
+	if got, err := repo.GetByEmail(ctx, "zoe@example.com"); err != nil || got.ID != zoe.ID || got.PasswordHash != "hash" {
+		t.Errorf("GetByEmail() = %+v, %v, want %s with its password hash", got, err, zoe.ID)
+	}
+	if got, err := repo.GetByID(ctx, adam.ID); err != nil || got.EmployeeID != "employee-1" {
+		t.Errorf("GetByID() = %+v, %v, want %s", got, err, adam.ID)
+	}
+	if _, err := repo.GetByID(ctx, "missing"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("GetByID() missing error = %v, want %v", err, domain.ErrUserNotFound)
+	}
+
+	all, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if len(all) != 2 || all[0].ID != adam.ID || all[1].ID != zoe.ID {
+		t.Errorf("GetAll() = %+v, want Adam then Zoe", all)
+	}
+
+	// Updating leaves the password hash alone
+	adam.Role = domain.UserRoleManager
+	adam.Active = false
+	if err := repo.Update(ctx, adam); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := repo.SetPasswordHash(ctx, adam.ID, "new-hash"); err != nil {
+		t.Fatalf("SetPasswordHash() error = %v", err)
+	}
+	if got, _ := repo.GetByID(ctx, adam.ID); got.Role != domain.UserRoleManager || got.Active || got.PasswordHash != "new-hash" {
+		t.Errorf("GetByID() after Update() = %+v", got)
+	}
+	if got, _ := repo.GetByID(ctx, zoe.ID); got.PasswordHash != "hash" {
+		t.Errorf("SetPasswordHash() changed another user's hash to %q", got.PasswordHash)
+	}
+
+	adam.Email = "zoe@example.com"
+	if err := repo.Update(ctx, adam); !errors.Is(err, domain.ErrUserAlreadyExists) {
+		t.Errorf("Update() duplicate email error = %v, want %v", err, domain.ErrUserAlreadyExists)
+	}
+	if err := repo.Update(ctx, &domain.User{ID: "missing", Email: "missing@example.com"}); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Update() missing error = %v, want %v", err, domain.ErrUserNotFound)
+	}
+	if err := repo.SetPasswordHash(ctx, "missing", "hash"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("SetPasswordHash() missing error = %v, want %v", err, domain.ErrUserNotFound)
+	}
+}
+
+func testSessions(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	repo := repos.Sessions
+	now := time.Now()
+
+	first := &domain.Session{Token: "token-1", TokenHash: "hash-1", UserID: "user-1", CSRFToken: "csrf-1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	second := &domain.Session{TokenHash: "hash-2", UserID: "user-1", CSRFToken: "csrf-2", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	other := &domain.Session{TokenHash: "hash-3", UserID: "user-2", CSRFToken: "csrf-3", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	for _, session := range []*domain.Session{first, second, other} {
+		if err := repo.Create(ctx, session); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	got, err := repo.GetByTokenHash(ctx, "hash-1")
+	if err != nil {
+		t.Fatalf("GetByTokenHash() error = %v", err)
+	}
+	if got.UserID != "user-1" || got.CSRFToken != "csrf-1" || got.Token != "" || got.ExpiresAt.Sub(first.ExpiresAt).Abs() > time.Millisecond {
+		t.Errorf("GetByTokenHash() = %+v, want the session without its token", got)
+	}
+	if _, err := repo.GetByTokenHash(ctx, "missing"); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("GetByTokenHash() missing error = %v, want %v", err, domain.ErrSessionNotFound)
+	}
+
+	if err := repo.Delete(ctx, "hash-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.GetByTokenHash(ctx, "hash-1"); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("GetByTokenHash() after Delete() error = %v, want %v", err, domain.ErrSessionNotFound)
+	}
+
+	if err := repo.DeleteByUser(ctx, "user-1"); err != nil {
+		t.Fatalf("DeleteByUser() error = %v", err)
+	}
+	if _, err := repo.GetByTokenHash(ctx, "hash-2"); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("GetByTokenHash() after DeleteByUser() error = %v, want %v", err, domain.ErrSessionNotFound)
+	}
+	if _, err := repo.GetByTokenHash(ctx, "hash-3"); err != nil {
+		t.Errorf("DeleteByUser() removed another user's session: %v", err)
+	}
+}
+
+func testShiftSwaps(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	repo := repos.ShiftSwaps
+
+	shift := domain.ShiftAssignment{ID: "assignment-1", EmployeeID: "employee-1", Date: day(6), ShiftType: "morning"}
+	first := &domain.ShiftSwap{ScheduleID: "schedule-1", Kind: domain.ShiftSwapKindGiveaway, Status: domain.ShiftSwapStatusOpen, Shift: shift}
+	second := &domain.ShiftSwap{ScheduleID: "schedule-1", Kind: domain.ShiftSwapKindSwap, Status: domain.ShiftSwapStatusOpen, Shift: shift}
+	other := &domain.ShiftSwap{ScheduleID: "schedule-2", Kind: domain.ShiftSwapKindGiveaway, Status: domain.ShiftSwapStatusOpen, Shift: shift}
+	for _, swap := range []*domain.ShiftSwap{first, second, other} {
+		if err := repo.Create(ctx, swap); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		time.Sleep(2 * time.Millisecond) // distinct creation times
+	}
+
+	got, err := repo.GetByID(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.Shift.ID != "assignment-1" || !got.Shift.Date.Equal(day(6)) || got.ReturnShift != nil || got.ClaimedAt != nil {
+		t.Errorf("GetByID() = %+v, want the offered shift", got)
+	}
+	if _, err := repo.GetByID(ctx, "missing"); !errors.Is(err, domain.ErrShiftSwapNotFound) {
+		t.Errorf("GetByID() missing error = %v, want %v", err, domain.ErrShiftSwapNotFound)
+	}
+
+	if swaps, err := repo.GetBySchedule(ctx, "schedule-1"); err != nil || len(swaps) != 2 || swaps[0].ID != first.ID || swaps[1].ID != second.ID {
+		t.Errorf("GetBySchedule() = %+v, %v, want both swaps of the schedule, oldest first", swaps, err)
+	}
+
+	claimedAt := time.Now()
+	second.Status = domain.ShiftSwapStatusClaimed
+	second.ClaimedBy = "employee-2"
+	second.ClaimantName = "Jane"
+	second.ReturnShift = &domain.ShiftAssignment{ID: "assignment-2", EmployeeID: "employee-2", Date: day(7), ShiftType: "evening"}
+	second.ClaimedAt = &claimedAt
+	if err := repo.Update(ctx, second); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, _ = repo.GetByID(ctx, second.ID)
+	if got.Status != domain.ShiftSwapStatusClaimed || got.ClaimedBy != "employee-2" || got.ReturnShift == nil || got.ReturnShift.ID != "assignment-2" || got.ClaimedAt == nil {
+		t.Errorf("GetByID() after Update() = %+v", got)
+	}
+	if err := repo.Update(ctx, &domain.ShiftSwap{ID: "missing"}); !errors.Is(err, domain.ErrShiftSwapNotFound) {
+		t.Errorf("Update() missing error = %v, want %v", err, domain.ErrShiftSwapNotFound)
+	}
+
+	if swaps, err := repo.GetByStatus(ctx, domain.ShiftSwapStatusClaimed); err != nil || len(swaps) != 1 || swaps[0].ID != second.ID {
+		t.Errorf("GetByStatus(claimed) = %+v, %v, want the claimed swap", swaps, err)
+	}
+	if swaps, err := repo.GetByStatus(ctx, domain.ShiftSwapStatusOpen, domain.ShiftSwapStatusClaimed); err != nil || len(swaps) != 3 {
+		t.Errorf("GetByStatus(open, claimed) = %d swaps, %v, want 3", len(swaps), err)
+	}
+}
+
+func testNotifications(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	repo := repos.Notifications
+
+	for _, message := range []string{"first", "second", "third"} {
+		if err := repo.Create(ctx, &domain.Notification{EmployeeID: "employee-1", Message: message}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		time.Sleep(2 * time.Millisecond) // distinct creation times
+	}
+	if err := repo.Create(ctx, &domain.Notification{EmployeeID: "employee-2", Message: "other"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, err := repo.GetByEmployee(ctx, "employee-1", 2)
+	if err != nil {
+		t.Fatalf("GetByEmployee() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Message != "third" || got[1].Message != "second" || got[0].ReadAt != nil {
+		t.Errorf("GetByEmployee() = %+v, want the two newest unread notifications", got)
+	}
+
+	if err := repo.MarkRead(ctx, "employee-1"); err != nil {
+		t.Fatalf("MarkRead() error = %v", err)
+	}
+	got, _ = repo.GetByEmployee(ctx, "employee-1", 10)
+	for _, notification := range got {
+		if notification.ReadAt == nil {
+			t.Errorf("%q is unread after MarkRead()", notification.Message)
+		}
+	}
+	if len(got) != 3 {
+		t.Errorf("GetByEmployee() = %d notifications, want 3", len(got))
+	}
+	if other, _ := repo.GetByEmployee(ctx, "employee-2", 10); len(other) != 1 || other[0].ReadAt != nil {
+		t.Errorf("MarkRead() changed another employee's notifications: %+v", other)
+	}
+}
+
+func testLeaveRequests(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	repo := repos.LeaveRequests
+
+	january := &domain.LeaveRequest{EmployeeID: "employee-1", EmployeeName: "John", Type: domain.LeaveTypeVacation, StartDate: day(6), EndDate: day(10), Days: 5, Status: domain.LeaveStatusPending}
+	march := &domain.LeaveRequest{EmployeeID: "employee-1", EmployeeName: "John", Type: domain.LeaveTypeSick, StartDate: day(6).AddDate(0, 2, 0), EndDate: day(7).AddDate(0, 2, 0), Days: 1.5, Status: domain.LeaveStatusPending}
+	other := &domain.LeaveRequest{EmployeeID: "employee-2", EmployeeName: "Jane", Type: domain.LeaveTypeVacation, StartDate: day(1), EndDate: day(2), Days: 2, Status: domain.LeaveStatusApproved}
+	for _, request := range []*domain.LeaveRequest{january, march, other} {
+		if err := repo.Create(ctx, request); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	got, err := repo.GetByID(ctx, march.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.Days != 1.5 || !got.StartDate.Equal(march.StartDate) || got.Status != domain.LeaveStatusPending || got.DecidedAt != nil {
+		t.Errorf("GetByID() = %+v, want %+v", got, march)
+	}
+	if _, err := repo.GetByID(ctx, "missing"); !errors.Is(err, domain.ErrLeaveNotFound) {
+		t.Errorf("GetByID() missing error = %v, want %v", err, domain.ErrLeaveNotFound)
+	}
+
+	// An employee's requests are listed newest first, requests by status oldest first
+	if requests, err := repo.GetByEmployee(ctx, "employee-1"); err != nil || len(requests) != 2 || requests[0].ID != march.ID || requests[1].ID != january.ID {
+		t.Errorf("GetByEmployee() = %+v, %v, want March then January", requests, err)
+	}
+	if requests, err := repo.GetByStatus(ctx, domain.LeaveStatusPending, domain.LeaveStatusApproved); err != nil || len(requests) != 3 || requests[0].ID != other.ID || requests[2].ID != march.ID {
+		t.Errorf("GetByStatus() = %+v, %v, want all three by start date", requests, err)
+	}
+
+	decidedAt := time.Now()
+	january.Status = domain.LeaveStatusApproved
+	january.DecidedBy = "manager@example.com"
+	january.DecidedAt = &decidedAt
+	january.Conflicts = []domain.LeaveConflict{{ScheduleID: "schedule-1", AssignmentID: "assignment-1", Date: day(7), ShiftType: "morning"}}
+	if err := repo.Update(ctx, january); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, _ = repo.GetByID(ctx, january.ID)
+	if got.Status != domain.LeaveStatusApproved || got.DecidedBy != "manager@example.com" || got.DecidedAt == nil || len(got.Conflicts) != 1 || !got.Conflicts[0].Date.Equal(day(7)) {
+		t.Errorf("GetByID() after Update() = %+v", got)
+	}
+	if err := repo.Update(ctx, &domain.LeaveRequest{ID: "missing"}); !errors.Is(err, domain.ErrLeaveNotFound) {
+		t.Errorf("Update() missing error = %v, want %v", err, domain.ErrLeaveNotFound)
+	}
+
+	if requests, err := repo.GetByStatus(ctx, domain.LeaveStatusPending); err != nil || len(requests) != 1 || requests[0].ID != march.ID {
+		t.Errorf("GetByStatus(pending) = %+v, %v, want the March request", requests, err)
+	}
+}
+
+func testAudit(t *testing.T, repos *repository.Repositories) {
+	ctx := context.Background()
+	repo := repos.Audit
+
+	at := func(d int) time.Time { return day(d).Add(9 * time.Hour) }
+	entries := []*domain.AuditEntry{
+		{Timestamp: at(1), Actor: "admin@example.com", EntityType: domain.AuditEntityEmployee, EntityID: "employee-1", EntityName: "Jane", Action: domain.AuditActionCreate,
+			Changes: []domain.FieldChange{{Field: "name", After: "Jane"}}},
+		{Timestamp: at(2), Actor: "manager@example.com", EntityType: domain.AuditEntitySchedule, EntityID: "schedule-1", Action: domain.AuditActionRemoveAssignment,
+			Changes: []domain.FieldChange{{Field: "assignment", Before: "Jane, Morning on Sat 2025-01-04"}}},
+		{Timestamp: at(3), Actor: "admin@example.com", EntityType: domain.AuditEntityEmployee, EntityID: "employee-1", EntityName: "Jane", Action: domain.AuditActionUpdate},
+		{Timestamp: at(4), Actor: "admin@example.com", EntityType: domain.AuditEntityEmployee, EntityID: "employee-2", EntityName: "John", Action: domain.AuditActionCreate},
+	}
+	for _, entry := range entries {
+		if err := repo.Append(ctx, entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+		if entry.ID == "" {
+			t.Fatal("Append() did not set the ID")
+		}
+	}
+	if err := repo.Append(ctx, &domain.AuditEntry{Actor: "system", EntityType: domain.AuditEntityCompanyConfig, Action: domain.AuditActionUpdate}); err != nil {
+		t.Fatalf("Append() without timestamp error = %v", err)
+	}
+
+	ids := func(entries []domain.AuditEntry) []string {
+		var ids []string
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		return ids
+	}
+
+	all, err := repo.List(ctx, domain.AuditFilter{Until: day(10)})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if want := []string{entries[3].ID, entries[2].ID, entries[1].ID, entries[0].ID}; !slices.Equal(ids(all), want) {
+		t.Errorf("List() = %v, want %v, newest first", ids(all), want)
+	}
+	if got := all[3]; got.Actor != "admin@example.com" || got.EntityName != "Jane" || !got.Timestamp.Equal(at(1)) ||
+		len(got.Changes) != 1 || got.Changes[0] != (domain.FieldChange{Field: "name", After: "Jane"}) {
+		t.Errorf("List() entry = %+v, want the appended one", got)
+	}
+
+	for name, tt := range map[string]struct {
+		filter domain.AuditFilter
+		want   []string
+	}{
+		"entity": {domain.AuditFilter{EntityType: domain.AuditEntityEmployee, EntityID: "employee-1"}, []string{entries[2].ID, entries[0].ID}},
+		"actor":  {domain.AuditFilter{Actor: "manager@example.com"}, []string{entries[1].ID}},
+		"action": {domain.AuditFilter{Action: domain.AuditActionCreate}, []string{entries[3].ID, entries[0].ID}},
+		"period": {domain.AuditFilter{Since: at(2), Until: at(4)}, []string{entries[2].ID, entries[1].ID}},
+		"limit":  {domain.AuditFilter{EntityType: domain.AuditEntityEmployee, Limit: 2}, []string{entries[3].ID, entries[2].ID}},
+	} {
+		got, err := repo.List(ctx, tt.filter)
+		if err != nil || !slices.Equal(ids(got), tt.want) {
+			t.Errorf("List(%s) = %v, %v, want %v", name, ids(got), err, tt.want)
+		}
+	}
+
+	deleted, err := repo.DeleteBefore(ctx, at(3))
+	if err != nil || deleted != 2 {
+		t.Errorf("DeleteBefore() = %d, %v, want 2", deleted, err)
+	}
+	if got, _ := repo.List(ctx, domain.AuditFilter{Until: day(10)}); !slices.Equal(ids(got), []string{entries[3].ID, entries[2].ID}) {
+		t.Errorf("List() after DeleteBefore() = %v, want the two newest", ids(got))
+	}
+	if got, _ := repo.List(ctx, domain.AuditFilter{EntityType: domain.AuditEntityCompanyConfig}); len(got) != 1 || got[0].Timestamp.IsZero() {
+		t.Errorf("List(company config) = %+v, want the entry stamped when appended", got)
+	}
+}
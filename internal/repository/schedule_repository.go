@@ -21,12 +21,15 @@ type ScheduleRepository interface {
 	// GetByPeriod retrieves schedules for a specific period
 	GetByPeriod(ctx context.Context, start, end time.Time) ([]domain.Schedule, error)
 
-	// Update updates an existing schedule
+	// Update updates an existing schedule if it is still at schedule.Version,
+	// and increments the version. It returns domain.ErrConflict otherwise.
 	Update(ctx context.Context, schedule *domain.Schedule) error
 
 	// Delete deletes a schedule
 	Delete(ctx context.Context, id string) error
 
-	// MarkAsSent marks a schedule as sent to n8n
+	// MarkAsSent records that a schedule was sent to n8n without changing its
+	// status. It increments the version, so that an update made on the schedule
+	// as it was before the delivery conflicts instead of clearing the flag.
 	MarkAsSent(ctx context.Context, id string) error
 }
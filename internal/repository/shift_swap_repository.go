@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// ShiftSwapRepository defines the interface for shift swap data operations
+type ShiftSwapRepository interface {
+	// Create creates a new shift swap
+	Create(ctx context.Context, swap *domain.ShiftSwap) error
+
+	// GetByID retrieves a shift swap by ID
+	GetByID(ctx context.Context, id string) (*domain.ShiftSwap, error)
+
+	// GetBySchedule retrieves every shift swap of a schedule, oldest first
+	GetBySchedule(ctx context.Context, scheduleID string) ([]domain.ShiftSwap, error)
+
+	// GetByStatus retrieves the shift swaps with any of the given statuses, oldest first
+	GetByStatus(ctx context.Context, statuses ...string) ([]domain.ShiftSwap, error)
+
+	// Update updates an existing shift swap
+	Update(ctx context.Context, swap *domain.ShiftSwap) error
+}
+
+// NotificationRepository defines the interface for notification data operations
+type NotificationRepository interface {
+	// Create stores a new notification
+	Create(ctx context.Context, notification *domain.Notification) error
+
+	// GetByEmployee retrieves an employee's most recent notifications, newest first
+	GetByEmployee(ctx context.Context, employeeID string, limit int) ([]domain.Notification, error)
+
+	// MarkRead marks all of an employee's notifications as read
+	MarkRead(ctx context.Context, employeeID string) error
+}
@@ -0,0 +1,99 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type auditRepository struct {
+	db *DB
+}
+
+// NewAuditRepository creates a new SQL audit repository
+func NewAuditRepository(db *DB) repository.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	_, err := r.db.exec(ctx, `INSERT INTO audit_log (id, recorded_at, actor, entity_type, entity_id, entity_name, action, changes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID, r.db.timeValue(entry.Timestamp), entry.Actor, entry.EntityType, entry.EntityID, entry.EntityName,
+		entry.Action, jsonArg{entry.Changes})
+	return err
+}
+
+func (r *auditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	var where []string
+	var args []any
+	for _, condition := range []struct {
+		column, value string
+	}{
+		{"entity_type", filter.EntityType},
+		{"entity_id", filter.EntityID},
+		{"actor", filter.Actor},
+		{"action", filter.Action},
+	} {
+		if condition.value != "" {
+			where = append(where, condition.column+" = ?")
+			args = append(args, condition.value)
+		}
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "recorded_at >= ?")
+		args = append(args, r.db.timeValue(filter.Since))
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "recorded_at < ?")
+		args = append(args, r.db.timeValue(filter.Until))
+	}
+
+	query := "SELECT id, recorded_at, actor, entity_type, entity_id, entity_name, action, changes FROM audit_log"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY recorded_at DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.AuditEntry{}
+	for rows.Next() {
+		var entry domain.AuditEntry
+		if err := rows.Scan(&entry.ID, timeColumn{&entry.Timestamp}, &entry.Actor, &entry.EntityType, &entry.EntityID,
+			&entry.EntityName, &entry.Action, jsonColumn{&entry.Changes}); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *auditRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	result, err := r.db.exec(ctx, "DELETE FROM audit_log WHERE recorded_at < ?", r.db.timeValue(cutoff))
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := result.RowsAffected()
+	return int(n), err
+}
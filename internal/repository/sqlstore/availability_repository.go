@@ -0,0 +1,149 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+const availabilityColumns = "id, employee_id, start_date, end_date, type, reason, shift_types, status, leave_request_id, recurrence, start_time, end_time, created_at, updated_at"
+
+type availabilityRepository struct {
+	db *DB
+}
+
+// NewAvailabilityRepository creates a new SQL availability repository
+func NewAvailabilityRepository(db *DB) repository.AvailabilityRepository {
+	return &availabilityRepository{db: db}
+}
+
+func (r *availabilityRepository) Create(ctx context.Context, availability *domain.Availability) error {
+	if availability.ID == "" {
+		availability.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	availability.CreatedAt = now
+	availability.UpdatedAt = now
+
+	_, err := r.db.exec(ctx, "INSERT INTO availability ("+availabilityColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		availability.ID, availability.EmployeeID, r.db.timeValue(availability.StartDate), r.db.timeValue(availability.EndDate),
+		availability.Type, availability.Reason, jsonArg{availability.ShiftTypes}, availability.Status, availability.LeaveRequestID,
+		jsonArg{availability.Recurrence}, availability.StartTime, availability.EndTime,
+		r.db.timeValue(availability.CreatedAt), r.db.timeValue(availability.UpdatedAt))
+	return err
+}
+
+func (r *availabilityRepository) GetByID(ctx context.Context, id string) (*domain.Availability, error) {
+	availability, err := scanAvailability(r.db.queryRow(ctx, "SELECT "+availabilityColumns+" FROM availability WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrAvailabilityNotFound
+		}
+		return nil, err
+	}
+
+	return &availability, nil
+}
+
+func (r *availabilityRepository) GetByEmployee(ctx context.Context, employeeID string) ([]domain.Availability, error) {
+	return r.find(ctx, "employee_id = ?", employeeID)
+}
+
+func (r *availabilityRepository) GetByStatus(ctx context.Context, statuses ...string) ([]domain.Availability, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+
+	return r.find(ctx, "status IN ("+placeholders(len(statuses))+")", stringArgs(statuses)...)
+}
+
+func (r *availabilityRepository) GetByPeriod(ctx context.Context, start, end time.Time) ([]domain.Availability, error) {
+	// Periods are compared by calendar day, so the query leaves a day of slack
+	// on both sides and the exact check is made on the results
+	candidates, err := r.find(ctx, "start_date <= ? AND (end_date >= ? OR end_date = ?)",
+		r.db.timeValue(end.AddDate(0, 0, 1)), r.db.timeValue(start.AddDate(0, 0, -1)), r.db.timeValue(time.Time{}))
+	if err != nil {
+		return nil, err
+	}
+
+	var availability []domain.Availability
+	for _, avail := range candidates {
+		if avail.OverlapsPeriod(start, end) {
+			availability = append(availability, avail)
+		}
+	}
+	return availability, nil
+}
+
+// forEmployees retrieves the availability periods of the given employees,
+// grouped by employee ID
+func (r *availabilityRepository) forEmployees(ctx context.Context, employeeIDs []string) (map[string][]domain.Availability, error) {
+	availability, err := r.find(ctx, "employee_id IN ("+placeholders(len(employeeIDs))+")", stringArgs(employeeIDs)...)
+	if err != nil {
+		return nil, err
+	}
+
+	byEmployee := make(map[string][]domain.Availability)
+	for _, avail := range availability {
+		byEmployee[avail.EmployeeID] = append(byEmployee[avail.EmployeeID], avail)
+	}
+	return byEmployee, nil
+}
+
+func (r *availabilityRepository) find(ctx context.Context, where string, args ...any) ([]domain.Availability, error) {
+	rows, err := r.db.query(ctx, "SELECT "+availabilityColumns+" FROM availability WHERE "+where+" ORDER BY start_date, created_at", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var availability []domain.Availability
+	for rows.Next() {
+		avail, err := scanAvailability(rows)
+		if err != nil {
+			return nil, err
+		}
+		availability = append(availability, avail)
+	}
+
+	return availability, rows.Err()
+}
+
+func (r *availabilityRepository) Update(ctx context.Context, availability *domain.Availability) error {
+	availability.UpdatedAt = time.Now()
+
+	result, err := r.db.exec(ctx, `UPDATE availability SET start_date = ?, end_date = ?, type = ?, reason = ?, shift_types = ?,
+		status = ?, recurrence = ?, start_time = ?, end_time = ?, updated_at = ? WHERE id = ?`,
+		r.db.timeValue(availability.StartDate), r.db.timeValue(availability.EndDate), availability.Type, availability.Reason,
+		jsonArg{availability.ShiftTypes}, availability.Status, jsonArg{availability.Recurrence}, availability.StartTime,
+		availability.EndTime, r.db.timeValue(availability.UpdatedAt), availability.ID)
+	if err != nil {
+		return err
+	}
+
+	return matched(result, domain.ErrAvailabilityNotFound)
+}
+
+func (r *availabilityRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.exec(ctx, "DELETE FROM availability WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	return matched(result, domain.ErrAvailabilityNotFound)
+}
+
+func scanAvailability(row scanner) (domain.Availability, error) {
+	var avail domain.Availability
+
+	err := row.Scan(&avail.ID, &avail.EmployeeID, timeColumn{&avail.StartDate}, timeColumn{&avail.EndDate}, &avail.Type,
+		&avail.Reason, jsonColumn{&avail.ShiftTypes}, &avail.Status, &avail.LeaveRequestID, jsonColumn{&avail.Recurrence},
+		&avail.StartTime, &avail.EndTime, timeColumn{&avail.CreatedAt}, timeColumn{&avail.UpdatedAt})
+	return avail, err
+}
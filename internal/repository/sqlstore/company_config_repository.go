@@ -0,0 +1,110 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type companyConfigRepository struct {
+	db *DB
+}
+
+// NewCompanyConfigRepository creates a new SQL company configuration
+// repository. The configuration is a single row holding it as JSON.
+func NewCompanyConfigRepository(db *DB) repository.CompanyConfigRepository {
+	return &companyConfigRepository{db: db}
+}
+
+// Get retrieves the company configuration
+func (r *companyConfigRepository) Get(ctx context.Context) (*domain.CompanyConfig, error) {
+	var config domain.CompanyConfig
+	var id string
+	var version int
+	var createdAt, updatedAt time.Time
+
+	err := r.db.queryRow(ctx, "SELECT id, config, version, created_at, updated_at FROM company_config").
+		Scan(&id, jsonColumn{&config}, &version, timeColumn{&createdAt}, timeColumn{&updatedAt})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrCompanyConfigNotFound
+		}
+		return nil, err
+	}
+
+	config.ID = id
+	config.Version = version
+	config.CreatedAt = createdAt
+	config.UpdatedAt = updatedAt
+	return &config, nil
+}
+
+// Create creates a new company configuration
+func (r *companyConfigRepository) Create(ctx context.Context, config *domain.CompanyConfig) error {
+	existing, err := r.Get(ctx)
+	if err != nil && err != domain.ErrCompanyConfigNotFound {
+		return err
+	}
+	if existing != nil {
+		return domain.ErrCompanyConfigAlreadyExists
+	}
+
+	config.ID = uuid.New().String()
+	config.CreatedAt = time.Now()
+	config.UpdatedAt = config.CreatedAt
+	config.Version = 1
+
+	_, err = r.db.exec(ctx, "INSERT INTO company_config (id, config, version, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		config.ID, jsonArg{config}, config.Version, r.db.timeValue(config.CreatedAt), r.db.timeValue(config.UpdatedAt))
+	return err
+}
+
+// Update updates the company configuration (upsert if doesn't exist)
+func (r *companyConfigRepository) Update(ctx context.Context, config *domain.CompanyConfig) error {
+	existing, err := r.Get(ctx)
+	if err != nil && err != domain.ErrCompanyConfigNotFound {
+		return err
+	}
+	if existing == nil {
+		return r.Create(ctx, config)
+	}
+
+	config.ID = existing.ID
+	config.CreatedAt = existing.CreatedAt
+	config.UpdatedAt = time.Now()
+
+	result, err := r.db.exec(ctx, "UPDATE company_config SET config = ?, version = version + 1, updated_at = ? WHERE id = ? AND version = ?",
+		jsonArg{config}, r.db.timeValue(config.UpdatedAt), config.ID, config.Version)
+	if err != nil {
+		return err
+	}
+
+	if err := r.db.matchedVersion(ctx, result, "company_config", config.ID, domain.ErrCompanyConfigNotFound); err != nil {
+		return err
+	}
+	config.Version++
+	return nil
+}
+
+// GetOrCreate retrieves the config or creates a default one if it doesn't exist
+func (r *companyConfigRepository) GetOrCreate(ctx context.Context) (*domain.CompanyConfig, error) {
+	config, err := r.Get(ctx)
+	if err == nil {
+		return config, nil
+	}
+
+	if err != domain.ErrCompanyConfigNotFound {
+		return nil, err
+	}
+
+	config = domain.DefaultCompanyConfig()
+	if err := r.Create(ctx, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
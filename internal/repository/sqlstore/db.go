@@ -0,0 +1,389 @@
+// Package sqlstore implements the repositories on SQLite or PostgreSQL. The
+// schema is created and upgraded by migrations embedded in the binary.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the pgx driver
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Drivers
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+// DB is a connection to a SQLite or PostgreSQL database
+type DB struct {
+	db     *sql.DB
+	driver string
+}
+
+// Open connects to the database and applies any migrations it is missing.
+// dsn is the SQLite database file or the PostgreSQL connection URL.
+func Open(ctx context.Context, driver, dsn string) (*DB, error) {
+	var db *sql.DB
+	var err error
+
+	switch driver {
+	case DriverSQLite:
+		db, err = sql.Open("sqlite", dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+		}
+		// SQLite allows one writer at a time, and every connection to an
+		// in-memory database would open a database of its own
+		db.SetMaxOpenConns(1)
+	case DriverPostgres:
+		db, err = sql.Open("pgx", dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open PostgreSQL database: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown SQL driver %q", driver)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to the database: %w", err)
+	}
+
+	store := &DB{db: db, driver: driver}
+	if err := store.migrate(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate the database: %w", err)
+	}
+
+	return store, nil
+}
+
+// Close closes the database connection
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// migrate applies the embedded migrations of the driver that have not been
+// applied yet, in the order of their file names, each in a transaction
+func (d *DB) migrate(ctx context.Context) error {
+	if _, err := d.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version TEXT PRIMARY KEY,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return err
+	}
+
+	applied := make(map[string]bool)
+	rows, err := d.db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	dir := path.Join("migrations", d.driver)
+	entries, err := fs.ReadDir(migrations, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		version := strings.TrimSuffix(entry.Name(), ".sql")
+		if applied[version] {
+			continue
+		}
+
+		script, err := fs.ReadFile(migrations, path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := d.applyMigration(ctx, version, string(script)); err != nil {
+			return fmt.Errorf("migration %s: %w", version, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *DB) applyMigration(ctx context.Context, version, script string) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range strings.Split(script, ";\n") {
+		if strings.TrimSpace(stripComments(statement)) == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, d.rebind("INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)"),
+		version, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// stripComments removes the SQL line comments of a statement
+func stripComments(statement string) string {
+	lines := strings.Split(statement, "\n")
+	lines = slices.DeleteFunc(lines, func(line string) bool {
+		return strings.HasPrefix(strings.TrimSpace(line), "--")
+	})
+	return strings.Join(lines, "\n")
+}
+
+func (d *DB) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return d.db.ExecContext(ctx, d.rebind(query), args...)
+}
+
+func (d *DB) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return d.db.QueryContext(ctx, d.rebind(query), args...)
+}
+
+func (d *DB) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return d.db.QueryRowContext(ctx, d.rebind(query), args...)
+}
+
+// rebind replaces the ? placeholders of a query with the numbered ones
+// PostgreSQL expects
+func (d *DB) rebind(query string) string {
+	if d.driver != DriverPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// placeholders returns n comma-separated ? placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// stringArgs converts strings to query arguments
+func stringArgs(values []string) []any {
+	args := make([]any, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
+
+// isUniqueViolation reports whether err is a violated unique constraint
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505"
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
+
+// matched returns notFound when a statement matched no row
+func matched(result sql.Result, notFound error) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// matchedVersion explains a versioned update of the row of table with the
+// id: notFound if there is no such row, and domain.ErrConflict if the update
+// matched nothing because the row is at another version
+func (d *DB) matchedVersion(ctx context.Context, result sql.Result, table, id string, notFound error) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := d.queryRow(ctx, "SELECT EXISTS (SELECT 1 FROM "+table+" WHERE id = ?)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return notFound
+	}
+	return domain.ErrConflict
+}
+
+// timeFormat is how times are written to SQLite: in UTC with a fixed number
+// of digits, so that comparing the text compares the times
+const timeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// timeValue converts a time to a query argument
+func (d *DB) timeValue(t time.Time) any {
+	if d.driver == DriverSQLite {
+		return t.UTC().Format(timeFormat)
+	}
+	return t.UTC()
+}
+
+// nullTimeValue converts an optional time to a query argument
+func (d *DB) nullTimeValue(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return d.timeValue(*t)
+}
+
+// timeColumn scans a time column, as a time in UTC like MongoDB returns them
+type timeColumn struct {
+	t *time.Time
+}
+
+func (c timeColumn) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		*c.t = v.UTC()
+	case string:
+		return c.parse(v)
+	case []byte:
+		return c.parse(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into a time", src)
+	}
+	return nil
+}
+
+func (c timeColumn) parse(value string) error {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return err
+	}
+	*c.t = t.UTC()
+	return nil
+}
+
+// nullTimeColumn scans an optional time column
+type nullTimeColumn struct {
+	t **time.Time
+}
+
+func (c nullTimeColumn) Scan(src any) error {
+	if src == nil {
+		*c.t = nil
+		return nil
+	}
+	var t time.Time
+	if err := (timeColumn{&t}).Scan(src); err != nil {
+		return err
+	}
+	*c.t = &t
+	return nil
+}
+
+// jsonArg is a query argument for a JSON column
+type jsonArg struct {
+	v any
+}
+
+func (a jsonArg) Value() (driver.Value, error) {
+	data, err := json.Marshal(a.v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// jsonColumn scans a JSON column into the value v points to
+type jsonColumn struct {
+	v any
+}
+
+func (c jsonColumn) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), c.v)
+	case []byte:
+		return json.Unmarshal(v, c.v)
+	default:
+		return fmt.Errorf("cannot scan %T into JSON", src)
+	}
+}
+
+// nullString converts an empty string to NULL, for unique columns that only
+// some rows have a value for
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// scanner is a *sql.Row or *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// NewRepositories creates every repository on db
+func NewRepositories(db *DB) *repository.Repositories {
+	return &repository.Repositories{
+		Employees:     NewEmployeeRepository(db),
+		Availability:  NewAvailabilityRepository(db),
+		Schedules:     NewScheduleRepository(db),
+		CompanyConfig: NewCompanyConfigRepository(db),
+		Users:         NewUserRepository(db),
+		Sessions:      NewSessionRepository(db),
+		ShiftSwaps:    NewShiftSwapRepository(db),
+		Notifications: NewNotificationRepository(db),
+		LeaveRequests: NewLeaveRepository(db),
+		Audit:         NewAuditRepository(db),
+	}
+}
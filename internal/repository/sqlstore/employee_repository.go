@@ -0,0 +1,190 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+const employeeColumns = "id, name, email, role, role_description, monthly_hours, active, skills, leave_allowances, calendar_token, version, created_at, updated_at"
+
+type employeeRepository struct {
+	db           *DB
+	availability *availabilityRepository
+}
+
+// NewEmployeeRepository creates a new SQL employee repository. Employees are
+// loaded with their availability from the availability table.
+func NewEmployeeRepository(db *DB) repository.EmployeeRepository {
+	return &employeeRepository{
+		db:           db,
+		availability: &availabilityRepository{db: db},
+	}
+}
+
+func (r *employeeRepository) Create(ctx context.Context, employee *domain.Employee) error {
+	if employee.ID == "" {
+		employee.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	employee.CreatedAt = now
+	employee.UpdatedAt = now
+	employee.Active = true
+	employee.Version = 1
+
+	// Availability is stored in its own table
+	_, err := r.db.exec(ctx, "INSERT INTO employees ("+employeeColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		employee.ID, employee.Name, employee.Email, employee.Role, employee.RoleDescription, employee.MonthlyHours,
+		employee.Active, jsonArg{employee.Skills}, jsonArg{employee.LeaveAllowances}, nullString(employee.CalendarToken),
+		employee.Version, r.db.timeValue(employee.CreatedAt), r.db.timeValue(employee.UpdatedAt))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrEmployeeAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *employeeRepository) GetByID(ctx context.Context, id string) (*domain.Employee, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+func (r *employeeRepository) GetAll(ctx context.Context) ([]domain.Employee, error) {
+	return r.find(ctx, "SELECT "+employeeColumns+" FROM employees ORDER BY name, created_at")
+}
+
+func (r *employeeRepository) GetActive(ctx context.Context) ([]domain.Employee, error) {
+	return r.find(ctx, "SELECT "+employeeColumns+" FROM employees WHERE active = ? ORDER BY name, created_at", true)
+}
+
+func (r *employeeRepository) Update(ctx context.Context, employee *domain.Employee) error {
+	employee.UpdatedAt = time.Now()
+
+	result, err := r.db.exec(ctx, `UPDATE employees SET name = ?, email = ?, role = ?, role_description = ?, monthly_hours = ?,
+		skills = ?, leave_allowances = ?, active = ?, version = version + 1, updated_at = ? WHERE id = ? AND version = ?`,
+		employee.Name, employee.Email, employee.Role, employee.RoleDescription, employee.MonthlyHours,
+		jsonArg{employee.Skills}, jsonArg{employee.LeaveAllowances}, employee.Active, r.db.timeValue(employee.UpdatedAt),
+		employee.ID, employee.Version)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrEmployeeAlreadyExists
+		}
+		return err
+	}
+
+	if err := r.db.matchedVersion(ctx, result, "employees", employee.ID, domain.ErrEmployeeNotFound); err != nil {
+		return err
+	}
+	employee.Version++
+	return nil
+}
+
+func (r *employeeRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.exec(ctx, "UPDATE employees SET active = ?, version = version + 1, updated_at = ? WHERE id = ?",
+		false, r.db.timeValue(time.Now()), id)
+	if err != nil {
+		return err
+	}
+
+	return matched(result, domain.ErrEmployeeNotFound)
+}
+
+func (r *employeeRepository) GetByEmail(ctx context.Context, email string) (*domain.Employee, error) {
+	return r.findOne(ctx, "email = ?", email)
+}
+
+func (r *employeeRepository) GetByCalendarToken(ctx context.Context, token string) (*domain.Employee, error) {
+	return r.findOne(ctx, "calendar_token = ?", token)
+}
+
+func (r *employeeRepository) SetCalendarToken(ctx context.Context, id, token string) error {
+	result, err := r.db.exec(ctx, "UPDATE employees SET calendar_token = ?, updated_at = ? WHERE id = ?",
+		nullString(token), r.db.timeValue(time.Now()), id)
+	if err != nil {
+		return err
+	}
+
+	return matched(result, domain.ErrEmployeeNotFound)
+}
+
+func (r *employeeRepository) findOne(ctx context.Context, where string, args ...any) (*domain.Employee, error) {
+	employee, err := scanEmployee(r.db.queryRow(ctx, "SELECT "+employeeColumns+" FROM employees WHERE "+where, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+
+	employees := []domain.Employee{employee}
+	if err := r.withAvailability(ctx, employees); err != nil {
+		return nil, err
+	}
+	return &employees[0], nil
+}
+
+func (r *employeeRepository) find(ctx context.Context, query string, args ...any) ([]domain.Employee, error) {
+	rows, err := r.db.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	employees := []domain.Employee{}
+	for rows.Next() {
+		employee, err := scanEmployee(rows)
+		if err != nil {
+			return nil, err
+		}
+		employees = append(employees, employee)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := r.withAvailability(ctx, employees); err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
+
+// withAvailability fills in the employees' availability from the availability table
+func (r *employeeRepository) withAvailability(ctx context.Context, employees []domain.Employee) error {
+	if len(employees) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(employees))
+	for i, employee := range employees {
+		ids[i] = employee.ID
+	}
+
+	byEmployee, err := r.availability.forEmployees(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range employees {
+		employees[i].Availability = byEmployee[employees[i].ID]
+	}
+	return nil
+}
+
+func scanEmployee(row scanner) (domain.Employee, error) {
+	var employee domain.Employee
+	var calendarToken sql.NullString
+
+	err := row.Scan(&employee.ID, &employee.Name, &employee.Email, &employee.Role, &employee.RoleDescription,
+		&employee.MonthlyHours, &employee.Active, jsonColumn{&employee.Skills}, jsonColumn{&employee.LeaveAllowances},
+		&calendarToken, &employee.Version, timeColumn{&employee.CreatedAt}, timeColumn{&employee.UpdatedAt})
+	employee.CalendarToken = calendarToken.String
+	return employee, err
+}
@@ -0,0 +1,104 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+const leaveColumns = "id, employee_id, employee_name, type, start_date, end_date, days, reason, status, conflicts, decided_by, decided_at, created_at, updated_at"
+
+type leaveRepository struct {
+	db *DB
+}
+
+// NewLeaveRepository creates a new SQL leave request repository
+func NewLeaveRepository(db *DB) repository.LeaveRepository {
+	return &leaveRepository{db: db}
+}
+
+func (r *leaveRepository) Create(ctx context.Context, request *domain.LeaveRequest) error {
+	if request.ID == "" {
+		request.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	request.CreatedAt = now
+	request.UpdatedAt = now
+
+	_, err := r.db.exec(ctx, "INSERT INTO leave_requests ("+leaveColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		request.ID, request.EmployeeID, request.EmployeeName, request.Type, r.db.timeValue(request.StartDate),
+		r.db.timeValue(request.EndDate), request.Days, request.Reason, request.Status, jsonArg{request.Conflicts},
+		request.DecidedBy, r.db.nullTimeValue(request.DecidedAt), r.db.timeValue(request.CreatedAt), r.db.timeValue(request.UpdatedAt))
+	return err
+}
+
+func (r *leaveRepository) GetByID(ctx context.Context, id string) (*domain.LeaveRequest, error) {
+	request, err := scanLeaveRequest(r.db.queryRow(ctx, "SELECT "+leaveColumns+" FROM leave_requests WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrLeaveNotFound
+		}
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+func (r *leaveRepository) GetByEmployee(ctx context.Context, employeeID string) ([]domain.LeaveRequest, error) {
+	return r.find(ctx, "employee_id = ? ORDER BY start_date DESC, created_at", employeeID)
+}
+
+func (r *leaveRepository) GetByStatus(ctx context.Context, statuses ...string) ([]domain.LeaveRequest, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+
+	return r.find(ctx, "status IN ("+placeholders(len(statuses))+") ORDER BY start_date, created_at", stringArgs(statuses)...)
+}
+
+func (r *leaveRepository) find(ctx context.Context, where string, args ...any) ([]domain.LeaveRequest, error) {
+	rows, err := r.db.query(ctx, "SELECT "+leaveColumns+" FROM leave_requests WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []domain.LeaveRequest
+	for rows.Next() {
+		request, err := scanLeaveRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+
+	return requests, rows.Err()
+}
+
+func (r *leaveRepository) Update(ctx context.Context, request *domain.LeaveRequest) error {
+	request.UpdatedAt = time.Now()
+
+	result, err := r.db.exec(ctx, "UPDATE leave_requests SET status = ?, conflicts = ?, decided_by = ?, decided_at = ?, updated_at = ? WHERE id = ?",
+		request.Status, jsonArg{request.Conflicts}, request.DecidedBy, r.db.nullTimeValue(request.DecidedAt),
+		r.db.timeValue(request.UpdatedAt), request.ID)
+	if err != nil {
+		return err
+	}
+
+	return matched(result, domain.ErrLeaveNotFound)
+}
+
+func scanLeaveRequest(row scanner) (domain.LeaveRequest, error) {
+	var request domain.LeaveRequest
+
+	err := row.Scan(&request.ID, &request.EmployeeID, &request.EmployeeName, &request.Type, timeColumn{&request.StartDate},
+		timeColumn{&request.EndDate}, &request.Days, &request.Reason, &request.Status, jsonColumn{&request.Conflicts},
+		&request.DecidedBy, nullTimeColumn{&request.DecidedAt}, timeColumn{&request.CreatedAt}, timeColumn{&request.UpdatedAt})
+	return request, err
+}
@@ -0,0 +1,148 @@
+-- Tables mirror the MongoDB collections. Nested values that are only ever
+-- read and written whole are kept in JSONB columns.
+
+CREATE TABLE employees (
+    id               TEXT PRIMARY KEY,
+    name             TEXT NOT NULL,
+    email            TEXT NOT NULL UNIQUE,
+    role             TEXT NOT NULL,
+    role_description TEXT NOT NULL DEFAULT '',
+    monthly_hours    INTEGER NOT NULL,
+    active           BOOLEAN NOT NULL,
+    skills           JSONB NOT NULL DEFAULT 'null',
+    leave_allowances JSONB NOT NULL DEFAULT 'null',
+    calendar_token   TEXT UNIQUE,
+    created_at       TIMESTAMPTZ NOT NULL,
+    updated_at       TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX employees_active ON employees (active);
+
+CREATE TABLE availability (
+    id               TEXT PRIMARY KEY,
+    employee_id      TEXT NOT NULL,
+    start_date       TIMESTAMPTZ NOT NULL,
+    end_date         TIMESTAMPTZ NOT NULL,
+    type             TEXT NOT NULL,
+    reason           TEXT NOT NULL DEFAULT '',
+    shift_types      JSONB NOT NULL DEFAULT 'null',
+    status           TEXT NOT NULL DEFAULT '',
+    leave_request_id TEXT NOT NULL DEFAULT '',
+    recurrence       JSONB NOT NULL DEFAULT 'null',
+    start_time       TEXT NOT NULL DEFAULT '',
+    end_time         TEXT NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL,
+    updated_at       TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX availability_employee ON availability (employee_id, start_date);
+CREATE INDEX availability_period ON availability (start_date, end_date);
+CREATE INDEX availability_status ON availability (status);
+
+CREATE TABLE schedules (
+    id                    TEXT PRIMARY KEY,
+    period_start          TIMESTAMPTZ NOT NULL,
+    period_end            TIMESTAMPTZ NOT NULL,
+    employees             JSONB NOT NULL DEFAULT 'null',
+    assignments           JSONB NOT NULL DEFAULT 'null',
+    understaffed          JSONB NOT NULL DEFAULT 'null',
+    relaxed_constraints   JSONB NOT NULL DEFAULT 'null',
+    strategy              TEXT NOT NULL DEFAULT '',
+    score                 JSONB NOT NULL DEFAULT 'null',
+    timezone              TEXT NOT NULL DEFAULT '',
+    shift_definitions     JSONB NOT NULL DEFAULT 'null',
+    warnings              JSONB NOT NULL DEFAULT 'null',
+    edited_at             TIMESTAMPTZ,
+    status                TEXT NOT NULL,
+    transitions           JSONB NOT NULL DEFAULT 'null',
+    published_assignments JSONB NOT NULL DEFAULT 'null',
+    cancelled_assignments JSONB NOT NULL DEFAULT 'null',
+    sent_to_n8n           BOOLEAN NOT NULL DEFAULT FALSE,
+    sent_at               TIMESTAMPTZ,
+    created_at            TIMESTAMPTZ NOT NULL,
+    updated_at            TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX schedules_period ON schedules (period_start, period_end);
+CREATE INDEX schedules_status ON schedules (status);
+
+-- The company configuration is a single row, stored whole
+CREATE TABLE company_config (
+    id         TEXT PRIMARY KEY,
+    config     JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE users (
+    id            TEXT PRIMARY KEY,
+    name          TEXT NOT NULL,
+    email         TEXT NOT NULL UNIQUE,
+    role          TEXT NOT NULL,
+    employee_id   TEXT NOT NULL DEFAULT '',
+    password_hash TEXT NOT NULL DEFAULT '',
+    active        BOOLEAN NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE sessions (
+    token_hash TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    csrf_token TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX sessions_user ON sessions (user_id);
+CREATE INDEX sessions_expires ON sessions (expires_at);
+
+CREATE TABLE shift_swaps (
+    id            TEXT PRIMARY KEY,
+    schedule_id   TEXT NOT NULL,
+    kind          TEXT NOT NULL,
+    status        TEXT NOT NULL,
+    shift         JSONB NOT NULL,
+    note          TEXT NOT NULL DEFAULT '',
+    claimed_by    TEXT NOT NULL DEFAULT '',
+    claimant_name TEXT NOT NULL DEFAULT '',
+    return_shift  JSONB NOT NULL DEFAULT 'null',
+    decided_by    TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL,
+    claimed_at    TIMESTAMPTZ,
+    decided_at    TIMESTAMPTZ,
+    updated_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX shift_swaps_schedule ON shift_swaps (schedule_id);
+CREATE INDEX shift_swaps_status ON shift_swaps (status);
+
+CREATE TABLE notifications (
+    id          TEXT PRIMARY KEY,
+    employee_id TEXT NOT NULL,
+    message     TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL,
+    read_at     TIMESTAMPTZ
+);
+
+CREATE INDEX notifications_employee ON notifications (employee_id, created_at DESC);
+
+CREATE TABLE leave_requests (
+    id            TEXT PRIMARY KEY,
+    employee_id   TEXT NOT NULL,
+    employee_name TEXT NOT NULL,
+    type          TEXT NOT NULL,
+    start_date    TIMESTAMPTZ NOT NULL,
+    end_date      TIMESTAMPTZ NOT NULL,
+    days          DOUBLE PRECISION NOT NULL,
+    reason        TEXT NOT NULL DEFAULT '',
+    status        TEXT NOT NULL,
+    conflicts     JSONB NOT NULL DEFAULT 'null',
+    decided_by    TEXT NOT NULL DEFAULT '',
+    decided_at    TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX leave_requests_employee ON leave_requests (employee_id, start_date DESC);
+CREATE INDEX leave_requests_status ON leave_requests (status);
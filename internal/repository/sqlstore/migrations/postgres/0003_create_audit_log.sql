@@ -0,0 +1,18 @@
+-- The audit trail: one row per change to an employee, a schedule or the
+-- company configuration. Rows are only inserted, and deleted after the
+-- retention period.
+
+CREATE TABLE audit_log (
+    id          TEXT PRIMARY KEY,
+    recorded_at TIMESTAMPTZ NOT NULL,
+    actor       TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id   TEXT NOT NULL,
+    entity_name TEXT NOT NULL DEFAULT '',
+    action      TEXT NOT NULL,
+    changes     JSONB NOT NULL DEFAULT 'null'
+);
+
+CREATE INDEX audit_log_entity ON audit_log (entity_type, entity_id, recorded_at DESC);
+
+CREATE INDEX audit_log_recorded_at ON audit_log (recorded_at DESC);
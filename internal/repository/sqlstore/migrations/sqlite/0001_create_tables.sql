@@ -0,0 +1,149 @@
+-- Tables mirror the MongoDB collections. Nested values that are only ever
+-- read and written whole are kept as JSON text. Times are stored as UTC
+-- text, which sorts chronologically.
+
+CREATE TABLE employees (
+    id               TEXT PRIMARY KEY,
+    name             TEXT NOT NULL,
+    email            TEXT NOT NULL UNIQUE,
+    role             TEXT NOT NULL,
+    role_description TEXT NOT NULL DEFAULT '',
+    monthly_hours    INTEGER NOT NULL,
+    active           BOOLEAN NOT NULL,
+    skills           TEXT NOT NULL DEFAULT 'null',
+    leave_allowances TEXT NOT NULL DEFAULT 'null',
+    calendar_token   TEXT UNIQUE,
+    created_at       DATETIME NOT NULL,
+    updated_at       DATETIME NOT NULL
+);
+
+CREATE INDEX employees_active ON employees (active);
+
+CREATE TABLE availability (
+    id               TEXT PRIMARY KEY,
+    employee_id      TEXT NOT NULL,
+    start_date       DATETIME NOT NULL,
+    end_date         DATETIME NOT NULL,
+    type             TEXT NOT NULL,
+    reason           TEXT NOT NULL DEFAULT '',
+    shift_types      TEXT NOT NULL DEFAULT 'null',
+    status           TEXT NOT NULL DEFAULT '',
+    leave_request_id TEXT NOT NULL DEFAULT '',
+    recurrence       TEXT NOT NULL DEFAULT 'null',
+    start_time       TEXT NOT NULL DEFAULT '',
+    end_time         TEXT NOT NULL DEFAULT '',
+    created_at       DATETIME NOT NULL,
+    updated_at       DATETIME NOT NULL
+);
+
+CREATE INDEX availability_employee ON availability (employee_id, start_date);
+CREATE INDEX availability_period ON availability (start_date, end_date);
+CREATE INDEX availability_status ON availability (status);
+
+CREATE TABLE schedules (
+    id                    TEXT PRIMARY KEY,
+    period_start          DATETIME NOT NULL,
+    period_end            DATETIME NOT NULL,
+    employees             TEXT NOT NULL DEFAULT 'null',
+    assignments           TEXT NOT NULL DEFAULT 'null',
+    understaffed          TEXT NOT NULL DEFAULT 'null',
+    relaxed_constraints   TEXT NOT NULL DEFAULT 'null',
+    strategy              TEXT NOT NULL DEFAULT '',
+    score                 TEXT NOT NULL DEFAULT 'null',
+    timezone              TEXT NOT NULL DEFAULT '',
+    shift_definitions     TEXT NOT NULL DEFAULT 'null',
+    warnings              TEXT NOT NULL DEFAULT 'null',
+    edited_at             DATETIME,
+    status                TEXT NOT NULL,
+    transitions           TEXT NOT NULL DEFAULT 'null',
+    published_assignments TEXT NOT NULL DEFAULT 'null',
+    cancelled_assignments TEXT NOT NULL DEFAULT 'null',
+    sent_to_n8n           BOOLEAN NOT NULL DEFAULT 0,
+    sent_at               DATETIME,
+    created_at            DATETIME NOT NULL,
+    updated_at            DATETIME NOT NULL
+);
+
+CREATE INDEX schedules_period ON schedules (period_start, period_end);
+CREATE INDEX schedules_status ON schedules (status);
+
+-- The company configuration is a single row, stored whole
+CREATE TABLE company_config (
+    id         TEXT PRIMARY KEY,
+    config     TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE users (
+    id            TEXT PRIMARY KEY,
+    name          TEXT NOT NULL,
+    email         TEXT NOT NULL UNIQUE,
+    role          TEXT NOT NULL,
+    employee_id   TEXT NOT NULL DEFAULT '',
+    password_hash TEXT NOT NULL DEFAULT '',
+    active        BOOLEAN NOT NULL,
+    created_at    DATETIME NOT NULL,
+    updated_at    DATETIME NOT NULL
+);
+
+CREATE TABLE sessions (
+    token_hash TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    csrf_token TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL
+);
+
+CREATE INDEX sessions_user ON sessions (user_id);
+CREATE INDEX sessions_expires ON sessions (expires_at);
+
+CREATE TABLE shift_swaps (
+    id            TEXT PRIMARY KEY,
+    schedule_id   TEXT NOT NULL,
+    kind          TEXT NOT NULL,
+    status        TEXT NOT NULL,
+    shift         TEXT NOT NULL,
+    note          TEXT NOT NULL DEFAULT '',
+    claimed_by    TEXT NOT NULL DEFAULT '',
+    claimant_name TEXT NOT NULL DEFAULT '',
+    return_shift  TEXT NOT NULL DEFAULT 'null',
+    decided_by    TEXT NOT NULL DEFAULT '',
+    created_at    DATETIME NOT NULL,
+    claimed_at    DATETIME,
+    decided_at    DATETIME,
+    updated_at    DATETIME NOT NULL
+);
+
+CREATE INDEX shift_swaps_schedule ON shift_swaps (schedule_id);
+CREATE INDEX shift_swaps_status ON shift_swaps (status);
+
+CREATE TABLE notifications (
+    id          TEXT PRIMARY KEY,
+    employee_id TEXT NOT NULL,
+    message     TEXT NOT NULL,
+    created_at  DATETIME NOT NULL,
+    read_at     DATETIME
+);
+
+CREATE INDEX notifications_employee ON notifications (employee_id, created_at DESC);
+
+CREATE TABLE leave_requests (
+    id            TEXT PRIMARY KEY,
+    employee_id   TEXT NOT NULL,
+    employee_name TEXT NOT NULL,
+    type          TEXT NOT NULL,
+    start_date    DATETIME NOT NULL,
+    end_date      DATETIME NOT NULL,
+    days          REAL NOT NULL,
+    reason        TEXT NOT NULL DEFAULT '',
+    status        TEXT NOT NULL,
+    conflicts     TEXT NOT NULL DEFAULT 'null',
+    decided_by    TEXT NOT NULL DEFAULT '',
+    decided_at    DATETIME,
+    created_at    DATETIME NOT NULL,
+    updated_at    DATETIME NOT NULL
+);
+
+CREATE INDEX leave_requests_employee ON leave_requests (employee_id, start_date DESC);
+CREATE INDEX leave_requests_status ON leave_requests (status);
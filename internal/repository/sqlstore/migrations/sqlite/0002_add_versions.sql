@@ -0,0 +1,8 @@
+-- Rows count their saved changes, so that an update can be made only if the
+-- row is still at the version it was loaded at. Existing rows start at 1.
+
+ALTER TABLE employees ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE schedules ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE company_config ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,64 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type notificationRepository struct {
+	db *DB
+}
+
+// NewNotificationRepository creates a new SQL notification repository
+func NewNotificationRepository(db *DB) repository.NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+	notification.CreatedAt = time.Now()
+
+	_, err := r.db.exec(ctx, "INSERT INTO notifications (id, employee_id, message, created_at, read_at) VALUES (?, ?, ?, ?, ?)",
+		notification.ID, notification.EmployeeID, notification.Message, r.db.timeValue(notification.CreatedAt),
+		r.db.nullTimeValue(notification.ReadAt))
+	return err
+}
+
+func (r *notificationRepository) GetByEmployee(ctx context.Context, employeeID string, limit int) ([]domain.Notification, error) {
+	query := "SELECT id, employee_id, message, created_at, read_at FROM notifications WHERE employee_id = ? ORDER BY created_at DESC"
+	args := []any{employeeID}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []domain.Notification
+	for rows.Next() {
+		var notification domain.Notification
+		if err := rows.Scan(&notification.ID, &notification.EmployeeID, &notification.Message,
+			timeColumn{&notification.CreatedAt}, nullTimeColumn{&notification.ReadAt}); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, employeeID string) error {
+	_, err := r.db.exec(ctx, "UPDATE notifications SET read_at = ? WHERE employee_id = ? AND read_at IS NULL",
+		r.db.timeValue(time.Now()), employeeID)
+	return err
+}
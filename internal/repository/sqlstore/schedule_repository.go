@@ -0,0 +1,142 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+const scheduleColumns = `id, period_start, period_end, employees, assignments, understaffed, relaxed_constraints, strategy, score,
+	timezone, shift_definitions, warnings, edited_at, status, transitions, published_assignments, cancelled_assignments,
+	sent_to_n8n, sent_at, version, created_at, updated_at`
+
+type scheduleRepository struct {
+	db *DB
+}
+
+// NewScheduleRepository creates a new SQL schedule repository
+func NewScheduleRepository(db *DB) repository.ScheduleRepository {
+	return &scheduleRepository{db: db}
+}
+
+func (r *scheduleRepository) Create(ctx context.Context, schedule *domain.Schedule) error {
+	if schedule.ID == "" {
+		schedule.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+	schedule.Version = 1
+
+	_, err := r.db.exec(ctx, "INSERT INTO schedules ("+scheduleColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		schedule.ID, r.db.timeValue(schedule.PeriodStart), r.db.timeValue(schedule.PeriodEnd),
+		jsonArg{schedule.Employees}, jsonArg{schedule.Assignments}, jsonArg{schedule.Understaffed},
+		jsonArg{schedule.RelaxedConstraints}, schedule.Strategy, jsonArg{schedule.Score}, schedule.Timezone,
+		jsonArg{schedule.ShiftDefinitions}, jsonArg{schedule.Warnings}, r.db.nullTimeValue(schedule.EditedAt), schedule.Status,
+		jsonArg{schedule.Transitions}, jsonArg{schedule.PublishedAssignments}, jsonArg{schedule.CancelledAssignments},
+		schedule.SentToN8N, r.db.nullTimeValue(schedule.SentAt), schedule.Version, r.db.timeValue(schedule.CreatedAt),
+		r.db.timeValue(schedule.UpdatedAt))
+	return err
+}
+
+func (r *scheduleRepository) GetByID(ctx context.Context, id string) (*domain.Schedule, error) {
+	schedule, err := scanSchedule(r.db.queryRow(ctx, "SELECT "+scheduleColumns+" FROM schedules WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrScheduleNotFound
+		}
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+func (r *scheduleRepository) GetAll(ctx context.Context) ([]domain.Schedule, error) {
+	return r.find(ctx, "SELECT "+scheduleColumns+" FROM schedules ORDER BY period_start DESC, created_at")
+}
+
+func (r *scheduleRepository) GetByPeriod(ctx context.Context, start, end time.Time) ([]domain.Schedule, error) {
+	return r.find(ctx, "SELECT "+scheduleColumns+" FROM schedules WHERE period_start >= ? AND period_end <= ? ORDER BY period_start DESC, created_at",
+		r.db.timeValue(start), r.db.timeValue(end))
+}
+
+func (r *scheduleRepository) find(ctx context.Context, query string, args ...any) ([]domain.Schedule, error) {
+	rows, err := r.db.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []domain.Schedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func (r *scheduleRepository) Update(ctx context.Context, schedule *domain.Schedule) error {
+	schedule.UpdatedAt = time.Now()
+
+	result, err := r.db.exec(ctx, `UPDATE schedules SET period_start = ?, period_end = ?, employees = ?, assignments = ?,
+		understaffed = ?, relaxed_constraints = ?, score = ?, warnings = ?, edited_at = ?, status = ?, transitions = ?,
+		published_assignments = ?, cancelled_assignments = ?, sent_to_n8n = ?, sent_at = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND version = ?`,
+		r.db.timeValue(schedule.PeriodStart), r.db.timeValue(schedule.PeriodEnd), jsonArg{schedule.Employees},
+		jsonArg{schedule.Assignments}, jsonArg{schedule.Understaffed}, jsonArg{schedule.RelaxedConstraints},
+		jsonArg{schedule.Score}, jsonArg{schedule.Warnings}, r.db.nullTimeValue(schedule.EditedAt), schedule.Status,
+		jsonArg{schedule.Transitions}, jsonArg{schedule.PublishedAssignments}, jsonArg{schedule.CancelledAssignments},
+		schedule.SentToN8N, r.db.nullTimeValue(schedule.SentAt), r.db.timeValue(schedule.UpdatedAt), schedule.ID, schedule.Version)
+	if err != nil {
+		return err
+	}
+
+	if err := r.db.matchedVersion(ctx, result, "schedules", schedule.ID, domain.ErrScheduleNotFound); err != nil {
+		return err
+	}
+	schedule.Version++
+	return nil
+}
+
+func (r *scheduleRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.exec(ctx, "DELETE FROM schedules WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	return matched(result, domain.ErrScheduleNotFound)
+}
+
+func (r *scheduleRepository) MarkAsSent(ctx context.Context, id string) error {
+	now := r.db.timeValue(time.Now())
+
+	result, err := r.db.exec(ctx, "UPDATE schedules SET sent_to_n8n = ?, sent_at = ?, version = version + 1, updated_at = ? WHERE id = ?", true, now, now, id)
+	if err != nil {
+		return err
+	}
+
+	return matched(result, domain.ErrScheduleNotFound)
+}
+
+func scanSchedule(row scanner) (domain.Schedule, error) {
+	var schedule domain.Schedule
+
+	err := row.Scan(&schedule.ID, timeColumn{&schedule.PeriodStart}, timeColumn{&schedule.PeriodEnd},
+		jsonColumn{&schedule.Employees}, jsonColumn{&schedule.Assignments}, jsonColumn{&schedule.Understaffed},
+		jsonColumn{&schedule.RelaxedConstraints}, &schedule.Strategy, jsonColumn{&schedule.Score}, &schedule.Timezone,
+		jsonColumn{&schedule.ShiftDefinitions}, jsonColumn{&schedule.Warnings}, nullTimeColumn{&schedule.EditedAt},
+		&schedule.Status, jsonColumn{&schedule.Transitions}, jsonColumn{&schedule.PublishedAssignments},
+		jsonColumn{&schedule.CancelledAssignments}, &schedule.SentToN8N, nullTimeColumn{&schedule.SentAt},
+		&schedule.Version, timeColumn{&schedule.CreatedAt}, timeColumn{&schedule.UpdatedAt})
+	return schedule, err
+}
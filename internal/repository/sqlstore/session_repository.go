@@ -0,0 +1,57 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+type sessionRepository struct {
+	db *DB
+}
+
+// NewSessionRepository creates a new SQL session repository. Expired sessions
+// are removed whenever a session is created.
+func NewSessionRepository(db *DB) repository.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	if _, err := r.db.exec(ctx, "DELETE FROM sessions WHERE expires_at <= ?", r.db.timeValue(time.Now())); err != nil {
+		return err
+	}
+
+	// The token itself is never stored
+	_, err := r.db.exec(ctx, "INSERT INTO sessions (token_hash, user_id, csrf_token, created_at, expires_at) VALUES (?, ?, ?, ?, ?)",
+		session.TokenHash, session.UserID, session.CSRFToken, r.db.timeValue(session.CreatedAt), r.db.timeValue(session.ExpiresAt))
+	return err
+}
+
+func (r *sessionRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	var session domain.Session
+
+	err := r.db.queryRow(ctx, "SELECT token_hash, user_id, csrf_token, created_at, expires_at FROM sessions WHERE token_hash = ?", tokenHash).
+		Scan(&session.TokenHash, &session.UserID, &session.CSRFToken, timeColumn{&session.CreatedAt}, timeColumn{&session.ExpiresAt})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *sessionRepository) Delete(ctx context.Context, tokenHash string) error {
+	_, err := r.db.exec(ctx, "DELETE FROM sessions WHERE token_hash = ?", tokenHash)
+	return err
+}
+
+func (r *sessionRepository) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := r.db.exec(ctx, "DELETE FROM sessions WHERE user_id = ?", userID)
+	return err
+}
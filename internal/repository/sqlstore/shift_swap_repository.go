@@ -0,0 +1,106 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+const shiftSwapColumns = "id, schedule_id, kind, status, shift, note, claimed_by, claimant_name, return_shift, decided_by, created_at, claimed_at, decided_at, updated_at"
+
+type shiftSwapRepository struct {
+	db *DB
+}
+
+// NewShiftSwapRepository creates a new SQL shift swap repository
+func NewShiftSwapRepository(db *DB) repository.ShiftSwapRepository {
+	return &shiftSwapRepository{db: db}
+}
+
+func (r *shiftSwapRepository) Create(ctx context.Context, swap *domain.ShiftSwap) error {
+	if swap.ID == "" {
+		swap.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	swap.CreatedAt = now
+	swap.UpdatedAt = now
+
+	_, err := r.db.exec(ctx, "INSERT INTO shift_swaps ("+shiftSwapColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		swap.ID, swap.ScheduleID, swap.Kind, swap.Status, jsonArg{swap.Shift}, swap.Note, swap.ClaimedBy, swap.ClaimantName,
+		jsonArg{swap.ReturnShift}, swap.DecidedBy, r.db.timeValue(swap.CreatedAt), r.db.nullTimeValue(swap.ClaimedAt),
+		r.db.nullTimeValue(swap.DecidedAt), r.db.timeValue(swap.UpdatedAt))
+	return err
+}
+
+func (r *shiftSwapRepository) GetByID(ctx context.Context, id string) (*domain.ShiftSwap, error) {
+	swap, err := scanShiftSwap(r.db.queryRow(ctx, "SELECT "+shiftSwapColumns+" FROM shift_swaps WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrShiftSwapNotFound
+		}
+		return nil, err
+	}
+
+	return &swap, nil
+}
+
+func (r *shiftSwapRepository) GetBySchedule(ctx context.Context, scheduleID string) ([]domain.ShiftSwap, error) {
+	return r.find(ctx, "schedule_id = ?", scheduleID)
+}
+
+func (r *shiftSwapRepository) GetByStatus(ctx context.Context, statuses ...string) ([]domain.ShiftSwap, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+
+	return r.find(ctx, "status IN ("+placeholders(len(statuses))+")", stringArgs(statuses)...)
+}
+
+// find returns the matching shift swaps, oldest first
+func (r *shiftSwapRepository) find(ctx context.Context, where string, args ...any) ([]domain.ShiftSwap, error) {
+	rows, err := r.db.query(ctx, "SELECT "+shiftSwapColumns+" FROM shift_swaps WHERE "+where+" ORDER BY created_at", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var swaps []domain.ShiftSwap
+	for rows.Next() {
+		swap, err := scanShiftSwap(rows)
+		if err != nil {
+			return nil, err
+		}
+		swaps = append(swaps, swap)
+	}
+
+	return swaps, rows.Err()
+}
+
+func (r *shiftSwapRepository) Update(ctx context.Context, swap *domain.ShiftSwap) error {
+	swap.UpdatedAt = time.Now()
+
+	result, err := r.db.exec(ctx, `UPDATE shift_swaps SET status = ?, note = ?, claimed_by = ?, claimant_name = ?,
+		return_shift = ?, decided_by = ?, claimed_at = ?, decided_at = ?, updated_at = ? WHERE id = ?`,
+		swap.Status, swap.Note, swap.ClaimedBy, swap.ClaimantName, jsonArg{swap.ReturnShift}, swap.DecidedBy,
+		r.db.nullTimeValue(swap.ClaimedAt), r.db.nullTimeValue(swap.DecidedAt), r.db.timeValue(swap.UpdatedAt), swap.ID)
+	if err != nil {
+		return err
+	}
+
+	return matched(result, domain.ErrShiftSwapNotFound)
+}
+
+func scanShiftSwap(row scanner) (domain.ShiftSwap, error) {
+	var swap domain.ShiftSwap
+
+	err := row.Scan(&swap.ID, &swap.ScheduleID, &swap.Kind, &swap.Status, jsonColumn{&swap.Shift}, &swap.Note,
+		&swap.ClaimedBy, &swap.ClaimantName, jsonColumn{&swap.ReturnShift}, &swap.DecidedBy, timeColumn{&swap.CreatedAt},
+		nullTimeColumn{&swap.ClaimedAt}, nullTimeColumn{&swap.DecidedAt}, timeColumn{&swap.UpdatedAt})
+	return swap, err
+}
@@ -0,0 +1,63 @@
+package sqlstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isak/restySched/internal/repository"
+	"github.com/isak/restySched/internal/repository/repositorytest"
+)
+
+func TestConformanceSQLite(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) *repository.Repositories {
+		return NewRepositories(openTestDB(t, DriverSQLite, filepath.Join(t.TempDir(), "test.db")))
+	})
+}
+
+// The PostgreSQL run needs a server. Set POSTGRES_TEST_URL, such as
+// postgres://localhost/restysched_test, to a database that can be emptied.
+func TestConformancePostgres(t *testing.T) {
+	url := os.Getenv("POSTGRES_TEST_URL")
+	if url == "" {
+		t.Skip("POSTGRES_TEST_URL is not set")
+	}
+
+	repositorytest.Run(t, func(t *testing.T) *repository.Repositories {
+		db := openTestDB(t, DriverPostgres, url)
+		for _, table := range []string{"employees", "availability", "schedules", "company_config", "users", "sessions", "shift_swaps", "notifications", "leave_requests", "audit_log"} {
+			if _, err := db.exec(context.Background(), "DELETE FROM "+table); err != nil {
+				t.Fatalf("emptying %s: %v", table, err)
+			}
+		}
+		return NewRepositories(db)
+	})
+}
+
+func TestMigrateTwice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	openTestDB(t, DriverSQLite, path).Close()
+
+	// Reopening an up-to-date database applies nothing
+	db := openTestDB(t, DriverSQLite, path)
+	var applied int
+	if err := db.queryRow(context.Background(), "SELECT COUNT(*) FROM schema_migrations").Scan(&applied); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := migrations.ReadDir("migrations/sqlite")
+	if applied != len(entries) {
+		t.Errorf("schema_migrations has %d rows, want %d", applied, len(entries))
+	}
+}
+
+func openTestDB(t *testing.T, driver, dsn string) *DB {
+	t.Helper()
+
+	db, err := Open(context.Background(), driver, dsn)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
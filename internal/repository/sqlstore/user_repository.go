@@ -0,0 +1,118 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+const userColumns = "id, name, email, role, employee_id, password_hash, active, created_at, updated_at"
+
+type userRepository struct {
+	db *DB
+}
+
+// NewUserRepository creates a new SQL user repository
+func NewUserRepository(db *DB) repository.UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.Active = true
+
+	_, err := r.db.exec(ctx, "INSERT INTO users ("+userColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		user.ID, user.Name, user.Email, user.Role, user.EmployeeID, user.PasswordHash, user.Active,
+		r.db.timeValue(user.CreatedAt), r.db.timeValue(user.UpdatedAt))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrUserAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.findOne(ctx, "email = ?", email)
+}
+
+func (r *userRepository) findOne(ctx context.Context, where string, args ...any) (*domain.User, error) {
+	user, err := scanUser(r.db.queryRow(ctx, "SELECT "+userColumns+" FROM users WHERE "+where, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) GetAll(ctx context.Context) ([]domain.User, error) {
+	rows, err := r.db.query(ctx, "SELECT "+userColumns+" FROM users ORDER BY name, created_at")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	user.UpdatedAt = time.Now()
+
+	result, err := r.db.exec(ctx, "UPDATE users SET name = ?, email = ?, role = ?, employee_id = ?, active = ?, updated_at = ? WHERE id = ?",
+		user.Name, user.Email, user.Role, user.EmployeeID, user.Active, r.db.timeValue(user.UpdatedAt), user.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrUserAlreadyExists
+		}
+		return err
+	}
+
+	return matched(result, domain.ErrUserNotFound)
+}
+
+func (r *userRepository) SetPasswordHash(ctx context.Context, id, hash string) error {
+	result, err := r.db.exec(ctx, "UPDATE users SET password_hash = ?, updated_at = ? WHERE id = ?",
+		hash, r.db.timeValue(time.Now()), id)
+	if err != nil {
+		return err
+	}
+
+	return matched(result, domain.ErrUserNotFound)
+}
+
+func scanUser(row scanner) (domain.User, error) {
+	var user domain.User
+
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.EmployeeID, &user.PasswordHash, &user.Active,
+		timeColumn{&user.CreatedAt}, timeColumn{&user.UpdatedAt})
+	return user, err
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// UserRepository defines the interface for user account data operations
+type UserRepository interface {
+	// Create creates a new user
+	Create(ctx context.Context, user *domain.User) error
+
+	// GetByID retrieves a user by ID
+	GetByID(ctx context.Context, id string) (*domain.User, error)
+
+	// GetByEmail retrieves a user by email
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+
+	// GetAll retrieves all users
+	GetAll(ctx context.Context) ([]domain.User, error)
+
+	// Update updates an existing user, except for the password hash
+	Update(ctx context.Context, user *domain.User) error
+
+	// SetPasswordHash replaces a user's password hash
+	SetPasswordHash(ctx context.Context, id, hash string) error
+}
+
+// SessionRepository defines the interface for login session data operations
+type SessionRepository interface {
+	// Create stores a new session
+	Create(ctx context.Context, session *domain.Session) error
+
+	// GetByTokenHash retrieves a session by the hash of its token
+	GetByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error)
+
+	// Delete removes a session
+	Delete(ctx context.Context, tokenHash string) error
+
+	// DeleteByUser removes every session of a user
+	DeleteByUser(ctx context.Context, userID string) error
+}
@@ -32,13 +32,23 @@ func (s *Scheduler) Start() error {
 	// Schedule to run every 2 weeks (14 days)
 	_, err := s.scheduler.NewJob(
 		gocron.DurationJob(14*24*time.Hour),
-		gocron.NewTask(s.generateAndSendSchedule),
+		gocron.NewTask(s.generateSchedule),
 		gocron.WithName("biweekly-schedule-generation"),
 	)
 	if err != nil {
 		return err
 	}
 
+	// Complete published schedules once their period is over
+	_, err = s.scheduler.NewJob(
+		gocron.DurationJob(time.Hour),
+		gocron.NewTask(s.completeEndedSchedules),
+		gocron.WithName("schedule-completion"),
+	)
+	if err != nil {
+		return err
+	}
+
 	log.Println("Scheduler started - will generate schedules every 2 weeks")
 	s.scheduler.Start()
 	return nil
@@ -51,29 +61,37 @@ func (s *Scheduler) Stop() error {
 
 // RunNow triggers immediate schedule generation (useful for testing)
 func (s *Scheduler) RunNow() error {
-	s.generateAndSendSchedule()
+	s.generateSchedule()
 	return nil
 }
 
-func (s *Scheduler) generateAndSendSchedule() {
+// schedulerActor is recorded as the actor of lifecycle transitions made by the scheduler
+const schedulerActor = "scheduler"
+
+// generateSchedule generates the next draft schedule. Drafts are left for a
+// manager to approve and publish, which sends them to n8n.
+func (s *Scheduler) generateSchedule() {
 	ctx := context.Background()
 
 	log.Println("Starting biweekly schedule generation...")
 
-	schedule, err := s.scheduleService.GenerateBiweeklySchedule(ctx)
+	schedule, err := s.scheduleService.GenerateBiweeklySchedule(ctx, "")
 	if err != nil {
 		log.Printf("ERROR: Failed to generate schedule: %v", err)
 		return
 	}
 
-	log.Printf("Schedule generated successfully: %s", schedule.ID)
+	log.Printf("Schedule generated successfully: %s (draft, awaiting approval)", schedule.ID)
+}
 
-	// Send to n8n (if configured)
-	if err := s.scheduleService.SendScheduleToN8N(ctx, schedule.ID); err != nil {
-		log.Printf("WARNING: Failed to send schedule to n8n: %v", err)
-		log.Printf("Schedule saved but not sent to n8n. You can manually send it later from the UI.")
+func (s *Scheduler) completeEndedSchedules() {
+	completed, err := s.scheduleService.CompleteEndedSchedules(context.Background(), time.Now(), schedulerActor)
+	if err != nil {
+		log.Printf("ERROR: Failed to complete ended schedules: %v", err)
 		return
 	}
 
-	log.Printf("Schedule sent to n8n successfully: %s", schedule.ID)
+	if completed > 0 {
+		log.Printf("Completed %d ended schedules", completed)
+	}
 }
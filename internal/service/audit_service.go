@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/isak/restySched/internal/auth"
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// systemActor is recorded as the actor of changes made without a signed-in
+// user, such as on startup
+const systemActor = "system"
+
+// pruneInterval is how often entries past the retention are deleted
+const pruneInterval = 24 * time.Hour
+
+// AuditService keeps the audit trail of changes to employees, their
+// availability, schedules and the company configuration
+type AuditService struct {
+	repo      repository.AuditRepository
+	retention time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	prunedAt time.Time
+}
+
+// NewAuditService creates a new audit service. Entries older than retention
+// are deleted; a retention of 0 keeps them forever.
+func NewAuditService(repo repository.AuditRepository, retention time.Duration) *AuditService {
+	return &AuditService{repo: repo, retention: retention, now: time.Now}
+}
+
+// Record appends an entry for a change that has been saved. The actor is the
+// signed-in user of ctx unless the entry names one. Updates that change
+// nothing are not recorded. A failed write is logged rather than returned, as
+// the change itself has already been made.
+func (s *AuditService) Record(ctx context.Context, entry domain.AuditEntry) {
+	if len(entry.Changes) == 0 && entry.Action == domain.AuditActionUpdate {
+		return
+	}
+	if entry.Actor == "" {
+		entry.Actor = contextActor(ctx)
+	}
+	entry.ID = ""
+	entry.Timestamp = s.now()
+
+	if err := s.repo.Append(ctx, &entry); err != nil {
+		log.Error().Err(err).
+			Str("entity_type", entry.EntityType).
+			Str("entity_id", entry.EntityID).
+			Str("action", entry.Action).
+			Msg("Failed to record audit entry")
+	}
+
+	s.pruneDaily(ctx)
+}
+
+// recordAvailability records a change to an availability period as a change
+// to its employee, named as looked up in employeeRepo. An empty actor is the
+// signed-in user of ctx.
+func (s *AuditService) recordAvailability(ctx context.Context, employeeRepo repository.EmployeeRepository, actor, employeeID, action string, before, after *domain.Availability) {
+	entry := domain.AuditEntry{
+		Actor:      actor,
+		EntityType: domain.AuditEntityEmployee,
+		EntityID:   employeeID,
+		Action:     action,
+		Changes:    domain.DiffAvailability(before, after),
+	}
+	if employee, err := employeeRepo.GetByID(ctx, employeeID); err == nil {
+		entry.EntityName = employee.Name
+	}
+
+	s.Record(ctx, entry)
+}
+
+// List retrieves the audit entries the filter selects, newest first
+func (s *AuditService) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Retention returns how long entries are kept; 0 keeps them forever
+func (s *AuditService) Retention() time.Duration {
+	return s.retention
+}
+
+// Prune deletes the entries older than the retention and returns how many
+// were deleted
+func (s *AuditService) Prune(ctx context.Context) (int, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	s.prunedAt = s.now()
+	s.mu.Unlock()
+
+	return s.repo.DeleteBefore(ctx, s.now().Add(-s.retention))
+}
+
+// pruneDaily prunes the trail if it has not been pruned for a day, so that
+// the retention holds without a separate job
+func (s *AuditService) pruneDaily(ctx context.Context) {
+	s.mu.Lock()
+	due := s.retention > 0 && s.now().Sub(s.prunedAt) >= pruneInterval
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+
+	if _, err := s.Prune(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to delete old audit entries")
+	}
+}
+
+// contextActor identifies who made a change by the email of the signed-in
+// user of ctx
+func contextActor(ctx context.Context) string {
+	if user := auth.User(ctx); user != nil {
+		return user.Email
+	}
+	return systemActor
+}
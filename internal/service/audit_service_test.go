@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/isak/restySched/internal/auth"
+	"github.com/isak/restySched/internal/domain"
+)
+
+// MockAuditRepository is a mock implementation of AuditRepository for testing
+type MockAuditRepository struct {
+	entries []domain.AuditEntry
+}
+
+func (m *MockAuditRepository) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	entry.ID = fmt.Sprintf("audit%d", len(m.entries)+1)
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	var entries []domain.AuditEntry
+	for _, entry := range slices.Backward(m.entries) {
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+func (m *MockAuditRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	kept := m.entries[:0]
+	for _, entry := range m.entries {
+		if !entry.Timestamp.Before(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	deleted := len(m.entries) - len(kept)
+	m.entries = kept
+	return deleted, nil
+}
+
+// newTestAuditService returns an audit service that keeps entries in memory
+func newTestAuditService() *AuditService {
+	return NewAuditService(&MockAuditRepository{}, 0)
+}
+
+// auditEntries returns the entries recorded by an audit service from newTestAuditService, newest first
+func auditEntries(t *testing.T, audit *AuditService) []domain.AuditEntry {
+	t.Helper()
+	entries, err := audit.List(context.Background(), domain.AuditFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestAuditService_Record(t *testing.T) {
+	audit := newTestAuditService()
+	ctx := auth.WithUser(context.Background(), &domain.User{Email: "manager@example.com"}, "")
+
+	audit.Record(ctx, domain.AuditEntry{EntityType: domain.AuditEntityEmployee, EntityID: "emp1", Action: domain.AuditActionDelete})
+	audit.Record(ctx, domain.AuditEntry{EntityType: domain.AuditEntityEmployee, EntityID: "emp1", Action: domain.AuditActionUpdate})
+	audit.Record(context.Background(), domain.AuditEntry{EntityType: domain.AuditEntitySchedule, EntityID: "s1", Action: domain.AuditActionGenerate})
+	audit.Record(ctx, domain.AuditEntry{EntityType: domain.AuditEntitySchedule, EntityID: "s1", Action: domain.ScheduleActionPublish, Actor: "scheduler"})
+
+	entries := auditEntries(t, audit)
+	if len(entries) != 3 {
+		t.Fatalf("Entries = %+v, want 3 as an update without changes is not recorded", entries)
+	}
+	for i, want := range []string{"scheduler", systemActor, "manager@example.com"} {
+		if entries[i].Actor != want {
+			t.Errorf("Entry %d actor = %q, want %q", i, entries[i].Actor, want)
+		}
+		if entries[i].Timestamp.IsZero() {
+			t.Errorf("Entry %d has no timestamp", i)
+		}
+	}
+}
+
+func TestAuditService_Prune(t *testing.T) {
+	now := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+	repo := &MockAuditRepository{entries: []domain.AuditEntry{
+		{ID: "old", Timestamp: now.AddDate(0, 0, -31)},
+		{ID: "recent", Timestamp: now.AddDate(0, 0, -29)},
+	}}
+
+	forever := NewAuditService(repo, 0)
+	if deleted, err := forever.Prune(context.Background()); err != nil || deleted != 0 {
+		t.Errorf("Prune() without retention = %d, %v, want 0, nil", deleted, err)
+	}
+
+	audit := NewAuditService(repo, 30*24*time.Hour)
+	audit.now = func() time.Time { return now }
+	deleted, err := audit.Prune(context.Background())
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if deleted != 1 || len(repo.entries) != 1 || repo.entries[0].ID != "recent" {
+		t.Errorf("Prune() deleted %d, kept %+v, want only the recent entry kept", deleted, repo.entries)
+	}
+}
+
+func TestScheduleService_AuditsEdits(t *testing.T) {
+	service, _, schedule := newEditableSchedule(t)
+	ctx := auth.WithUser(context.Background(), &domain.User{Email: "manager@example.com"}, "")
+
+	if _, err := service.RemoveAssignment(ctx, schedule.ID, 0, "a2"); err != nil {
+		t.Fatalf("RemoveAssignment() error = %v", err)
+	}
+
+	entries := auditEntries(t, service.audit)
+	if len(entries) != 2 || entries[1].Action != domain.AuditActionGenerate {
+		t.Fatalf("Entries = %+v, want the generation and the removal", entries)
+	}
+	removed := entries[0]
+	if removed.Action != domain.AuditActionRemoveAssignment || removed.Actor != "manager@example.com" ||
+		removed.EntityType != domain.AuditEntitySchedule || removed.EntityID != schedule.ID {
+		t.Errorf("Entry = %+v, want the manager removing an assignment of the schedule", removed)
+	}
+	if len(removed.Changes) != 1 || removed.Changes[0].Before != "John Doe, Morning on Wed 2025-01-08" || removed.Changes[0].After != "" {
+		t.Errorf("Changes = %+v, want John Doe's Wednesday morning shift removed", removed.Changes)
+	}
+}
+
+func TestEmployeeService_AuditsChanges(t *testing.T) {
+	repo := NewMockEmployeeRepository()
+	audit := newTestAuditService()
+	service := NewEmployeeService(repo, &MockAvailabilityRepository{}, audit)
+	ctx := context.Background()
+
+	employee, err := service.CreateEmployee(ctx, domain.EmployeeCreateInput{Name: "John Doe", Email: "john@example.com", Role: "Barista", MonthlyHours: 160})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := *employee
+	updated.MonthlyHours = 120
+	if err := service.UpdateEmployee(ctx, &updated); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.DeleteEmployee(ctx, employee.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := auditEntries(t, audit)
+	var actions []string
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+		if entry.EntityID != employee.ID || entry.EntityName != "John Doe" {
+			t.Errorf("Entry = %+v, want it to name John Doe", entry)
+		}
+	}
+	if want := []string{domain.AuditActionDeactivate, domain.AuditActionUpdate, domain.AuditActionCreate}; !slices.Equal(actions, want) {
+		t.Fatalf("Actions = %v, want %v", actions, want)
+	}
+	want := []domain.FieldChange{{Field: "monthly_hours", Before: "160", After: "120"}}
+	if !slices.Equal(entries[1].Changes, want) {
+		t.Errorf("Update changes = %+v, want %+v", entries[1].Changes, want)
+	}
+}
@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SessionTTL is how long a login session lasts
+const SessionTTL = 12 * time.Hour
+
+// AuthService handles user accounts, passwords and login sessions
+type AuthService struct {
+	users        repository.UserRepository
+	sessions     repository.SessionRepository
+	employeeRepo repository.EmployeeRepository
+
+	hashCost  int
+	dummyOnce sync.Once
+	dummyHash []byte
+	now       func() time.Time
+}
+
+// NewAuthService creates a new auth service
+func NewAuthService(
+	users repository.UserRepository,
+	sessions repository.SessionRepository,
+	employeeRepo repository.EmployeeRepository,
+) *AuthService {
+	return &AuthService{
+		users:        users,
+		sessions:     sessions,
+		employeeRepo: employeeRepo,
+		hashCost:     bcrypt.DefaultCost,
+		now:          time.Now,
+	}
+}
+
+// Login checks an email and password and starts a session for the user. The
+// returned session holds the token to give to the client.
+func (s *AuthService) Login(ctx context.Context, email, password string) (*domain.User, *domain.Session, error) {
+	user, err := s.users.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil, err
+	}
+
+	if user == nil || !user.Active {
+		// Compare against a dummy hash so that unknown emails take as long as wrong passwords
+		s.dummyOnce.Do(func() {
+			s.dummyHash, _ = bcrypt.GenerateFromPassword([]byte("restysched-dummy-password"), s.hashCost)
+		})
+		bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+		return nil, nil, domain.ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, nil, domain.ErrInvalidCredentials
+	}
+
+	session, err := s.newSession(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return nil, nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return user, session, nil
+}
+
+// Authenticate returns the active user and session a session token belongs to
+func (s *AuthService) Authenticate(ctx context.Context, token string) (*domain.User, *domain.Session, error) {
+	if token == "" {
+		return nil, nil, domain.ErrSessionNotFound
+	}
+
+	session, err := s.sessions.GetByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if session.Expired(s.now()) {
+		return nil, nil, domain.ErrSessionNotFound
+	}
+
+	user, err := s.users.GetByID(ctx, session.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil, domain.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !user.Active {
+		return nil, nil, domain.ErrSessionNotFound
+	}
+
+	return user, session, nil
+}
+
+// Logout ends the session a token belongs to
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	return s.sessions.Delete(ctx, hashToken(token))
+}
+
+// GetUser retrieves a user by ID
+func (s *AuthService) GetUser(ctx context.Context, id string) (*domain.User, error) {
+	return s.users.GetByID(ctx, id)
+}
+
+// GetAllUsers retrieves all users
+func (s *AuthService) GetAllUsers(ctx context.Context) ([]domain.User, error) {
+	return s.users.GetAll(ctx)
+}
+
+// CreateUser creates a user with a password
+func (s *AuthService) CreateUser(ctx context.Context, input domain.UserInput) (*domain.User, error) {
+	domain.SanitizeUserInput(&input)
+
+	user := &domain.User{
+		Name:       input.Name,
+		Email:      input.Email,
+		Role:       input.Role,
+		EmployeeID: input.EmployeeID,
+	}
+	if err := s.validateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	hash, err := s.hashPassword(input.Password)
+	if err != nil {
+		return nil, err
+	}
+	user.PasswordHash = hash
+
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// UpdateUser changes a user's details, and their password if the input has
+// one. Changing the role or password signs the user out everywhere.
+func (s *AuthService) UpdateUser(ctx context.Context, id string, input domain.UserInput) (*domain.User, error) {
+	domain.SanitizeUserInput(&input)
+
+	user, err := s.users.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	roleChanged := user.Role != input.Role || user.EmployeeID != input.EmployeeID
+	if user.Role == domain.UserRoleAdmin && input.Role != domain.UserRoleAdmin {
+		if err := s.checkOtherAdmins(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	user.Name = input.Name
+	user.Email = input.Email
+	user.Role = input.Role
+	user.EmployeeID = input.EmployeeID
+	if err := s.validateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	var hash string
+	if input.Password != "" {
+		if hash, err = s.hashPassword(input.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	if hash != "" {
+		if err := s.users.SetPasswordHash(ctx, user.ID, hash); err != nil {
+			return nil, err
+		}
+		user.PasswordHash = hash
+	}
+
+	if roleChanged || hash != "" {
+		if err := s.sessions.DeleteByUser(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("failed to end sessions: %w", err)
+		}
+	}
+
+	return user, nil
+}
+
+// DeactivateUser stops a user from signing in and ends their sessions
+func (s *AuthService) DeactivateUser(ctx context.Context, id string) error {
+	user, err := s.users.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == domain.UserRoleAdmin {
+		if err := s.checkOtherAdmins(ctx, user.ID); err != nil {
+			return err
+		}
+	}
+
+	user.Active = false
+	if err := s.users.Update(ctx, user); err != nil {
+		return err
+	}
+
+	return s.sessions.DeleteByUser(ctx, user.ID)
+}
+
+// ReactivateUser lets a deactivated user sign in again
+func (s *AuthService) ReactivateUser(ctx context.Context, id string) error {
+	user, err := s.users.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.validateUser(ctx, user); err != nil {
+		return err
+	}
+
+	user.Active = true
+	return s.users.Update(ctx, user)
+}
+
+// ChangePassword replaces a user's own password after checking the current one
+func (s *AuthService) ChangePassword(ctx context.Context, userID, current, password string) error {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(current)) != nil {
+		return domain.ErrInvalidCredentials
+	}
+
+	hash, err := s.hashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.users.SetPasswordHash(ctx, user.ID, hash)
+}
+
+// EnsureAdmin creates an admin account with the given email and password if
+// there are no users yet, so that a new installation can be signed in to.
+// It reports whether the account was created.
+func (s *AuthService) EnsureAdmin(ctx context.Context, email, password string) (bool, error) {
+	users, err := s.users.GetAll(ctx)
+	if err != nil {
+		return false, err
+	}
+	if len(users) > 0 {
+		return false, nil
+	}
+
+	_, err = s.CreateUser(ctx, domain.UserInput{
+		Name:     "Administrator",
+		Email:    email,
+		Role:     domain.UserRoleAdmin,
+		Password: password,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create the admin account: %w", err)
+	}
+
+	return true, nil
+}
+
+// validateUser checks the user's fields, that the email is not taken, and that
+// an employee account belongs to an active employee without another account
+func (s *AuthService) validateUser(ctx context.Context, user *domain.User) error {
+	if err := user.Validate(); err != nil {
+		return err
+	}
+
+	existing, err := s.users.GetByEmail(ctx, user.Email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return err
+	}
+	if existing != nil && existing.ID != user.ID {
+		return domain.ErrUserAlreadyExists
+	}
+
+	if user.Role != domain.UserRoleEmployee {
+		return nil
+	}
+
+	employee, err := s.employeeRepo.GetByID(ctx, user.EmployeeID)
+	if errors.Is(err, domain.ErrEmployeeNotFound) {
+		return domain.ErrUserEmployeeRequired
+	}
+	if err != nil {
+		return err
+	}
+	if !employee.Active {
+		return domain.ErrUserEmployeeRequired
+	}
+
+	users, err := s.users.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range users {
+		if other.ID != user.ID && other.Active && other.EmployeeID == user.EmployeeID {
+			return domain.ErrEmployeeHasAccount
+		}
+	}
+
+	return nil
+}
+
+// checkOtherAdmins returns ErrLastAdmin unless an active admin other than the
+// given user exists
+func (s *AuthService) checkOtherAdmins(ctx context.Context, userID string) error {
+	users, err := s.users.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range users {
+		if other.ID != userID && other.Active && other.Role == domain.UserRoleAdmin {
+			return nil
+		}
+	}
+	return domain.ErrLastAdmin
+}
+
+func (s *AuthService) hashPassword(password string) (string, error) {
+	if err := domain.ValidatePassword(password); err != nil {
+		return "", err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.hashCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// newSession creates a session with new random session and CSRF tokens
+func (s *AuthService) newSession(userID string) (*domain.Session, error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	csrfToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	return &domain.Session{
+		Token:     token,
+		TokenHash: hashToken(token),
+		UserID:    userID,
+		CSRFToken: csrfToken,
+		CreatedAt: now,
+		ExpiresAt: now.Add(SessionTTL),
+	}, nil
+}
+
+// randomToken returns a random, URL-safe token
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hash a session token is stored under
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
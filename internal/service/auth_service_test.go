@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MockUserRepository is a mock implementation of UserRepository for testing
+type MockUserRepository struct {
+	users     map[string]domain.User
+	idCounter int
+}
+
+func NewMockUserRepository() *MockUserRepository {
+	return &MockUserRepository{users: make(map[string]domain.User)}
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user *domain.User) error {
+	if user.ID == "" {
+		m.idCounter++
+		user.ID = fmt.Sprintf("user-%d", m.idCounter)
+	}
+	user.Active = true
+	m.users[user.ID] = *user
+	return nil
+}
+
+func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	user, ok := m.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	for _, user := range m.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (m *MockUserRepository) GetAll(ctx context.Context) ([]domain.User, error) {
+	var result []domain.User
+	for _, user := range m.users {
+		result = append(result, user)
+	}
+	return result, nil
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, user *domain.User) error {
+	stored, ok := m.users[user.ID]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	hash := stored.PasswordHash
+	stored = *user
+	stored.PasswordHash = hash
+	m.users[user.ID] = stored
+	return nil
+}
+
+func (m *MockUserRepository) SetPasswordHash(ctx context.Context, id, hash string) error {
+	user, ok := m.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	user.PasswordHash = hash
+	m.users[id] = user
+	return nil
+}
+
+// MockSessionRepository is a mock implementation of SessionRepository for testing
+type MockSessionRepository struct {
+	sessions map[string]domain.Session
+}
+
+func NewMockSessionRepository() *MockSessionRepository {
+	return &MockSessionRepository{sessions: make(map[string]domain.Session)}
+}
+
+func (m *MockSessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	stored := *session
+	stored.Token = ""
+	m.sessions[session.TokenHash] = stored
+	return nil
+}
+
+func (m *MockSessionRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	session, ok := m.sessions[tokenHash]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (m *MockSessionRepository) Delete(ctx context.Context, tokenHash string) error {
+	delete(m.sessions, tokenHash)
+	return nil
+}
+
+func (m *MockSessionRepository) DeleteByUser(ctx context.Context, userID string) error {
+	for hash, session := range m.sessions {
+		if session.UserID == userID {
+			delete(m.sessions, hash)
+		}
+	}
+	return nil
+}
+
+// newTestAuthService returns an auth service with a fast hash cost, an admin
+// account and an active employee emp1 without an account
+func newTestAuthService(t *testing.T) (*AuthService, *MockSessionRepository, *domain.User) {
+	t.Helper()
+	employeeRepo := NewMockEmployeeRepository()
+	employeeRepo.Create(context.Background(), &domain.Employee{ID: "emp1", Name: "Emp One", Email: "emp1@example.com"})
+
+	sessions := NewMockSessionRepository()
+	s := NewAuthService(NewMockUserRepository(), sessions, employeeRepo)
+	s.hashCost = bcrypt.MinCost
+
+	admin, err := s.CreateUser(context.Background(), domain.UserInput{
+		Name: "Admin", Email: " Admin@Example.com ", Role: domain.UserRoleAdmin, Password: "admin-password",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	return s, sessions, admin
+}
+
+func TestAuthService_LoginAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	s, sessions, admin := newTestAuthService(t)
+
+	if admin.Email != "admin@example.com" || admin.PasswordHash == "admin-password" {
+		t.Fatalf("Created user = %+v, want a lower-case email and a hashed password", admin)
+	}
+
+	for _, creds := range [][2]string{{"admin@example.com", "wrong-password"}, {"nobody@example.com", "admin-password"}} {
+		if _, _, err := s.Login(ctx, creds[0], creds[1]); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Errorf("Login(%q, %q) error = %v, want ErrInvalidCredentials", creds[0], creds[1], err)
+		}
+	}
+
+	user, session, err := s.Login(ctx, "ADMIN@example.com", "admin-password")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if user.ID != admin.ID || session.Token == "" || session.CSRFToken == "" {
+		t.Fatalf("Login() = %+v, %+v, want the admin with session and CSRF tokens", user, session)
+	}
+	if _, ok := sessions.sessions[session.Token]; ok {
+		t.Error("Session is stored under its plain token")
+	}
+
+	authenticated, stored, err := s.Authenticate(ctx, session.Token)
+	if err != nil || authenticated.ID != admin.ID || stored.CSRFToken != session.CSRFToken {
+		t.Fatalf("Authenticate() = %+v, %+v, %v, want the admin's session", authenticated, stored, err)
+	}
+	if _, _, err := s.Authenticate(ctx, session.Token+"x"); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("Authenticate() with an unknown token error = %v, want ErrSessionNotFound", err)
+	}
+
+	s.now = func() time.Time { return time.Now().Add(SessionTTL + time.Minute) }
+	if _, _, err := s.Authenticate(ctx, session.Token); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("Authenticate() of an expired session error = %v, want ErrSessionNotFound", err)
+	}
+	s.now = time.Now
+
+	if err := s.Logout(ctx, session.Token); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if _, _, err := s.Authenticate(ctx, session.Token); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("Authenticate() after logout error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestAuthService_CreateUser(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestAuthService(t)
+
+	tests := []struct {
+		name    string
+		input   domain.UserInput
+		wantErr error
+	}{
+		{"manager", domain.UserInput{Name: "Manager", Email: "manager@example.com", Role: domain.UserRoleManager, Password: "password1"}, nil},
+		{"employee", domain.UserInput{Name: "Emp One", Email: "emp1@example.com", Role: domain.UserRoleEmployee, EmployeeID: "emp1", Password: "password1"}, nil},
+		{"second account of an employee", domain.UserInput{Name: "Emp One", Email: "other@example.com", Role: domain.UserRoleEmployee, EmployeeID: "emp1", Password: "password1"}, domain.ErrEmployeeHasAccount},
+		{"employee without an employee", domain.UserInput{Name: "Nobody", Email: "nobody@example.com", Role: domain.UserRoleEmployee, Password: "password1"}, domain.ErrUserEmployeeRequired},
+		{"unknown employee", domain.UserInput{Name: "Nobody", Email: "nobody@example.com", Role: domain.UserRoleEmployee, EmployeeID: "emp9", Password: "password1"}, domain.ErrUserEmployeeRequired},
+		{"duplicate email", domain.UserInput{Name: "Admin", Email: "ADMIN@example.com", Role: domain.UserRoleManager, Password: "password1"}, domain.ErrUserAlreadyExists},
+		{"unknown role", domain.UserInput{Name: "Owner", Email: "owner@example.com", Role: "owner", Password: "password1"}, domain.ErrInvalidUserRole},
+		{"short password", domain.UserInput{Name: "Short", Email: "short@example.com", Role: domain.UserRoleManager, Password: "short"}, domain.ErrInvalidPassword},
+		{"invalid email", domain.UserInput{Name: "Mail", Email: "not-an-email", Role: domain.UserRoleManager, Password: "password1"}, domain.ErrInvalidUserEmail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.CreateUser(ctx, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateUser() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthService_UpdateUser(t *testing.T) {
+	ctx := context.Background()
+	s, _, admin := newTestAuthService(t)
+
+	manager, err := s.CreateUser(ctx, domain.UserInput{Name: "Manager", Email: "manager@example.com", Role: domain.UserRoleManager, Password: "password1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, session, err := s.Login(ctx, "manager@example.com", "password1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A new password replaces the old one and signs the user out
+	if _, err := s.UpdateUser(ctx, manager.ID, domain.UserInput{Name: "Manager", Email: "manager@example.com", Role: domain.UserRoleManager, Password: "password2"}); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if _, _, err := s.Authenticate(ctx, session.Token); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("Session after a password reset error = %v, want ErrSessionNotFound", err)
+	}
+	if _, _, err := s.Login(ctx, "manager@example.com", "password2"); err != nil {
+		t.Errorf("Login() with the new password error = %v", err)
+	}
+
+	// An empty password keeps the current one
+	if _, err := s.UpdateUser(ctx, manager.ID, domain.UserInput{Name: "Manager Two", Email: "manager@example.com", Role: domain.UserRoleManager}); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if _, _, err := s.Login(ctx, "manager@example.com", "password2"); err != nil {
+		t.Errorf("Login() after updating without a password error = %v", err)
+	}
+
+	// The last admin keeps the admin role and cannot be deactivated
+	if _, err := s.UpdateUser(ctx, admin.ID, domain.UserInput{Name: "Admin", Email: "admin@example.com", Role: domain.UserRoleManager}); !errors.Is(err, domain.ErrLastAdmin) {
+		t.Errorf("Demoting the last admin error = %v, want ErrLastAdmin", err)
+	}
+	if err := s.DeactivateUser(ctx, admin.ID); !errors.Is(err, domain.ErrLastAdmin) {
+		t.Errorf("Deactivating the last admin error = %v, want ErrLastAdmin", err)
+	}
+
+	if err := s.DeactivateUser(ctx, manager.ID); err != nil {
+		t.Fatalf("DeactivateUser() error = %v", err)
+	}
+	if _, _, err := s.Login(ctx, "manager@example.com", "password2"); !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Errorf("Login() of a deactivated user error = %v, want ErrInvalidCredentials", err)
+	}
+
+	if err := s.ReactivateUser(ctx, manager.ID); err != nil {
+		t.Fatalf("ReactivateUser() error = %v", err)
+	}
+	if _, _, err := s.Login(ctx, "manager@example.com", "password2"); err != nil {
+		t.Errorf("Login() of a reactivated user error = %v", err)
+	}
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	s, _, admin := newTestAuthService(t)
+
+	if err := s.ChangePassword(ctx, admin.ID, "wrong-password", "new-password"); !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Errorf("ChangePassword() with a wrong current password error = %v, want ErrInvalidCredentials", err)
+	}
+	if err := s.ChangePassword(ctx, admin.ID, "admin-password", "short"); !errors.Is(err, domain.ErrInvalidPassword) {
+		t.Errorf("ChangePassword() to a short password error = %v, want ErrInvalidPassword", err)
+	}
+	if err := s.ChangePassword(ctx, admin.ID, "admin-password", "new-password"); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if _, _, err := s.Login(ctx, "admin@example.com", "new-password"); err != nil {
+		t.Errorf("Login() with the changed password error = %v", err)
+	}
+}
+
+func TestAuthService_EnsureAdmin(t *testing.T) {
+	ctx := context.Background()
+	s := NewAuthService(NewMockUserRepository(), NewMockSessionRepository(), NewMockEmployeeRepository())
+	s.hashCost = bcrypt.MinCost
+
+	created, err := s.EnsureAdmin(ctx, "root@example.com", "root-password")
+	if err != nil || !created {
+		t.Fatalf("EnsureAdmin() = %v, %v, want the admin created", created, err)
+	}
+	if created, err := s.EnsureAdmin(ctx, "other@example.com", "other-password"); err != nil || created {
+		t.Errorf("EnsureAdmin() with existing users = %v, %v, want nothing created", created, err)
+	}
+	if user, _, err := s.Login(ctx, "root@example.com", "root-password"); err != nil || user.Role != domain.UserRoleAdmin {
+		t.Errorf("Login() as the created admin = %+v, %v", user, err)
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/ical"
+	"github.com/isak/restySched/internal/repository"
+)
+
+// CalendarService builds personal iCalendar feeds of employees' published shifts
+type CalendarService struct {
+	employeeRepo repository.EmployeeRepository
+	scheduleRepo repository.ScheduleRepository
+	companyRepo  repository.CompanyConfigRepository
+	audit        *AuditService
+}
+
+// NewCalendarService creates a new calendar service
+func NewCalendarService(
+	employeeRepo repository.EmployeeRepository,
+	scheduleRepo repository.ScheduleRepository,
+	companyRepo repository.CompanyConfigRepository,
+	audit *AuditService,
+) *CalendarService {
+	return &CalendarService{
+		employeeRepo: employeeRepo,
+		scheduleRepo: scheduleRepo,
+		companyRepo:  companyRepo,
+		audit:        audit,
+	}
+}
+
+// EnsureFeedToken returns the employee with a calendar feed token, creating the
+// token on first use
+func (s *CalendarService) EnsureFeedToken(ctx context.Context, employeeID string) (*domain.Employee, error) {
+	employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if employee.CalendarToken != "" {
+		return employee, nil
+	}
+	return s.setFeedToken(ctx, employee)
+}
+
+// RotateFeedToken gives the employee a new calendar feed token. The old feed URL
+// stops working immediately.
+func (s *CalendarService) RotateFeedToken(ctx context.Context, employeeID string) (*domain.Employee, error) {
+	employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.setFeedToken(ctx, employee)
+}
+
+func (s *CalendarService) setFeedToken(ctx context.Context, employee *domain.Employee) (*domain.Employee, error) {
+	token, err := newFeedToken()
+	if err != nil {
+		return nil, err
+	}
+
+	previous := employee.CalendarToken
+	if err := s.employeeRepo.SetCalendarToken(ctx, employee.ID, token); err != nil {
+		return nil, fmt.Errorf("failed to set calendar token: %w", err)
+	}
+
+	employee.CalendarToken = token
+	s.audit.Record(ctx, domain.AuditEntry{
+		EntityType: domain.AuditEntityEmployee,
+		EntityID:   employee.ID,
+		EntityName: employee.Name,
+		Action:     domain.AuditActionUpdate,
+		Changes:    domain.DiffCalendarToken(previous, token),
+	})
+	return employee, nil
+}
+
+// EmployeeFeed builds the calendar of the active employee a feed token belongs
+// to. It holds the employee's shifts from every published schedule, including
+// completed and archived ones, and cancels shifts removed since they were published.
+func (s *CalendarService) EmployeeFeed(ctx context.Context, token string) (*ical.Calendar, error) {
+	if token == "" {
+		return nil, domain.ErrEmployeeNotFound
+	}
+
+	employee, err := s.employeeRepo.GetByCalendarToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !employee.Active {
+		return nil, domain.ErrEmployeeNotFound
+	}
+
+	companyConfig, err := s.companyRepo.GetOrCreate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load company configuration: %w", err)
+	}
+
+	schedules, err := s.scheduleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+
+	name := employee.Name
+	if companyConfig.CompanyName != "" {
+		name += " - " + companyConfig.CompanyName
+	}
+
+	calendar := &ical.Calendar{
+		Name:     name,
+		Location: companyConfig.WorkingHours.Location(),
+		Stamp:    time.Now(),
+	}
+	for i := range schedules {
+		schedule := &schedules[i]
+		for _, a := range schedule.VisibleAssignments() {
+			if a.EmployeeID == employee.ID {
+				calendar.Events = append(calendar.Events, assignmentEvent(schedule, a, false))
+			}
+		}
+		for _, a := range schedule.CancelledAssignments {
+			if a.EmployeeID == employee.ID {
+				calendar.Events = append(calendar.Events, assignmentEvent(schedule, a, true))
+			}
+		}
+	}
+
+	return calendar, nil
+}
+
+// EmployeeShift is a published shift of an employee, dated in the schedule's timezone
+type EmployeeShift struct {
+	domain.ShiftAssignment
+	ScheduleID string `json:"schedule_id"`
+	ShiftName  string `json:"shift_name"`
+}
+
+// UpcomingShifts returns the employee's published shifts on or after the
+// company's calendar day of from, in order
+func (s *CalendarService) UpcomingShifts(ctx context.Context, employeeID string, from time.Time) ([]EmployeeShift, error) {
+	companyConfig, err := s.companyRepo.GetOrCreate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load company configuration: %w", err)
+	}
+
+	schedules, err := s.scheduleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+
+	from = from.In(companyConfig.WorkingHours.Location())
+	today := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+
+	shifts := []EmployeeShift{}
+	for i := range schedules {
+		schedule := &schedules[i]
+		for _, a := range schedule.VisibleAssignments() {
+			if a.EmployeeID != employeeID || a.Date.Before(today) {
+				continue
+			}
+			name := a.ShiftType
+			if def := schedule.ShiftDefinition(a.ShiftType); def != nil {
+				name = def.DisplayName()
+			}
+			a.Date = a.Date.In(schedule.Location())
+			shifts = append(shifts, EmployeeShift{ShiftAssignment: a, ScheduleID: schedule.ID, ShiftName: name})
+		}
+	}
+
+	sort.SliceStable(shifts, func(i, j int) bool {
+		if !shifts[i].Date.Equal(shifts[j].Date) {
+			return shifts[i].Date.Before(shifts[j].Date)
+		}
+		return shifts[i].StartTime < shifts[j].StartTime
+	})
+
+	return shifts, nil
+}
+
+// MonthHours compares the hours an employee is scheduled for in a month with
+// their monthly hours
+type MonthHours struct {
+	Month     string  `json:"month"` // YYYY-MM
+	Scheduled float64 `json:"scheduled"`
+	Target    int     `json:"target"`
+}
+
+// HoursInMonth sums the employee's published shift hours in the company's
+// calendar month of date
+func (s *CalendarService) HoursInMonth(ctx context.Context, employeeID string, date time.Time) (*MonthHours, error) {
+	employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	companyConfig, err := s.companyRepo.GetOrCreate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load company configuration: %w", err)
+	}
+
+	schedules, err := s.scheduleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+
+	date = date.In(companyConfig.WorkingHours.Location())
+	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	end := start.AddDate(0, 1, 0)
+
+	hours := &MonthHours{Month: start.Format("2006-01"), Target: employee.MonthlyHours}
+	for i := range schedules {
+		for _, a := range schedules[i].VisibleAssignments() {
+			if a.EmployeeID == employeeID && !a.Date.Before(start) && a.Date.Before(end) {
+				hours.Scheduled += a.Hours
+			}
+		}
+	}
+
+	return hours, nil
+}
+
+// assignmentEvent converts a published assignment to a calendar event
+func assignmentEvent(schedule *domain.Schedule, a domain.ShiftAssignment, cancelled bool) ical.Event {
+	// The assignment's own times are what was published, even if the shift
+	// definition has changed since
+	span := domain.ShiftDefinition{StartTime: a.StartTime, EndTime: a.EndTime}
+	start, end := span.Span(a.Date, schedule.Location())
+
+	name := a.ShiftType
+	if def := schedule.ShiftDefinition(a.ShiftType); def != nil {
+		name = def.DisplayName()
+	}
+
+	// Every change to the schedule increments its version, so as the sequence
+	// an update or cancellation outranks what subscribers fetched before
+	return ical.Event{
+		UID:          a.UID() + "@restysched",
+		Summary:      name + " shift",
+		Description:  fmt.Sprintf("%s - %s, %.1f paid hours", a.StartTime, a.EndTime, a.Hours),
+		Start:        start,
+		End:          end,
+		LastModified: schedule.UpdatedAt,
+		Sequence:     schedule.Version,
+		Cancelled:    cancelled,
+	}
+}
+
+// newFeedToken returns a random, URL-safe calendar feed token
+func newFeedToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate calendar token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/ical"
+)
+
+// feedEvents returns the UIDs of a calendar's confirmed and cancelled events
+func feedEvents(calendar *ical.Calendar) (map[string]bool, map[string]bool) {
+	confirmed, cancelled := make(map[string]bool), make(map[string]bool)
+	for _, event := range calendar.Events {
+		if event.Cancelled {
+			cancelled[event.UID] = true
+		} else {
+			confirmed[event.UID] = true
+		}
+	}
+	return confirmed, cancelled
+}
+
+func TestCalendarService_EmployeeFeed(t *testing.T) {
+	ctx := context.Background()
+	scheduleService, scheduleRepo, schedule := newEditableSchedule(t)
+	calendarService := NewCalendarService(scheduleService.employeeRepo, scheduleRepo, scheduleService.companyRepo, scheduleService.audit)
+
+	emp1, err := calendarService.EnsureFeedToken(ctx, "emp1")
+	if err != nil {
+		t.Fatalf("EnsureFeedToken() error = %v", err)
+	}
+	emp2, err := calendarService.EnsureFeedToken(ctx, "emp2")
+	if err != nil {
+		t.Fatalf("EnsureFeedToken() error = %v", err)
+	}
+	if emp1.CalendarToken == "" || emp1.CalendarToken == emp2.CalendarToken {
+		t.Fatalf("Feed tokens = %q and %q, want distinct tokens", emp1.CalendarToken, emp2.CalendarToken)
+	}
+	if again, _ := calendarService.EnsureFeedToken(ctx, "emp1"); again.CalendarToken != emp1.CalendarToken {
+		t.Error("EnsureFeedToken() replaced an existing token")
+	}
+
+	publish := func() {
+		t.Helper()
+		if _, err := scheduleService.ApproveSchedule(ctx, schedule.ID, 0, "manager"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := scheduleService.PublishSchedule(ctx, schedule.ID, 0, "manager", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	feed := func(token string) (map[string]bool, map[string]bool) {
+		t.Helper()
+		calendar, err := calendarService.EmployeeFeed(ctx, token)
+		if err != nil {
+			t.Fatalf("EmployeeFeed() error = %v", err)
+		}
+		return feedEvents(calendar)
+	}
+	sequence := func(token, uid string) int {
+		t.Helper()
+		calendar, err := calendarService.EmployeeFeed(ctx, token)
+		if err != nil {
+			t.Fatalf("EmployeeFeed() error = %v", err)
+		}
+		for _, event := range calendar.Events {
+			if event.UID == uid {
+				return event.Sequence
+			}
+		}
+		t.Fatalf("Feed has no event %s", uid)
+		return 0
+	}
+
+	if confirmed, _ := feed(emp1.CalendarToken); len(confirmed) != 0 {
+		t.Errorf("Draft schedule shows %d events, want none", len(confirmed))
+	}
+
+	publish()
+	if confirmed, cancelled := feed(emp1.CalendarToken); len(confirmed) != 5 || len(cancelled) != 0 {
+		t.Errorf("Published feed = %d confirmed, %d cancelled, want 5 and 0", len(confirmed), len(cancelled))
+	}
+	published := sequence(emp1.CalendarToken, "a2@restysched")
+
+	// Edits are not visible until the schedule is published again
+	if _, err := scheduleService.ReopenSchedule(ctx, schedule.ID, 0, "manager"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scheduleService.RemoveAssignment(ctx, schedule.ID, 0, "a2"); err != nil {
+		t.Fatal(err)
+	}
+	edited, err := scheduleService.AddAssignment(ctx, schedule.ID, 0, "emp2", schedule.PeriodStart, domain.ShiftTypeEvening)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scheduleService.SwapAssignments(ctx, schedule.ID, 0, "a0", edited.Assignments[len(edited.Assignments)-1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if confirmed, _ := feed(emp1.CalendarToken); len(confirmed) != 5 {
+		t.Errorf("Feed while reopened = %d confirmed, want the 5 published", len(confirmed))
+	}
+
+	publish()
+	confirmed, cancelled := feed(emp1.CalendarToken)
+	if len(confirmed) != 4 || !cancelled["a2@restysched"] || !cancelled["a0@restysched"] || confirmed["a0@restysched"] {
+		t.Errorf("emp1 feed after republishing: confirmed %v, cancelled %v", confirmed, cancelled)
+	}
+	if cancelledAt := sequence(emp1.CalendarToken, "a2@restysched"); cancelledAt <= published {
+		t.Errorf("Sequence of the cancelled event = %d, want more than the %d it was published with", cancelledAt, published)
+	}
+	confirmed, cancelled = feed(emp2.CalendarToken)
+	if len(confirmed) != 1 || !confirmed["a0@restysched"] || len(cancelled) != 0 {
+		t.Errorf("emp2 feed after republishing: confirmed %v, cancelled %v", confirmed, cancelled)
+	}
+
+	oldToken := emp1.CalendarToken
+	rotated, err := calendarService.RotateFeedToken(ctx, "emp1")
+	if err != nil {
+		t.Fatalf("RotateFeedToken() error = %v", err)
+	}
+	if rotated.CalendarToken == oldToken {
+		t.Error("RotateFeedToken() kept the old token")
+	}
+	if _, err := calendarService.EmployeeFeed(ctx, oldToken); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Errorf("EmployeeFeed() with rotated token error = %v, want %v", err, domain.ErrEmployeeNotFound)
+	}
+	if _, err := calendarService.EmployeeFeed(ctx, ""); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Errorf("EmployeeFeed() with empty token error = %v, want %v", err, domain.ErrEmployeeNotFound)
+	}
+}
+
+func TestCalendarService_UpcomingShifts(t *testing.T) {
+	ctx := context.Background()
+	scheduleService, scheduleRepo, schedule := newEditableSchedule(t)
+	calendarService := NewCalendarService(scheduleService.employeeRepo, scheduleRepo, scheduleService.companyRepo, scheduleService.audit)
+
+	shifts, err := calendarService.UpcomingShifts(ctx, "emp1", schedule.PeriodStart)
+	if err != nil {
+		t.Fatalf("UpcomingShifts() error = %v", err)
+	}
+	if len(shifts) != 0 {
+		t.Errorf("Draft schedule shows %d shifts, want none", len(shifts))
+	}
+
+	if _, err := scheduleService.ApproveSchedule(ctx, schedule.ID, 0, "manager"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scheduleService.PublishSchedule(ctx, schedule.ID, 0, "manager", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Shifts before the given day are left out, even if it is mid-day
+	shifts, err = calendarService.UpcomingShifts(ctx, "emp1", schedule.PeriodStart.AddDate(0, 0, 2).Add(15*time.Hour))
+	if err != nil {
+		t.Fatalf("UpcomingShifts() error = %v", err)
+	}
+	if len(shifts) != 3 || shifts[0].ID != "a2" || shifts[2].ID != "a4" {
+		t.Errorf("UpcomingShifts() = %+v, want a2 to a4", shifts)
+	}
+	if shifts[0].ScheduleID != schedule.ID || shifts[0].ShiftName == "" {
+		t.Errorf("UpcomingShifts()[0] = %+v, want the schedule ID and shift name", shifts[0])
+	}
+
+	if shifts, _ := calendarService.UpcomingShifts(ctx, "emp2", schedule.PeriodStart); len(shifts) != 0 {
+		t.Errorf("emp2 has %d shifts, want none", len(shifts))
+	}
+}
+
+func TestCalendarService_HoursInMonth(t *testing.T) {
+	ctx := context.Background()
+	scheduleService, scheduleRepo, schedule := newEditableSchedule(t)
+	calendarService := NewCalendarService(scheduleService.employeeRepo, scheduleRepo, scheduleService.companyRepo, scheduleService.audit)
+
+	if _, err := scheduleService.ApproveSchedule(ctx, schedule.ID, 0, "manager"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scheduleService.PublishSchedule(ctx, schedule.ID, 0, "manager", false); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		date time.Time
+		want MonthHours
+	}{
+		{"month of the schedule", time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC), MonthHours{Month: "2025-01", Scheduled: 20, Target: 160}},
+		{"next month", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), MonthHours{Month: "2025-02", Scheduled: 0, Target: 160}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hours, err := calendarService.HoursInMonth(ctx, "emp1", tt.date)
+			if err != nil {
+				t.Fatalf("HoursInMonth() error = %v", err)
+			}
+			if *hours != tt.want {
+				t.Errorf("HoursInMonth() = %+v, want %+v", *hours, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarService_AuditsFeedTokens(t *testing.T) {
+	ctx := context.Background()
+	scheduleService, scheduleRepo, _ := newEditableSchedule(t)
+	audit := newTestAuditService()
+	calendarService := NewCalendarService(scheduleService.employeeRepo, scheduleRepo, scheduleService.companyRepo, audit)
+
+	created, err := calendarService.EnsureFeedToken(ctx, "emp1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdToken := created.CalendarToken
+	if _, err := calendarService.EnsureFeedToken(ctx, "emp1"); err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := calendarService.RotateFeedToken(ctx, "emp1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := auditEntries(t, audit)
+	want := [][]domain.FieldChange{
+		{{Field: "calendar_token", Before: "(previous token, redacted)", After: "(new token, redacted)"}},
+		{{Field: "calendar_token", After: "(new token, redacted)"}},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Entries = %+v, want the token created and rotated", entries)
+	}
+	for i, entry := range entries {
+		if entry.Action != domain.AuditActionUpdate || entry.EntityID != "emp1" || !slices.Equal(entry.Changes, want[i]) {
+			t.Errorf("Entry %d = %+v, want an update changing %+v", i, entry, want[i])
+		}
+		for _, change := range entry.Changes {
+			for _, token := range []string{createdToken, rotated.CalendarToken} {
+				if strings.Contains(change.Before+change.After, token) {
+					t.Errorf("Entry %d reveals a feed token: %+v", i, change)
+				}
+			}
+		}
+	}
+}
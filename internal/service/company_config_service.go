@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+)
+
+// CompanyConfigService reads and replaces the company configuration
+type CompanyConfigService struct {
+	repo  repository.CompanyConfigRepository
+	audit *AuditService
+}
+
+// NewCompanyConfigService creates a new company configuration service
+func NewCompanyConfigService(repo repository.CompanyConfigRepository, audit *AuditService) *CompanyConfigService {
+	return &CompanyConfigService{
+		repo:  repo,
+		audit: audit,
+	}
+}
+
+// GetConfig returns the company configuration, creating the default one if
+// none has been saved yet
+func (s *CompanyConfigService) GetConfig(ctx context.Context) (*domain.CompanyConfig, error) {
+	return s.repo.GetOrCreate(ctx)
+}
+
+// SaveConfig replaces the stored company configuration with config. A version
+// of 0 replaces whatever version is stored; any other version must still be
+// the stored one, or domain.ErrConflict is returned.
+func (s *CompanyConfigService) SaveConfig(ctx context.Context, config *domain.CompanyConfig, version int) error {
+	before, err := s.repo.Get(ctx)
+	switch {
+	case errors.Is(err, domain.ErrCompanyConfigNotFound):
+		before = nil
+	case err != nil:
+		return err
+	}
+
+	action := domain.AuditActionCreate
+	if before != nil {
+		action = domain.AuditActionUpdate
+		config.ID = before.ID
+		if version == 0 {
+			version = before.Version
+		}
+	}
+
+	config.Version = version
+	if err := s.repo.Update(ctx, config); err != nil {
+		return err
+	}
+
+	s.recordConfig(ctx, action, before, config)
+	return nil
+}
+
+// recordConfig records a change to the company configuration in the audit trail
+func (s *CompanyConfigService) recordConfig(ctx context.Context, action string, before, after *domain.CompanyConfig) {
+	s.audit.Record(ctx, domain.AuditEntry{
+		EntityType: domain.AuditEntityCompanyConfig,
+		EntityID:   after.ID,
+		EntityName: after.CompanyName,
+		Action:     action,
+		Changes:    domain.Diff(before, after),
+	})
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+func TestCompanyConfigService_AuditsChanges(t *testing.T) {
+	ctx := context.Background()
+	audit := newTestAuditService()
+	service := NewCompanyConfigService(&MockCompanyConfigRepository{}, audit)
+
+	if err := service.SaveConfig(ctx, &domain.CompanyConfig{ID: "config", CompanyName: "Cafe"}, 0); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+	if err := service.SaveConfig(ctx, &domain.CompanyConfig{CompanyName: "Corner Cafe"}, 0); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	config, err := service.GetConfig(ctx)
+	if err != nil || config.ID != "config" || config.CompanyName != "Corner Cafe" {
+		t.Fatalf("GetConfig() = %+v, %v, want the renamed configuration", config, err)
+	}
+
+	entries := auditEntries(t, audit)
+	if len(entries) != 2 {
+		t.Fatalf("Entries = %+v, want the configuration created and updated", entries)
+	}
+	if created := entries[1]; created.Action != domain.AuditActionCreate || created.EntityType != domain.AuditEntityCompanyConfig {
+		t.Errorf("First entry = %+v, want the configuration created", created)
+	}
+	rename := domain.FieldChange{Field: "company_name", Before: "Cafe", After: "Corner Cafe"}
+	if updated := entries[0]; updated.Action != domain.AuditActionUpdate || updated.EntityID != "config" ||
+		updated.EntityName != "Corner Cafe" || len(updated.Changes) != 1 || updated.Changes[0] != rename {
+		t.Errorf("Second entry = %+v, want the rename", updated)
+	}
+}
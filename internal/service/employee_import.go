@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// Employee import row actions
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionSkip   = "skip"   // the row has errors and is not imported
+	ImportActionFailed = "failed" // the row is valid but could not be saved
+)
+
+// EmployeeImportOptions controls how an employee import is applied
+type EmployeeImportOptions struct {
+	DryRun         bool // validate only, without saving anything
+	UpdateExisting bool // update employees whose email already exists instead of rejecting the row
+}
+
+// EmployeeImportRow is the outcome of one row of an employee import
+type EmployeeImportRow struct {
+	Line       int // line in the file, counting the header as line 1
+	Input      domain.EmployeeCreateInput
+	Action     string
+	EmployeeID string // the created or updated employee
+	Errors     []string
+}
+
+// EmployeeImportReport describes what an employee import did, or would do in a dry run
+type EmployeeImportReport struct {
+	DryRun  bool
+	Rows    []EmployeeImportRow
+	Created int
+	Updated int
+	Skipped int
+	Failed  int
+}
+
+func (r EmployeeImportRow) hasError(message string) bool {
+	for _, e := range r.Errors {
+		if e == message {
+			return true
+		}
+	}
+	return false
+}
+
+// importColumns maps accepted header names to employee fields
+var importColumns = map[string]string{
+	"name":             "name",
+	"full_name":        "name",
+	"email":            "email",
+	"e_mail":           "email",
+	"role":             "role",
+	"role_description": "role_description",
+	"description":      "role_description",
+	"monthly_hours":    "monthly_hours",
+	"hours":            "monthly_hours",
+	"skills":           "skills",
+}
+
+// requiredImportColumns are the columns every import file must have
+var requiredImportColumns = []string{"name", "email", "role", "monthly_hours"}
+
+// ImportEmployees creates employees from the records of a CSV or XLSX file whose
+// first record is a header row. Every row is sanitized and validated like the
+// employee form; rows with errors are skipped and reported, and unless the
+// import is a dry run the valid rows are saved one by one. A row that cannot be
+// saved is reported as failed with its error; the rows saved before and after it
+// are kept, so the report shows exactly which lines were imported.
+func (s *EmployeeService) ImportEmployees(ctx context.Context, records [][]string, opts EmployeeImportOptions) (*EmployeeImportReport, error) {
+	if len(records) == 0 {
+		return nil, domain.ErrImportMissingColumns
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		key := strings.ToLower(strings.TrimSpace(header))
+		key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+		if field, ok := importColumns[key]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	var missing []string
+	for _, field := range requiredImportColumns {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrImportMissingColumns, strings.Join(missing, ", "))
+	}
+
+	report := &EmployeeImportReport{DryRun: opts.DryRun}
+	targets := make([]*domain.Employee, 0, len(records)-1)
+	seenEmails := make(map[string]int)
+
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row, employee, err := s.importRow(ctx, record, columns, opts)
+		if err != nil {
+			return nil, err
+		}
+		row.Line = i + 2
+
+		if line, ok := seenEmails[row.Input.Email]; ok && row.Input.Email != "" {
+			row.Errors = append(row.Errors, fmt.Sprintf("email is also used on line %d", line))
+		} else {
+			seenEmails[row.Input.Email] = row.Line
+		}
+
+		if len(row.Errors) > 0 {
+			row.Action = ImportActionSkip
+		}
+		report.Rows = append(report.Rows, row)
+		targets = append(targets, employee)
+	}
+
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		if row.Action == ImportActionSkip {
+			report.Skipped++
+			continue
+		}
+		if !opts.DryRun {
+			if err := s.applyImportRow(ctx, row, targets[i], columns); err != nil {
+				row.Action = ImportActionFailed
+				row.Errors = append(row.Errors, err.Error())
+				report.Failed++
+				continue
+			}
+		}
+		if row.Action == ImportActionCreate {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+	}
+
+	return report, nil
+}
+
+// importRow parses and validates one record. It returns the employee the row
+// would update, if any.
+func (s *EmployeeService) importRow(ctx context.Context, record []string, columns map[string]int, opts EmployeeImportOptions) (EmployeeImportRow, *domain.Employee, error) {
+	value := func(field string) string {
+		if i, ok := columns[field]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	row := EmployeeImportRow{
+		Action: ImportActionCreate,
+		Input: domain.EmployeeCreateInput{
+			Name:            value("name"),
+			Email:           value("email"),
+			Role:            value("role"),
+			RoleDescription: value("role_description"),
+		},
+	}
+
+	if hours := value("monthly_hours"); hours != "" {
+		// Spreadsheets may store whole numbers as "160.0"
+		monthlyHours, err := strconv.ParseFloat(hours, 64)
+		if err != nil || monthlyHours != float64(int(monthlyHours)) {
+			row.Errors = append(row.Errors, domain.ErrInvalidMonthlyHours.Error())
+		}
+		row.Input.MonthlyHours = int(monthlyHours)
+	}
+
+	skills, err := domain.ParseSkills(value("skills"))
+	if err != nil {
+		row.Errors = append(row.Errors, err.Error())
+	}
+	row.Input.Skills = skills
+
+	domain.SanitizeEmployeeInput(&row.Input)
+
+	candidate := &domain.Employee{
+		Name:            row.Input.Name,
+		Email:           row.Input.Email,
+		Role:            row.Input.Role,
+		RoleDescription: row.Input.RoleDescription,
+		MonthlyHours:    row.Input.MonthlyHours,
+		Skills:          row.Input.Skills,
+	}
+	if err := candidate.Validate(); err != nil && !row.hasError(err.Error()) {
+		row.Errors = append(row.Errors, err.Error())
+	}
+	if candidate.Email == "" {
+		return row, nil, nil
+	}
+
+	existing, err := s.repo.GetByEmail(ctx, candidate.Email)
+	if err != nil && err != domain.ErrEmployeeNotFound {
+		return row, nil, err
+	}
+	if existing == nil {
+		return row, nil, nil
+	}
+
+	if !opts.UpdateExisting {
+		row.Errors = append(row.Errors, domain.ErrEmployeeAlreadyExists.Error())
+		return row, nil, nil
+	}
+	row.Action = ImportActionUpdate
+	row.EmployeeID = existing.ID
+	return row, existing, nil
+}
+
+// applyImportRow saves a valid import row. Updates keep the employee's
+// availability and calendar feed, and only replace the role description and
+// skills if the file has those columns.
+func (s *EmployeeService) applyImportRow(ctx context.Context, row *EmployeeImportRow, existing *domain.Employee, columns map[string]int) error {
+	if row.Action == ImportActionCreate {
+		employee := &domain.Employee{
+			Name:            row.Input.Name,
+			Email:           row.Input.Email,
+			Role:            row.Input.Role,
+			RoleDescription: row.Input.RoleDescription,
+			MonthlyHours:    row.Input.MonthlyHours,
+			Skills:          row.Input.Skills,
+		}
+		if err := s.repo.Create(ctx, employee); err != nil {
+			return err
+		}
+		s.recordEmployee(ctx, domain.AuditActionCreate, nil, employee)
+		row.EmployeeID = employee.ID
+		return nil
+	}
+
+	before := *existing
+	existing.Name = row.Input.Name
+	existing.Role = row.Input.Role
+	existing.MonthlyHours = row.Input.MonthlyHours
+	if _, ok := columns["role_description"]; ok {
+		existing.RoleDescription = row.Input.RoleDescription
+	}
+	if _, ok := columns["skills"]; ok {
+		existing.Skills = row.Input.Skills
+	}
+	if err := s.repo.Update(ctx, existing); err != nil {
+		return err
+	}
+	s.recordEmployee(ctx, domain.AuditActionUpdate, &before, existing)
+	return nil
+}
+
+// isBlankRecord reports whether every field of a record is empty, as in the
+// trailing rows spreadsheet apps often export
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -2,19 +2,23 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/isak/restySched/internal/domain"
 	"github.com/isak/restySched/internal/repository"
 )
 
-// EmployeeService handles business logic for employees
+// EmployeeService handles business logic for employees and their availability
 type EmployeeService struct {
-	repo repository.EmployeeRepository
+	repo             repository.EmployeeRepository
+	availabilityRepo repository.AvailabilityRepository
+	audit            *AuditService
 }
 
-// NewEmployeeService creates a new employee service
-func NewEmployeeService(repo repository.EmployeeRepository) *EmployeeService {
-	return &EmployeeService{repo: repo}
+// NewEmployeeService creates a new employee service. Every change it makes is
+// recorded in the audit trail.
+func NewEmployeeService(repo repository.EmployeeRepository, availabilityRepo repository.AvailabilityRepository, audit *AuditService) *EmployeeService {
+	return &EmployeeService{repo: repo, availabilityRepo: availabilityRepo, audit: audit}
 }
 
 // CreateEmployee creates a new employee
@@ -29,6 +33,8 @@ func (s *EmployeeService) CreateEmployee(ctx context.Context, input domain.Emplo
 		Role:            input.Role,
 		RoleDescription: input.RoleDescription,
 		MonthlyHours:    input.MonthlyHours,
+		Skills:          input.Skills,
+		LeaveAllowances: input.LeaveAllowances,
 	}
 
 	// Validate employee data
@@ -49,6 +55,7 @@ func (s *EmployeeService) CreateEmployee(ctx context.Context, input domain.Emplo
 	if err := s.repo.Create(ctx, employee); err != nil {
 		return nil, err
 	}
+	s.recordEmployee(ctx, domain.AuditActionCreate, nil, employee)
 
 	return employee, nil
 }
@@ -74,51 +81,255 @@ func (s *EmployeeService) UpdateEmployee(ctx context.Context, employee *domain.E
 		return err
 	}
 
-	return s.repo.Update(ctx, employee)
+	// The email may only change to one no other employee has
+	existing, err := s.repo.GetByEmail(ctx, employee.Email)
+	if err != nil && err != domain.ErrEmployeeNotFound {
+		return err
+	}
+	if existing != nil && existing.ID != employee.ID {
+		return domain.ErrEmployeeAlreadyExists
+	}
+
+	before, err := s.repo.GetByID(ctx, employee.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(ctx, employee); err != nil {
+		return err
+	}
+	s.recordEmployee(ctx, domain.AuditActionUpdate, before, employee)
+
+	return nil
 }
 
 // DeleteEmployee soft deletes an employee
 func (s *EmployeeService) DeleteEmployee(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	before, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	after := *before
+	after.Active = false
+	s.recordEmployee(ctx, domain.AuditActionDeactivate, before, &after)
+
+	return nil
 }
 
-// AddEmployeeAvailability adds a new availability period to an employee
-func (s *EmployeeService) AddEmployeeAvailability(ctx context.Context, id string, availability domain.Availability) (*domain.Employee, error) {
-	employee, err := s.repo.GetByID(ctx, id)
+// recordEmployee records a change to an employee in the audit trail
+func (s *EmployeeService) recordEmployee(ctx context.Context, action string, before, after *domain.Employee) {
+	employee := after
+	if employee == nil {
+		employee = before
+	}
+
+	s.audit.Record(ctx, domain.AuditEntry{
+		EntityType: domain.AuditEntityEmployee,
+		EntityID:   employee.ID,
+		EntityName: employee.Name,
+		Action:     action,
+		Changes:    domain.DiffEmployees(before, after),
+	})
+}
+
+// AddEmployeeAvailability adds a new availability period to an employee. It
+// applies at once, as entered by a manager.
+func (s *EmployeeService) AddEmployeeAvailability(ctx context.Context, employeeID string, availability domain.Availability) (*domain.Availability, error) {
+	availability.Status = domain.AvailabilityStatusApproved
+	return s.addAvailability(ctx, employeeID, availability, domain.AuditActionAddAvailability)
+}
+
+// SubmitAvailability adds an availability period an employee entered
+// themselves. It is ignored when scheduling until a manager approves it.
+func (s *EmployeeService) SubmitAvailability(ctx context.Context, employeeID string, availability domain.Availability) (*domain.Availability, error) {
+	availability.Status = domain.AvailabilityStatusPending
+	return s.addAvailability(ctx, employeeID, availability, domain.AuditActionSubmitAvailability)
+}
+
+func (s *EmployeeService) addAvailability(ctx context.Context, employeeID string, availability domain.Availability, action string) (*domain.Availability, error) {
+	if err := availability.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetByID(ctx, employeeID); err != nil {
+		return nil, err
+	}
+
+	availability.ID = ""
+	availability.EmployeeID = employeeID
+	availability.LeaveRequestID = ""
+	if err := s.availabilityRepo.Create(ctx, &availability); err != nil {
+		return nil, err
+	}
+	s.recordAvailability(ctx, employeeID, action, nil, &availability)
+
+	return &availability, nil
+}
+
+// GetAvailability retrieves one of an employee's availability periods
+func (s *EmployeeService) GetAvailability(ctx context.Context, employeeID, id string) (*domain.Availability, error) {
+	availability, err := s.availabilityRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if availability.EmployeeID != employeeID {
+		return nil, domain.ErrAvailabilityNotFound
+	}
+	return availability, nil
+}
 
-	// Add the new availability period
-	employee.Availability = append(employee.Availability, availability)
+// GetAvailabilityInPeriod lists every employee's availability periods that
+// apply to any day from start to end
+func (s *EmployeeService) GetAvailabilityInPeriod(ctx context.Context, start, end time.Time) ([]domain.Availability, error) {
+	return s.availabilityRepo.GetByPeriod(ctx, start, end)
+}
 
-	// Update in repository
-	if err := s.repo.Update(ctx, employee); err != nil {
+// UpdateEmployeeAvailability replaces the dates, type, shift types, time
+// window and repeat rule of an availability period, as entered by a manager.
+// The period applies at once; periods from leave requests cannot be changed.
+func (s *EmployeeService) UpdateEmployeeAvailability(ctx context.Context, employeeID, id string, changes domain.Availability) (*domain.Availability, error) {
+	if err := changes.Validate(); err != nil {
 		return nil, err
 	}
 
-	return employee, nil
+	availability, err := s.GetAvailability(ctx, employeeID, id)
+	if err != nil {
+		return nil, err
+	}
+	if availability.LeaveRequestID != "" {
+		return nil, domain.ErrAvailabilityFromLeave
+	}
+	before := *availability
+
+	availability.StartDate = changes.StartDate
+	availability.EndDate = changes.EndDate
+	availability.Type = changes.Type
+	availability.Reason = changes.Reason
+	availability.ShiftTypes = changes.ShiftTypes
+	availability.Recurrence = changes.Recurrence
+	availability.StartTime = changes.StartTime
+	availability.EndTime = changes.EndTime
+	availability.Status = domain.AvailabilityStatusApproved
+	if err := s.availabilityRepo.Update(ctx, availability); err != nil {
+		return nil, err
+	}
+	s.recordAvailability(ctx, employeeID, domain.AuditActionUpdateAvailability, &before, availability)
+
+	return availability, nil
+}
+
+// RemoveEmployeeAvailability removes an availability period from an employee
+func (s *EmployeeService) RemoveEmployeeAvailability(ctx context.Context, employeeID, id string) error {
+	availability, err := s.GetAvailability(ctx, employeeID, id)
+	if err != nil {
+		return err
+	}
+	if availability.LeaveRequestID != "" {
+		return domain.ErrAvailabilityFromLeave
+	}
+
+	return s.deleteAvailability(ctx, availability, domain.AuditActionRemoveAvailability)
+}
+
+// WithdrawAvailability removes an availability period an employee submitted,
+// as long as it has not been approved
+func (s *EmployeeService) WithdrawAvailability(ctx context.Context, employeeID, id string) error {
+	availability, err := s.GetAvailability(ctx, employeeID, id)
+	if err != nil {
+		return err
+	}
+	if availability.Approved() {
+		return domain.ErrAvailabilityApproved
+	}
+
+	return s.deleteAvailability(ctx, availability, domain.AuditActionWithdrawAvailability)
+}
+
+func (s *EmployeeService) deleteAvailability(ctx context.Context, availability *domain.Availability, action string) error {
+	if err := s.availabilityRepo.Delete(ctx, availability.ID); err != nil {
+		return err
+	}
+	s.recordAvailability(ctx, availability.EmployeeID, action, availability, nil)
+
+	return nil
+}
+
+// ApproveAvailability approves a pending availability period, so that
+// scheduling honours it
+func (s *EmployeeService) ApproveAvailability(ctx context.Context, employeeID, id string) (*domain.Availability, error) {
+	return s.reviewAvailability(ctx, employeeID, id, domain.AvailabilityStatusApproved, domain.AuditActionApproveAvailability)
+}
+
+// RejectAvailability rejects a pending availability period. It stays on the
+// employee's list so they can see it was rejected.
+func (s *EmployeeService) RejectAvailability(ctx context.Context, employeeID, id string) (*domain.Availability, error) {
+	return s.reviewAvailability(ctx, employeeID, id, domain.AvailabilityStatusRejected, domain.AuditActionRejectAvailability)
 }
 
-// RemoveEmployeeAvailability removes an availability period from an employee by index
-func (s *EmployeeService) RemoveEmployeeAvailability(ctx context.Context, id string, index int) (*domain.Employee, error) {
-	employee, err := s.repo.GetByID(ctx, id)
+func (s *EmployeeService) reviewAvailability(ctx context.Context, employeeID, id string, status, action string) (*domain.Availability, error) {
+	availability, err := s.GetAvailability(ctx, employeeID, id)
 	if err != nil {
 		return nil, err
 	}
+	if !availability.Pending() {
+		return nil, domain.ErrAvailabilityReviewed
+	}
+	before := *availability
 
-	// Validate index
-	if index < 0 || index >= len(employee.Availability) {
-		return nil, domain.ErrEmployeeNotFound // Could create a new error for invalid index
+	availability.Status = status
+	if err := s.availabilityRepo.Update(ctx, availability); err != nil {
+		return nil, err
 	}
+	s.recordAvailability(ctx, employeeID, action, &before, availability)
+
+	return availability, nil
+}
 
-	// Remove the availability period at the specified index
-	employee.Availability = append(employee.Availability[:index], employee.Availability[index+1:]...)
+// recordAvailability records a change to one of an employee's availability
+// periods in the audit trail of the employee
+func (s *EmployeeService) recordAvailability(ctx context.Context, employeeID, action string, before, after *domain.Availability) {
+	s.audit.recordAvailability(ctx, s.repo, "", employeeID, action, before, after)
+}
 
-	// Update in repository
-	if err := s.repo.Update(ctx, employee); err != nil {
+// PendingAvailability is an availability period waiting for approval
+type PendingAvailability struct {
+	EmployeeID   string              `json:"employee_id"`
+	EmployeeName string              `json:"employee_name"`
+	Availability domain.Availability `json:"availability"`
+}
+
+// GetPendingAvailability lists the availability periods of active employees
+// that wait for approval
+func (s *EmployeeService) GetPendingAvailability(ctx context.Context) ([]PendingAvailability, error) {
+	employees, err := s.repo.GetActive(ctx)
+	if err != nil {
 		return nil, err
 	}
+	names := make(map[string]string, len(employees))
+	for _, employee := range employees {
+		names[employee.ID] = employee.Name
+	}
 
-	return employee, nil
+	availability, err := s.availabilityRepo.GetByStatus(ctx, domain.AvailabilityStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []PendingAvailability{}
+	for _, avail := range availability {
+		if name, ok := names[avail.EmployeeID]; ok {
+			pending = append(pending, PendingAvailability{
+				EmployeeID:   avail.EmployeeID,
+				EmployeeName: name,
+				Availability: avail,
+			})
+		}
+	}
+
+	return pending, nil
 }
@@ -2,16 +2,23 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/isak/restySched/internal/domain"
 )
 
-// MockEmployeeRepository is a mock implementation of EmployeeRepository for testing
+// MockEmployeeRepository is a mock implementation of EmployeeRepository for
+// testing. Linked to an availability repository, it loads employees with their
+// availability from it like the real repositories; otherwise employees keep
+// the availability they were stored with.
 type MockEmployeeRepository struct {
-	employees map[string]*domain.Employee
-	idCounter int
+	employees    map[string]*domain.Employee
+	idCounter    int
+	availability *MockAvailabilityRepository
 }
 
 func NewMockEmployeeRepository() *MockEmployeeRepository {
@@ -36,13 +43,13 @@ func (m *MockEmployeeRepository) GetByID(ctx context.Context, id string) (*domai
 	if !ok {
 		return nil, domain.ErrEmployeeNotFound
 	}
-	return emp, nil
+	return m.withAvailability(emp), nil
 }
 
 func (m *MockEmployeeRepository) GetAll(ctx context.Context) ([]domain.Employee, error) {
 	var result []domain.Employee
 	for _, emp := range m.employees {
-		result = append(result, *emp)
+		result = append(result, *m.withAvailability(emp))
 	}
 	return result, nil
 }
@@ -51,12 +58,23 @@ func (m *MockEmployeeRepository) GetActive(ctx context.Context) ([]domain.Employ
 	var result []domain.Employee
 	for _, emp := range m.employees {
 		if emp.Active {
-			result = append(result, *emp)
+			result = append(result, *m.withAvailability(emp))
 		}
 	}
 	return result, nil
 }
 
+// withAvailability returns a copy of the employee with the availability of the
+// linked availability repository, or the employee itself if none is linked
+func (m *MockEmployeeRepository) withAvailability(emp *domain.Employee) *domain.Employee {
+	if m.availability == nil {
+		return emp
+	}
+	loaded := *emp
+	loaded.Availability, _ = m.availability.GetByEmployee(context.Background(), emp.ID)
+	return &loaded
+}
+
 func (m *MockEmployeeRepository) Update(ctx context.Context, employee *domain.Employee) error {
 	if _, ok := m.employees[employee.ID]; !ok {
 		return domain.ErrEmployeeNotFound
@@ -83,9 +101,93 @@ func (m *MockEmployeeRepository) GetByEmail(ctx context.Context, email string) (
 	return nil, domain.ErrEmployeeNotFound
 }
 
+func (m *MockEmployeeRepository) GetByCalendarToken(ctx context.Context, token string) (*domain.Employee, error) {
+	for _, emp := range m.employees {
+		if emp.CalendarToken == token {
+			return emp, nil
+		}
+	}
+	return nil, domain.ErrEmployeeNotFound
+}
+
+func (m *MockEmployeeRepository) SetCalendarToken(ctx context.Context, id, token string) error {
+	emp, ok := m.employees[id]
+	if !ok {
+		return domain.ErrEmployeeNotFound
+	}
+	emp.CalendarToken = token
+	return nil
+}
+
+// MockAvailabilityRepository is a mock implementation of AvailabilityRepository for testing
+type MockAvailabilityRepository struct {
+	periods   []domain.Availability
+	idCounter int
+}
+
+func (m *MockAvailabilityRepository) Create(ctx context.Context, availability *domain.Availability) error {
+	if availability.ID == "" {
+		m.idCounter++
+		availability.ID = fmt.Sprintf("avail-%d", m.idCounter)
+	}
+	m.periods = append(m.periods, *availability)
+	return nil
+}
+
+func (m *MockAvailabilityRepository) GetByID(ctx context.Context, id string) (*domain.Availability, error) {
+	for _, avail := range m.periods {
+		if avail.ID == id {
+			return &avail, nil
+		}
+	}
+	return nil, domain.ErrAvailabilityNotFound
+}
+
+func (m *MockAvailabilityRepository) GetByEmployee(ctx context.Context, employeeID string) ([]domain.Availability, error) {
+	return m.filter(func(a domain.Availability) bool { return a.EmployeeID == employeeID }), nil
+}
+
+func (m *MockAvailabilityRepository) GetByStatus(ctx context.Context, statuses ...string) ([]domain.Availability, error) {
+	return m.filter(func(a domain.Availability) bool { return slices.Contains(statuses, a.Status) }), nil
+}
+
+func (m *MockAvailabilityRepository) GetByPeriod(ctx context.Context, start, end time.Time) ([]domain.Availability, error) {
+	return m.filter(func(a domain.Availability) bool { return a.OverlapsPeriod(start, end) }), nil
+}
+
+func (m *MockAvailabilityRepository) filter(keep func(domain.Availability) bool) []domain.Availability {
+	var result []domain.Availability
+	for _, avail := range m.periods {
+		if keep(avail) {
+			result = append(result, avail)
+		}
+	}
+	return result
+}
+
+func (m *MockAvailabilityRepository) Update(ctx context.Context, availability *domain.Availability) error {
+	for i := range m.periods {
+		if m.periods[i].ID == availability.ID {
+			m.periods[i] = *availability
+			return nil
+		}
+	}
+	return domain.ErrAvailabilityNotFound
+}
+
+func (m *MockAvailabilityRepository) Delete(ctx context.Context, id string) error {
+	for i := range m.periods {
+		if m.periods[i].ID == id {
+			m.periods = slices.Delete(m.periods, i, i+1)
+			return nil
+		}
+	}
+	return domain.ErrAvailabilityNotFound
+}
+
 func TestCreateEmployee(t *testing.T) {
 	repo := NewMockEmployeeRepository()
-	service := NewEmployeeService(repo)
+	service := NewEmployeeService(repo, &MockAvailabilityRepository{}, newTestAuditService())
 
 	input := domain.EmployeeCreateInput{
 		Name:            "John Doe",
@@ -115,7 +217,7 @@ func TestCreateEmployee(t *testing.T) {
 
 func TestCreateEmployeeDuplicate(t *testing.T) {
 	repo := NewMockEmployeeRepository()
-	service := NewEmployeeService(repo)
+	service := NewEmployeeService(repo, &MockAvailabilityRepository{}, newTestAuditService())
 
 	input := domain.EmployeeCreateInput{
 		Name:            "Jane Doe",
@@ -138,9 +240,31 @@ func TestCreateEmployeeDuplicate(t *testing.T) {
 	}
 }
 
+func TestUpdateEmployeeDuplicateEmail(t *testing.T) {
+	ctx := context.Background()
+	service := NewEmployeeService(NewMockEmployeeRepository(), &MockAvailabilityRepository{}, newTestAuditService())
+
+	john, _ := service.CreateEmployee(ctx, domain.EmployeeCreateInput{Name: "John Doe", Email: "john@example.com", Role: "Waiter", MonthlyHours: 160})
+	if _, err := service.CreateEmployee(ctx, domain.EmployeeCreateInput{Name: "Jane Doe", Email: "jane@example.com", Role: "Chef", MonthlyHours: 160}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keeping the same email is allowed
+	john.Role = "Head waiter"
+	if err := service.UpdateEmployee(ctx, john); err != nil {
+		t.Errorf("UpdateEmployee() error = %v", err)
+	}
+
+	changed := *john
+	changed.Email = "jane@example.com"
+	if err := service.UpdateEmployee(ctx, &changed); err != domain.ErrEmployeeAlreadyExists {
+		t.Errorf("UpdateEmployee() with another employee's email error = %v, want %v", err, domain.ErrEmployeeAlreadyExists)
+	}
+}
+
 func TestGetActiveEmployees(t *testing.T) {
 	repo := NewMockEmployeeRepository()
-	service := NewEmployeeService(repo)
+	service := NewEmployeeService(repo, &MockAvailabilityRepository{}, newTestAuditService())
 
 	// Create active employee
 	input1 := domain.EmployeeCreateInput{
@@ -177,3 +301,239 @@ func TestGetActiveEmployees(t *testing.T) {
 		t.Errorf("Expected active employee ID %s, got %s", emp1.ID, active[0].ID)
 	}
 }
+
+func TestAvailabilityApproval(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockEmployeeRepository()
+	repo.availability = &MockAvailabilityRepository{}
+	service := NewEmployeeService(repo, repo.availability, newTestAuditService())
+
+	employee, err := service.CreateEmployee(ctx, domain.EmployeeCreateInput{
+		Name: "Jane", Email: "jane@example.com", Role: "Chef", MonthlyHours: 160,
+	})
+	if err != nil {
+		t.Fatalf("CreateEmployee() error = %v", err)
+	}
+
+	monday := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	off := domain.Availability{StartDate: monday, EndDate: monday, Type: domain.AvailabilityTypeUnavailable}
+
+	approved, err := service.AddEmployeeAvailability(ctx, employee.ID, off)
+	if err != nil {
+		t.Fatalf("AddEmployeeAvailability() error = %v", err)
+	}
+	var submitted []string
+	for i := 0; i < 2; i++ {
+		added, err := service.SubmitAvailability(ctx, employee.ID, off)
+		if err != nil {
+			t.Fatalf("SubmitAvailability() error = %v", err)
+		}
+		submitted = append(submitted, added.ID)
+	}
+	if _, err := service.SubmitAvailability(ctx, "missing", off); !errors.Is(err, domain.ErrEmployeeNotFound) {
+		t.Errorf("SubmitAvailability(missing employee) error = %v, want %v", err, domain.ErrEmployeeNotFound)
+	}
+
+	pending, err := service.GetPendingAvailability(ctx)
+	if err != nil {
+		t.Fatalf("GetPendingAvailability() error = %v", err)
+	}
+	if len(pending) != 2 || pending[0].Availability.ID != submitted[0] || pending[1].Availability.ID != submitted[1] || pending[0].EmployeeName != "Jane" {
+		t.Fatalf("GetPendingAvailability() = %+v, want the two submitted periods", pending)
+	}
+
+	if err := service.WithdrawAvailability(ctx, employee.ID, approved.ID); !errors.Is(err, domain.ErrAvailabilityApproved) {
+		t.Errorf("WithdrawAvailability(approved) error = %v, want %v", err, domain.ErrAvailabilityApproved)
+	}
+	if _, err := service.ApproveAvailability(ctx, employee.ID, approved.ID); !errors.Is(err, domain.ErrAvailabilityReviewed) {
+		t.Errorf("ApproveAvailability(approved) error = %v, want %v", err, domain.ErrAvailabilityReviewed)
+	}
+	if _, err := service.ApproveAvailability(ctx, employee.ID, "missing"); !errors.Is(err, domain.ErrAvailabilityNotFound) {
+		t.Errorf("ApproveAvailability(missing) error = %v, want %v", err, domain.ErrAvailabilityNotFound)
+	}
+	if _, err := service.ApproveAvailability(ctx, "someone-else", submitted[0]); !errors.Is(err, domain.ErrAvailabilityNotFound) {
+		t.Errorf("ApproveAvailability(another employee's period) error = %v, want %v", err, domain.ErrAvailabilityNotFound)
+	}
+
+	if _, err := service.ApproveAvailability(ctx, employee.ID, submitted[0]); err != nil {
+		t.Fatalf("ApproveAvailability() error = %v", err)
+	}
+	if _, err := service.RejectAvailability(ctx, employee.ID, submitted[1]); err != nil {
+		t.Fatalf("RejectAvailability() error = %v", err)
+	}
+
+	employee, err = service.GetEmployee(ctx, employee.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statuses []string
+	for _, a := range employee.Availability {
+		statuses = append(statuses, a.Status)
+	}
+	want := []string{domain.AvailabilityStatusApproved, domain.AvailabilityStatusApproved, domain.AvailabilityStatusRejected}
+	if fmt.Sprint(statuses) != fmt.Sprint(want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+
+	// Rejected periods can be withdrawn by the employee
+	if err := service.WithdrawAvailability(ctx, employee.ID, submitted[1]); err != nil {
+		t.Fatalf("WithdrawAvailability(rejected) error = %v", err)
+	}
+	if employee, _ = service.GetEmployee(ctx, employee.ID); len(employee.Availability) != 2 {
+		t.Errorf("availability = %d periods, want 2", len(employee.Availability))
+	}
+
+	// Managers change a period by its ID; it applies at once
+	changes := off
+	changes.EndDate = monday.AddDate(0, 0, 4)
+	changes.StartTime, changes.EndTime = "12:00", "18:00"
+	updated, err := service.UpdateEmployeeAvailability(ctx, employee.ID, approved.ID, changes)
+	if err != nil {
+		t.Fatalf("UpdateEmployeeAvailability() error = %v", err)
+	}
+	if updated.ID != approved.ID || !updated.EndDate.Equal(changes.EndDate) || updated.StartTime != "12:00" || !updated.Approved() {
+		t.Errorf("Updated availability = %+v", updated)
+	}
+	inWeek, _ := service.GetAvailabilityInPeriod(ctx, monday.AddDate(0, 0, 3), monday.AddDate(0, 0, 10))
+	if len(inWeek) != 1 || inWeek[0].ID != approved.ID {
+		t.Errorf("GetAvailabilityInPeriod() = %+v, want the updated period", inWeek)
+	}
+
+	if err := service.RemoveEmployeeAvailability(ctx, employee.ID, approved.ID); err != nil {
+		t.Fatalf("RemoveEmployeeAvailability() error = %v", err)
+	}
+	if _, err := service.GetAvailability(ctx, employee.ID, approved.ID); !errors.Is(err, domain.ErrAvailabilityNotFound) {
+		t.Errorf("GetAvailability(removed) error = %v, want %v", err, domain.ErrAvailabilityNotFound)
+	}
+}
+
+func TestImportEmployees(t *testing.T) {
+	ctx := context.Background()
+
+	newService := func(t *testing.T) (*EmployeeService, *domain.Employee) {
+		t.Helper()
+		service := NewEmployeeService(NewMockEmployeeRepository(), &MockAvailabilityRepository{}, newTestAuditService())
+		existing, err := service.CreateEmployee(ctx, domain.EmployeeCreateInput{
+			Name: "Jane Doe", Email: "jane@example.com", Role: "Chef", RoleDescription: "Head chef", MonthlyHours: 120,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		existing.Availability = []domain.Availability{{Type: domain.AvailabilityTypeUnavailable}}
+		return service, existing
+	}
+
+	records := [][]string{
+		{"Name", "E-mail", "Role", "Monthly Hours", "Skills"},
+		{" John Smith ", "JOHN@example.com", "Waiter", "160", "bartender, first-aid:2026-05-31"},
+		{"Jane Doe", "jane@example.com", "Sous chef", "140.0", ""},
+		{"", "not-an-email", "Waiter", "lots", ""},
+		{"John Again", "john@example.com", "Waiter", "80", ""},
+		{"", "", "", "", ""},
+	}
+
+	t.Run("dry run reports row errors without saving", func(t *testing.T) {
+		service, _ := newService(t)
+
+		report, err := service.ImportEmployees(ctx, records, EmployeeImportOptions{DryRun: true})
+		if err != nil {
+			t.Fatalf("ImportEmployees() error = %v", err)
+		}
+		if report.Created != 1 || report.Updated != 0 || report.Skipped != 3 || len(report.Rows) != 4 {
+			t.Fatalf("Report = %d created, %d updated, %d skipped in %d rows, want 1, 0, 3 in 4",
+				report.Created, report.Updated, report.Skipped, len(report.Rows))
+		}
+
+		john := report.Rows[0]
+		if john.Line != 2 || john.Action != ImportActionCreate || john.Input.Name != "John Smith" || john.Input.Email != "john@example.com" || len(john.Input.Skills) != 2 {
+			t.Errorf("Row for John = %+v", john)
+		}
+		if jane := report.Rows[1]; jane.Action != ImportActionSkip || len(jane.Errors) != 1 || jane.Errors[0] != domain.ErrEmployeeAlreadyExists.Error() {
+			t.Errorf("Row for existing email = %+v, want skipped as already existing", jane)
+		}
+		// Validation stops at the first invalid field after the hours
+		if invalid := report.Rows[2]; len(invalid.Errors) != 2 || invalid.Errors[0] != domain.ErrInvalidMonthlyHours.Error() || invalid.Errors[1] != domain.ErrInvalidEmployeeName.Error() {
+			t.Errorf("Invalid row errors = %v, want hours and name errors", invalid.Errors)
+		}
+		if duplicate := report.Rows[3]; duplicate.Line != 5 || len(duplicate.Errors) != 1 || duplicate.Errors[0] != "email is also used on line 2" {
+			t.Errorf("Duplicate row = %+v, want error about line 2", duplicate)
+		}
+
+		if employees, _ := service.GetAllEmployees(ctx); len(employees) != 1 {
+			t.Errorf("Dry run saved employees: %d employees, want 1", len(employees))
+		}
+	})
+
+	t.Run("import saves valid rows and updates existing by email", func(t *testing.T) {
+		service, existing := newService(t)
+
+		report, err := service.ImportEmployees(ctx, records, EmployeeImportOptions{UpdateExisting: true})
+		if err != nil {
+			t.Fatalf("ImportEmployees() error = %v", err)
+		}
+		if report.Created != 1 || report.Updated != 1 || report.Skipped != 2 {
+			t.Errorf("Report = %d created, %d updated, %d skipped, want 1, 1, 2", report.Created, report.Updated, report.Skipped)
+		}
+
+		john, err := service.repo.GetByEmail(ctx, "john@example.com")
+		if err != nil || john.Name != "John Smith" || report.Rows[0].EmployeeID != john.ID {
+			t.Errorf("Imported employee = %+v, %v", john, err)
+		}
+
+		jane, err := service.GetEmployee(ctx, existing.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if jane.Role != "Sous chef" || jane.MonthlyHours != 140 || len(jane.Skills) != 0 {
+			t.Errorf("Updated employee = %+v, want role, hours and skills from the file", jane)
+		}
+		if jane.RoleDescription != "Head chef" || len(jane.Availability) != 1 {
+			t.Errorf("Update replaced fields the file does not have: %+v", jane)
+		}
+	})
+
+	t.Run("a row that cannot be saved is reported as failed", func(t *testing.T) {
+		service, _ := newService(t)
+		service.repo = &failingCreateEmployeeRepository{MockEmployeeRepository: service.repo.(*MockEmployeeRepository), email: "john@example.com"}
+
+		report, err := service.ImportEmployees(ctx, [][]string{
+			{"name", "email", "role", "monthly_hours"},
+			{"John Smith", "john@example.com", "Waiter", "160"},
+			{"Mary Major", "mary@example.com", "Waiter", "80"},
+		}, EmployeeImportOptions{})
+		if err != nil {
+			t.Fatalf("ImportEmployees() error = %v", err)
+		}
+		if report.Created != 1 || report.Failed != 1 {
+			t.Errorf("Report = %d created, %d failed, want 1, 1", report.Created, report.Failed)
+		}
+		if john := report.Rows[0]; john.Action != ImportActionFailed || len(john.Errors) != 1 || john.EmployeeID != "" {
+			t.Errorf("Row for John = %+v, want failed with the save error", john)
+		}
+		if mary := report.Rows[1]; mary.Action != ImportActionCreate || mary.EmployeeID == "" {
+			t.Errorf("Row for Mary = %+v, want created", mary)
+		}
+	})
+
+	t.Run("missing required columns", func(t *testing.T) {
+		service, _ := newService(t)
+
+		_, err := service.ImportEmployees(ctx, [][]string{{"name", "email"}}, EmployeeImportOptions{})
+		if !errors.Is(err, domain.ErrImportMissingColumns) {
+			t.Errorf("ImportEmployees() error = %v, want %v", err, domain.ErrImportMissingColumns)
+		}
+	})
+}
+
+// failingCreateEmployeeRepository fails to create the employee with email
+type failingCreateEmployeeRepository struct {
+	*MockEmployeeRepository
+	email string
+}
+
+func (m *failingCreateEmployeeRepository) Create(ctx context.Context, employee *domain.Employee) error {
+	if employee.Email == m.email {
+		return errors.New("database unavailable")
+	}
+	return m.MockEmployeeRepository.Create(ctx, employee)
+}
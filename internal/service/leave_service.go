@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// LeaveService handles employees' time-off requests: it keeps them within
+// their annual allowances, turns approved leave into availability that blocks
+// scheduling, and flags published shifts the leave collides with
+type LeaveService struct {
+	leaveRepo        repository.LeaveRepository
+	employeeRepo     repository.EmployeeRepository
+	availabilityRepo repository.AvailabilityRepository
+	scheduleRepo     repository.ScheduleRepository
+	companyRepo      repository.CompanyConfigRepository
+	notificationRepo repository.NotificationRepository
+	audit            *AuditService
+	now              func() time.Time
+}
+
+// NewLeaveService creates a new leave service
+func NewLeaveService(
+	leaveRepo repository.LeaveRepository,
+	employeeRepo repository.EmployeeRepository,
+	availabilityRepo repository.AvailabilityRepository,
+	scheduleRepo repository.ScheduleRepository,
+	companyRepo repository.CompanyConfigRepository,
+	notificationRepo repository.NotificationRepository,
+	audit *AuditService,
+) *LeaveService {
+	return &LeaveService{
+		leaveRepo:        leaveRepo,
+		employeeRepo:     employeeRepo,
+		availabilityRepo: availabilityRepo,
+		scheduleRepo:     scheduleRepo,
+		companyRepo:      companyRepo,
+		notificationRepo: notificationRepo,
+		audit:            audit,
+		now:              time.Now,
+	}
+}
+
+// LeaveBalance is an employee's use of one leave type in a calendar year, in
+// working days. Types without an allowance are not limited.
+type LeaveBalance struct {
+	Type      string  `json:"type"`
+	Limited   bool    `json:"limited"`
+	Allowance float64 `json:"allowance,omitempty"`
+	Taken     float64 `json:"taken"`   // approved
+	Pending   float64 `json:"pending"` // waiting for approval
+	Remaining float64 `json:"remaining,omitempty"`
+}
+
+// RequestLeave files a leave request for an employee. It must not overlap the
+// employee's other pending or approved leave and, with pending requests
+// counted, must fit the remaining allowance for its type.
+func (s *LeaveService) RequestLeave(ctx context.Context, employeeID, leaveType string, start, end time.Time, reason string) (*domain.LeaveRequest, error) {
+	employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !employee.Active {
+		return nil, domain.ErrEmployeeNotFound
+	}
+
+	request := &domain.LeaveRequest{
+		EmployeeID:   employee.ID,
+		EmployeeName: employee.Name,
+		Type:         leaveType,
+		StartDate:    start,
+		EndDate:      end,
+		Reason:       domain.SanitizeLeaveReason(reason),
+		Status:       domain.LeaveStatusPending,
+	}
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	companyConfig, err := s.companyRepo.GetOrCreate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load company configuration: %w", err)
+	}
+	existing, err := s.leaveRepo.GetByEmployee(ctx, employee.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range existing {
+		if existing[i].Active() && existing[i].Overlaps(request) {
+			return nil, domain.ErrLeaveOverlaps
+		}
+	}
+	if err := checkAllowance(employee, companyConfig, existing, request); err != nil {
+		return nil, err
+	}
+
+	request.Days = request.WorkingDays(companyConfig.WorkingHours, 0)
+	if request.Conflicts, err = s.conflicts(ctx, request); err != nil {
+		return nil, err
+	}
+
+	if err := s.leaveRepo.Create(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to create leave request: %w", err)
+	}
+
+	return request, nil
+}
+
+// ApproveLeave approves a pending request, re-checking the allowance, and adds
+// the leave to the employee's availability so schedules are generated around
+// it. Published shifts during the leave are flagged again but do not prevent
+// approval; a manager reassigns them.
+func (s *LeaveService) ApproveLeave(ctx context.Context, id, actor string) (*domain.LeaveRequest, error) {
+	request, err := s.leaveRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != domain.LeaveStatusPending {
+		return nil, domain.ErrLeaveNotPending
+	}
+
+	employee, err := s.employeeRepo.GetByID(ctx, request.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	companyConfig, err := s.companyRepo.GetOrCreate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load company configuration: %w", err)
+	}
+	existing, err := s.leaveRepo.GetByEmployee(ctx, employee.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAllowance(employee, companyConfig, existing, request); err != nil {
+		return nil, err
+	}
+
+	if request.Conflicts, err = s.conflicts(ctx, request); err != nil {
+		return nil, err
+	}
+
+	availability := request.Availability()
+	if err := s.availabilityRepo.Create(ctx, &availability); err != nil {
+		return nil, fmt.Errorf("failed to add availability: %w", err)
+	}
+	if err := s.decide(ctx, request, domain.LeaveStatusApproved, actor); err != nil {
+		// Leave that is still pending must not block scheduling
+		if delErr := s.availabilityRepo.Delete(ctx, availability.ID); delErr != nil {
+			log.Error().Err(delErr).Str("leave_request_id", request.ID).Msg("Failed to remove availability of unapproved leave")
+		}
+		return nil, err
+	}
+	s.audit.recordAvailability(ctx, s.employeeRepo, actor, employee.ID, domain.AuditActionAddAvailability, nil, &availability)
+
+	message := fmt.Sprintf("Your %s from %s was approved.", domain.LeaveTypeLabel(request.Type), describeLeave(request))
+	if len(request.Conflicts) > 0 {
+		message += fmt.Sprintf(" You are still scheduled for %d shift(s) during it until they are reassigned.", len(request.Conflicts))
+	}
+	s.notify(ctx, employee.ID, message)
+
+	return request, nil
+}
+
+// RejectLeave turns down a pending request
+func (s *LeaveService) RejectLeave(ctx context.Context, id, actor string) (*domain.LeaveRequest, error) {
+	request, err := s.leaveRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != domain.LeaveStatusPending {
+		return nil, domain.ErrLeaveNotPending
+	}
+
+	if err := s.decide(ctx, request, domain.LeaveStatusRejected, actor); err != nil {
+		return nil, err
+	}
+
+	s.notify(ctx, request.EmployeeID,
+		fmt.Sprintf("Your %s from %s was rejected.", domain.LeaveTypeLabel(request.Type), describeLeave(request)))
+
+	return request, nil
+}
+
+// CancelLeave cancels one of an employee's pending or approved requests. The
+// availability of approved leave is removed, so the days can be scheduled again.
+func (s *LeaveService) CancelLeave(ctx context.Context, employeeID, id, actor string) (*domain.LeaveRequest, error) {
+	request, err := s.leaveRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.EmployeeID != employeeID {
+		return nil, domain.ErrLeaveNotFound
+	}
+	if !request.Active() {
+		return nil, domain.ErrLeaveNotActive
+	}
+
+	if request.Status == domain.LeaveStatusApproved {
+		availability, err := s.availabilityRepo.GetByEmployee(ctx, employeeID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, avail := range availability {
+			if avail.LeaveRequestID != request.ID {
+				continue
+			}
+			if err := s.availabilityRepo.Delete(ctx, avail.ID); err != nil {
+				return nil, fmt.Errorf("failed to remove availability: %w", err)
+			}
+			s.audit.recordAvailability(ctx, s.employeeRepo, actor, employeeID, domain.AuditActionRemoveAvailability, &avail, nil)
+		}
+	}
+
+	if err := s.decide(ctx, request, domain.LeaveStatusCancelled, actor); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// GetLeave retrieves a leave request by ID
+func (s *LeaveService) GetLeave(ctx context.Context, id string) (*domain.LeaveRequest, error) {
+	return s.leaveRepo.GetByID(ctx, id)
+}
+
+// EmployeeLeave lists an employee's leave requests, latest first
+func (s *LeaveService) EmployeeLeave(ctx context.Context, employeeID string) ([]domain.LeaveRequest, error) {
+	requests, err := s.leaveRepo.GetByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if requests == nil {
+		requests = []domain.LeaveRequest{}
+	}
+	return requests, nil
+}
+
+// PendingLeave lists the requests waiting for approval, earliest first, with
+// their conflicts with published schedules brought up to date
+func (s *LeaveService) PendingLeave(ctx context.Context) ([]domain.LeaveRequest, error) {
+	requests, err := s.leaveRepo.GetByStatus(ctx, domain.LeaveStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	if requests == nil {
+		requests = []domain.LeaveRequest{}
+	}
+
+	schedules, err := s.scheduleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+	for i := range requests {
+		requests[i].Conflicts = leaveConflicts(schedules, &requests[i])
+	}
+	return requests, nil
+}
+
+// Balances returns the employee's allowance, taken and pending leave and the
+// remaining allowance per leave type in a calendar year
+func (s *LeaveService) Balances(ctx context.Context, employeeID string, year int) ([]LeaveBalance, error) {
+	employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	companyConfig, err := s.companyRepo.GetOrCreate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load company configuration: %w", err)
+	}
+	requests, err := s.leaveRepo.GetByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]LeaveBalance, 0, len(domain.LeaveTypes()))
+	for _, leaveType := range domain.LeaveTypes() {
+		balance := LeaveBalance{Type: leaveType}
+		for i := range requests {
+			if requests[i].Type != leaveType {
+				continue
+			}
+			switch requests[i].Status {
+			case domain.LeaveStatusApproved:
+				balance.Taken += requests[i].WorkingDays(companyConfig.WorkingHours, year)
+			case domain.LeaveStatusPending:
+				balance.Pending += requests[i].WorkingDays(companyConfig.WorkingHours, year)
+			}
+		}
+		if allowance, ok := employee.LeaveAllowance(leaveType); ok {
+			balance.Limited = true
+			balance.Allowance = allowance
+			balance.Remaining = allowance - balance.Taken - balance.Pending
+		}
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+func (s *LeaveService) decide(ctx context.Context, request *domain.LeaveRequest, status, actor string) error {
+	now := s.now()
+	request.Status = status
+	request.DecidedBy = actor
+	request.DecidedAt = &now
+	if err := s.leaveRepo.Update(ctx, request); err != nil {
+		return fmt.Errorf("failed to update leave request: %w", err)
+	}
+	return nil
+}
+
+// notify leaves a notification for an employee. Failing to store it does not
+// undo the change it reports.
+func (s *LeaveService) notify(ctx context.Context, employeeID, message string) {
+	if err := s.notificationRepo.Create(ctx, &domain.Notification{EmployeeID: employeeID, Message: message}); err != nil {
+		log.Warn().Err(err).Str("employee_id", employeeID).Msg("Failed to store notification")
+	}
+}
+
+// conflicts returns the published shifts the employee works during the leave
+func (s *LeaveService) conflicts(ctx context.Context, request *domain.LeaveRequest) ([]domain.LeaveConflict, error) {
+	schedules, err := s.scheduleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+	return leaveConflicts(schedules, request), nil
+}
+
+// leaveConflicts returns the shifts staff see the employee working during the
+// leave. Archived schedules are only kept for reference and are skipped.
+func leaveConflicts(schedules []domain.Schedule, request *domain.LeaveRequest) []domain.LeaveConflict {
+	var conflicts []domain.LeaveConflict
+	for i := range schedules {
+		schedule := &schedules[i]
+		if schedule.Status == domain.ScheduleStatusArchived {
+			continue
+		}
+		for _, a := range schedule.VisibleAssignments() {
+			if a.EmployeeID != request.EmployeeID || !request.Covers(a.Date.In(schedule.Location())) {
+				continue
+			}
+			conflicts = append(conflicts, domain.LeaveConflict{
+				ScheduleID:   schedule.ID,
+				AssignmentID: a.ID,
+				Date:         a.Date,
+				ShiftType:    a.ShiftType,
+				StartTime:    a.StartTime,
+				EndTime:      a.EndTime,
+			})
+		}
+	}
+	return conflicts
+}
+
+// checkAllowance fails if the request, with the employee's other pending and
+// approved leave of its type, takes more than the allowance in any calendar
+// year it falls in
+func checkAllowance(employee *domain.Employee, companyConfig *domain.CompanyConfig, existing []domain.LeaveRequest, request *domain.LeaveRequest) error {
+	allowance, ok := employee.LeaveAllowance(request.Type)
+	if !ok {
+		return nil
+	}
+
+	for year := request.StartDate.Year(); year <= request.EndDate.Year(); year++ {
+		used := request.WorkingDays(companyConfig.WorkingHours, year)
+		for i := range existing {
+			other := &existing[i]
+			if other.ID == request.ID || other.Type != request.Type || !other.Active() {
+				continue
+			}
+			used += other.WorkingDays(companyConfig.WorkingHours, year)
+		}
+		if used > allowance {
+			return domain.ErrLeaveAllowanceExceeded
+		}
+	}
+	return nil
+}
+
+func describeLeave(request *domain.LeaveRequest) string {
+	return fmt.Sprintf("%s to %s", request.StartDate.Format("Mon Jan 2"), request.EndDate.Format("Mon Jan 2"))
+}
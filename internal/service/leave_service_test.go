@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// MockLeaveRepository is a mock implementation of LeaveRepository for testing
+type MockLeaveRepository struct {
+	requests []*domain.LeaveRequest
+}
+
+func (m *MockLeaveRepository) Create(ctx context.Context, request *domain.LeaveRequest) error {
+	request.ID = fmt.Sprintf("mock-leave-%d", len(m.requests)+1)
+	clone := *request
+	m.requests = append(m.requests, &clone)
+	return nil
+}
+
+func (m *MockLeaveRepository) GetByID(ctx context.Context, id string) (*domain.LeaveRequest, error) {
+	for _, request := range m.requests {
+		if request.ID == id {
+			clone := *request
+			return &clone, nil
+		}
+	}
+	return nil, domain.ErrLeaveNotFound
+}
+
+func (m *MockLeaveRepository) GetByEmployee(ctx context.Context, employeeID string) ([]domain.LeaveRequest, error) {
+	var requests []domain.LeaveRequest
+	for _, request := range m.requests {
+		if request.EmployeeID == employeeID {
+			requests = append(requests, *request)
+		}
+	}
+	return requests, nil
+}
+
+func (m *MockLeaveRepository) GetByStatus(ctx context.Context, statuses ...string) ([]domain.LeaveRequest, error) {
+	var requests []domain.LeaveRequest
+	for _, request := range m.requests {
+		if slices.Contains(statuses, request.Status) {
+			requests = append(requests, *request)
+		}
+	}
+	return requests, nil
+}
+
+func (m *MockLeaveRepository) Update(ctx context.Context, request *domain.LeaveRequest) error {
+	for i, existing := range m.requests {
+		if existing.ID == request.ID {
+			clone := *request
+			m.requests[i] = &clone
+			return nil
+		}
+	}
+	return domain.ErrLeaveNotFound
+}
+
+func TestLeaveService_Workflow(t *testing.T) {
+	ctx := context.Background()
+	swaps, scheduleRepo, notifications, _ := newSwapMarketplace(t)
+	employeeRepo := swaps.employeeRepo.(*MockEmployeeRepository)
+	employeeRepo.employees["emp1"].LeaveAllowances = map[string]float64{domain.LeaveTypeVacation: 5}
+	employeeRepo.availability = &MockAvailabilityRepository{}
+	service := NewLeaveService(&MockLeaveRepository{}, employeeRepo, employeeRepo.availability, scheduleRepo, swaps.companyRepo, notifications, swaps.audit)
+
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	// emp1 works every morning of the published week of Jan 6
+	leave, err := service.RequestLeave(ctx, "emp1", domain.LeaveTypeVacation, day(8), day(9), " family ")
+	if err != nil {
+		t.Fatalf("RequestLeave() error = %v", err)
+	}
+	if leave.Status != domain.LeaveStatusPending || leave.Days != 2 || leave.Reason != "family" || leave.EmployeeName != "John Doe" {
+		t.Errorf("Leave request = %+v", leave)
+	}
+	if len(leave.Conflicts) != 2 || leave.Conflicts[0].AssignmentID != "a2" || leave.Conflicts[1].AssignmentID != "a3" {
+		t.Errorf("Conflicts = %+v, want the shifts of Jan 8 and 9", leave.Conflicts)
+	}
+
+	if _, err := service.RequestLeave(ctx, "emp1", domain.LeaveTypeSick, day(9), day(10), ""); !errors.Is(err, domain.ErrLeaveOverlaps) {
+		t.Errorf("RequestLeave() overlapping error = %v, want %v", err, domain.ErrLeaveOverlaps)
+	}
+	if _, err := service.RequestLeave(ctx, "emp1", domain.LeaveTypeVacation, day(13), day(16), ""); !errors.Is(err, domain.ErrLeaveAllowanceExceeded) {
+		t.Errorf("RequestLeave() beyond allowance error = %v, want %v", err, domain.ErrLeaveAllowanceExceeded)
+	}
+	if _, err := service.RequestLeave(ctx, "emp1", domain.LeaveTypeSick, day(13), day(16), ""); err != nil {
+		t.Errorf("RequestLeave() of a type without allowance error = %v", err)
+	}
+	if _, err := service.RequestLeave(ctx, "emp1", "holiday", day(20), day(20), ""); !errors.Is(err, domain.ErrInvalidLeaveType) {
+		t.Errorf("RequestLeave() invalid type error = %v, want %v", err, domain.ErrInvalidLeaveType)
+	}
+
+	approved, err := service.ApproveLeave(ctx, leave.ID, "manager")
+	if err != nil {
+		t.Fatalf("ApproveLeave() error = %v", err)
+	}
+	if approved.Status != domain.LeaveStatusApproved || approved.DecidedBy != "manager" || approved.DecidedAt == nil {
+		t.Errorf("Approved leave = %+v", approved)
+	}
+	if _, err := service.ApproveLeave(ctx, leave.ID, "manager"); !errors.Is(err, domain.ErrLeaveNotPending) {
+		t.Errorf("ApproveLeave() twice error = %v, want %v", err, domain.ErrLeaveNotPending)
+	}
+	if employee, _ := employeeRepo.GetByID(ctx, "emp1"); employee.IsAvailableOn(domain.ShiftTypeMorning, day(8).Add(9*time.Hour), day(8).Add(13*time.Hour)) {
+		t.Error("Approved leave does not block scheduling")
+	}
+	if got, _ := notifications.GetByEmployee(ctx, "emp1", notificationLimit); len(got) != 1 {
+		t.Errorf("Notifications = %+v, want one for the approval", got)
+	}
+
+	balances, err := service.Balances(ctx, "emp1", 2025)
+	if err != nil {
+		t.Fatalf("Balances() error = %v", err)
+	}
+	want := map[string]LeaveBalance{
+		domain.LeaveTypeVacation: {Type: domain.LeaveTypeVacation, Limited: true, Allowance: 5, Taken: 2, Remaining: 3},
+		domain.LeaveTypeSick:     {Type: domain.LeaveTypeSick, Pending: 4},
+	}
+	for _, balance := range balances {
+		if expected, ok := want[balance.Type]; ok && balance != expected {
+			t.Errorf("Balance = %+v, want %+v", balance, expected)
+		}
+	}
+
+	if _, err := service.CancelLeave(ctx, "emp2", leave.ID, "jane"); !errors.Is(err, domain.ErrLeaveNotFound) {
+		t.Errorf("CancelLeave() by another employee error = %v, want %v", err, domain.ErrLeaveNotFound)
+	}
+	if _, err := service.CancelLeave(ctx, "emp1", leave.ID, "john"); err != nil {
+		t.Fatalf("CancelLeave() error = %v", err)
+	}
+	if employee, _ := employeeRepo.GetByID(ctx, "emp1"); !employee.IsAvailableOn(domain.ShiftTypeMorning, day(8).Add(9*time.Hour), day(8).Add(13*time.Hour)) {
+		t.Error("Cancelled leave still blocks scheduling")
+	}
+	if _, err := service.CancelLeave(ctx, "emp1", leave.ID, "john"); !errors.Is(err, domain.ErrLeaveNotActive) {
+		t.Errorf("CancelLeave() twice error = %v, want %v", err, domain.ErrLeaveNotActive)
+	}
+}
+
+func TestLeaveService_AuditsAvailability(t *testing.T) {
+	ctx := context.Background()
+	swaps, scheduleRepo, notifications, _ := newSwapMarketplace(t)
+	employeeRepo := swaps.employeeRepo.(*MockEmployeeRepository)
+	employeeRepo.availability = &MockAvailabilityRepository{}
+	audit := newTestAuditService()
+	service := NewLeaveService(&MockLeaveRepository{}, employeeRepo, employeeRepo.availability, scheduleRepo, swaps.companyRepo, notifications, audit)
+
+	day := time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)
+	leave, err := service.RequestLeave(ctx, "emp1", domain.LeaveTypeSick, day, day, "flu")
+	if err != nil {
+		t.Fatalf("RequestLeave() error = %v", err)
+	}
+	if _, err := service.ApproveLeave(ctx, leave.ID, "manager@example.com"); err != nil {
+		t.Fatalf("ApproveLeave() error = %v", err)
+	}
+	if _, err := service.CancelLeave(ctx, "emp1", leave.ID, "john@example.com"); err != nil {
+		t.Fatalf("CancelLeave() error = %v", err)
+	}
+
+	entries := auditEntries(t, audit)
+	if len(entries) != 2 {
+		t.Fatalf("Entries = %+v, want the leave's availability added and removed", entries)
+	}
+	described := "unavailable 2025-01-08: Sick leave"
+	for i, want := range []struct {
+		actor, action string
+		change        domain.FieldChange
+	}{
+		{"john@example.com", domain.AuditActionRemoveAvailability, domain.FieldChange{Field: "availability", Before: described}},
+		{"manager@example.com", domain.AuditActionAddAvailability, domain.FieldChange{Field: "availability", After: described}},
+	} {
+		entry := entries[i]
+		if entry.Actor != want.actor || entry.Action != want.action || entry.EntityType != domain.AuditEntityEmployee ||
+			entry.EntityID != "emp1" || entry.EntityName != "John Doe" || len(entry.Changes) != 1 || entry.Changes[0] != want.change {
+			t.Errorf("Entry %d = %+v, want %s by %s changing %+v", i, entry, want.action, want.actor, want.change)
+		}
+	}
+}
+
+func TestLeaveService_FailedApprovalRemovesAvailability(t *testing.T) {
+	ctx := context.Background()
+	swaps, scheduleRepo, notifications, _ := newSwapMarketplace(t)
+	employeeRepo := swaps.employeeRepo.(*MockEmployeeRepository)
+	employeeRepo.availability = &MockAvailabilityRepository{}
+	leaveRepo := &failingUpdateLeaveRepository{}
+	audit := newTestAuditService()
+	service := NewLeaveService(leaveRepo, employeeRepo, employeeRepo.availability, scheduleRepo, swaps.companyRepo, notifications, audit)
+
+	day := time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)
+	leave, err := service.RequestLeave(ctx, "emp1", domain.LeaveTypeSick, day, day, "")
+	if err != nil {
+		t.Fatalf("RequestLeave() error = %v", err)
+	}
+
+	leaveRepo.err = errors.New("database unavailable")
+	if _, err := service.ApproveLeave(ctx, leave.ID, "manager"); err == nil {
+		t.Fatal("ApproveLeave() error = nil, want the update error")
+	}
+	if stored, _ := leaveRepo.GetByID(ctx, leave.ID); stored.Status != domain.LeaveStatusPending {
+		t.Errorf("Leave status = %s, want %s", stored.Status, domain.LeaveStatusPending)
+	}
+	if availability, _ := employeeRepo.availability.GetByEmployee(ctx, "emp1"); len(availability) != 0 {
+		t.Errorf("Availability = %+v, want none for leave that was not approved", availability)
+	}
+	if entries := auditEntries(t, audit); len(entries) != 0 {
+		t.Errorf("Entries = %+v, want none", entries)
+	}
+}
+
+// failingUpdateLeaveRepository fails every update while err is set
+type failingUpdateLeaveRepository struct {
+	MockLeaveRepository
+	err error
+}
+
+func (m *failingUpdateLeaveRepository) Update(ctx context.Context, request *domain.LeaveRequest) error {
+	if m.err != nil {
+		return m.err
+	}
+	return m.MockLeaveRepository.Update(ctx, request)
+}
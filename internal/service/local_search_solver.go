@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultSearchIterations = 20000
+	initialTemperature      = 20.0
+	finalTemperature        = 0.05
+)
+
+// LocalSearchSolver is a scheduling strategy that starts from the greedy schedule
+// and improves it with simulated annealing over the weighted scheduling objective
+// (coverage, policy violations, hour-target deviation, preferences and fairness).
+// Availability, one shift per day and MaxEmployees are never broken; scheduling
+// policies are only broken when that buys more coverage than it costs.
+type LocalSearchSolver struct {
+	iterations int
+	weights    ObjectiveWeights
+	seed       int64
+	initial    Strategy
+}
+
+// NewLocalSearchSolver creates a new local search solver. The search is seeded
+// deterministically so the same input always produces the same schedule.
+func NewLocalSearchSolver() *LocalSearchSolver {
+	return &LocalSearchSolver{
+		iterations: defaultSearchIterations,
+		weights:    DefaultObjectiveWeights,
+		seed:       1,
+		initial:    NewShiftGenerator(),
+	}
+}
+
+// GenerateShifts creates shift assignments for employees over the schedule period.
+// When ctx is done the search stops early and the best schedule found so far is
+// returned.
+func (s *LocalSearchSolver) GenerateShifts(ctx context.Context, employees []domain.Employee, config *domain.CompanyConfig, periodStart, periodEnd time.Time, history []domain.ShiftAssignment) GenerationResult {
+	p := newProblem(employees, config, periodStart, periodEnd, history)
+	if len(p.days) == 0 || len(employees) == 0 {
+		log.Warn().Msg("No workdays or employees in schedule period")
+		return GenerationResult{}
+	}
+
+	initial := s.initial.GenerateShifts(ctx, employees, config, periodStart, periodEnd, history)
+	current := p.solutionFrom(initial.Assignments)
+	tally := p.newTally(current)
+	currentCost := tally.score(s.weights).Objective
+	best, bestCost := current.clone(), currentCost
+
+	rng := rand.New(rand.NewSource(s.seed))
+	temperature := initialTemperature
+	cooling := math.Pow(finalTemperature/initialTemperature, 1/float64(s.iterations))
+
+	for i := 0; i < s.iterations; i++ {
+		if ctx.Err() != nil {
+			log.Warn().Err(ctx.Err()).Int("iterations", i).Msg("Local search stopped early")
+			break
+		}
+		temperature *= cooling
+
+		m := s.randomMove(p, current, rng)
+		if m == nil {
+			continue
+		}
+		tally.update(m.day, m.shifts, m.employees)
+
+		cost := tally.score(s.weights).Objective
+		delta := cost - currentCost
+		if delta > 0 && rng.Float64() >= math.Exp(-delta/temperature) {
+			m.undo()
+			tally.update(m.day, m.shifts, m.employees)
+			continue
+		}
+
+		currentCost = cost
+		if cost < bestCost {
+			best, bestCost = current.clone(), cost
+		}
+	}
+
+	result := GenerationResult{Assignments: p.assignments(best)}
+	result.Understaffed, result.RelaxedConstraints = p.findings(best)
+	result.Score = p.score(best, DefaultObjectiveWeights)
+
+	log.Info().
+		Int("total_assignments", len(result.Assignments)).
+		Float64("initial_objective", initial.Score.Objective).
+		Float64("objective", result.Score.Objective).
+		Msg("Local search shift generation complete")
+
+	return result
+}
+
+// move is a change made to a solution: the day it was made on, the shifts and
+// employees whose score it changed and a function that reverts it
+type move struct {
+	day       int
+	shifts    []int
+	employees []int
+	undo      func()
+}
+
+// randomMove applies a random neighbouring change to sol, or returns nil if the
+// drawn move was not possible
+func (s *LocalSearchSolver) randomMove(p *problem, sol *solution, rng *rand.Rand) *move {
+	d := rng.Intn(len(p.days))
+	r := rng.Intn(len(p.requirements))
+	if p.shiftDefs[r] == nil {
+		return nil
+	}
+	staff := sol.staff[d][r]
+
+	switch rng.Intn(4) {
+	case 0: // Add an employee who is off that day
+		e := rng.Intn(len(p.employees))
+		if len(staff) >= p.requirements[r].MaxEmployees || sol.working[d][e] >= 0 || !p.available[d][r][e] {
+			return nil
+		}
+		sol.add(d, r, e)
+		return &move{day: d, shifts: []int{r}, employees: []int{e}, undo: func() {
+			sol.remove(d, r, len(sol.staff[d][r])-1)
+		}}
+
+	case 1: // Remove an employee from the shift
+		if len(staff) == 0 {
+			return nil
+		}
+		e := sol.remove(d, r, rng.Intn(len(staff)))
+		return &move{day: d, shifts: []int{r}, employees: []int{e}, undo: func() {
+			sol.add(d, r, e)
+		}}
+
+	case 2: // Hand the shift to an employee who is off that day
+		e := rng.Intn(len(p.employees))
+		if len(staff) == 0 || sol.working[d][e] >= 0 || !p.available[d][r][e] {
+			return nil
+		}
+		slot := rng.Intn(len(staff))
+		old := staff[slot]
+		staff[slot] = e
+		sol.working[d][old], sol.working[d][e] = -1, r
+		return &move{day: d, shifts: []int{r}, employees: []int{old, e}, undo: func() {
+			staff[slot] = old
+			sol.working[d][e], sol.working[d][old] = -1, r
+		}}
+
+	default: // Swap two employees between shifts on the same day
+		r2 := rng.Intn(len(p.requirements))
+		other := sol.staff[d][r2]
+		if r2 == r || p.shiftDefs[r2] == nil || len(staff) == 0 || len(other) == 0 {
+			return nil
+		}
+		i, j := rng.Intn(len(staff)), rng.Intn(len(other))
+		a, b := staff[i], other[j]
+		if !p.available[d][r2][a] || !p.available[d][r][b] {
+			return nil
+		}
+		staff[i], other[j] = b, a
+		sol.working[d][a], sol.working[d][b] = r2, r
+		return &move{day: d, shifts: []int{r, r2}, employees: []int{a, b}, undo: func() {
+			staff[i], other[j] = a, b
+			sol.working[d][a], sol.working[d][b] = r, r2
+		}}
+	}
+}
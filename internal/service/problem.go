@@ -0,0 +1,632 @@
+package service
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultShiftRequirements is used when the company has not configured any shift requirements
+var defaultShiftRequirements = []domain.ShiftRequirement{
+	{ShiftType: domain.ShiftTypeFullDay, MinEmployees: 1, MaxEmployees: 3},
+}
+
+// defaultWorkingDays is used when the company has not configured working days (Monday to Friday)
+var defaultWorkingDays = []int{1, 2, 3, 4, 5}
+
+// problem is a schedule period resolved against the company configuration. Every
+// scheduling strategy works from the same problem so they see the same days,
+// targets and limits and are scored by the same objective.
+type problem struct {
+	employees        []domain.Employee
+	requirements     []domain.ShiftRequirement
+	shiftDefs        []*domain.ShiftDefinition // parallel to requirements, nil for unknown shift types
+	skillNeeds       [][]domain.SkillNeed      // parallel to requirements
+	workingHours     domain.WorkingHours
+	loc              *time.Location
+	days             []time.Time // working days at local midnight
+	targets          []float64   // target hours per employee for the period
+	monthFraction    float64     // length of the period as a fraction of a month
+	limits           schedulingLimits
+	fairDistribution bool
+	carried          []employeeState // per employee, the state left by shifts before the period
+
+	// Lookups precomputed per day and requirement
+	shifts    [][]candidateShift
+	available [][][]bool // day -> requirement -> employee
+	preferred [][][]bool // day -> requirement -> employee
+}
+
+// employeeState tracks what has been assigned to an employee so far in a generation run
+type employeeState struct {
+	employee        domain.Employee
+	targetHours     float64
+	assignedHours   float64
+	lastWorkedDay   time.Time
+	lastShiftEnd    time.Time
+	consecutiveDays int
+}
+
+// schedulingLimits are the scheduling policies resolved for a single generation run
+type schedulingLimits struct {
+	maxConsecutiveDays int           // 0 means no limit
+	minRest            time.Duration // 0 means no limit
+	overtimeHours      float64       // hours allowed above the period target
+}
+
+// candidateShift is a concrete shift on a specific day being filled
+type candidateShift struct {
+	shiftType string
+	date      time.Time
+	start     time.Time
+	end       time.Time
+	hours     float64
+}
+
+func newCandidateShift(def domain.ShiftDefinition, date time.Time, loc *time.Location) candidateShift {
+	start, end := def.Span(date, loc)
+	return candidateShift{
+		shiftType: def.Type,
+		date:      date,
+		start:     start,
+		end:       end,
+		hours:     def.HoursOn(date, loc),
+	}
+}
+
+// newProblem resolves the company configuration for the given period, falling back
+// to defaults for anything that is not configured. history holds shifts already
+// worked in earlier schedules; those before the period carry consecutive days and
+// rest over into it.
+func newProblem(employees []domain.Employee, config *domain.CompanyConfig, periodStart, periodEnd time.Time, history []domain.ShiftAssignment) *problem {
+	p := &problem{
+		employees:    employees,
+		requirements: defaultShiftRequirements,
+		workingHours: domain.WorkingHours{WorkingDays: defaultWorkingDays},
+		limits:       schedulingLimits{overtimeHours: math.Inf(1)},
+	}
+	if config != nil {
+		p.workingHours = config.WorkingHours
+		if len(p.workingHours.WorkingDays) == 0 {
+			p.workingHours.WorkingDays = defaultWorkingDays
+		}
+		if len(config.ShiftRequirements) > 0 {
+			p.requirements = config.ShiftRequirements
+		}
+		p.fairDistribution = config.SchedulingPolicies.FairDistribution
+	}
+	p.loc = p.workingHours.Location()
+
+	// Calendar days in the company's timezone, counting both the first and last day
+	calendarDays := 0
+	lastDay := startOfDay(periodEnd, p.loc)
+	for day := startOfDay(periodStart, p.loc); !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+		calendarDays++
+		if p.workingHours.IsWorkingDay(day.Weekday()) {
+			p.days = append(p.days, day)
+		}
+	}
+	monthFraction := float64(calendarDays) / 30.0 // Approximate
+	p.monthFraction = monthFraction
+
+	if config != nil {
+		p.limits = resolveLimits(config.SchedulingPolicies, monthFraction)
+	}
+
+	// Target hours = (monthly hours * fraction of month in this period)
+	p.targets = make([]float64, len(employees))
+	for e, emp := range employees {
+		p.targets[e] = float64(emp.MonthlyHours) * monthFraction
+	}
+
+	p.shiftDefs = make([]*domain.ShiftDefinition, len(p.requirements))
+	p.skillNeeds = make([][]domain.SkillNeed, len(p.requirements))
+	for r, req := range p.requirements {
+		p.shiftDefs[r] = config.GetShiftDefinition(req.ShiftType)
+		if p.shiftDefs[r] == nil {
+			log.Warn().
+				Str("shift_type", req.ShiftType).
+				Msg("Unknown shift type in shift requirements, skipping")
+		}
+		p.skillNeeds[r] = req.SkillNeeds()
+	}
+
+	p.shifts = make([][]candidateShift, len(p.days))
+	p.available = make([][][]bool, len(p.days))
+	p.preferred = make([][][]bool, len(p.days))
+	for d, day := range p.days {
+		p.shifts[d] = make([]candidateShift, len(p.requirements))
+		p.available[d] = make([][]bool, len(p.requirements))
+		p.preferred[d] = make([][]bool, len(p.requirements))
+		for r, def := range p.shiftDefs {
+			p.available[d][r] = make([]bool, len(employees))
+			p.preferred[d][r] = make([]bool, len(employees))
+			if def == nil {
+				continue
+			}
+			shift := newCandidateShift(*def, day, p.loc)
+			p.shifts[d][r] = shift
+			for e := range employees {
+				p.available[d][r][e] = employees[e].IsAvailableOn(def.Type, shift.start, shift.end)
+				p.preferred[d][r][e] = employees[e].GetPreference(def.Type, shift.start, shift.end) > 0
+			}
+		}
+	}
+
+	p.carryOver(history, startOfDay(periodStart, p.loc))
+
+	return p
+}
+
+// carryOver replays each employee's shifts before periodStart so that a streak of
+// working days or a late shift just before the period counts against it
+func (p *problem) carryOver(history []domain.ShiftAssignment, periodStart time.Time) {
+	p.carried = make([]employeeState, len(p.employees))
+
+	type workedShift struct {
+		employee int
+		shift    candidateShift
+	}
+	var worked []workedShift
+	for _, a := range history {
+		e := p.employeeIndex(a.EmployeeID)
+		date := startOfDay(a.Date, p.loc)
+		if e < 0 || !date.Before(periodStart) {
+			continue
+		}
+		def := domain.ShiftDefinition{Type: a.ShiftType, StartTime: a.StartTime, EndTime: a.EndTime}
+		worked = append(worked, workedShift{employee: e, shift: newCandidateShift(def, date, p.loc)})
+	}
+	slices.SortStableFunc(worked, func(a, b workedShift) int {
+		return a.shift.start.Compare(b.shift.start)
+	})
+
+	for _, w := range worked {
+		state := &p.carried[w.employee]
+		if state.lastWorkedDay.Equal(w.shift.date) {
+			// A second shift the same day, e.g. from overlapping schedules
+			state.lastShiftEnd = w.shift.end
+			continue
+		}
+		state.record(w.shift)
+	}
+}
+
+// newState returns fresh tracking state for employee e, continuing from the
+// shifts they worked before the period
+func (p *problem) newState(e int) *employeeState {
+	carried := p.carried[e]
+	return &employeeState{
+		employee:        p.employees[e],
+		targetHours:     p.targets[e],
+		lastWorkedDay:   carried.lastWorkedDay,
+		lastShiftEnd:    carried.lastShiftEnd,
+		consecutiveDays: carried.consecutiveDays,
+	}
+}
+
+// newStates returns fresh per-employee tracking state for a generation run
+func (p *problem) newStates() []*employeeState {
+	states := make([]*employeeState, len(p.employees))
+	for e := range p.employees {
+		states[e] = p.newState(e)
+	}
+	return states
+}
+
+// startOfDay returns local midnight of the calendar day t falls on in loc
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// resolveLimits converts the company's scheduling policies into limits for this period.
+// MaxOvertimeHours is a monthly figure and is prorated over the period.
+func resolveLimits(policies domain.SchedulingPolicies, monthFraction float64) schedulingLimits {
+	limits := schedulingLimits{
+		maxConsecutiveDays: policies.MaxConsecutiveDays,
+		minRest:            time.Duration(policies.MinRestHours) * time.Hour,
+	}
+	if policies.AllowOvertime {
+		limits.overtimeHours = float64(policies.MaxOvertimeHours) * monthFraction
+	}
+	return limits
+}
+
+// record updates the state after the employee has been given shift
+func (st *employeeState) record(shift candidateShift) {
+	if st.lastWorkedDay.AddDate(0, 0, 1).Equal(shift.date) {
+		st.consecutiveDays++
+	} else {
+		st.consecutiveDays = 1
+	}
+	st.lastWorkedDay = shift.date
+	st.lastShiftEnd = shift.end
+	st.assignedHours += shift.hours
+}
+
+// violations returns the scheduling constraints the employee would break by working shift
+func violations(state *employeeState, shift candidateShift, limits schedulingLimits) []string {
+	var broken []string
+
+	if state.assignedHours+shift.hours > state.targetHours+limits.overtimeHours {
+		broken = append(broken, domain.ConstraintOvertime)
+	}
+
+	if limits.maxConsecutiveDays > 0 {
+		consecutive := 1
+		if state.lastWorkedDay.AddDate(0, 0, 1).Equal(shift.date) {
+			consecutive = state.consecutiveDays + 1
+		}
+		if consecutive > limits.maxConsecutiveDays {
+			broken = append(broken, domain.ConstraintMaxConsecutiveDays)
+		}
+	}
+
+	if limits.minRest > 0 && !state.lastShiftEnd.IsZero() && shift.start.Sub(state.lastShiftEnd) < limits.minRest {
+		broken = append(broken, domain.ConstraintMinRestHours)
+	}
+
+	return broken
+}
+
+// solution is a set of assignments indexed by working day and shift requirement
+type solution struct {
+	staff   [][][]int // day -> requirement -> employee indexes
+	working [][]int   // day -> employee -> requirement index, or -1 when off
+}
+
+func (p *problem) newSolution() *solution {
+	sol := &solution{
+		staff:   make([][][]int, len(p.days)),
+		working: make([][]int, len(p.days)),
+	}
+	for d := range p.days {
+		sol.staff[d] = make([][]int, len(p.requirements))
+		sol.working[d] = make([]int, len(p.employees))
+		for e := range sol.working[d] {
+			sol.working[d][e] = -1
+		}
+	}
+	return sol
+}
+
+func (sol *solution) clone() *solution {
+	c := &solution{
+		staff:   make([][][]int, len(sol.staff)),
+		working: make([][]int, len(sol.working)),
+	}
+	for d := range sol.staff {
+		c.staff[d] = make([][]int, len(sol.staff[d]))
+		for r := range sol.staff[d] {
+			c.staff[d][r] = append([]int(nil), sol.staff[d][r]...)
+		}
+		c.working[d] = append([]int(nil), sol.working[d]...)
+	}
+	return c
+}
+
+func (sol *solution) add(d, r, e int) {
+	sol.staff[d][r] = append(sol.staff[d][r], e)
+	sol.working[d][e] = r
+}
+
+func (sol *solution) remove(d, r, slot int) int {
+	e := sol.staff[d][r][slot]
+	sol.staff[d][r] = append(sol.staff[d][r][:slot], sol.staff[d][r][slot+1:]...)
+	sol.working[d][e] = -1
+	return e
+}
+
+// solutionFrom indexes assignments against the problem. Assignments on days or
+// shift types outside the problem are ignored.
+func (p *problem) solutionFrom(assignments []domain.ShiftAssignment) *solution {
+	sol := p.newSolution()
+
+	dayIndex := make(map[string]int, len(p.days))
+	for d, day := range p.days {
+		dayIndex[day.Format("2006-01-02")] = d
+	}
+	employeeIndex := make(map[string]int, len(p.employees))
+	for e, emp := range p.employees {
+		employeeIndex[emp.ID] = e
+	}
+
+	for _, a := range assignments {
+		d, ok := dayIndex[a.Date.In(p.loc).Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		e, ok := employeeIndex[a.EmployeeID]
+		if !ok || sol.working[d][e] >= 0 {
+			continue
+		}
+		for r, req := range p.requirements {
+			if req.ShiftType == a.ShiftType && p.shiftDefs[r] != nil {
+				sol.add(d, r, e)
+				break
+			}
+		}
+	}
+
+	return sol
+}
+
+// assignments converts a solution back into shift assignments, ordered by day and shift
+func (p *problem) assignments(sol *solution) []domain.ShiftAssignment {
+	var assignments []domain.ShiftAssignment
+	for d := range p.days {
+		for r, staff := range sol.staff[d] {
+			for _, e := range staff {
+				assignments = append(assignments, p.newAssignment(d, r, e))
+			}
+		}
+	}
+	return assignments
+}
+
+// newAssignment creates the assignment of employee e to requirement r on day d
+func (p *problem) newAssignment(d, r, e int) domain.ShiftAssignment {
+	def := p.shiftDefs[r]
+	return domain.ShiftAssignment{
+		EmployeeID:   p.employees[e].ID,
+		EmployeeName: p.employees[e].Name,
+		Date:         p.days[d],
+		ShiftType:    def.Type,
+		StartTime:    def.StartTime,
+		EndTime:      def.EndTime,
+		Hours:        p.shifts[d][r].hours,
+	}
+}
+
+// locate returns the day and requirement indexes of a shift type on the calendar
+// day of date, or false if it is not a working day of the problem or the shift
+// type is not required
+func (p *problem) locate(date time.Time, shiftType string) (int, int, bool) {
+	day := date.In(p.loc).Format("2006-01-02")
+	for d := range p.days {
+		if p.days[d].Format("2006-01-02") != day {
+			continue
+		}
+		for r, req := range p.requirements {
+			if req.ShiftType == shiftType && p.shiftDefs[r] != nil {
+				return d, r, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// employeeIndex returns the index of the employee with the given ID, or -1
+func (p *problem) employeeIndex(id string) int {
+	for e := range p.employees {
+		if p.employees[e].ID == id {
+			return e
+		}
+	}
+	return -1
+}
+
+// ObjectiveWeights weighs the terms of the scheduling objective. Lower objective
+// values are better.
+type ObjectiveWeights struct {
+	CoverageGap     float64 // per missing employee or missing skill on a shift
+	PolicyViolation float64 // per broken scheduling policy
+	HourDeviation   float64 // per hour an employee is away from their target
+	Preference      float64 // reward per shift worked on a preferred day
+	Fairness        float64 // per percentage point of spread in assigned/target ratios
+}
+
+// DefaultObjectiveWeights prioritises coverage, then policies, then hours and fairness
+var DefaultObjectiveWeights = ObjectiveWeights{
+	CoverageGap:     100,
+	PolicyViolation: 50,
+	HourDeviation:   1,
+	Preference:      2,
+	Fairness:        0.5,
+}
+
+// score evaluates a solution against the weighted objective
+func (p *problem) score(sol *solution, w ObjectiveWeights) domain.ScheduleScore {
+	return p.newTally(sol).score(w)
+}
+
+// employeeScore holds the terms of the objective one employee contributes
+type employeeScore struct {
+	violations int
+	hours      float64
+	preferred  int
+}
+
+// scoreTally keeps the terms of the objective per shift and per employee, so a
+// change to a solution only re-evaluates the shifts and employees it touches
+type scoreTally struct {
+	p            *problem
+	sol          *solution
+	gaps         [][]int // day -> requirement -> coverage gap
+	employees    []employeeScore
+	coverageGaps int
+	violations   int
+	preferred    int
+}
+
+func (p *problem) newTally(sol *solution) *scoreTally {
+	t := &scoreTally{
+		p:         p,
+		sol:       sol,
+		gaps:      make([][]int, len(p.days)),
+		employees: make([]employeeScore, len(p.employees)),
+	}
+	for d := range p.days {
+		t.gaps[d] = make([]int, len(p.requirements))
+		for r := range p.requirements {
+			t.gaps[d][r] = p.coverageGap(sol, d, r)
+			t.coverageGaps += t.gaps[d][r]
+		}
+	}
+	for e := range p.employees {
+		t.employees[e] = p.scoreEmployee(sol, e)
+		t.violations += t.employees[e].violations
+		t.preferred += t.employees[e].preferred
+	}
+	return t
+}
+
+// update re-evaluates the given shifts on day d and the given employees after
+// the solution changed
+func (t *scoreTally) update(d int, shifts, employees []int) {
+	for _, r := range shifts {
+		gap := t.p.coverageGap(t.sol, d, r)
+		t.coverageGaps += gap - t.gaps[d][r]
+		t.gaps[d][r] = gap
+	}
+	for _, e := range employees {
+		es := t.p.scoreEmployee(t.sol, e)
+		t.violations += es.violations - t.employees[e].violations
+		t.preferred += es.preferred - t.employees[e].preferred
+		t.employees[e] = es
+	}
+}
+
+// score sums the tallied terms into the weighted objective
+func (t *scoreTally) score(w ObjectiveWeights) domain.ScheduleScore {
+	score := domain.ScheduleScore{
+		CoverageGaps:     t.coverageGaps,
+		PolicyViolations: t.violations,
+		PreferredShifts:  t.preferred,
+	}
+
+	var mean float64
+	ratios := 0
+	for e, es := range t.employees {
+		target := t.p.targets[e]
+		score.HourDeviation += math.Abs(es.hours - target)
+		if target > 0 {
+			mean += es.hours / target * 100
+			ratios++
+		}
+	}
+
+	if ratios > 0 {
+		mean /= float64(ratios)
+		var variance float64
+		for e, es := range t.employees {
+			if target := t.p.targets[e]; target > 0 {
+				r := es.hours / target * 100
+				variance += (r - mean) * (r - mean)
+			}
+		}
+		score.Fairness = math.Sqrt(variance / float64(ratios))
+	}
+
+	score.Objective = w.CoverageGap*float64(score.CoverageGaps) +
+		w.PolicyViolation*float64(score.PolicyViolations) +
+		w.HourDeviation*score.HourDeviation -
+		w.Preference*float64(score.PreferredShifts)
+	if t.p.fairDistribution {
+		score.Objective += w.Fairness * score.Fairness
+	}
+
+	return score
+}
+
+// coverageGap returns how many employees and skilled employees shift r on day d is short of
+func (p *problem) coverageGap(sol *solution, d, r int) int {
+	if p.shiftDefs[r] == nil {
+		return 0
+	}
+	staff := sol.staff[d][r]
+	gap := max(p.requirements[r].MinEmployees-len(staff), 0)
+	for _, need := range p.skillNeeds[r] {
+		covered := 0
+		for _, e := range staff {
+			if p.employees[e].HasSkill(need.Skill, p.days[d]) {
+				covered++
+			}
+		}
+		gap += max(need.Count-covered, 0)
+	}
+	return gap
+}
+
+// scoreEmployee evaluates the shifts employee e works in a solution
+func (p *problem) scoreEmployee(sol *solution, e int) employeeScore {
+	var es employeeScore
+	state := p.newState(e)
+	for d := range p.days {
+		r := sol.working[d][e]
+		if r < 0 {
+			continue
+		}
+		shift := p.shifts[d][r]
+		es.violations += len(violations(state, shift, p.limits))
+		state.record(shift)
+		if p.preferred[d][r][e] {
+			es.preferred++
+		}
+	}
+	es.hours = state.assignedHours
+	return es
+}
+
+// findings lists the understaffed shifts and broken policies of a solution
+func (p *problem) findings(sol *solution) ([]domain.UnderstaffedShift, []domain.RelaxedConstraint) {
+	var understaffed []domain.UnderstaffedShift
+	var relaxed []domain.RelaxedConstraint
+
+	for d, day := range p.days {
+		for r, req := range p.requirements {
+			if p.shiftDefs[r] == nil {
+				continue
+			}
+			staff := sol.staff[d][r]
+			var missing []string
+			for _, need := range p.skillNeeds[r] {
+				covered := 0
+				for _, e := range staff {
+					if p.employees[e].HasSkill(need.Skill, day) {
+						covered++
+					}
+				}
+				if covered < need.Count {
+					missing = append(missing, need.Skill)
+				}
+			}
+			if len(staff) < req.MinEmployees || len(missing) > 0 {
+				understaffed = append(understaffed, domain.UnderstaffedShift{
+					Date:          day,
+					ShiftType:     req.ShiftType,
+					Required:      req.MinEmployees,
+					Assigned:      len(staff),
+					MissingSkills: missing,
+				})
+			}
+		}
+	}
+
+	for e, emp := range p.employees {
+		state := p.newState(e)
+		for d, day := range p.days {
+			r := sol.working[d][e]
+			if r < 0 {
+				continue
+			}
+			shift := p.shifts[d][r]
+			for _, constraint := range violations(state, shift, p.limits) {
+				relaxed = append(relaxed, domain.RelaxedConstraint{
+					Date:         day,
+					ShiftType:    shift.shiftType,
+					EmployeeID:   emp.ID,
+					EmployeeName: emp.Name,
+					Constraint:   constraint,
+				})
+			}
+			state.record(shift)
+		}
+	}
+
+	return understaffed, relaxed
+}
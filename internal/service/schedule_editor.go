@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/isak/restySched/internal/domain"
+)
+
+// AddAssignment adds a shift for an employee to a draft schedule. date is a
+// calendar date in the company's timezone.
+func (s *ScheduleService) AddAssignment(ctx context.Context, scheduleID string, version int, employeeID string, date time.Time, shiftType string) (*domain.Schedule, error) {
+	return s.editSchedule(ctx, scheduleID, version, domain.AuditActionAddAssignment, func(schedule *domain.Schedule, p *problem) error {
+		e := p.employeeIndex(employeeID)
+		if e < 0 {
+			employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+			if err != nil {
+				return err
+			}
+			if !employee.Active {
+				return domain.ErrEmployeeNotFound
+			}
+			schedule.Employees = append(schedule.Employees, *employee)
+			p.addEmployee(*employee)
+			e = len(p.employees) - 1
+		}
+
+		d, r, ok := p.locate(domain.LocalMidnight(date, p.loc), shiftType)
+		if !ok {
+			return domain.ErrInvalidAssignment
+		}
+
+		assignment := p.newAssignment(d, r, e)
+		assignment.ID = uuid.New().String()
+		schedule.Assignments = append(schedule.Assignments, assignment)
+		return nil
+	})
+}
+
+// RemoveAssignment removes a shift from a draft schedule
+func (s *ScheduleService) RemoveAssignment(ctx context.Context, scheduleID string, version int, assignmentID string) (*domain.Schedule, error) {
+	return s.editSchedule(ctx, scheduleID, version, domain.AuditActionRemoveAssignment, func(schedule *domain.Schedule, p *problem) error {
+		i := schedule.FindAssignment(assignmentID)
+		if i < 0 {
+			return domain.ErrAssignmentNotFound
+		}
+		schedule.Assignments = append(schedule.Assignments[:i], schedule.Assignments[i+1:]...)
+		return nil
+	})
+}
+
+// MoveAssignment moves a shift in a draft schedule to another day and/or shift
+// type, keeping the employee. date is a calendar date in the company's timezone.
+func (s *ScheduleService) MoveAssignment(ctx context.Context, scheduleID string, version int, assignmentID string, date time.Time, shiftType string) (*domain.Schedule, error) {
+	return s.editSchedule(ctx, scheduleID, version, domain.AuditActionMoveAssignment, func(schedule *domain.Schedule, p *problem) error {
+		i := schedule.FindAssignment(assignmentID)
+		if i < 0 {
+			return domain.ErrAssignmentNotFound
+		}
+
+		e := p.employeeIndex(schedule.Assignments[i].EmployeeID)
+		d, r, ok := p.locate(domain.LocalMidnight(date, p.loc), shiftType)
+		if e < 0 || !ok {
+			return domain.ErrInvalidAssignment
+		}
+
+		moved := p.newAssignment(d, r, e)
+		moved.ID = assignmentID
+		schedule.Assignments[i] = moved
+		return nil
+	})
+}
+
+// SwapAssignments swaps the employees of two shifts in a draft schedule
+func (s *ScheduleService) SwapAssignments(ctx context.Context, scheduleID string, version int, assignmentID, otherID string) (*domain.Schedule, error) {
+	return s.editSchedule(ctx, scheduleID, version, domain.AuditActionSwapAssignments, func(schedule *domain.Schedule, p *problem) error {
+		i, j := schedule.FindAssignment(assignmentID), schedule.FindAssignment(otherID)
+		if i < 0 || j < 0 || i == j {
+			return domain.ErrAssignmentNotFound
+		}
+
+		a, b := &schedule.Assignments[i], &schedule.Assignments[j]
+		a.EmployeeID, b.EmployeeID = b.EmployeeID, a.EmployeeID
+		a.EmployeeName, b.EmployeeName = b.EmployeeName, a.EmployeeName
+		return nil
+	})
+}
+
+// editSchedule loads a draft schedule, applies edit to it and saves it once the
+// result has been re-validated. Edits that leave an employee with two shifts on
+// one day are rejected; availability, staffing and scheduling policies are
+// re-checked and reported as warnings, understaffed shifts and relaxed policies.
+// A version other than 0 is the version of the schedule the edit was made on;
+// if the schedule has changed since, ErrConflict is returned. The edit is
+// recorded in the audit trail as action.
+func (s *ScheduleService) editSchedule(ctx context.Context, id string, version int, action string, edit func(*domain.Schedule, *problem) error) (*domain.Schedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := domain.CheckVersion(version, schedule.Version); err != nil {
+		return nil, err
+	}
+
+	if !schedule.IsEditable() {
+		return nil, domain.ErrScheduleNotEditable
+	}
+
+	companyConfig, err := s.companyRepo.GetOrCreate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load company configuration: %w", err)
+	}
+
+	// Validate against current availability rather than the snapshot taken at generation
+	if err := s.refreshEmployees(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	// Schedules generated before assignments had IDs get them on their first edit
+	for i := range schedule.Assignments {
+		if schedule.Assignments[i].ID == "" {
+			schedule.Assignments[i].ID = uuid.New().String()
+		}
+	}
+
+	schedules, err := s.scheduleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+
+	before := snapshot(schedule)
+	p := newProblem(schedule.Employees, companyConfig, schedule.PeriodStart, schedule.PeriodEnd, publishedShifts(schedules, schedule.ID))
+	if err := edit(schedule, p); err != nil {
+		return nil, err
+	}
+
+	if err := p.revalidate(schedule); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	schedule.EditedAt = &now
+
+	if err := s.scheduleRepo.Update(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to update schedule: %w", err)
+	}
+	s.recordSchedule(ctx, action, "", before, schedule)
+
+	return schedule, nil
+}
+
+// refreshEmployees replaces the schedule's employee snapshot with their current
+// records, keeping the snapshot for employees that no longer exist
+func (s *ScheduleService) refreshEmployees(ctx context.Context, schedule *domain.Schedule) error {
+	current, err := s.employeeRepo.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get employees: %w", err)
+	}
+
+	byID := make(map[string]domain.Employee, len(current))
+	for _, emp := range current {
+		byID[emp.ID] = emp
+	}
+	for i, emp := range schedule.Employees {
+		if fresh, ok := byID[emp.ID]; ok {
+			schedule.Employees[i] = fresh
+		}
+	}
+	return nil
+}
+
+// addEmployee adds an employee to the problem after it has been built
+func (p *problem) addEmployee(employee domain.Employee) {
+	p.employees = append(p.employees, employee)
+	p.targets = append(p.targets, float64(employee.MonthlyHours)*p.monthFraction)
+	for d := range p.days {
+		for r, def := range p.shiftDefs {
+			available, preferred := false, false
+			if def != nil {
+				shift := p.shifts[d][r]
+				available = employee.IsAvailableOn(def.Type, shift.start, shift.end)
+				preferred = employee.GetPreference(def.Type, shift.start, shift.end) > 0
+			}
+			p.available[d][r] = append(p.available[d][r], available)
+			p.preferred[d][r] = append(p.preferred[d][r], preferred)
+		}
+	}
+}
+
+// revalidate checks an edited schedule and refreshes its findings, score and warnings
+func (p *problem) revalidate(schedule *domain.Schedule) error {
+	working := make(map[string]bool)
+	for _, a := range schedule.Assignments {
+		key := a.EmployeeID + "/" + a.Date.In(p.loc).Format("2006-01-02")
+		if working[key] {
+			return domain.ErrEmployeeDoubleBooked
+		}
+		working[key] = true
+	}
+
+	sol := p.solutionFrom(schedule.Assignments)
+	schedule.Understaffed, schedule.RelaxedConstraints = p.findings(sol)
+	score := p.score(sol, DefaultObjectiveWeights)
+	schedule.Score = &score
+
+	// Warn about unavailable employees and every assignment beyond a shift's maximum
+	schedule.Warnings = nil
+	staffed := make(map[[2]int]int)
+	for _, a := range schedule.Assignments {
+		d, r, ok := p.locate(a.Date, a.ShiftType)
+		e := p.employeeIndex(a.EmployeeID)
+		if !ok || e < 0 {
+			continue
+		}
+		if !p.available[d][r][e] {
+			schedule.Warnings = append(schedule.Warnings, newScheduleWarning(a, domain.WarningUnavailable))
+		}
+		staffed[[2]int{d, r}]++
+		if staffed[[2]int{d, r}] > p.requirements[r].MaxEmployees {
+			schedule.Warnings = append(schedule.Warnings, newScheduleWarning(a, domain.WarningOverstaffed))
+		}
+	}
+
+	return nil
+}
+
+func newScheduleWarning(a domain.ShiftAssignment, kind string) domain.ScheduleWarning {
+	return domain.ScheduleWarning{
+		AssignmentID: a.ID,
+		Date:         a.Date,
+		ShiftType:    a.ShiftType,
+		EmployeeID:   a.EmployeeID,
+		EmployeeName: a.EmployeeName,
+		Kind:         kind,
+	}
+}
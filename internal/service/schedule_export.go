@@ -0,0 +1,103 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/export"
+)
+
+// AssignmentsSheet returns a schedule's shift assignments as a table with one
+// row per assignment, ordered by day, shift start and employee
+func (s *ScheduleService) AssignmentsSheet(schedule *domain.Schedule) export.Sheet {
+	assignments := make([]domain.ShiftAssignment, len(schedule.Assignments))
+	copy(assignments, schedule.Assignments)
+	sort.SliceStable(assignments, func(i, j int) bool {
+		a, b := assignments[i], assignments[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if a.StartTime != b.StartTime {
+			return a.StartTime < b.StartTime
+		}
+		return a.EmployeeName < b.EmployeeName
+	})
+
+	loc := schedule.Location()
+	sheet := export.Sheet{
+		Name:   "Assignments",
+		Header: []string{"Date", "Day", "Employee ID", "Employee", "Shift Type", "Shift", "Start", "End", "Hours"},
+	}
+	for _, a := range assignments {
+		name := a.ShiftType
+		if def := schedule.ShiftDefinition(a.ShiftType); def != nil {
+			name = def.DisplayName()
+		}
+		date := a.Date.In(loc)
+		sheet.Rows = append(sheet.Rows, []any{
+			date.Format("2006-01-02"),
+			date.Format("Monday"),
+			a.EmployeeID,
+			a.EmployeeName,
+			a.ShiftType,
+			name,
+			a.StartTime,
+			a.EndTime,
+			a.Hours,
+		})
+	}
+
+	return sheet
+}
+
+// HoursSheet returns a per-employee summary of a schedule's hours and shifts,
+// with one shift count column per shift type
+func (s *ScheduleService) HoursSheet(schedule *domain.Schedule) export.Sheet {
+	stats := s.GetScheduleStats(schedule)
+
+	var shiftTypes []string
+	seen := make(map[string]bool)
+	for _, def := range exportShiftDefinitions(schedule) {
+		shiftTypes = append(shiftTypes, def.Type)
+		seen[def.Type] = true
+	}
+	// Shift types that were removed from the definitions still get a column
+	for _, a := range schedule.Assignments {
+		if !seen[a.ShiftType] {
+			shiftTypes = append(shiftTypes, a.ShiftType)
+			seen[a.ShiftType] = true
+		}
+	}
+
+	sheet := export.Sheet{
+		Name:   "Hours",
+		Header: []string{"Employee ID", "Employee", "Role", "Monthly Hours", "Assigned Hours", "Shifts"},
+	}
+	for _, shiftType := range shiftTypes {
+		name := shiftType
+		if def := schedule.ShiftDefinition(shiftType); def != nil {
+			name = def.DisplayName()
+		}
+		sheet.Header = append(sheet.Header, name)
+	}
+
+	for _, emp := range schedule.Employees {
+		empStats := stats.EmployeeStats[emp.ID]
+		row := []any{emp.ID, emp.Name, emp.Role, emp.MonthlyHours, empStats.TotalHours, empStats.TotalShifts}
+		for _, shiftType := range shiftTypes {
+			row = append(row, empStats.ShiftTypes[shiftType])
+		}
+		sheet.Rows = append(sheet.Rows, row)
+	}
+
+	return sheet
+}
+
+// exportShiftDefinitions returns the shifts a schedule was generated with,
+// falling back to the default definitions for older schedules
+func exportShiftDefinitions(schedule *domain.Schedule) []domain.ShiftDefinition {
+	if len(schedule.ShiftDefinitions) > 0 {
+		return schedule.ShiftDefinitions
+	}
+	return domain.GetShiftDefinitions()
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+func TestScheduleService_ExportSheets(t *testing.T) {
+	service, _, schedule := newEditableSchedule(t)
+
+	// An evening shift on the first day sorts after the morning shift
+	schedule.Assignments = append(schedule.Assignments, domain.ShiftAssignment{
+		ID: "a5", EmployeeID: "emp2", EmployeeName: "Jane Smith", Date: schedule.PeriodStart,
+		ShiftType: domain.ShiftTypeEvening, StartTime: "17:00", EndTime: "21:00", Hours: 4,
+	})
+
+	assignments := service.AssignmentsSheet(schedule)
+	if len(assignments.Rows) != 6 {
+		t.Fatalf("Assignments sheet has %d rows, want 6", len(assignments.Rows))
+	}
+	first, second := assignments.Rows[0], assignments.Rows[1]
+	if first[0] != "2025-01-06" || first[1] != "Monday" || first[3] != "John Doe" || first[8] != 4.0 {
+		t.Errorf("First assignment row = %v", first)
+	}
+	if second[2] != "emp2" || second[4] != domain.ShiftTypeEvening {
+		t.Errorf("Second assignment row = %v, want Jane Smith's evening shift", second)
+	}
+
+	hours := service.HoursSheet(schedule)
+	if len(hours.Rows) != len(schedule.Employees) {
+		t.Fatalf("Hours sheet has %d rows, want %d", len(hours.Rows), len(schedule.Employees))
+	}
+	column := func(name string) int {
+		for i, h := range hours.Header {
+			if h == name {
+				return i
+			}
+		}
+		t.Fatalf("Hours sheet has no %q column: %v", name, hours.Header)
+		return -1
+	}
+	morning := column(schedule.ShiftDefinition(domain.ShiftTypeMorning).DisplayName())
+	for _, row := range hours.Rows {
+		switch row[0] {
+		case "emp1":
+			if row[column("Assigned Hours")] != 20.0 || row[column("Shifts")] != 5 || row[morning] != 5 {
+				t.Errorf("emp1 hours row = %v, want 20 hours in 5 morning shifts", row)
+			}
+		case "emp2":
+			if row[column("Assigned Hours")] != 4.0 || row[morning] != 0 {
+				t.Errorf("emp2 hours row = %v, want 4 hours and no morning shifts", row)
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// TransitionSchedule applies a lifecycle action to a schedule on behalf of actor.
+// Reopening a published schedule clears its n8n delivery so that publishing it
+// again sends the new version. A version other than 0 is the version of the
+// schedule the action was chosen on; if the schedule has changed since,
+// ErrConflict is returned.
+func (s *ScheduleService) TransitionSchedule(ctx context.Context, id string, version int, action, actor string) (*domain.Schedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := domain.CheckVersion(version, schedule.Version); err != nil {
+		return nil, err
+	}
+
+	before := snapshot(schedule)
+	if err := schedule.Transition(action, actor, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if action == domain.ScheduleActionReopen {
+		schedule.SentToN8N = false
+		schedule.SentAt = nil
+	}
+
+	if err := s.scheduleRepo.Update(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to %s schedule: %w", action, err)
+	}
+	s.recordSchedule(ctx, action, actor, before, schedule)
+
+	return schedule, nil
+}
+
+// ApproveSchedule signs off a draft schedule so it can be published
+func (s *ScheduleService) ApproveSchedule(ctx context.Context, id string, version int, actor string) (*domain.Schedule, error) {
+	return s.TransitionSchedule(ctx, id, version, domain.ScheduleActionApprove, actor)
+}
+
+// PublishSchedule releases an approved schedule to staff. With sendToN8N the
+// schedule is also sent to the n8n webhook; a failed delivery does not undo the
+// publication and is reported as ErrScheduleNotDelivered together with the
+// published schedule, which can then be sent again with SendScheduleToN8N.
+func (s *ScheduleService) PublishSchedule(ctx context.Context, id string, version int, actor string, sendToN8N bool) (*domain.Schedule, error) {
+	schedule, err := s.TransitionSchedule(ctx, id, version, domain.ScheduleActionPublish, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if sendToN8N {
+		if err := s.deliverToN8N(ctx, schedule); err != nil {
+			return schedule, fmt.Errorf("%w: %v", domain.ErrScheduleNotDelivered, err)
+		}
+	}
+
+	return schedule, nil
+}
+
+// CompleteSchedule marks a published schedule as worked
+func (s *ScheduleService) CompleteSchedule(ctx context.Context, id string, version int, actor string) (*domain.Schedule, error) {
+	return s.TransitionSchedule(ctx, id, version, domain.ScheduleActionComplete, actor)
+}
+
+// ReopenSchedule returns an approved or published schedule to draft so it can be
+// edited and published again
+func (s *ScheduleService) ReopenSchedule(ctx context.Context, id string, version int, actor string) (*domain.Schedule, error) {
+	return s.TransitionSchedule(ctx, id, version, domain.ScheduleActionReopen, actor)
+}
+
+// ArchiveSchedule archives a completed schedule or an unused draft
+func (s *ScheduleService) ArchiveSchedule(ctx context.Context, id string, version int, actor string) (*domain.Schedule, error) {
+	return s.TransitionSchedule(ctx, id, version, domain.ScheduleActionArchive, actor)
+}
+
+// CompleteEndedSchedules completes every published schedule whose last day,
+// in its own timezone, is over at now. It returns the number of schedules completed.
+func (s *ScheduleService) CompleteEndedSchedules(ctx context.Context, now time.Time, actor string) (int, error) {
+	schedules, err := s.scheduleRepo.GetAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schedules: %w", err)
+	}
+
+	completed := 0
+	for _, schedule := range schedules {
+		loc := schedule.Location()
+		ended := domain.LocalMidnight(schedule.PeriodEnd.In(loc), loc).AddDate(0, 0, 1)
+		if schedule.LifecycleStatus() != domain.ScheduleStatusPublished || now.Before(ended) {
+			continue
+		}
+
+		if _, err := s.CompleteSchedule(ctx, schedule.ID, 0, actor); err != nil {
+			return completed, err
+		}
+		completed++
+	}
+
+	return completed, nil
+}
+
+// deliverToN8N sends a schedule to the n8n webhook and records the delivery
+func (s *ScheduleService) deliverToN8N(ctx context.Context, schedule *domain.Schedule) error {
+	payload := s.buildN8NPayload(ctx, schedule)
+
+	if err := s.n8nClient.SendSchedule(ctx, payload); err != nil {
+		return fmt.Errorf("failed to send schedule to n8n: %w", err)
+	}
+
+	if err := s.scheduleRepo.MarkAsSent(ctx, schedule.ID); err != nil {
+		return fmt.Errorf("failed to mark schedule as sent: %w", err)
+	}
+
+	before := snapshot(schedule)
+	now := time.Now()
+	schedule.SentToN8N = true
+	schedule.SentAt = &now
+	schedule.Version++
+	s.recordSchedule(ctx, domain.AuditActionSendToN8N, "", before, schedule)
+	return nil
+}
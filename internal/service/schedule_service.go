@@ -3,8 +3,10 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/isak/restySched/internal/domain"
 	"github.com/isak/restySched/internal/n8n"
 	"github.com/isak/restySched/internal/repository"
@@ -16,30 +18,60 @@ type ScheduleService struct {
 	employeeRepo   repository.EmployeeRepository
 	companyRepo    repository.CompanyConfigRepository
 	n8nClient      n8n.Client
+	audit          *AuditService
 	shiftGenerator *ShiftGenerator
+	strategies     map[string]Strategy
 }
 
-// NewScheduleService creates a new schedule service
+// NewScheduleService creates a new schedule service. Every change it makes is
+// recorded in the audit trail.
 func NewScheduleService(
 	scheduleRepo repository.ScheduleRepository,
 	employeeRepo repository.EmployeeRepository,
 	companyRepo repository.CompanyConfigRepository,
 	n8nClient n8n.Client,
+	audit *AuditService,
 ) *ScheduleService {
+	shiftGenerator := NewShiftGenerator()
 	return &ScheduleService{
 		scheduleRepo:   scheduleRepo,
 		employeeRepo:   employeeRepo,
 		companyRepo:    companyRepo,
 		n8nClient:      n8nClient,
-		shiftGenerator: NewShiftGenerator(),
+		audit:          audit,
+		shiftGenerator: shiftGenerator,
+		strategies: map[string]Strategy{
+			domain.SchedulingStrategyGreedy:      shiftGenerator,
+			domain.SchedulingStrategyLocalSearch: NewLocalSearchSolver(),
+		},
 	}
 }
 
-// GenerateSchedule generates a new schedule for the given period
-func (s *ScheduleService) GenerateSchedule(ctx context.Context, periodStart, periodEnd time.Time) (*domain.Schedule, error) {
+// GenerateSchedule generates a new schedule for the given period. periodStart and
+// periodEnd are calendar dates, both included; their time of day is ignored and the
+// period is snapped to local midnight in the company's timezone. The strategy
+// selects the scheduling algorithm; when empty, the company's configured strategy
+// is used, falling back to the greedy generator.
+func (s *ScheduleService) GenerateSchedule(ctx context.Context, periodStart, periodEnd time.Time, strategy string) (*domain.Schedule, error) {
+	if strategy != "" && !domain.IsValidSchedulingStrategy(strategy) {
+		return nil, domain.ErrUnknownSchedulingStrategy
+	}
+
+	// Shift requirements and policies come from the company configuration
+	companyConfig, err := s.companyRepo.GetOrCreate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load company configuration: %w", err)
+	}
+
+	loc := companyConfig.WorkingHours.Location()
+	periodStart = domain.LocalMidnight(periodStart, loc)
+	periodEnd = domain.LocalMidnight(periodEnd, loc)
 	if periodEnd.Before(periodStart) {
 		return nil, domain.ErrInvalidSchedulePeriod
 	}
+	if periodEnd.After(periodStart.AddDate(0, 0, domain.MaxSchedulePeriodDays-1)) {
+		return nil, domain.ErrSchedulePeriodTooLong
+	}
 
 	// Get all active employees
 	employees, err := s.employeeRepo.GetActive(ctx)
@@ -51,59 +83,93 @@ func (s *ScheduleService) GenerateSchedule(ctx context.Context, periodStart, per
 		return nil, fmt.Errorf("no active employees found")
 	}
 
+	if strategy == "" {
+		strategy = companyConfig.SchedulingStrategy
+	}
+	if strategy == "" {
+		strategy = domain.SchedulingStrategyGreedy
+	}
+	generator, ok := s.strategies[strategy]
+	if !ok {
+		return nil, domain.ErrUnknownSchedulingStrategy
+	}
+
+	// Shifts already published carry consecutive days and rest into the period
+	schedules, err := s.scheduleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+
 	// Generate shift assignments
-	assignments := s.shiftGenerator.GenerateShifts(employees, periodStart, periodEnd)
+	result := generator.GenerateShifts(ctx, employees, companyConfig, periodStart, periodEnd, publishedShifts(schedules, ""))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for i := range result.Assignments {
+		result.Assignments[i].ID = uuid.New().String()
+	}
 
 	schedule := &domain.Schedule{
-		PeriodStart: periodStart,
-		PeriodEnd:   periodEnd,
-		Employees:   employees,
-		Assignments: assignments,
-		Status:      domain.ScheduleStatusDraft,
-		SentToN8N:   false,
+		PeriodStart:        periodStart,
+		PeriodEnd:          periodEnd,
+		Employees:          employees,
+		Assignments:        result.Assignments,
+		Understaffed:       result.Understaffed,
+		RelaxedConstraints: result.RelaxedConstraints,
+		Timezone:           loc.String(),
+		ShiftDefinitions:   companyConfig.GetShiftDefinitions(),
+		Strategy:           strategy,
+		Score:              &result.Score,
+		Status:             domain.ScheduleStatusDraft,
+		SentToN8N:          false,
 	}
 
 	if err := s.scheduleRepo.Create(ctx, schedule); err != nil {
 		return nil, fmt.Errorf("failed to create schedule: %w", err)
 	}
+	s.recordSchedule(ctx, domain.AuditActionGenerate, "", nil, schedule)
 
 	return schedule, nil
 }
 
-// GenerateBiweeklySchedule generates a schedule for the next 2 weeks
-func (s *ScheduleService) GenerateBiweeklySchedule(ctx context.Context) (*domain.Schedule, error) {
-	now := time.Now()
-	periodStart := now
-	periodEnd := now.AddDate(0, 0, 14) // 2 weeks
+// GenerateBiweeklySchedule generates a schedule for today and the following 13 days
+func (s *ScheduleService) GenerateBiweeklySchedule(ctx context.Context, strategy string) (*domain.Schedule, error) {
+	return s.GeneratePresetSchedule(ctx, domain.PeriodPresetNextTwoWeeks, strategy)
+}
 
-	return s.GenerateSchedule(ctx, periodStart, periodEnd)
+// GeneratePresetSchedule generates a schedule for a period preset such as the next
+// calendar week, evaluated in the company's timezone
+func (s *ScheduleService) GeneratePresetSchedule(ctx context.Context, preset, strategy string) (*domain.Schedule, error) {
+	companyConfig, err := s.companyRepo.GetOrCreate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load company configuration: %w", err)
+	}
+
+	periodStart, periodEnd, err := domain.PresetPeriod(preset, time.Now(), companyConfig.WorkingHours.Location())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GenerateSchedule(ctx, periodStart, periodEnd, strategy)
 }
 
-// SendScheduleToN8N sends a schedule to the n8n webhook
+// SendScheduleToN8N sends a published schedule to the n8n webhook, for example
+// when delivery failed while publishing
 func (s *ScheduleService) SendScheduleToN8N(ctx context.Context, scheduleID string) error {
 	schedule, err := s.scheduleRepo.GetByID(ctx, scheduleID)
 	if err != nil {
 		return err
 	}
 
-	if schedule.SentToN8N {
-		return domain.ErrScheduleAlreadySent
-	}
-
-	// Convert to n8n payload
-	payload := s.buildN8NPayload(ctx, schedule)
-
-	// Send to n8n
-	if err := s.n8nClient.SendSchedule(ctx, payload); err != nil {
-		return fmt.Errorf("failed to send schedule to n8n: %w", err)
+	if schedule.LifecycleStatus() != domain.ScheduleStatusPublished {
+		return domain.ErrScheduleNotPublished
 	}
 
-	// Mark as sent
-	if err := s.scheduleRepo.MarkAsSent(ctx, scheduleID); err != nil {
-		return fmt.Errorf("failed to mark schedule as sent: %w", err)
+	if schedule.SentToN8N {
+		return domain.ErrScheduleAlreadySent
 	}
 
-	return nil
+	return s.deliverToN8N(ctx, schedule)
 }
 
 // GetSchedule retrieves a schedule by ID
@@ -121,9 +187,61 @@ func (s *ScheduleService) GetSchedulesByPeriod(ctx context.Context, start, end t
 	return s.scheduleRepo.GetByPeriod(ctx, start, end)
 }
 
-// DeleteSchedule deletes a schedule
+// DeleteSchedule deletes a draft schedule. Schedules that have been approved
+// may already be in employees' calendars and are archived instead.
 func (s *ScheduleService) DeleteSchedule(ctx context.Context, id string) error {
-	return s.scheduleRepo.Delete(ctx, id)
+	schedule, err := s.scheduleRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !schedule.IsEditable() {
+		return domain.ErrScheduleNotDeletable
+	}
+
+	if err := s.scheduleRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.recordSchedule(ctx, domain.AuditActionDelete, "", schedule, nil)
+
+	return nil
+}
+
+// publishedShifts returns the shifts staff see in every schedule except the one
+// with excludeID. Archived schedules are only kept for reference and are skipped.
+func publishedShifts(schedules []domain.Schedule, excludeID string) []domain.ShiftAssignment {
+	var shifts []domain.ShiftAssignment
+	for i := range schedules {
+		if schedules[i].ID == excludeID || schedules[i].Status == domain.ScheduleStatusArchived {
+			continue
+		}
+		shifts = append(shifts, schedules[i].VisibleAssignments()...)
+	}
+	return shifts
+}
+
+// recordSchedule records a change to a schedule in the audit trail. actor is
+// who made it, or "" for the signed-in user.
+func (s *ScheduleService) recordSchedule(ctx context.Context, action, actor string, before, after *domain.Schedule) {
+	schedule := after
+	if schedule == nil {
+		schedule = before
+	}
+
+	s.audit.Record(ctx, domain.AuditEntry{
+		Actor:      actor,
+		EntityType: domain.AuditEntitySchedule,
+		EntityID:   schedule.ID,
+		EntityName: domain.SchedulePeriodName(schedule),
+		Action:     action,
+		Changes:    domain.DiffSchedules(before, after),
+	})
+}
+
+// snapshot copies a schedule before it is changed, for the audit trail
+func snapshot(schedule *domain.Schedule) *domain.Schedule {
+	before := *schedule
+	before.Assignments = slices.Clone(schedule.Assignments)
+	return &before
 }
 
 // GetScheduleStats returns statistics for a schedule
@@ -177,6 +295,8 @@ func (s *ScheduleService) buildN8NPayload(ctx context.Context, schedule *domain.
 			MonthlyHours:    emp.MonthlyHours,
 			AssignedHours:   empStats.TotalHours,
 			AssignedShifts:  empStats.TotalShifts,
+			Skills:          emp.Skills,
+			Availability:    approvedAvailability(emp, schedule.PeriodStart, schedule.PeriodEnd),
 		}
 	}
 
@@ -198,3 +318,15 @@ func (s *ScheduleService) buildN8NPayload(ctx context.Context, schedule *domain.
 		CompanyContext: companyContext,
 	}
 }
+
+// approvedAvailability returns the employee's approved availability that
+// applies to any day from start to end
+func approvedAvailability(employee domain.Employee, start, end time.Time) []domain.Availability {
+	var availability []domain.Availability
+	for _, avail := range employee.Availability {
+		if avail.Approved() && avail.OverlapsPeriod(start, end) {
+			availability = append(availability, avail)
+		}
+	}
+	return availability
+}
@@ -0,0 +1,540 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// MockScheduleRepository is a mock implementation of ScheduleRepository for testing
+type MockScheduleRepository struct {
+	schedules map[string]*domain.Schedule
+	idCounter int
+}
+
+func NewMockScheduleRepository() *MockScheduleRepository {
+	return &MockScheduleRepository{
+		schedules: make(map[string]*domain.Schedule),
+	}
+}
+
+func (m *MockScheduleRepository) Create(ctx context.Context, schedule *domain.Schedule) error {
+	if schedule.ID == "" {
+		m.idCounter++
+		schedule.ID = fmt.Sprintf("mock-schedule-%d", m.idCounter)
+	}
+	m.schedules[schedule.ID] = schedule
+	return nil
+}
+
+func (m *MockScheduleRepository) GetByID(ctx context.Context, id string) (*domain.Schedule, error) {
+	schedule, ok := m.schedules[id]
+	if !ok {
+		return nil, domain.ErrScheduleNotFound
+	}
+	// Return a copy so unsaved changes do not leak into the repository
+	clone := *schedule
+	clone.Assignments = append([]domain.ShiftAssignment(nil), schedule.Assignments...)
+	clone.Employees = append([]domain.Employee(nil), schedule.Employees...)
+	return &clone, nil
+}
+
+func (m *MockScheduleRepository) GetAll(ctx context.Context) ([]domain.Schedule, error) {
+	var result []domain.Schedule
+	for _, schedule := range m.schedules {
+		result = append(result, *schedule)
+	}
+	return result, nil
+}
+
+func (m *MockScheduleRepository) GetByPeriod(ctx context.Context, start, end time.Time) ([]domain.Schedule, error) {
+	var result []domain.Schedule
+	for _, schedule := range m.schedules {
+		if !schedule.PeriodStart.Before(start) && !schedule.PeriodEnd.After(end) {
+			result = append(result, *schedule)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockScheduleRepository) Update(ctx context.Context, schedule *domain.Schedule) error {
+	if _, ok := m.schedules[schedule.ID]; !ok {
+		return domain.ErrScheduleNotFound
+	}
+	schedule.Version++
+	stored := *schedule
+	m.schedules[schedule.ID] = &stored
+	return nil
+}
+
+func (m *MockScheduleRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.schedules[id]; !ok {
+		return domain.ErrScheduleNotFound
+	}
+	delete(m.schedules, id)
+	return nil
+}
+
+func (m *MockScheduleRepository) MarkAsSent(ctx context.Context, id string) error {
+	schedule, ok := m.schedules[id]
+	if !ok {
+		return domain.ErrScheduleNotFound
+	}
+	now := time.Now()
+	schedule.SentToN8N = true
+	schedule.SentAt = &now
+	schedule.Version++
+	return nil
+}
+
+// MockCompanyConfigRepository is a mock implementation of CompanyConfigRepository for testing
+type MockCompanyConfigRepository struct {
+	config *domain.CompanyConfig
+}
+
+func (m *MockCompanyConfigRepository) Get(ctx context.Context) (*domain.CompanyConfig, error) {
+	if m.config == nil {
+		return nil, domain.ErrCompanyConfigNotFound
+	}
+	return m.config, nil
+}
+
+func (m *MockCompanyConfigRepository) Create(ctx context.Context, config *domain.CompanyConfig) error {
+	m.config = config
+	return nil
+}
+
+func (m *MockCompanyConfigRepository) Update(ctx context.Context, config *domain.CompanyConfig) error {
+	m.config = config
+	return nil
+}
+
+func (m *MockCompanyConfigRepository) GetOrCreate(ctx context.Context) (*domain.CompanyConfig, error) {
+	if m.config == nil {
+		m.config = &domain.CompanyConfig{CompanyName: "Test Company"}
+	}
+	return m.config, nil
+}
+
+// newEditableSchedule sets up a schedule service with a one-week draft schedule.
+// Every weekday has one morning shift for emp1; emp2 is unavailable on Wednesday.
+func newEditableSchedule(t *testing.T) (*ScheduleService, *MockScheduleRepository, *domain.Schedule) {
+	t.Helper()
+
+	employeeRepo := NewMockEmployeeRepository()
+	scheduleRepo := NewMockScheduleRepository()
+	companyRepo := &MockCompanyConfigRepository{config: &domain.CompanyConfig{
+		CompanyName:  "Test Company",
+		WorkingHours: domain.WorkingHours{WorkingDays: []int{1, 2, 3, 4, 5}},
+		ShiftRequirements: []domain.ShiftRequirement{
+			{ShiftType: domain.ShiftTypeMorning, MinEmployees: 1, MaxEmployees: 1},
+			{ShiftType: domain.ShiftTypeEvening, MinEmployees: 0, MaxEmployees: 1},
+		},
+	}}
+
+	wednesday := time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)
+	for _, emp := range []*domain.Employee{
+		{ID: "emp1", Name: "John Doe", MonthlyHours: 160},
+		{ID: "emp2", Name: "Jane Smith", MonthlyHours: 160, Availability: []domain.Availability{
+			{StartDate: wednesday, EndDate: wednesday, Type: domain.AvailabilityTypeUnavailable},
+		}},
+	} {
+		if err := employeeRepo.Create(context.Background(), emp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	service := NewScheduleService(scheduleRepo, employeeRepo, companyRepo, nil, newTestAuditService())
+	schedule, err := service.GenerateSchedule(context.Background(),
+		time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), "")
+	if err != nil {
+		t.Fatalf("GenerateSchedule() error = %v", err)
+	}
+
+	// Start every test from the same known assignments
+	schedule.Assignments = nil
+	for d := 0; d < 5; d++ {
+		schedule.Assignments = append(schedule.Assignments, domain.ShiftAssignment{
+			ID:           fmt.Sprintf("a%d", d),
+			EmployeeID:   "emp1",
+			EmployeeName: "John Doe",
+			Date:         schedule.PeriodStart.AddDate(0, 0, d),
+			ShiftType:    domain.ShiftTypeMorning,
+			StartTime:    "09:00",
+			EndTime:      "13:00",
+			Hours:        4,
+		})
+	}
+	if err := scheduleRepo.Update(context.Background(), schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	return service, scheduleRepo, schedule
+}
+
+func TestScheduleService_GenerateSchedule_ContinuesPublishedSchedules(t *testing.T) {
+	ctx := context.Background()
+	employeeRepo := NewMockEmployeeRepository()
+	scheduleRepo := NewMockScheduleRepository()
+	companyRepo := &MockCompanyConfigRepository{config: &domain.CompanyConfig{
+		CompanyName: "Test Company",
+		ShiftRequirements: []domain.ShiftRequirement{
+			{ShiftType: domain.ShiftTypeMorning, MinEmployees: 0, MaxEmployees: 1},
+		},
+		SchedulingPolicies: domain.SchedulingPolicies{MinRestHours: 11, AllowOvertime: true, MaxOvertimeHours: 40},
+	}}
+	for _, emp := range []*domain.Employee{
+		{ID: "emp1", Name: "John Doe", MonthlyHours: 160},
+		{ID: "emp2", Name: "Jane Smith", MonthlyHours: 80},
+	} {
+		if err := employeeRepo.Create(ctx, emp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Both worked a Sunday night shift, but only emp1's schedule is still in use
+	sunday := time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)
+	night := func(employeeID string) []domain.ShiftAssignment {
+		return []domain.ShiftAssignment{{EmployeeID: employeeID, Date: sunday, ShiftType: domain.ShiftTypeNight, StartTime: "21:00", EndTime: "05:00"}}
+	}
+	for _, schedule := range []*domain.Schedule{
+		{PeriodStart: sunday, PeriodEnd: sunday, Status: domain.ScheduleStatusPublished, PublishedAssignments: night("emp1")},
+		{PeriodStart: sunday, PeriodEnd: sunday, Status: domain.ScheduleStatusArchived, PublishedAssignments: night("emp2")},
+	} {
+		if err := scheduleRepo.Create(ctx, schedule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	service := NewScheduleService(scheduleRepo, employeeRepo, companyRepo, nil, newTestAuditService())
+	monday := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	schedule, err := service.GenerateSchedule(ctx, monday, monday, "")
+	if err != nil {
+		t.Fatalf("GenerateSchedule() error = %v", err)
+	}
+
+	// emp1 is furthest from their target and would get the shift if rested
+	if len(schedule.Assignments) != 1 || schedule.Assignments[0].EmployeeID != "emp2" {
+		t.Errorf("Assignments = %+v, want emp2 on Monday morning after emp1's night shift", schedule.Assignments)
+	}
+	if len(schedule.RelaxedConstraints) != 0 {
+		t.Errorf("RelaxedConstraints = %v, want none", schedule.RelaxedConstraints)
+	}
+}
+
+func TestScheduleService_EditAssignments(t *testing.T) {
+	ctx := context.Background()
+	wednesday := time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	t.Run("add assignment warns about availability", func(t *testing.T) {
+		service, _, schedule := newEditableSchedule(t)
+
+		edited, err := service.AddAssignment(ctx, schedule.ID, 0, "emp2", wednesday, domain.ShiftTypeEvening)
+		if err != nil {
+			t.Fatalf("AddAssignment() error = %v", err)
+		}
+
+		if len(edited.Assignments) != 6 {
+			t.Fatalf("Assignments = %d, want 6", len(edited.Assignments))
+		}
+		added := edited.Assignments[5]
+		if added.ID == "" || added.EmployeeName != "Jane Smith" || added.StartTime != "17:00" || added.Hours != 4 {
+			t.Errorf("Added assignment = %+v", added)
+		}
+		if len(edited.Warnings) != 1 || edited.Warnings[0].Kind != domain.WarningUnavailable || edited.Warnings[0].AssignmentID != added.ID {
+			t.Errorf("Warnings = %+v, want one unavailable warning for the new assignment", edited.Warnings)
+		}
+		if edited.EditedAt == nil {
+			t.Error("EditedAt should be set after an edit")
+		}
+	})
+
+	t.Run("add assignment above maximum warns", func(t *testing.T) {
+		service, _, schedule := newEditableSchedule(t)
+
+		edited, err := service.AddAssignment(ctx, schedule.ID, 0, "emp2", wednesday.AddDate(0, 0, 1), domain.ShiftTypeMorning)
+		if err != nil {
+			t.Fatalf("AddAssignment() error = %v", err)
+		}
+		if len(edited.Warnings) != 1 || edited.Warnings[0].Kind != domain.WarningOverstaffed {
+			t.Errorf("Warnings = %+v, want one overstaffed warning", edited.Warnings)
+		}
+	})
+
+	t.Run("remove assignment reports understaffing", func(t *testing.T) {
+		service, _, schedule := newEditableSchedule(t)
+
+		edited, err := service.RemoveAssignment(ctx, schedule.ID, 0, "a2")
+		if err != nil {
+			t.Fatalf("RemoveAssignment() error = %v", err)
+		}
+		if len(edited.Assignments) != 4 || edited.FindAssignment("a2") >= 0 {
+			t.Errorf("Assignment a2 was not removed: %+v", edited.Assignments)
+		}
+		if len(edited.Understaffed) != 1 || !edited.Understaffed[0].Date.Equal(wednesday) {
+			t.Errorf("Understaffed = %+v, want Wednesday morning", edited.Understaffed)
+		}
+	})
+
+	t.Run("move assignment keeps its ID", func(t *testing.T) {
+		service, _, schedule := newEditableSchedule(t)
+
+		edited, err := service.MoveAssignment(ctx, schedule.ID, 0, "a4", time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), domain.ShiftTypeEvening)
+		if err != nil {
+			t.Fatalf("MoveAssignment() error = %v", err)
+		}
+		moved := edited.Assignments[edited.FindAssignment("a4")]
+		if moved.ShiftType != domain.ShiftTypeEvening || moved.StartTime != "17:00" {
+			t.Errorf("Moved assignment = %+v", moved)
+		}
+	})
+
+	t.Run("swap assignments", func(t *testing.T) {
+		service, _, schedule := newEditableSchedule(t)
+
+		edited, err := service.AddAssignment(ctx, schedule.ID, 0, "emp2", time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), domain.ShiftTypeEvening)
+		if err != nil {
+			t.Fatalf("AddAssignment() error = %v", err)
+		}
+		added := edited.Assignments[5].ID
+
+		edited, err = service.SwapAssignments(ctx, schedule.ID, 0, "a0", added)
+		if err != nil {
+			t.Fatalf("SwapAssignments() error = %v", err)
+		}
+		if got := edited.Assignments[edited.FindAssignment("a0")].EmployeeID; got != "emp2" {
+			t.Errorf("a0 employee = %s, want emp2", got)
+		}
+		if got := edited.Assignments[edited.FindAssignment(added)].EmployeeID; got != "emp1" {
+			t.Errorf("added employee = %s, want emp1", got)
+		}
+	})
+
+	t.Run("rejected edits are not saved", func(t *testing.T) {
+		service, repo, schedule := newEditableSchedule(t)
+
+		tests := []struct {
+			name    string
+			edit    func() error
+			wantErr error
+		}{
+			{"double booking", func() error {
+				_, err := service.MoveAssignment(ctx, schedule.ID, 0, "a0", wednesday, domain.ShiftTypeEvening)
+				return err
+			}, domain.ErrEmployeeDoubleBooked},
+			{"weekend", func() error {
+				_, err := service.AddAssignment(ctx, schedule.ID, 0, "emp2", time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC), domain.ShiftTypeMorning)
+				return err
+			}, domain.ErrInvalidAssignment},
+			{"shift type not required", func() error {
+				_, err := service.AddAssignment(ctx, schedule.ID, 0, "emp2", wednesday, domain.ShiftTypeNight)
+				return err
+			}, domain.ErrInvalidAssignment},
+			{"unknown employee", func() error {
+				_, err := service.AddAssignment(ctx, schedule.ID, 0, "nobody", wednesday, domain.ShiftTypeEvening)
+				return err
+			}, domain.ErrEmployeeNotFound},
+			{"unknown assignment", func() error {
+				_, err := service.RemoveAssignment(ctx, schedule.ID, 0, "missing")
+				return err
+			}, domain.ErrAssignmentNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if err := tt.edit(); !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				if saved := repo.schedules[schedule.ID]; len(saved.Assignments) != 5 || saved.EditedAt != nil {
+					t.Errorf("Rejected edit was saved: %+v", saved.Assignments)
+				}
+			})
+		}
+	})
+
+	t.Run("edits of a changed schedule conflict", func(t *testing.T) {
+		service, repo, schedule := newEditableSchedule(t)
+		loaded := schedule.Version
+
+		if _, err := service.RemoveAssignment(ctx, schedule.ID, loaded, "a0"); err != nil {
+			t.Fatalf("RemoveAssignment() error = %v", err)
+		}
+		if _, err := service.RemoveAssignment(ctx, schedule.ID, loaded, "a1"); !errors.Is(err, domain.ErrConflict) {
+			t.Errorf("RemoveAssignment() of the loaded version error = %v, want %v", err, domain.ErrConflict)
+		}
+		if _, err := service.ApproveSchedule(ctx, schedule.ID, loaded, "manager"); !errors.Is(err, domain.ErrConflict) {
+			t.Errorf("ApproveSchedule() of the loaded version error = %v, want %v", err, domain.ErrConflict)
+		}
+		if saved := repo.schedules[schedule.ID]; len(saved.Assignments) != 4 || saved.LifecycleStatus() != domain.ScheduleStatusDraft {
+			t.Errorf("Conflicting changes were saved: %d assignments, status %s", len(saved.Assignments), saved.LifecycleStatus())
+		}
+	})
+
+	t.Run("published schedules must be reopened", func(t *testing.T) {
+		service, _, schedule := newEditableSchedule(t)
+		if _, err := service.ApproveSchedule(ctx, schedule.ID, 0, "manager"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := service.PublishSchedule(ctx, schedule.ID, 0, "manager", false); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := service.RemoveAssignment(ctx, schedule.ID, 0, "a0"); !errors.Is(err, domain.ErrScheduleNotEditable) {
+			t.Fatalf("RemoveAssignment() on published schedule error = %v, want %v", err, domain.ErrScheduleNotEditable)
+		}
+
+		reopened, err := service.ReopenSchedule(ctx, schedule.ID, 0, "manager")
+		if err != nil {
+			t.Fatalf("ReopenSchedule() error = %v", err)
+		}
+		if reopened.Status != domain.ScheduleStatusDraft || reopened.SentToN8N {
+			t.Errorf("Reopened schedule status = %s, sent = %v", reopened.Status, reopened.SentToN8N)
+		}
+
+		if _, err := service.RemoveAssignment(ctx, schedule.ID, 0, "a0"); err != nil {
+			t.Errorf("RemoveAssignment() after reopen error = %v", err)
+		}
+
+		if _, err := service.ReopenSchedule(ctx, schedule.ID, 0, "manager"); !errors.Is(err, domain.ErrInvalidScheduleTransition) {
+			t.Errorf("ReopenSchedule() on draft error = %v, want %v", err, domain.ErrInvalidScheduleTransition)
+		}
+	})
+}
+
+// MockN8NClient records the schedules sent to n8n, failing with err if set
+type MockN8NClient struct {
+	sent []string
+	err  error
+}
+
+func (m *MockN8NClient) SendSchedule(ctx context.Context, payload domain.N8NSchedulePayload) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, payload.ScheduleID)
+	return nil
+}
+
+func TestScheduleService_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("publish sends to n8n", func(t *testing.T) {
+		service, _, schedule := newEditableSchedule(t)
+		client := &MockN8NClient{}
+		service.n8nClient = client
+
+		if _, err := service.PublishSchedule(ctx, schedule.ID, 0, "manager", true); !errors.Is(err, domain.ErrInvalidScheduleTransition) {
+			t.Fatalf("PublishSchedule() on draft error = %v, want %v", err, domain.ErrInvalidScheduleTransition)
+		}
+		if _, err := service.ApproveSchedule(ctx, schedule.ID, 0, "alice"); err != nil {
+			t.Fatalf("ApproveSchedule() error = %v", err)
+		}
+
+		published, err := service.PublishSchedule(ctx, schedule.ID, 0, "bob", true)
+		if err != nil {
+			t.Fatalf("PublishSchedule() error = %v", err)
+		}
+		if published.Status != domain.ScheduleStatusPublished || !published.SentToN8N || len(client.sent) != 1 {
+			t.Errorf("Published schedule status = %s, sent = %v, deliveries = %d", published.Status, published.SentToN8N, len(client.sent))
+		}
+
+		if len(published.Transitions) != 2 {
+			t.Fatalf("Transitions = %+v, want approve and publish", published.Transitions)
+		}
+		approved, last := published.Transitions[0], published.Transitions[1]
+		if approved.Actor != "alice" || approved.From != domain.ScheduleStatusDraft || approved.To != domain.ScheduleStatusApproved {
+			t.Errorf("Approve transition = %+v", approved)
+		}
+		if last.Actor != "bob" || last.Action != domain.ScheduleActionPublish || last.At.IsZero() {
+			t.Errorf("Publish transition = %+v", last)
+		}
+
+		if err := service.SendScheduleToN8N(ctx, schedule.ID); !errors.Is(err, domain.ErrScheduleAlreadySent) {
+			t.Errorf("SendScheduleToN8N() after delivery error = %v, want %v", err, domain.ErrScheduleAlreadySent)
+		}
+	})
+
+	t.Run("failed delivery keeps the schedule published", func(t *testing.T) {
+		service, repo, schedule := newEditableSchedule(t)
+		client := &MockN8NClient{err: errors.New("webhook unreachable")}
+		service.n8nClient = client
+
+		if _, err := service.ApproveSchedule(ctx, schedule.ID, 0, "manager"); err != nil {
+			t.Fatal(err)
+		}
+		published, err := service.PublishSchedule(ctx, schedule.ID, 0, "manager", true)
+		if !errors.Is(err, domain.ErrScheduleNotDelivered) {
+			t.Fatalf("PublishSchedule() error = %v, want %v", err, domain.ErrScheduleNotDelivered)
+		}
+		if published == nil || repo.schedules[schedule.ID].Status != domain.ScheduleStatusPublished || repo.schedules[schedule.ID].SentToN8N {
+			t.Errorf("Schedule after failed delivery = %+v", repo.schedules[schedule.ID])
+		}
+
+		client.err = nil
+		if err := service.SendScheduleToN8N(ctx, schedule.ID); err != nil {
+			t.Errorf("SendScheduleToN8N() retry error = %v", err)
+		}
+	})
+
+	t.Run("only drafts can be deleted", func(t *testing.T) {
+		service, repo, schedule := newEditableSchedule(t)
+		if _, err := service.ApproveSchedule(ctx, schedule.ID, 0, "manager"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := service.DeleteSchedule(ctx, schedule.ID); !errors.Is(err, domain.ErrScheduleNotDeletable) {
+			t.Errorf("DeleteSchedule() on approved schedule error = %v, want %v", err, domain.ErrScheduleNotDeletable)
+		}
+		if _, ok := repo.schedules[schedule.ID]; !ok {
+			t.Fatal("Approved schedule was deleted")
+		}
+
+		if _, err := service.ReopenSchedule(ctx, schedule.ID, 0, "manager"); err != nil {
+			t.Fatal(err)
+		}
+		if err := service.DeleteSchedule(ctx, schedule.ID); err != nil {
+			t.Errorf("DeleteSchedule() on draft error = %v", err)
+		}
+	})
+
+	t.Run("drafts cannot be sent to n8n", func(t *testing.T) {
+		service, _, schedule := newEditableSchedule(t)
+		service.n8nClient = &MockN8NClient{}
+
+		if err := service.SendScheduleToN8N(ctx, schedule.ID); !errors.Is(err, domain.ErrScheduleNotPublished) {
+			t.Errorf("SendScheduleToN8N() on draft error = %v, want %v", err, domain.ErrScheduleNotPublished)
+		}
+	})
+
+	t.Run("ended schedules are completed", func(t *testing.T) {
+		service, repo, schedule := newEditableSchedule(t)
+		if _, err := service.ApproveSchedule(ctx, schedule.ID, 0, "manager"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := service.PublishSchedule(ctx, schedule.ID, 0, "manager", false); err != nil {
+			t.Fatal(err)
+		}
+
+		// The schedule's last day is Friday 2025-01-10
+		completed, err := service.CompleteEndedSchedules(ctx, time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC), "scheduler")
+		if err != nil || completed != 0 {
+			t.Fatalf("CompleteEndedSchedules() on last day = %d, %v, want 0", completed, err)
+		}
+
+		completed, err = service.CompleteEndedSchedules(ctx, time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC), "scheduler")
+		if err != nil || completed != 1 {
+			t.Fatalf("CompleteEndedSchedules() after period = %d, %v, want 1", completed, err)
+		}
+		if saved := repo.schedules[schedule.ID]; saved.Status != domain.ScheduleStatusCompleted || saved.LastTransition(domain.ScheduleStatusCompleted).Actor != "scheduler" {
+			t.Errorf("Schedule after completion = %s, %+v", saved.Status, saved.Transitions)
+		}
+
+		if _, err := service.ArchiveSchedule(ctx, schedule.ID, 0, "manager"); err != nil {
+			t.Errorf("ArchiveSchedule() on completed schedule error = %v", err)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"math"
 	"time"
 
@@ -8,7 +9,8 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// ShiftGenerator handles the logic for generating shift assignments
+// ShiftGenerator is the greedy scheduling strategy. It fills each day in turn,
+// handing shifts to the employees furthest from their target hours.
 type ShiftGenerator struct{}
 
 // NewShiftGenerator creates a new shift generator
@@ -16,170 +18,269 @@ func NewShiftGenerator() *ShiftGenerator {
 	return &ShiftGenerator{}
 }
 
-// GenerateShifts creates shift assignments for employees over the schedule period
-func (g *ShiftGenerator) GenerateShifts(employees []domain.Employee, periodStart, periodEnd time.Time) []domain.ShiftAssignment {
-	var assignments []domain.ShiftAssignment
+// relaxationOrder lists the constraints that may be broken to reach minimum staffing,
+// least harmful first. Each step allows everything before it as well.
+var relaxationOrder = []string{
+	domain.ConstraintOvertime,
+	domain.ConstraintMaxConsecutiveDays,
+	domain.ConstraintMinRestHours,
+}
 
-	// Calculate total days in period (excluding weekends for now)
-	totalDays := g.countWorkdays(periodStart, periodEnd)
-	if totalDays == 0 {
+// GenerateShifts creates shift assignments for employees over the schedule period.
+// Every shift requirement in the company config is filled each day with at least
+// MinEmployees and at most MaxEmployees; shifts that cannot reach their minimum are
+// reported as understaffed. Scheduling policies are hard constraints, except that
+// they may be relaxed to reach minimum staffing, in which case every broken
+// constraint is reported.
+func (g *ShiftGenerator) GenerateShifts(ctx context.Context, employees []domain.Employee, config *domain.CompanyConfig, periodStart, periodEnd time.Time, history []domain.ShiftAssignment) GenerationResult {
+	var result GenerationResult
+
+	p := newProblem(employees, config, periodStart, periodEnd, history)
+	if len(p.days) == 0 {
 		log.Warn().Msg("No workdays in schedule period")
-		return assignments
+		return result
 	}
 
 	log.Debug().
-		Int("total_days", totalDays).
+		Int("total_days", len(p.days)).
 		Int("employees", len(employees)).
+		Int("shift_requirements", len(p.requirements)).
+		Str("timezone", p.loc.String()).
 		Msg("Generating shifts")
 
-	// Calculate how many hours each employee should work during this period
-	employeeTargets := g.calculateEmployeeTargets(employees, periodStart, periodEnd)
-
-	// Track hours assigned to each employee
-	assignedHours := make(map[string]float64)
-	for _, emp := range employees {
-		assignedHours[emp.ID] = 0
+	// Generate shifts calendar day by calendar day in the company's timezone
+	states := p.newStates()
+	for d := range p.days {
+		g.assignDayShifts(p, states, d, &result)
 	}
 
-	// Generate shifts day by day
-	currentDate := periodStart
-	for currentDate.Before(periodEnd) || currentDate.Equal(periodEnd) {
-		// Skip weekends (Saturday = 6, Sunday = 0)
-		if currentDate.Weekday() == time.Saturday || currentDate.Weekday() == time.Sunday {
-			currentDate = currentDate.AddDate(0, 0, 1)
-			continue
-		}
-
-		// Assign shifts for this day
-		dayShifts := g.assignDayShifts(employees, currentDate, employeeTargets, assignedHours)
-		assignments = append(assignments, dayShifts...)
-
-		currentDate = currentDate.AddDate(0, 0, 1)
-	}
+	result.Score = p.score(p.solutionFrom(result.Assignments), DefaultObjectiveWeights)
 
 	log.Info().
-		Int("total_assignments", len(assignments)).
+		Int("total_assignments", len(result.Assignments)).
+		Int("understaffed_shifts", len(result.Understaffed)).
+		Int("relaxed_constraints", len(result.RelaxedConstraints)).
 		Msg("Shift generation complete")
 
-	return assignments
+	return result
 }
 
-// countWorkdays counts the number of weekdays in the period
-func (g *ShiftGenerator) countWorkdays(start, end time.Time) int {
-	count := 0
-	current := start
-	for current.Before(end) || current.Equal(end) {
-		if current.Weekday() != time.Saturday && current.Weekday() != time.Sunday {
-			count++
+// assignDayShifts assigns employees to every required shift for a single day.
+// Required skills are covered first, then minimum staffing is filled for all shifts
+// (even if that puts someone over their target), then remaining slots up to
+// MaxEmployees are handed out round-robin to employees who still need hours.
+// Each employee works at most one shift per day.
+func (g *ShiftGenerator) assignDayShifts(p *problem, states []*employeeState, d int, result *GenerationResult) {
+	requirements, shiftDefs, limits := p.requirements, p.shiftDefs, p.limits
+	date := p.days[d]
+
+	assignedToday := make(map[string]bool)
+	staffed := make([]int, len(requirements))
+	shiftStaff := make([][]*employeeState, len(requirements))
+
+	assign := func(i int, state *employeeState, shift candidateShift) {
+		def := shiftDefs[i]
+		result.Assignments = append(result.Assignments, domain.ShiftAssignment{
+			EmployeeID:   state.employee.ID,
+			EmployeeName: state.employee.Name,
+			Date:         date,
+			ShiftType:    def.Type,
+			StartTime:    def.StartTime,
+			EndTime:      def.EndTime,
+			Hours:        shift.hours,
+		})
+
+		state.record(shift)
+
+		assignedToday[state.employee.ID] = true
+		staffed[i]++
+		shiftStaff[i] = append(shiftStaff[i], state)
+	}
+
+	// pickForMinimum finds someone for a slot that must be filled, relaxing policies
+	// one at a time only when nobody can be scheduled without breaking them
+	pickForMinimum := func(req domain.ShiftRequirement, shift candidateShift, skill string) *employeeState {
+		state, broken := g.pickEmployee(states, shift, limits, assignedToday, false, nil, skill)
+		for step := 0; state == nil && step < len(relaxationOrder); step++ {
+			state, broken = g.pickEmployee(states, shift, limits, assignedToday, false, relaxationOrder[:step+1], skill)
+		}
+		for _, constraint := range broken {
+			log.Warn().
+				Str("employee", state.employee.Name).
+				Time("date", date).
+				Str("shift_type", req.ShiftType).
+				Str("constraint", constraint).
+				Msg("Relaxed scheduling constraint to reach minimum staffing")
+			result.RelaxedConstraints = append(result.RelaxedConstraints, domain.RelaxedConstraint{
+				Date:         date,
+				ShiftType:    req.ShiftType,
+				EmployeeID:   state.employee.ID,
+				EmployeeName: state.employee.Name,
+				Constraint:   constraint,
+			})
 		}
-		current = current.AddDate(0, 0, 1)
+		return state
 	}
-	return count
-}
 
-// calculateEmployeeTargets calculates target hours for each employee in the period
-func (g *ShiftGenerator) calculateEmployeeTargets(employees []domain.Employee, start, end time.Time) map[string]float64 {
-	targets := make(map[string]float64)
+	// Phase 1a: cover every required skill on every shift with qualified employees.
+	// Skill slots count towards the shift's staffing and never exceed MaxEmployees.
+	missingSkills := make([][]string, len(requirements))
+	for i, req := range requirements {
+		if shiftDefs[i] == nil {
+			continue
+		}
+		shift := p.shifts[d][i]
+		for _, need := range req.SkillNeeds() {
+			covered := 0
+			for _, state := range shiftStaff[i] {
+				if state.employee.HasSkill(need.Skill, date) {
+					covered++
+				}
+			}
+			for covered < need.Count && staffed[i] < req.MaxEmployees {
+				state := pickForMinimum(req, shift, need.Skill)
+				if state == nil {
+					break
+				}
+				assign(i, state, shift)
+				covered++
+			}
+			if covered < need.Count {
+				missingSkills[i] = append(missingSkills[i], need.Skill)
+			}
+		}
+	}
 
-	// Calculate how many months are in the period
-	days := end.Sub(start).Hours() / 24
-	monthFraction := days / 30.0 // Approximate
+	// Phase 1b: reach minimum staffing on every shift
+	for i, req := range requirements {
+		if shiftDefs[i] == nil {
+			continue
+		}
+		shift := p.shifts[d][i]
+		for staffed[i] < req.MinEmployees {
+			state := pickForMinimum(req, shift, "")
+			if state == nil {
+				break
+			}
+			assign(i, state, shift)
+		}
+	}
 
-	for _, emp := range employees {
-		// Target hours = (monthly hours * fraction of month in this period)
-		targets[emp.ID] = float64(emp.MonthlyHours) * monthFraction
+	// Phase 2: top up shifts towards their maximum, one slot per shift per round
+	for progress := true; progress; {
+		progress = false
+		for i, req := range requirements {
+			if shiftDefs[i] == nil || staffed[i] >= req.MaxEmployees {
+				continue
+			}
+			shift := p.shifts[d][i]
+			state, _ := g.pickEmployee(states, shift, limits, assignedToday, true, nil, "")
+			if state == nil {
+				continue
+			}
+			assign(i, state, shift)
+			progress = true
+		}
 	}
 
-	return targets
+	for i, req := range requirements {
+		if staffed[i] < req.MinEmployees || len(missingSkills[i]) > 0 {
+			result.Understaffed = append(result.Understaffed, domain.UnderstaffedShift{
+				Date:          date,
+				ShiftType:     req.ShiftType,
+				Required:      req.MinEmployees,
+				Assigned:      staffed[i],
+				MissingSkills: missingSkills[i],
+			})
+		}
+	}
 }
 
-// assignDayShifts assigns shifts to employees for a single day
-func (g *ShiftGenerator) assignDayShifts(
-	employees []domain.Employee,
-	date time.Time,
-	targets map[string]float64,
-	assignedHours map[string]float64,
-) []domain.ShiftAssignment {
-	var assignments []domain.ShiftAssignment
-
-	// Strategy: Assign full-day shifts to employees who need the most hours
-	// This is a simple round-robin approach - can be enhanced with more sophisticated algorithms
-
-	// Find employees who still need hours
-	type employeeNeed struct {
-		employee      domain.Employee
-		hoursNeeded   float64
-		percentNeeded float64
-	}
+// pickEmployee returns the available employee with the highest remaining need for
+// the given shift, together with the constraints they would break, or nil if nobody
+// qualifies. Only constraints listed in relaxed may be broken, and if skill is set only
+// employees holding that skill on the shift date are considered. Preferred shifts get
+// a bonus so they are handed out first. When onlyIfNeeded is set, employees who have
+// already reached their target hours are skipped.
+func (g *ShiftGenerator) pickEmployee(
+	states []*employeeState,
+	shift candidateShift,
+	limits schedulingLimits,
+	assignedToday map[string]bool,
+	onlyIfNeeded bool,
+	relaxed []string,
+	skill string,
+) (*employeeState, []string) {
+	var best *employeeState
+	var bestBroken []string
+	bestScore := math.Inf(-1)
+
+	for _, state := range states {
+		emp := &state.employee
+		if assignedToday[emp.ID] {
+			continue
+		}
 
-	var needsList []employeeNeed
-	shiftType := domain.ShiftTypeFullDay // We'll use full-day shifts
+		if skill != "" && !emp.HasSkill(skill, shift.date) {
+			continue
+		}
 
-	for _, emp := range employees {
-		// Check if employee is available on this date
-		if !emp.IsAvailableOn(date, shiftType) {
+		// Check if employee is available for the shift's hours
+		if !emp.IsAvailableOn(shift.shiftType, shift.start, shift.end) {
 			log.Debug().
 				Str("employee", emp.Name).
-				Time("date", date).
+				Time("date", shift.date).
+				Str("shift_type", shift.shiftType).
 				Msg("Employee unavailable, skipping")
 			continue
 		}
 
-		target := targets[emp.ID]
-		assigned := assignedHours[emp.ID]
-		needed := target - assigned
-
-		if needed > 0 {
-			percentNeeded := (needed / target) * 100
-
-			// Add preference bonus to prioritize preferred shifts
-			preferenceBonus := float64(emp.GetPreference(date, shiftType)) * 10.0
+		needed := state.targetHours - state.assignedHours
+		if onlyIfNeeded && needed <= 0 {
+			continue
+		}
 
-			needsList = append(needsList, employeeNeed{
-				employee:      emp,
-				hoursNeeded:   needed,
-				percentNeeded: percentNeeded + preferenceBonus,
-			})
+		broken := violations(state, shift, limits)
+		if !allRelaxed(broken, relaxed) {
+			continue
 		}
-	}
 
-	// Sort by percent needed + preference (highest first)
-	for i := 0; i < len(needsList); i++ {
-		for j := i + 1; j < len(needsList); j++ {
-			if needsList[j].percentNeeded > needsList[i].percentNeeded {
-				needsList[i], needsList[j] = needsList[j], needsList[i]
-			}
+		percentNeeded := 0.0
+		if state.targetHours > 0 {
+			percentNeeded = (needed / state.targetHours) * 100
 		}
-	}
 
-	// Assign shifts based on need and availability
-	// For simplicity, we'll assign 2-3 people per day with full-day shifts
-	shiftsToAssign := int(math.Min(float64(len(needsList)), 3))
+		// Add preference bonus to prioritize preferred shifts
+		score := percentNeeded + float64(emp.GetPreference(shift.shiftType, shift.start, shift.end))*10.0
 
-	for i := 0; i < shiftsToAssign; i++ {
-		emp := needsList[i].employee
+		// Prefer breaking as few constraints as possible
+		score -= float64(len(broken)) * 1000.0
 
-		// Assign a full-day shift (8 hours)
-		shiftDef := domain.GetShiftDefinition(shiftType)
-		if shiftDef == nil {
-			continue
+		if score > bestScore {
+			best = state
+			bestBroken = broken
+			bestScore = score
 		}
+	}
 
-		assignment := domain.ShiftAssignment{
-			EmployeeID:   emp.ID,
-			EmployeeName: emp.Name,
-			Date:         date,
-			ShiftType:    shiftDef.Type,
-			StartTime:    shiftDef.StartTime,
-			EndTime:      shiftDef.EndTime,
-			Hours:        shiftDef.Hours,
-		}
+	return best, bestBroken
+}
 
-		assignments = append(assignments, assignment)
-		assignedHours[emp.ID] += shiftDef.Hours
+// allRelaxed reports whether every broken constraint is in the relaxed set
+func allRelaxed(broken, relaxed []string) bool {
+	for _, b := range broken {
+		found := false
+		for _, r := range relaxed {
+			if b == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
 	}
-
-	return assignments
+	return true
 }
 
 // GetEmployeeStats returns statistics about shift assignments for an employee
@@ -1,6 +1,11 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
 	"testing"
 	"time"
 
@@ -30,10 +35,10 @@ func TestShiftGenerator_GenerateShifts(t *testing.T) {
 	}
 
 	// Generate shifts for 2 weeks (10 workdays)
-	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)  // Monday
-	end := time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)   // Friday (2 weeks later)
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC) // Monday
+	end := time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)  // Friday (2 weeks later)
 
-	assignments := generator.GenerateShifts(employees, start, end)
+	assignments := generator.GenerateShifts(context.Background(), employees, nil, start, end, nil).Assignments
 
 	// Verify assignments were created
 	if len(assignments) == 0 {
@@ -80,45 +85,457 @@ func TestShiftGenerator_GenerateShifts(t *testing.T) {
 	}
 }
 
-func TestShiftGenerator_CountWorkdays(t *testing.T) {
+func TestShiftGenerator_GenerateShifts_ShiftRequirements(t *testing.T) {
 	generator := NewShiftGenerator()
 
+	employees := []domain.Employee{
+		{ID: "emp1", Name: "John Doe", MonthlyHours: 160},
+		{ID: "emp2", Name: "Jane Smith", MonthlyHours: 160},
+		{ID: "emp3", Name: "Bob Johnson", MonthlyHours: 160},
+	}
+
+	config := &domain.CompanyConfig{
+		ShiftRequirements: []domain.ShiftRequirement{
+			{ShiftType: domain.ShiftTypeMorning, MinEmployees: 1, MaxEmployees: 1},
+			{ShiftType: domain.ShiftTypeEvening, MinEmployees: 3, MaxEmployees: 3},
+		},
+	}
+
+	// A single Monday
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	result := generator.GenerateShifts(context.Background(), employees, config, day, day, nil)
+
+	perShift := make(map[string]int)
+	perEmployee := make(map[string]int)
+	for _, assignment := range result.Assignments {
+		perShift[assignment.ShiftType]++
+		perEmployee[assignment.EmployeeID]++
+
+		def := domain.GetShiftDefinition(assignment.ShiftType)
+		if assignment.StartTime != def.StartTime || assignment.EndTime != def.EndTime || assignment.Hours != def.PaidHours() {
+			t.Errorf("Assignment %+v does not match shift definition %+v", assignment, def)
+		}
+	}
+
+	if perShift[domain.ShiftTypeMorning] != 1 {
+		t.Errorf("Morning shifts = %d, want 1", perShift[domain.ShiftTypeMorning])
+	}
+	if perShift[domain.ShiftTypeEvening] != 2 {
+		t.Errorf("Evening shifts = %d, want 2", perShift[domain.ShiftTypeEvening])
+	}
+	for empID, count := range perEmployee {
+		if count > 1 {
+			t.Errorf("Employee %s has %d shifts on one day, want at most 1", empID, count)
+		}
+	}
+
+	if len(result.Understaffed) != 1 {
+		t.Fatalf("Understaffed = %d entries, want 1", len(result.Understaffed))
+	}
+	gap := result.Understaffed[0]
+	if gap.ShiftType != domain.ShiftTypeEvening || gap.Required != 3 || gap.Assigned != 2 {
+		t.Errorf("Understaffed = %+v, want evening with 2 of 3", gap)
+	}
+}
+
+func TestShiftGenerator_GenerateShifts_CustomShiftDefinitions(t *testing.T) {
+	generator := NewShiftGenerator()
+
+	employees := []domain.Employee{
+		{ID: "emp1", Name: "John Doe", MonthlyHours: 160},
+		{ID: "emp2", Name: "Jane Smith", MonthlyHours: 160},
+	}
+
+	config := &domain.CompanyConfig{
+		ShiftDefinitions: []domain.ShiftDefinition{
+			{Type: "early", Name: "Early", StartTime: "06:30", EndTime: "14:30", UnpaidBreakMinutes: 30},
+			{Type: "lunch", Name: "Lunch", StartTime: "10:00", EndTime: "14:00"},
+		},
+		ShiftRequirements: []domain.ShiftRequirement{
+			{ShiftType: "early", MinEmployees: 1, MaxEmployees: 1},
+			{ShiftType: "lunch", MinEmployees: 1, MaxEmployees: 1},
+			{ShiftType: domain.ShiftTypeMorning, MinEmployees: 1, MaxEmployees: 1},
+		},
+	}
+
+	// A single Monday
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	result := generator.GenerateShifts(context.Background(), employees, config, day, day, nil)
+
+	wantHours := map[string]float64{"early": 7.5, "lunch": 4}
+	for _, assignment := range result.Assignments {
+		want, ok := wantHours[assignment.ShiftType]
+		if !ok {
+			t.Errorf("Assigned shift type %q that the company does not define", assignment.ShiftType)
+			continue
+		}
+		if assignment.Hours != want {
+			t.Errorf("%s shift hours = %.1f, want %.1f", assignment.ShiftType, assignment.Hours, want)
+		}
+		delete(wantHours, assignment.ShiftType)
+	}
+	if len(wantHours) > 0 {
+		t.Errorf("Shifts not assigned: %v", wantHours)
+	}
+}
+
+func TestNewProblem_WorkingDays(t *testing.T) {
+	tuesdayToSaturday := &domain.CompanyConfig{WorkingHours: domain.WorkingHours{WorkingDays: []int{2, 3, 4, 5, 6}}}
+	auckland := &domain.CompanyConfig{WorkingHours: domain.WorkingHours{Timezone: "Pacific/Auckland"}}
+
 	tests := []struct {
 		name     string
+		config   *domain.CompanyConfig
 		start    time.Time
 		end      time.Time
-		expected int
+		expected []time.Weekday
 	}{
 		{
 			name:     "one week monday to friday",
 			start:    time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),  // Monday
 			end:      time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), // Friday
-			expected: 5,
-		},
-		{
-			name:     "two weeks",
-			start:    time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),  // Monday
-			end:      time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC), // Friday
-			expected: 10,
+			expected: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
 		},
 		{
 			name:     "including weekend",
 			start:    time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),  // Monday
 			end:      time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC), // Sunday
-			expected: 5, // Should only count Mon-Fri
+			expected: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		},
+		{
+			name:     "mid-day start counts the whole day",
+			start:    time.Date(2025, 1, 6, 14, 30, 0, 0, time.UTC), // Monday afternoon
+			end:      time.Date(2025, 1, 7, 9, 0, 0, 0, time.UTC),   // Tuesday morning
+			expected: []time.Weekday{time.Monday, time.Tuesday},
+		},
+		{
+			name:     "configured working days",
+			config:   tuesdayToSaturday,
+			start:    time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),  // Sunday
+			end:      time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC), // Saturday
+			expected: []time.Weekday{time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+		},
+		{
+			name:     "days are calendar days in the company timezone",
+			config:   auckland,
+			start:    time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC), // Monday 01:00 in Auckland
+			end:      time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC), // Tuesday 01:00 in Auckland
+			expected: []time.Weekday{time.Monday, time.Tuesday},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := generator.countWorkdays(tt.start, tt.end)
-			if result != tt.expected {
-				t.Errorf("countWorkdays() = %d, want %d", result, tt.expected)
+			p := newProblem(nil, tt.config, tt.start, tt.end, nil)
+			var got []time.Weekday
+			for _, day := range p.days {
+				if h, m, _ := day.Clock(); h != 0 || m != 0 || day.Location() != p.loc {
+					t.Errorf("Day %v is not local midnight in %s", day, p.loc)
+				}
+				got = append(got, day.Weekday())
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Working days = %v, want %v", got, tt.expected)
 			}
 		})
 	}
 }
 
+func TestShiftGenerator_GenerateShifts_WorkingDaysAndTimezone(t *testing.T) {
+	generator := NewShiftGenerator()
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	employees := []domain.Employee{
+		{ID: "emp1", Name: "John Doe", MonthlyHours: 160},
+		{ID: "emp2", Name: "Jane Smith", MonthlyHours: 160},
+	}
+
+	config := &domain.CompanyConfig{
+		WorkingHours: domain.WorkingHours{
+			WorkingDays: []int{0, 2, 3, 4, 5, 6}, // Tuesday to Sunday
+			Timezone:    "Europe/Oslo",
+		},
+		ShiftRequirements: []domain.ShiftRequirement{
+			{ShiftType: domain.ShiftTypeNight, MinEmployees: 1, MaxEmployees: 1},
+		},
+	}
+
+	// Monday 2025-03-24 to Sunday 2025-03-30 (DST starts in Oslo on Sunday at 02:00).
+	// The period start is given in UTC late on Sunday, which is already Monday in Oslo.
+	start := time.Date(2025, 3, 23, 23, 30, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 30, 0, 0, 0, 0, oslo)
+
+	result := generator.GenerateShifts(context.Background(), employees, config, start, end, nil)
+
+	if len(result.Assignments) != 6 {
+		t.Fatalf("Assignments = %d, want 6 (Tuesday to Sunday)", len(result.Assignments))
+	}
+
+	for _, assignment := range result.Assignments {
+		if assignment.Date.Location().String() != oslo.String() {
+			t.Errorf("Assignment date %v is not in company timezone", assignment.Date)
+		}
+		if assignment.Date.Weekday() == time.Monday {
+			t.Errorf("Assignment scheduled on Monday, which is not a working day")
+		}
+		if h, m, _ := assignment.Date.Clock(); h != 0 || m != 0 {
+			t.Errorf("Assignment date %v is not local midnight", assignment.Date)
+		}
+
+		want := 8.0
+		if assignment.Date.Weekday() == time.Saturday {
+			want = 7.0 // 21:00 Saturday to 05:00 Sunday loses an hour to DST
+		}
+		if assignment.Hours != want {
+			t.Errorf("%s night shift hours = %.1f, want %.1f", assignment.Date.Weekday(), assignment.Hours, want)
+		}
+	}
+}
+
+func TestShiftGenerator_GenerateShifts_SchedulingPolicies(t *testing.T) {
+	generator := NewShiftGenerator()
+
+	monday := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	friday := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("max consecutive days", func(t *testing.T) {
+		employees := []domain.Employee{{ID: "emp1", Name: "John Doe", MonthlyHours: 160}}
+		config := &domain.CompanyConfig{
+			ShiftRequirements: []domain.ShiftRequirement{
+				{ShiftType: domain.ShiftTypeMorning, MinEmployees: 0, MaxEmployees: 1},
+			},
+			SchedulingPolicies: domain.SchedulingPolicies{MaxConsecutiveDays: 3, AllowOvertime: true, MaxOvertimeHours: 40},
+		}
+
+		result := generator.GenerateShifts(context.Background(), employees, config, monday, friday, nil)
+
+		if len(result.Assignments) != 4 {
+			t.Fatalf("Assignments = %d, want 4 (Mon-Wed, rest Thu, Fri)", len(result.Assignments))
+		}
+		for _, assignment := range result.Assignments {
+			if assignment.Date.Weekday() == time.Thursday {
+				t.Error("Employee scheduled on a fourth consecutive day")
+			}
+		}
+		if len(result.RelaxedConstraints) != 0 {
+			t.Errorf("RelaxedConstraints = %v, want none", result.RelaxedConstraints)
+		}
+	})
+
+	t.Run("min rest hours", func(t *testing.T) {
+		employees := []domain.Employee{
+			{ID: "emp1", Name: "John Doe", MonthlyHours: 160},
+			{ID: "emp2", Name: "Jane Smith", MonthlyHours: 160},
+		}
+		config := &domain.CompanyConfig{
+			ShiftRequirements: []domain.ShiftRequirement{
+				{ShiftType: domain.ShiftTypeMorning, MinEmployees: 1, MaxEmployees: 1},
+				{ShiftType: domain.ShiftTypeNight, MinEmployees: 0, MaxEmployees: 1},
+			},
+			SchedulingPolicies: domain.SchedulingPolicies{MinRestHours: 11, AllowOvertime: true, MaxOvertimeHours: 40},
+		}
+
+		result := generator.GenerateShifts(context.Background(), employees, config, monday, friday, nil)
+
+		nightBefore := make(map[string]bool)
+		for _, assignment := range result.Assignments {
+			if assignment.ShiftType == domain.ShiftTypeNight {
+				nightBefore[assignment.EmployeeID+assignment.Date.AddDate(0, 0, 1).Format("2006-01-02")] = true
+			}
+		}
+		for _, assignment := range result.Assignments {
+			if assignment.ShiftType == domain.ShiftTypeMorning && nightBefore[assignment.EmployeeID+assignment.Date.Format("2006-01-02")] {
+				t.Errorf("%s works a morning shift on %s right after a night shift", assignment.EmployeeID, assignment.Date.Format("Jan 2"))
+			}
+		}
+		if len(result.RelaxedConstraints) != 0 {
+			t.Errorf("RelaxedConstraints = %v, want none", result.RelaxedConstraints)
+		}
+	})
+
+	t.Run("earlier schedules carry over", func(t *testing.T) {
+		employees := []domain.Employee{{ID: "emp1", Name: "John Doe", MonthlyHours: 160}}
+		config := &domain.CompanyConfig{
+			WorkingHours: domain.WorkingHours{WorkingDays: []int{0, 1, 2, 3, 4, 5, 6}},
+			ShiftRequirements: []domain.ShiftRequirement{
+				{ShiftType: domain.ShiftTypeMorning, MinEmployees: 0, MaxEmployees: 1},
+			},
+			SchedulingPolicies: domain.SchedulingPolicies{MaxConsecutiveDays: 3, MinRestHours: 11, AllowOvertime: true, MaxOvertimeHours: 40},
+		}
+		worked := func(day int, shiftType, start, end string) domain.ShiftAssignment {
+			return domain.ShiftAssignment{
+				EmployeeID: "emp1",
+				Date:       time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC),
+				ShiftType:  shiftType,
+				StartTime:  start,
+				EndTime:    end,
+			}
+		}
+
+		// Friday to Sunday of the week before: Monday would be a fourth day in a row
+		history := []domain.ShiftAssignment{
+			worked(3, domain.ShiftTypeMorning, "09:00", "13:00"),
+			worked(4, domain.ShiftTypeMorning, "09:00", "13:00"),
+			worked(5, domain.ShiftTypeMorning, "09:00", "13:00"),
+			worked(7, domain.ShiftTypeMorning, "09:00", "13:00"), // inside the period, ignored
+		}
+		result := generator.GenerateShifts(context.Background(), employees, config, monday, friday, history)
+		if len(result.Assignments) != 3 || result.Assignments[0].Date.Weekday() != time.Tuesday {
+			t.Errorf("Assignments = %+v, want Tue-Thu after resting Monday", result.Assignments)
+		}
+
+		// A Sunday night shift ends at 05:00 Monday, too late for Monday morning
+		history = []domain.ShiftAssignment{worked(5, domain.ShiftTypeNight, "21:00", "05:00")}
+		result = generator.GenerateShifts(context.Background(), employees, config, monday, monday, history)
+		if len(result.Assignments) != 0 {
+			t.Errorf("Assignments = %+v, want none within 11 hours of the night shift", result.Assignments)
+		}
+		if len(result.RelaxedConstraints) != 0 {
+			t.Errorf("RelaxedConstraints = %v, want none", result.RelaxedConstraints)
+		}
+	})
+
+	t.Run("overtime relaxed only for minimum staffing", func(t *testing.T) {
+		// 30 monthly hours over 5 days is a 5 hour target, so a single 8 hour shift is already overtime
+		employees := []domain.Employee{{ID: "emp1", Name: "John Doe", MonthlyHours: 30}}
+		config := &domain.CompanyConfig{
+			ShiftRequirements: []domain.ShiftRequirement{
+				{ShiftType: domain.ShiftTypeFullDay, MinEmployees: 1, MaxEmployees: 1},
+			},
+			SchedulingPolicies: domain.SchedulingPolicies{AllowOvertime: false},
+		}
+
+		result := generator.GenerateShifts(context.Background(), employees, config, monday, friday, nil)
+
+		if len(result.Assignments) != 5 {
+			t.Fatalf("Assignments = %d, want 5", len(result.Assignments))
+		}
+		if len(result.RelaxedConstraints) != 5 {
+			t.Fatalf("RelaxedConstraints = %d, want 5", len(result.RelaxedConstraints))
+		}
+		for _, relaxed := range result.RelaxedConstraints {
+			if relaxed.Constraint != domain.ConstraintOvertime {
+				t.Errorf("Relaxed constraint = %s, want %s", relaxed.Constraint, domain.ConstraintOvertime)
+			}
+		}
+
+		config.ShiftRequirements[0].MinEmployees = 0
+		result = generator.GenerateShifts(context.Background(), employees, config, monday, friday, nil)
+		if len(result.Assignments) != 0 {
+			t.Errorf("Assignments = %d, want 0 when overtime is not needed for coverage", len(result.Assignments))
+		}
+	})
+}
+
+func TestShiftGenerator_GenerateShifts_RequiredSkills(t *testing.T) {
+	generator := NewShiftGenerator()
+
+	expired := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	employees := []domain.Employee{
+		{ID: "emp1", Name: "John Doe", MonthlyHours: 160},
+		{ID: "emp2", Name: "Jane Smith", MonthlyHours: 40, Skills: []domain.Skill{{Name: "first-aid"}}},
+		{ID: "emp3", Name: "Bob Johnson", MonthlyHours: 160, Skills: []domain.Skill{{Name: "bartender", ExpiresAt: &expired}}},
+	}
+
+	config := &domain.CompanyConfig{
+		ShiftRequirements: []domain.ShiftRequirement{
+			{ShiftType: domain.ShiftTypeEvening, MinEmployees: 2, MaxEmployees: 2, RequiredSkills: []string{"First-Aid", "Bartender"}},
+		},
+		SchedulingPolicies: domain.SchedulingPolicies{AllowOvertime: true, MaxOvertimeHours: 100},
+	}
+
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	result := generator.GenerateShifts(context.Background(), employees, config, day, day, nil)
+
+	hasFirstAid := false
+	for _, assignment := range result.Assignments {
+		if assignment.EmployeeID == "emp2" {
+			hasFirstAid = true
+		}
+	}
+	if !hasFirstAid {
+		t.Error("Evening shift has no first-aid qualified employee")
+	}
+	if len(result.Assignments) != 2 {
+		t.Errorf("Assignments = %d, want 2", len(result.Assignments))
+	}
+
+	if len(result.Understaffed) != 1 {
+		t.Fatalf("Understaffed = %d entries, want 1", len(result.Understaffed))
+	}
+	if missing := result.Understaffed[0].MissingSkills; len(missing) != 1 || missing[0] != "bartender" {
+		t.Errorf("MissingSkills = %v, want [bartender] since the certification has expired", missing)
+	}
+}
+
+func TestLocalSearchSolver_GenerateShifts(t *testing.T) {
+	solver := NewLocalSearchSolver()
+	greedy := NewShiftGenerator()
+
+	employees := []domain.Employee{
+		{ID: "emp1", Name: "John Doe", MonthlyHours: 160},
+		{ID: "emp2", Name: "Jane Smith", MonthlyHours: 80, Skills: []domain.Skill{{Name: "first-aid"}}},
+		{ID: "emp3", Name: "Bob Johnson", MonthlyHours: 120, Availability: []domain.Availability{
+			{
+				StartDate: time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+				Type:      domain.AvailabilityTypeUnavailable,
+			},
+		}},
+		{ID: "emp4", Name: "Alice Brown", MonthlyHours: 100},
+	}
+
+	config := &domain.CompanyConfig{
+		ShiftRequirements: []domain.ShiftRequirement{
+			{ShiftType: domain.ShiftTypeMorning, MinEmployees: 1, MaxEmployees: 2},
+			{ShiftType: domain.ShiftTypeEvening, MinEmployees: 1, MaxEmployees: 2, RequiredSkills: []string{"first-aid"}},
+		},
+		SchedulingPolicies: domain.SchedulingPolicies{
+			MaxConsecutiveDays: 5,
+			MinRestHours:       11,
+			AllowOvertime:      true,
+			MaxOvertimeHours:   20,
+			FairDistribution:   true,
+		},
+	}
+
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)
+
+	baseline := greedy.GenerateShifts(context.Background(), employees, config, start, end, nil)
+	result := solver.GenerateShifts(context.Background(), employees, config, start, end, nil)
+
+	if result.Score.Objective > baseline.Score.Objective {
+		t.Errorf("Local search objective %.1f is worse than greedy %.1f", result.Score.Objective, baseline.Score.Objective)
+	}
+
+	perDay := make(map[string]map[string]int)
+	for _, assignment := range result.Assignments {
+		key := assignment.Date.Format("2006-01-02")
+		if perDay[key] == nil {
+			perDay[key] = make(map[string]int)
+		}
+		perDay[key][assignment.EmployeeID]++
+		if perDay[key][assignment.EmployeeID] > 1 {
+			t.Errorf("%s has more than one shift on %s", assignment.EmployeeID, key)
+		}
+		shiftStart, shiftEnd := domain.ShiftDefinition{StartTime: assignment.StartTime, EndTime: assignment.EndTime}.Span(assignment.Date, assignment.Date.Location())
+		if assignment.EmployeeID == "emp3" && !employees[2].IsAvailableOn(assignment.ShiftType, shiftStart, shiftEnd) {
+			t.Errorf("emp3 scheduled on %s while unavailable", key)
+		}
+	}
+
+	again := solver.GenerateShifts(context.Background(), employees, config, start, end, nil)
+	if again.Score != result.Score || len(again.Assignments) != len(result.Assignments) {
+		t.Error("Local search is not deterministic for the same input")
+	}
+}
+
 func TestShiftGenerator_GetEmployeeStats(t *testing.T) {
 	generator := NewShiftGenerator()
 
@@ -192,10 +609,82 @@ func TestGetShiftDefinition(t *testing.T) {
 				if result.Type != tt.shiftType {
 					t.Errorf("ShiftType = %s, want %s", result.Type, tt.shiftType)
 				}
-				if result.Hours <= 0 {
+				if result.PaidHours() <= 0 {
 					t.Error("Hours should be > 0")
 				}
 			}
 		})
 	}
 }
+
+// largeProblem returns a month of four shifts a day for 80 employees, some of
+// them skilled, under every scheduling policy
+func largeProblem() ([]domain.Employee, *domain.CompanyConfig, time.Time, time.Time) {
+	employees := make([]domain.Employee, 80)
+	for i := range employees {
+		employees[i] = domain.Employee{ID: fmt.Sprintf("emp%d", i), Name: fmt.Sprintf("Employee %d", i), MonthlyHours: 80 + i%5*20}
+		if i%4 == 0 {
+			employees[i].Skills = []domain.Skill{{Name: "first-aid"}}
+		}
+	}
+	config := &domain.CompanyConfig{
+		WorkingHours: domain.WorkingHours{WorkingDays: []int{0, 1, 2, 3, 4, 5, 6}},
+		ShiftRequirements: []domain.ShiftRequirement{
+			{ShiftType: domain.ShiftTypeMorning, MinEmployees: 8, MaxEmployees: 12, RequiredSkills: []string{"first-aid"}},
+			{ShiftType: domain.ShiftTypeAfternoon, MinEmployees: 8, MaxEmployees: 12},
+			{ShiftType: domain.ShiftTypeEvening, MinEmployees: 6, MaxEmployees: 10, RequiredSkills: []string{"first-aid"}},
+			{ShiftType: domain.ShiftTypeNight, MinEmployees: 4, MaxEmployees: 6},
+		},
+		SchedulingPolicies: domain.SchedulingPolicies{
+			MaxConsecutiveDays: 5,
+			MinRestHours:       11,
+			AllowOvertime:      true,
+			MaxOvertimeHours:   20,
+			FairDistribution:   true,
+		},
+	}
+	return employees, config, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+}
+
+func TestLocalSearchSolver_IncrementalScore(t *testing.T) {
+	employees, config, start, end := largeProblem()
+	solver := NewLocalSearchSolver()
+	p := newProblem(employees, config, start, end, nil)
+	sol := p.solutionFrom(NewShiftGenerator().GenerateShifts(context.Background(), employees, config, start, end, nil).Assignments)
+	tally := p.newTally(sol)
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		m := solver.randomMove(p, sol, rng)
+		if m == nil {
+			continue
+		}
+		tally.update(m.day, m.shifts, m.employees)
+		if i%2 == 0 {
+			m.undo()
+			tally.update(m.day, m.shifts, m.employees)
+		}
+
+		if got, want := tally.score(DefaultObjectiveWeights), p.score(sol, DefaultObjectiveWeights); got != want {
+			t.Fatalf("After move %d tallied score = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestLocalSearchSolver_StopsWhenCancelled(t *testing.T) {
+	employees, config, start, end := largeProblem()
+	solver := NewLocalSearchSolver()
+	solver.iterations = math.MaxInt32
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	began := time.Now()
+	result := solver.GenerateShifts(ctx, employees, config, start, end, nil)
+	if elapsed := time.Since(began); elapsed > 5*time.Second {
+		t.Errorf("GenerateShifts() took %v after the context was done", elapsed)
+	}
+	if len(result.Assignments) == 0 {
+		t.Error("Assignments are empty, want the best schedule found before the deadline")
+	}
+}
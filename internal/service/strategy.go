@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// Strategy is a scheduling algorithm that turns employees and the company
+// configuration into shift assignments for a period. history holds the shifts
+// staff already work in other schedules, so policies such as maximum consecutive
+// days continue across the start of the period. Strategies that search for
+// longer stop when ctx is done and return the best result found so far.
+type Strategy interface {
+	GenerateShifts(ctx context.Context, employees []domain.Employee, config *domain.CompanyConfig, periodStart, periodEnd time.Time, history []domain.ShiftAssignment) GenerationResult
+}
+
+// GenerationResult holds the outcome of a shift generation run
+type GenerationResult struct {
+	Assignments        []domain.ShiftAssignment
+	Understaffed       []domain.UnderstaffedShift
+	RelaxedConstraints []domain.RelaxedConstraint
+	Score              domain.ScheduleScore // evaluated with DefaultObjectiveWeights so strategies can be compared
+}
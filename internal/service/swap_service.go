@@ -0,0 +1,607 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+	"github.com/isak/restySched/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// notificationLimit is how many recent notifications an employee sees
+const notificationLimit = 20
+
+// SwapService runs the marketplace where employees offer their published
+// shifts to colleagues, and applies approved swaps to the schedule
+type SwapService struct {
+	swapRepo         repository.ShiftSwapRepository
+	scheduleRepo     repository.ScheduleRepository
+	employeeRepo     repository.EmployeeRepository
+	companyRepo      repository.CompanyConfigRepository
+	notificationRepo repository.NotificationRepository
+	audit            *AuditService
+	now              func() time.Time
+}
+
+// NewSwapService creates a new swap service. Approved swaps are recorded in
+// the audit trail of their schedule.
+func NewSwapService(
+	swapRepo repository.ShiftSwapRepository,
+	scheduleRepo repository.ScheduleRepository,
+	employeeRepo repository.EmployeeRepository,
+	companyRepo repository.CompanyConfigRepository,
+	notificationRepo repository.NotificationRepository,
+	audit *AuditService,
+) *SwapService {
+	return &SwapService{
+		swapRepo:         swapRepo,
+		scheduleRepo:     scheduleRepo,
+		employeeRepo:     employeeRepo,
+		companyRepo:      companyRepo,
+		notificationRepo: notificationRepo,
+		audit:            audit,
+		now:              time.Now,
+	}
+}
+
+// OpenOffer is an open offer as seen by an employee who might claim it
+type OpenOffer struct {
+	domain.ShiftSwap
+	Eligible     bool                     `json:"eligible"`
+	Reason       string                   `json:"reason,omitempty"`        // why the employee cannot claim it
+	ReturnShifts []domain.ShiftAssignment `json:"return_shifts,omitempty"` // for swaps, the employee's shifts they could give in return
+}
+
+// OfferShift puts one of the employee's upcoming published shifts on offer
+func (s *SwapService) OfferShift(ctx context.Context, employeeID, scheduleID, assignmentID, kind, note string) (*domain.ShiftSwap, error) {
+	if kind != domain.ShiftSwapKindSwap && kind != domain.ShiftSwapKindGiveaway {
+		return nil, domain.ErrInvalidShiftSwapKind
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	shift, ok := s.upcomingShift(schedule, assignmentID, employeeID)
+	if !ok {
+		return nil, domain.ErrShiftNotSwappable
+	}
+	if err := s.checkNotOnOffer(ctx, assignmentID); err != nil {
+		return nil, err
+	}
+
+	swap := &domain.ShiftSwap{
+		ScheduleID: schedule.ID,
+		Kind:       kind,
+		Status:     domain.ShiftSwapStatusOpen,
+		Shift:      shift,
+		Note:       domain.SanitizeShiftSwapNote(note),
+	}
+	if err := s.swapRepo.Create(ctx, swap); err != nil {
+		return nil, fmt.Errorf("failed to create shift swap: %w", err)
+	}
+
+	return swap, nil
+}
+
+// OpenOffers lists the open offers of other employees. For an employee it
+// also tells whether they could claim each one; pass an empty employeeID to
+// list the offers without checking.
+func (s *SwapService) OpenOffers(ctx context.Context, employeeID string) ([]OpenOffer, error) {
+	swaps, err := s.swapRepo.GetByStatus(ctx, domain.ShiftSwapStatusOpen)
+	if err != nil {
+		return nil, err
+	}
+
+	offers := []OpenOffer{}
+	if employeeID == "" {
+		for _, swap := range swaps {
+			offers = append(offers, OpenOffer{ShiftSwap: swap})
+		}
+		return offers, nil
+	}
+
+	claimant, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	companyConfig, current, schedules, err := s.loadContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*domain.Schedule, len(schedules))
+	for i := range schedules {
+		byID[schedules[i].ID] = &schedules[i]
+	}
+	for i := range swaps {
+		swap := &swaps[i]
+		if swap.OfferedBy() == employeeID {
+			continue
+		}
+
+		schedule := byID[swap.ScheduleID]
+		if schedule == nil {
+			continue
+		}
+
+		offer := OpenOffer{ShiftSwap: *swap}
+		if swap.Kind == domain.ShiftSwapKindGiveaway {
+			err = s.checkEligible(schedule, companyConfig, current, schedules, swap, *claimant, nil)
+			offer.Eligible = err == nil
+		} else {
+			err = domain.ErrShiftSwapReturnNeeded
+			for _, a := range schedule.Assignments {
+				if _, ok := s.upcomingShift(schedule, a.ID, employeeID); !ok {
+					continue
+				}
+				returnShift := a
+				if checkErr := s.checkEligible(schedule, companyConfig, current, schedules, swap, *claimant, &returnShift); checkErr != nil {
+					err = checkErr
+					continue
+				}
+				offer.ReturnShifts = append(offer.ReturnShifts, a)
+			}
+			offer.Eligible = len(offer.ReturnShifts) > 0
+		}
+		if !offer.Eligible {
+			offer.Reason = err.Error()
+		}
+		offers = append(offers, offer)
+	}
+
+	return offers, nil
+}
+
+// ClaimShift claims an open offer for an employee who is available for the
+// shift, holds the skills it needs and stays within the scheduling policies.
+// A swap also needs one of the employee's own upcoming shifts in the same
+// schedule, which the offering employee takes over in return.
+func (s *SwapService) ClaimShift(ctx context.Context, swapID, employeeID, returnAssignmentID string) (*domain.ShiftSwap, error) {
+	swap, err := s.swapRepo.GetByID(ctx, swapID)
+	if err != nil {
+		return nil, err
+	}
+	if swap.Status != domain.ShiftSwapStatusOpen {
+		return nil, domain.ErrShiftSwapNotOpen
+	}
+	if swap.OfferedBy() == employeeID {
+		return nil, fmt.Errorf("%w: you offered this shift", domain.ErrShiftSwapNotEligible)
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(ctx, swap.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.upcomingShift(schedule, swap.Shift.ID, swap.OfferedBy()); !ok {
+		return nil, domain.ErrShiftSwapOutdated
+	}
+
+	var returnShift *domain.ShiftAssignment
+	if swap.Kind == domain.ShiftSwapKindSwap {
+		shift, ok := s.upcomingShift(schedule, returnAssignmentID, employeeID)
+		if !ok {
+			return nil, domain.ErrShiftSwapReturnNeeded
+		}
+		if err := s.checkNotOnOffer(ctx, returnAssignmentID); err != nil {
+			return nil, err
+		}
+		returnShift = &shift
+	}
+
+	claimant, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimant.Active {
+		return nil, domain.ErrEmployeeNotFound
+	}
+
+	companyConfig, current, schedules, err := s.loadContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEligible(schedule, companyConfig, current, schedules, swap, *claimant, returnShift); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	swap.Status = domain.ShiftSwapStatusClaimed
+	swap.ClaimedBy = claimant.ID
+	swap.ClaimantName = claimant.Name
+	swap.ReturnShift = returnShift
+	swap.ClaimedAt = &now
+	if err := s.swapRepo.Update(ctx, swap); err != nil {
+		return nil, fmt.Errorf("failed to update shift swap: %w", err)
+	}
+
+	s.notify(ctx, swap.OfferedBy(), fmt.Sprintf("%s claimed your %s. A manager will review the %s.",
+		claimant.Name, describeShift(schedule, swap.Shift), swap.Kind))
+
+	return swap, nil
+}
+
+// CancelOffer withdraws an offer that has not been decided yet. Only the
+// employee who offered the shift can cancel it.
+func (s *SwapService) CancelOffer(ctx context.Context, swapID, employeeID string) (*domain.ShiftSwap, error) {
+	swap, err := s.swapRepo.GetByID(ctx, swapID)
+	if err != nil {
+		return nil, err
+	}
+	if swap.OfferedBy() != employeeID {
+		return nil, domain.ErrNotOfferingEmployee
+	}
+	if !swap.Active() {
+		return nil, domain.ErrShiftSwapNotOpen
+	}
+
+	claimedBy := swap.ClaimedBy
+	swap.Status = domain.ShiftSwapStatusCancelled
+	if err := s.swapRepo.Update(ctx, swap); err != nil {
+		return nil, fmt.Errorf("failed to update shift swap: %w", err)
+	}
+
+	if claimedBy != "" {
+		if schedule, err := s.scheduleRepo.GetByID(ctx, swap.ScheduleID); err == nil {
+			s.notify(ctx, claimedBy, fmt.Sprintf("The offer of the %s you claimed was withdrawn.", describeShift(schedule, swap.Shift)))
+		}
+	}
+
+	return swap, nil
+}
+
+// ApproveSwap applies a claimed offer to its published schedule, re-checking
+// that both employees still work the shifts and that the claimant is still
+// eligible. If the offer cannot be marked approved the schedule change is
+// undone. Both employees are notified.
+func (s *SwapService) ApproveSwap(ctx context.Context, swapID, actor string) (*domain.ShiftSwap, error) {
+	swap, err := s.swapRepo.GetByID(ctx, swapID)
+	if err != nil {
+		return nil, err
+	}
+	if swap.Status != domain.ShiftSwapStatusClaimed {
+		return nil, domain.ErrShiftSwapNotClaimed
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(ctx, swap.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.upcomingShift(schedule, swap.Shift.ID, swap.OfferedBy()); !ok {
+		return nil, domain.ErrShiftSwapOutdated
+	}
+	if swap.ReturnShift != nil {
+		if _, ok := s.upcomingShift(schedule, swap.ReturnShift.ID, swap.ClaimedBy); !ok {
+			return nil, domain.ErrShiftSwapOutdated
+		}
+	}
+
+	offerer, err := s.employeeRepo.GetByID(ctx, swap.OfferedBy())
+	if err != nil {
+		return nil, err
+	}
+	claimant, err := s.employeeRepo.GetByID(ctx, swap.ClaimedBy)
+	if err != nil {
+		return nil, err
+	}
+	if !claimant.Active {
+		return nil, domain.ErrEmployeeNotFound
+	}
+
+	companyConfig, current, schedules, err := s.loadContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEligible(schedule, companyConfig, current, schedules, swap, *claimant, swap.ReturnShift); err != nil {
+		return nil, err
+	}
+
+	before := snapshot(schedule)
+	if err := schedule.ApplySwap(swap, *offerer, *claimant); err != nil {
+		return nil, err
+	}
+	if err := s.scheduleRepo.Update(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to update schedule: %w", err)
+	}
+	if err := s.decide(ctx, swap, domain.ShiftSwapStatusApproved, actor); err != nil {
+		// The offer is still claimed, so the shifts go back to who held them
+		before.Version = schedule.Version
+		if restoreErr := s.scheduleRepo.Update(ctx, before); restoreErr != nil {
+			log.Error().Err(restoreErr).Str("swap_id", swap.ID).Str("schedule_id", schedule.ID).Msg("Failed to undo swap of unapproved offer")
+		}
+		return nil, err
+	}
+	s.audit.Record(ctx, domain.AuditEntry{
+		Actor:      actor,
+		EntityType: domain.AuditEntitySchedule,
+		EntityID:   schedule.ID,
+		EntityName: domain.SchedulePeriodName(schedule),
+		Action:     domain.AuditActionApproveSwap,
+		Changes:    domain.DiffSchedules(before, schedule),
+	})
+
+	shift := describeShift(schedule, swap.Shift)
+	if swap.ReturnShift != nil {
+		returned := describeShift(schedule, *swap.ReturnShift)
+		s.notify(ctx, offerer.ID, fmt.Sprintf("Your swap with %s was approved: you now work the %s instead of the %s.", claimant.Name, returned, shift))
+		s.notify(ctx, claimant.ID, fmt.Sprintf("Your swap with %s was approved: you now work the %s instead of the %s.", offerer.Name, shift, returned))
+	} else {
+		s.notify(ctx, offerer.ID, fmt.Sprintf("%s takes over your %s.", claimant.Name, shift))
+		s.notify(ctx, claimant.ID, fmt.Sprintf("You now work the %s, taken over from %s.", shift, offerer.Name))
+	}
+
+	return swap, nil
+}
+
+// RejectSwap turns down a claimed offer. Both employees are notified.
+func (s *SwapService) RejectSwap(ctx context.Context, swapID, actor string) (*domain.ShiftSwap, error) {
+	swap, err := s.swapRepo.GetByID(ctx, swapID)
+	if err != nil {
+		return nil, err
+	}
+	if swap.Status != domain.ShiftSwapStatusClaimed {
+		return nil, domain.ErrShiftSwapNotClaimed
+	}
+
+	if err := s.decide(ctx, swap, domain.ShiftSwapStatusRejected, actor); err != nil {
+		return nil, err
+	}
+
+	if schedule, err := s.scheduleRepo.GetByID(ctx, swap.ScheduleID); err == nil {
+		message := fmt.Sprintf("The %s of the %s between %s and %s was rejected.",
+			swap.Kind, describeShift(schedule, swap.Shift), swap.Shift.EmployeeName, swap.ClaimantName)
+		s.notify(ctx, swap.OfferedBy(), message)
+		s.notify(ctx, swap.ClaimedBy, message)
+	}
+
+	return swap, nil
+}
+
+// GetSwap retrieves a shift swap by ID
+func (s *SwapService) GetSwap(ctx context.Context, id string) (*domain.ShiftSwap, error) {
+	return s.swapRepo.GetByID(ctx, id)
+}
+
+// PendingSwaps lists the claimed offers waiting for a manager's decision
+func (s *SwapService) PendingSwaps(ctx context.Context) ([]domain.ShiftSwap, error) {
+	return s.swapRepo.GetByStatus(ctx, domain.ShiftSwapStatusClaimed)
+}
+
+// EmployeeSwaps lists the open and claimed offers the employee made or claimed
+func (s *SwapService) EmployeeSwaps(ctx context.Context, employeeID string) ([]domain.ShiftSwap, error) {
+	swaps, err := s.swapRepo.GetByStatus(ctx, domain.ShiftSwapStatusOpen, domain.ShiftSwapStatusClaimed)
+	if err != nil {
+		return nil, err
+	}
+
+	mine := []domain.ShiftSwap{}
+	for _, swap := range swaps {
+		if swap.Involves(employeeID) {
+			mine = append(mine, swap)
+		}
+	}
+	return mine, nil
+}
+
+// ScheduleHistory lists every offer made in a schedule, oldest first
+func (s *SwapService) ScheduleHistory(ctx context.Context, scheduleID string) ([]domain.ShiftSwap, error) {
+	if _, err := s.scheduleRepo.GetByID(ctx, scheduleID); err != nil {
+		return nil, err
+	}
+
+	swaps, err := s.swapRepo.GetBySchedule(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if swaps == nil {
+		swaps = []domain.ShiftSwap{}
+	}
+	return swaps, nil
+}
+
+// Notifications returns an employee's recent notifications, newest first
+func (s *SwapService) Notifications(ctx context.Context, employeeID string) ([]domain.Notification, error) {
+	notifications, err := s.notificationRepo.GetByEmployee(ctx, employeeID, notificationLimit)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []domain.Notification{}
+	}
+	return notifications, nil
+}
+
+// MarkNotificationsRead marks all of an employee's notifications as read
+func (s *SwapService) MarkNotificationsRead(ctx context.Context, employeeID string) error {
+	return s.notificationRepo.MarkRead(ctx, employeeID)
+}
+
+func (s *SwapService) decide(ctx context.Context, swap *domain.ShiftSwap, status, actor string) error {
+	now := s.now()
+	swap.Status = status
+	swap.DecidedBy = actor
+	swap.DecidedAt = &now
+	if err := s.swapRepo.Update(ctx, swap); err != nil {
+		return fmt.Errorf("failed to update shift swap: %w", err)
+	}
+	return nil
+}
+
+// notify leaves a notification for an employee. Failing to store it does not
+// undo the change it reports.
+func (s *SwapService) notify(ctx context.Context, employeeID, message string) {
+	if err := s.notificationRepo.Create(ctx, &domain.Notification{EmployeeID: employeeID, Message: message}); err != nil {
+		log.Warn().Err(err).Str("employee_id", employeeID).Msg("Failed to store notification")
+	}
+}
+
+// upcomingShift returns the employee's assignment in a published schedule if
+// it has not started yet
+func (s *SwapService) upcomingShift(schedule *domain.Schedule, assignmentID, employeeID string) (domain.ShiftAssignment, bool) {
+	if assignmentID == "" || schedule.LifecycleStatus() != domain.ScheduleStatusPublished {
+		return domain.ShiftAssignment{}, false
+	}
+
+	i := schedule.FindAssignment(assignmentID)
+	if i < 0 || schedule.Assignments[i].EmployeeID != employeeID {
+		return domain.ShiftAssignment{}, false
+	}
+
+	a := schedule.Assignments[i]
+	start, _ := domain.ShiftDefinition{StartTime: a.StartTime, EndTime: a.EndTime}.Span(a.Date, schedule.Location())
+	if !start.After(s.now()) {
+		return domain.ShiftAssignment{}, false
+	}
+	return a, true
+}
+
+// checkNotOnOffer fails if an open or claimed offer already involves the assignment
+func (s *SwapService) checkNotOnOffer(ctx context.Context, assignmentID string) error {
+	active, err := s.swapRepo.GetByStatus(ctx, domain.ShiftSwapStatusOpen, domain.ShiftSwapStatusClaimed)
+	if err != nil {
+		return err
+	}
+	for _, swap := range active {
+		if swap.Shift.ID == assignmentID || (swap.ReturnShift != nil && swap.ReturnShift.ID == assignmentID) {
+			return domain.ErrShiftSwapExists
+		}
+	}
+	return nil
+}
+
+// loadContext loads the company configuration, the current employee records and
+// the schedules eligibility is checked against
+func (s *SwapService) loadContext(ctx context.Context) (*domain.CompanyConfig, map[string]domain.Employee, []domain.Schedule, error) {
+	companyConfig, err := s.companyRepo.GetOrCreate(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load company configuration: %w", err)
+	}
+
+	employees, err := s.employeeRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get employees: %w", err)
+	}
+
+	schedules, err := s.scheduleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get schedules: %w", err)
+	}
+
+	current := make(map[string]domain.Employee, len(employees))
+	for _, emp := range employees {
+		current[emp.ID] = emp
+	}
+	return companyConfig, current, schedules, nil
+}
+
+// checkEligible checks the schedule as it would be with the offered shift
+// worked by the claimant and, for a swap, the return shift worked by the
+// offering employee. The change must not double-book anyone, give anyone a
+// shift they are unavailable for, leave a shift short of a skill, or break a
+// scheduling policy for either employee that the schedule kept before.
+func (s *SwapService) checkEligible(
+	schedule *domain.Schedule,
+	companyConfig *domain.CompanyConfig,
+	current map[string]domain.Employee,
+	schedules []domain.Schedule,
+	swap *domain.ShiftSwap,
+	claimant domain.Employee,
+	returnShift *domain.ShiftAssignment,
+) error {
+	employees := make([]domain.Employee, 0, len(schedule.Employees)+1)
+	scheduled := false
+	for _, emp := range schedule.Employees {
+		if fresh, ok := current[emp.ID]; ok {
+			emp = fresh
+		}
+		scheduled = scheduled || emp.ID == claimant.ID
+		employees = append(employees, emp)
+	}
+	if !scheduled {
+		employees = append(employees, claimant)
+	}
+	p := newProblem(employees, companyConfig, schedule.PeriodStart, schedule.PeriodEnd, publishedShifts(schedules, schedule.ID))
+
+	offerer := swap.OfferedBy()
+	after := append([]domain.ShiftAssignment{}, schedule.Assignments...)
+	changed := make([]domain.ShiftAssignment, 0, 2)
+	for i := range after {
+		switch {
+		case after[i].ID == swap.Shift.ID:
+			after[i].EmployeeID = claimant.ID
+			changed = append(changed, after[i])
+		case returnShift != nil && after[i].ID == returnShift.ID:
+			after[i].EmployeeID = offerer
+			changed = append(changed, after[i])
+		}
+	}
+
+	working := make(map[string]bool)
+	for _, a := range after {
+		key := a.EmployeeID + "/" + a.Date.In(p.loc).Format("2006-01-02")
+		if working[key] {
+			return fmt.Errorf("%w: it would mean two shifts on one day", domain.ErrShiftSwapNotEligible)
+		}
+		working[key] = true
+	}
+
+	for _, a := range changed {
+		d, r, ok := p.locate(a.Date, a.ShiftType)
+		e := p.employeeIndex(a.EmployeeID)
+		if ok && e >= 0 && !p.available[d][r][e] {
+			return fmt.Errorf("%w: %s is not available for the %s", domain.ErrShiftSwapNotEligible, p.employees[e].Name, describeShift(schedule, a))
+		}
+	}
+
+	understaffedBefore, relaxedBefore := p.findings(p.solutionFrom(schedule.Assignments))
+	understaffedAfter, relaxedAfter := p.findings(p.solutionFrom(after))
+	if missingSkills(understaffedAfter) > missingSkills(understaffedBefore) {
+		return fmt.Errorf("%w: the shift needs a skill that would no longer be covered", domain.ErrShiftSwapNotEligible)
+	}
+
+	broken := make(map[string]bool)
+	for _, rc := range relaxedBefore {
+		broken[rc.EmployeeID+"/"+rc.Constraint] = true
+	}
+	for _, rc := range relaxedAfter {
+		if (rc.EmployeeID == claimant.ID || rc.EmployeeID == offerer) && !broken[rc.EmployeeID+"/"+rc.Constraint] {
+			return fmt.Errorf("%w: %s would break the %s rule", domain.ErrShiftSwapNotEligible, rc.EmployeeName, constraintDescription(rc.Constraint))
+		}
+	}
+
+	return nil
+}
+
+// missingSkills counts the skills missing across understaffed shifts
+func missingSkills(understaffed []domain.UnderstaffedShift) int {
+	n := 0
+	for _, u := range understaffed {
+		n += len(u.MissingSkills)
+	}
+	return n
+}
+
+func constraintDescription(constraint string) string {
+	switch constraint {
+	case domain.ConstraintOvertime:
+		return "overtime"
+	case domain.ConstraintMaxConsecutiveDays:
+		return "maximum consecutive days"
+	case domain.ConstraintMinRestHours:
+		return "minimum rest"
+	default:
+		return constraint
+	}
+}
+
+// describeShift names a shift for messages, such as "Morning shift on Mon Jan 6"
+func describeShift(schedule *domain.Schedule, a domain.ShiftAssignment) string {
+	name := a.ShiftType
+	if def := schedule.ShiftDefinition(a.ShiftType); def != nil {
+		name = def.DisplayName()
+	}
+	return fmt.Sprintf("%s shift on %s", name, a.Date.In(schedule.Location()).Format("Mon Jan 2"))
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/isak/restySched/internal/domain"
+)
+
+// MockShiftSwapRepository is a mock implementation of ShiftSwapRepository for testing
+type MockShiftSwapRepository struct {
+	swaps []*domain.ShiftSwap
+}
+
+func (m *MockShiftSwapRepository) Create(ctx context.Context, swap *domain.ShiftSwap) error {
+	swap.ID = fmt.Sprintf("mock-swap-%d", len(m.swaps)+1)
+	clone := *swap
+	m.swaps = append(m.swaps, &clone)
+	return nil
+}
+
+func (m *MockShiftSwapRepository) GetByID(ctx context.Context, id string) (*domain.ShiftSwap, error) {
+	for _, swap := range m.swaps {
+		if swap.ID == id {
+			clone := *swap
+			return &clone, nil
+		}
+	}
+	return nil, domain.ErrShiftSwapNotFound
+}
+
+func (m *MockShiftSwapRepository) GetBySchedule(ctx context.Context, scheduleID string) ([]domain.ShiftSwap, error) {
+	var swaps []domain.ShiftSwap
+	for _, swap := range m.swaps {
+		if swap.ScheduleID == scheduleID {
+			swaps = append(swaps, *swap)
+		}
+	}
+	return swaps, nil
+}
+
+func (m *MockShiftSwapRepository) GetByStatus(ctx context.Context, statuses ...string) ([]domain.ShiftSwap, error) {
+	var swaps []domain.ShiftSwap
+	for _, swap := range m.swaps {
+		if slices.Contains(statuses, swap.Status) {
+			swaps = append(swaps, *swap)
+		}
+	}
+	return swaps, nil
+}
+
+func (m *MockShiftSwapRepository) Update(ctx context.Context, swap *domain.ShiftSwap) error {
+	for i, existing := range m.swaps {
+		if existing.ID == swap.ID {
+			clone := *swap
+			m.swaps[i] = &clone
+			return nil
+		}
+	}
+	return domain.ErrShiftSwapNotFound
+}
+
+// MockNotificationRepository is a mock implementation of NotificationRepository for testing
+type MockNotificationRepository struct {
+	notifications []domain.Notification
+}
+
+func (m *MockNotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	m.notifications = append(m.notifications, *notification)
+	return nil
+}
+
+func (m *MockNotificationRepository) GetByEmployee(ctx context.Context, employeeID string, limit int) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+	for i := len(m.notifications) - 1; i >= 0 && len(notifications) < limit; i-- {
+		if m.notifications[i].EmployeeID == employeeID {
+			notifications = append(notifications, m.notifications[i])
+		}
+	}
+	return notifications, nil
+}
+
+func (m *MockNotificationRepository) MarkRead(ctx context.Context, employeeID string) error {
+	now := time.Now()
+	for i := range m.notifications {
+		if m.notifications[i].EmployeeID == employeeID && m.notifications[i].ReadAt == nil {
+			m.notifications[i].ReadAt = &now
+		}
+	}
+	return nil
+}
+
+// newSwapMarketplace publishes the schedule from newEditableSchedule with an
+// extra Monday evening shift for emp2, and returns a swap service whose clock
+// is set before the schedule starts
+func newSwapMarketplace(t *testing.T) (*SwapService, *MockScheduleRepository, *MockNotificationRepository, *domain.Schedule) {
+	t.Helper()
+	ctx := context.Background()
+
+	scheduleService, scheduleRepo, schedule := newEditableSchedule(t)
+	schedule.Assignments = append(schedule.Assignments, domain.ShiftAssignment{
+		ID:           "b0",
+		EmployeeID:   "emp2",
+		EmployeeName: "Jane Smith",
+		Date:         schedule.PeriodStart,
+		ShiftType:    domain.ShiftTypeEvening,
+		StartTime:    "17:00",
+		EndTime:      "21:00",
+		Hours:        4,
+	})
+	if err := scheduleRepo.Update(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scheduleService.ApproveSchedule(ctx, schedule.ID, 0, "manager"); err != nil {
+		t.Fatal(err)
+	}
+	schedule, err := scheduleService.PublishSchedule(ctx, schedule.ID, 0, "manager", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notifications := &MockNotificationRepository{}
+	service := NewSwapService(&MockShiftSwapRepository{}, scheduleRepo, scheduleService.employeeRepo, scheduleService.companyRepo, notifications, scheduleService.audit)
+	service.now = func() time.Time { return time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC) }
+
+	return service, scheduleRepo, notifications, schedule
+}
+
+func TestSwapService_Giveaway(t *testing.T) {
+	ctx := context.Background()
+	service, scheduleRepo, notifications, schedule := newSwapMarketplace(t)
+
+	swap, err := service.OfferShift(ctx, "emp1", schedule.ID, "a1", domain.ShiftSwapKindGiveaway, "  dentist  ")
+	if err != nil {
+		t.Fatalf("OfferShift() error = %v", err)
+	}
+	if swap.Status != domain.ShiftSwapStatusOpen || swap.Note != "dentist" || swap.OfferedBy() != "emp1" {
+		t.Errorf("Offer = %+v", swap)
+	}
+	if _, err := service.OfferShift(ctx, "emp1", schedule.ID, "a1", domain.ShiftSwapKindGiveaway, ""); !errors.Is(err, domain.ErrShiftSwapExists) {
+		t.Errorf("OfferShift() twice error = %v, want %v", err, domain.ErrShiftSwapExists)
+	}
+
+	offers, err := service.OpenOffers(ctx, "emp2")
+	if err != nil {
+		t.Fatalf("OpenOffers() error = %v", err)
+	}
+	if len(offers) != 1 || !offers[0].Eligible {
+		t.Fatalf("OpenOffers() = %+v, want one eligible offer", offers)
+	}
+	if offers, _ := service.OpenOffers(ctx, "emp1"); len(offers) != 0 {
+		t.Errorf("OpenOffers() for the offering employee = %+v, want none", offers)
+	}
+
+	if _, err := service.ApproveSwap(ctx, swap.ID, "manager"); !errors.Is(err, domain.ErrShiftSwapNotClaimed) {
+		t.Errorf("ApproveSwap() before claim error = %v, want %v", err, domain.ErrShiftSwapNotClaimed)
+	}
+	if _, err := service.ClaimShift(ctx, swap.ID, "emp2", ""); err != nil {
+		t.Fatalf("ClaimShift() error = %v", err)
+	}
+
+	approved, err := service.ApproveSwap(ctx, swap.ID, "manager")
+	if err != nil {
+		t.Fatalf("ApproveSwap() error = %v", err)
+	}
+	if approved.Status != domain.ShiftSwapStatusApproved || approved.DecidedBy != "manager" || approved.DecidedAt == nil {
+		t.Errorf("Approved swap = %+v", approved)
+	}
+
+	saved := scheduleRepo.schedules[schedule.ID]
+	if a := saved.Assignments[saved.FindAssignment("a1")]; a.EmployeeID != "emp2" || a.EmployeeName != "Jane Smith" {
+		t.Errorf("Assignment after giveaway = %+v, want emp2", a)
+	}
+	if len(saved.PublishedAssignments) == 0 || saved.PublishedAssignments[saved.FindAssignment("a1")].EmployeeID != "emp2" {
+		t.Error("Giveaway was not published to staff")
+	}
+
+	for _, id := range []string{"emp1", "emp2"} {
+		if got, _ := notifications.GetByEmployee(ctx, id, notificationLimit); len(got) == 0 {
+			t.Errorf("%s was not notified", id)
+		}
+	}
+
+	history, err := service.ScheduleHistory(ctx, schedule.ID)
+	if err != nil || len(history) != 1 || history[0].Status != domain.ShiftSwapStatusApproved {
+		t.Errorf("ScheduleHistory() = %+v, %v", history, err)
+	}
+}
+
+func TestSwapService_Swap(t *testing.T) {
+	ctx := context.Background()
+	service, scheduleRepo, _, schedule := newSwapMarketplace(t)
+
+	swap, err := service.OfferShift(ctx, "emp1", schedule.ID, "a0", domain.ShiftSwapKindSwap, "")
+	if err != nil {
+		t.Fatalf("OfferShift() error = %v", err)
+	}
+
+	offers, _ := service.OpenOffers(ctx, "emp2")
+	if len(offers) != 1 || len(offers[0].ReturnShifts) != 1 || offers[0].ReturnShifts[0].ID != "b0" {
+		t.Fatalf("OpenOffers() = %+v, want b0 as the return shift", offers)
+	}
+
+	if _, err := service.ClaimShift(ctx, swap.ID, "emp2", ""); !errors.Is(err, domain.ErrShiftSwapReturnNeeded) {
+		t.Errorf("ClaimShift() without return shift error = %v, want %v", err, domain.ErrShiftSwapReturnNeeded)
+	}
+	claimed, err := service.ClaimShift(ctx, swap.ID, "emp2", "b0")
+	if err != nil {
+		t.Fatalf("ClaimShift() error = %v", err)
+	}
+	if claimed.Status != domain.ShiftSwapStatusClaimed || claimed.ClaimantName != "Jane Smith" || claimed.ReturnShift == nil {
+		t.Errorf("Claimed swap = %+v", claimed)
+	}
+
+	if _, err := service.ApproveSwap(ctx, swap.ID, "manager"); err != nil {
+		t.Fatalf("ApproveSwap() error = %v", err)
+	}
+
+	saved := scheduleRepo.schedules[schedule.ID]
+	morning := saved.Assignments[saved.FindAssignment("a0")]
+	evening := saved.Assignments[saved.FindAssignment("b0")]
+	if morning.EmployeeID != "emp2" || evening.EmployeeID != "emp1" {
+		t.Errorf("Assignments after swap: a0 = %s, b0 = %s", morning.EmployeeID, evening.EmployeeID)
+	}
+}
+
+func TestSwapService_Rules(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unavailable employees cannot claim", func(t *testing.T) {
+		service, _, _, schedule := newSwapMarketplace(t)
+
+		swap, err := service.OfferShift(ctx, "emp1", schedule.ID, "a2", domain.ShiftSwapKindGiveaway, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		offers, _ := service.OpenOffers(ctx, "emp2")
+		if len(offers) != 1 || offers[0].Eligible || offers[0].Reason == "" {
+			t.Errorf("OpenOffers() = %+v, want an ineligible offer with a reason", offers)
+		}
+		if _, err := service.ClaimShift(ctx, swap.ID, "emp2", ""); !errors.Is(err, domain.ErrShiftSwapNotEligible) {
+			t.Errorf("ClaimShift() error = %v, want %v", err, domain.ErrShiftSwapNotEligible)
+		}
+	})
+
+	t.Run("only own upcoming published shifts can be offered", func(t *testing.T) {
+		service, scheduleRepo, _, schedule := newSwapMarketplace(t)
+
+		if _, err := service.OfferShift(ctx, "emp2", schedule.ID, "a1", domain.ShiftSwapKindGiveaway, ""); !errors.Is(err, domain.ErrShiftNotSwappable) {
+			t.Errorf("OfferShift() of a colleague's shift error = %v, want %v", err, domain.ErrShiftNotSwappable)
+		}
+		if _, err := service.OfferShift(ctx, "emp1", schedule.ID, "a1", "trade", ""); !errors.Is(err, domain.ErrInvalidShiftSwapKind) {
+			t.Errorf("OfferShift() with unknown kind error = %v, want %v", err, domain.ErrInvalidShiftSwapKind)
+		}
+
+		service.now = func() time.Time { return time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC) }
+		if _, err := service.OfferShift(ctx, "emp1", schedule.ID, "a1", domain.ShiftSwapKindGiveaway, ""); !errors.Is(err, domain.ErrShiftNotSwappable) {
+			t.Errorf("OfferShift() of a past shift error = %v, want %v", err, domain.ErrShiftNotSwappable)
+		}
+
+		schedule.Status = domain.ScheduleStatusDraft
+		if err := scheduleRepo.Update(ctx, schedule); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := service.OfferShift(ctx, "emp1", schedule.ID, "a4", domain.ShiftSwapKindGiveaway, ""); !errors.Is(err, domain.ErrShiftNotSwappable) {
+			t.Errorf("OfferShift() in a draft error = %v, want %v", err, domain.ErrShiftNotSwappable)
+		}
+	})
+
+	t.Run("only the offering employee can cancel", func(t *testing.T) {
+		service, _, notifications, schedule := newSwapMarketplace(t)
+
+		swap, err := service.OfferShift(ctx, "emp1", schedule.ID, "a1", domain.ShiftSwapKindGiveaway, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := service.ClaimShift(ctx, swap.ID, "emp2", ""); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := service.CancelOffer(ctx, swap.ID, "emp2"); !errors.Is(err, domain.ErrNotOfferingEmployee) {
+			t.Errorf("CancelOffer() by claimant error = %v, want %v", err, domain.ErrNotOfferingEmployee)
+		}
+		cancelled, err := service.CancelOffer(ctx, swap.ID, "emp1")
+		if err != nil || cancelled.Status != domain.ShiftSwapStatusCancelled {
+			t.Fatalf("CancelOffer() = %+v, %v", cancelled, err)
+		}
+		if got, _ := notifications.GetByEmployee(ctx, "emp2", notificationLimit); len(got) != 1 {
+			t.Errorf("Claimant notifications = %+v, want the withdrawal", got)
+		}
+		if _, err := service.ClaimShift(ctx, swap.ID, "emp2", ""); !errors.Is(err, domain.ErrShiftSwapNotOpen) {
+			t.Errorf("ClaimShift() after cancel error = %v, want %v", err, domain.ErrShiftSwapNotOpen)
+		}
+	})
+
+	t.Run("approval fails when the shift changed hands", func(t *testing.T) {
+		service, scheduleRepo, _, schedule := newSwapMarketplace(t)
+
+		swap, err := service.OfferShift(ctx, "emp1", schedule.ID, "a1", domain.ShiftSwapKindGiveaway, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := service.ClaimShift(ctx, swap.ID, "emp2", ""); err != nil {
+			t.Fatal(err)
+		}
+
+		saved := scheduleRepo.schedules[schedule.ID]
+		saved.Assignments[saved.FindAssignment("a1")].EmployeeID = "emp3"
+		if _, err := service.ApproveSwap(ctx, swap.ID, "manager"); !errors.Is(err, domain.ErrShiftSwapOutdated) {
+			t.Errorf("ApproveSwap() error = %v, want %v", err, domain.ErrShiftSwapOutdated)
+		}
+
+		rejected, err := service.RejectSwap(ctx, swap.ID, "manager")
+		if err != nil || rejected.Status != domain.ShiftSwapStatusRejected {
+			t.Errorf("RejectSwap() = %+v, %v", rejected, err)
+		}
+	})
+
+	t.Run("failed approval puts the shift back", func(t *testing.T) {
+		service, scheduleRepo, notifications, schedule := newSwapMarketplace(t)
+		swapRepo := &failingUpdateSwapRepository{}
+		service.swapRepo = swapRepo
+
+		swap, err := service.OfferShift(ctx, "emp1", schedule.ID, "a1", domain.ShiftSwapKindGiveaway, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := service.ClaimShift(ctx, swap.ID, "emp2", ""); err != nil {
+			t.Fatal(err)
+		}
+		sent := len(notifications.notifications)
+
+		swapRepo.err = errors.New("database unavailable")
+		if _, err := service.ApproveSwap(ctx, swap.ID, "manager"); err == nil {
+			t.Fatal("ApproveSwap() error = nil, want the update error")
+		}
+		saved := scheduleRepo.schedules[schedule.ID]
+		if holder := saved.Assignments[saved.FindAssignment("a1")].EmployeeID; holder != "emp1" {
+			t.Errorf("Shift holder = %s, want emp1 after the failed approval", holder)
+		}
+		if stored, _ := swapRepo.GetByID(ctx, swap.ID); stored.Status != domain.ShiftSwapStatusClaimed {
+			t.Errorf("Swap status = %s, want %s", stored.Status, domain.ShiftSwapStatusClaimed)
+		}
+		if len(notifications.notifications) != sent {
+			t.Errorf("Notifications = %+v, want none for the failed approval", notifications.notifications[sent:])
+		}
+
+		swapRepo.err = nil
+		if _, err := service.ApproveSwap(ctx, swap.ID, "manager"); err != nil {
+			t.Errorf("ApproveSwap() retry error = %v", err)
+		}
+	})
+}
+
+// failingUpdateSwapRepository fails every update while err is set
+type failingUpdateSwapRepository struct {
+	MockShiftSwapRepository
+	err error
+}
+
+func (m *failingUpdateSwapRepository) Update(ctx context.Context, swap *domain.ShiftSwap) error {
+	if m.err != nil {
+		return m.err
+	}
+	return m.MockShiftSwapRepository.Update(ctx, swap)
+}
@@ -0,0 +1,67 @@
+// Package storage opens the storage backend chosen in the configuration, so
+// that the rest of the application only sees the repository interfaces.
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/isak/restySched/internal/config"
+	"github.com/isak/restySched/internal/repository"
+	"github.com/isak/restySched/internal/repository/memory"
+	"github.com/isak/restySched/internal/repository/mongodb"
+	"github.com/isak/restySched/internal/repository/sqlstore"
+	"github.com/rs/zerolog/log"
+)
+
+// Storage is an open storage backend
+type Storage struct {
+	*repository.Repositories
+
+	close func(ctx context.Context) error
+}
+
+// Open connects to the backend chosen by STORAGE_DRIVER, preparing its
+// schema or indexes, and creates its repositories
+func Open(ctx context.Context, cfg *config.Config) (*Storage, error) {
+	switch cfg.StorageDriver {
+	case config.StorageDriverMongoDB:
+		db, err := mongodb.InitDB(cfg.MongoURI, cfg.MongoDatabase)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Info().Str("database", cfg.MongoDatabase).Msg("Connected to MongoDB")
+		return &Storage{
+			Repositories: mongodb.NewRepositories(db),
+			close:        db.Client().Disconnect,
+		}, nil
+
+	case config.StorageDriverSQLite, config.StorageDriverPostgres:
+		db, err := sqlstore.Open(ctx, cfg.StorageDriver, cfg.DatabaseURL)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Info().Str("driver", cfg.StorageDriver).Msg("Connected to the SQL database")
+		return &Storage{
+			Repositories: sqlstore.NewRepositories(db),
+			close:        func(context.Context) error { return db.Close() },
+		}, nil
+
+	case config.StorageDriverMemory:
+		log.Warn().Msg("Using in-memory storage; all data is lost when the server stops")
+		return &Storage{
+			Repositories: memory.NewRepositories(memory.NewDB()),
+			close:        func(context.Context) error { return nil },
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
+
+// Close disconnects from the backend
+func (s *Storage) Close(ctx context.Context) error {
+	return s.close(ctx)
+}